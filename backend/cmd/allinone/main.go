@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"backend/internal/adapter/http/handler"
+	"backend/internal/app"
+	"backend/internal/config"
+)
+
+// 停止指示を受けてから HTTP サーバーの処理中リクエストを待つ上限
+const shutdownTimeout = 10 * time.Second
+
+/**
+ * API とワーカーを 1 プロセスで起動し、停止指示が来るまで動かし続ける。
+ */
+func main() {
+	config.LoadDotEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		log.Fatalf("all-in-one 起動失敗: %v", err)
+	}
+}
+
+/**
+ * 共有依存を初期化し、HTTP サーバーと整形プールを並行して回す。
+ */
+func run(ctx context.Context) error {
+	container, err := app.NewAllInOneContainer(ctx)
+	if err != nil {
+		return fmt.Errorf("依存初期化失敗: %w", err)
+	}
+	defer func() {
+		if closeErr := container.Close(); closeErr != nil {
+			log.Printf("依存終了失敗: %v", closeErr)
+		}
+	}()
+
+	formatPool, err := app.NewFormatPool(container.Worker)
+	if err != nil {
+		return fmt.Errorf("ワーカープール初期化失敗: %w", err)
+	}
+
+	// どちらかが止まったらもう一方も止めるため、共通のキャンセルを用意する
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		log.Printf("worker started (pending format, workers=%d)", formatPool.Stats().Workers)
+		formatPool.Run(ctx)
+	}()
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", resolvePort()),
+		Handler: handler.NewRouter(container.API.DrawHandler, container.API.PostHandler, container.API.ClientIdentity, container.API.AdminHandler),
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		cancel()
+	}()
+
+	<-ctx.Done()
+
+	// 処理中のリクエストを捌き切ってから、整形プールの drain を待つ
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP サーバー停止失敗: %v", err)
+	}
+	wg.Wait()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("サーバー起動失敗: %w", err)
+	default:
+		return nil
+	}
+}
+
+/**
+ * Cloud Run が渡す PORT を優先し、未設定なら 8080 を使う。
+ */
+func resolvePort() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return port
+	}
+	return "8080"
+}
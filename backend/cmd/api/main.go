@@ -39,7 +39,7 @@ func run(ctx context.Context) error {
 	}()
 
 	// ルーティングを組み立てて、起動
-	router := drawhandler.NewRouter(container.DrawHandler, container.PostHandler)
+	router := drawhandler.NewRouter(container.DrawHandler, container.PostHandler, container.ClientIdentity, container.AdminHandler)
 	if err := router.Run(); err != nil {
 		return fmt.Errorf("サーバー起動失敗: %w", err)
 	}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"backend/internal/app"
+	"backend/internal/config"
+	"backend/internal/usecase/worker"
+)
+
+/**
+ * 取り残された整形待ち投稿を 1 回だけ突き合わせ、結果を標準出力へ書き出す。
+ * 修復に失敗した投稿があれば終了コード 1 で終わる。
+ */
+func main() {
+	os.Exit(run())
+}
+
+/**
+ * 後片付けを済ませてから終了コードを返せるよう、本体を main から切り離す。
+ */
+func run() int {
+	olderThan := flag.Duration("older-than", 15*time.Minute, "作成からこの時間を過ぎた pending / formatting 投稿だけを確認する")
+	limit := flag.Int("limit", 500, "1 回で確認する投稿の上限（0 なら無制限）")
+	dryRun := flag.Bool("dry-run", false, "再投入や状態の修復を行わず、行う予定の内容だけを表示する")
+	backfillDrawKeys := flag.Bool("backfill-draw-keys", false, "突き合わせの代わりに、抽選キーを持たない既存の draws へキーを振る")
+	flag.Parse()
+
+	config.LoadDotEnv()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	container, err := app.NewReconcileContainer(ctx)
+	if err != nil {
+		log.Printf("failed to initialize reconcile: %v", err)
+		return 1
+	}
+	defer func() {
+		if cerr := container.Close(); cerr != nil {
+			log.Printf("reconcile shutdown error: %v", cerr)
+		}
+	}()
+
+	if *backfillDrawKeys {
+		return backfill(ctx, container)
+	}
+
+	report, err := container.ReconcilePendingUsecase.Execute(ctx, worker.ReconcileInput{
+		OlderThan: *olderThan,
+		Limit:     *limit,
+		DryRun:    *dryRun,
+	})
+	if report != nil {
+		printReport(os.Stdout, report, *dryRun)
+	}
+	if err != nil {
+		log.Printf("reconcile error: %v", err)
+		return 1
+	}
+	if len(report.Failures) > 0 {
+		return 1
+	}
+	return 0
+}
+
+/**
+ * random_key 導入前の draws へ抽選キーを振り、更新件数を書き出す。
+ */
+func backfill(ctx context.Context, container *app.ReconcileContainer) int {
+	if container.DrawKeyBackfiller == nil {
+		log.Printf("draw repository does not store random keys")
+		return 1
+	}
+	updated, err := container.DrawKeyBackfiller.BackfillRandomKeys(ctx)
+	fmt.Fprintf(os.Stdout, "backfill-draw-keys: updated=%d\n", updated)
+	if err != nil {
+		log.Printf("backfill error: %v", err)
+		return 1
+	}
+	return 0
+}
+
+/**
+ * 確認件数と、再投入・修復・失敗した投稿を 1 行ずつ書き出す。
+ */
+func printReport(w io.Writer, report *worker.ReconcileReport, dryRun bool) {
+	mode := ""
+	if dryRun {
+		mode = " (dry-run)"
+	}
+	fmt.Fprintf(w, "reconcile%s: scanned=%d queued=%d requeued=%d repaired=%d failed=%d\n",
+		mode, report.Scanned, report.Queued, len(report.Requeued), len(report.Repaired), len(report.Failures))
+	for _, id := range report.Requeued {
+		fmt.Fprintf(w, "requeued\t%s\n", id)
+	}
+	for _, r := range report.Repaired {
+		fmt.Fprintf(w, "repaired\t%s\t%s\n", r.PostID, r.Status)
+	}
+	for _, f := range report.Failures {
+		fmt.Fprintf(w, "failed\t%s\t%v\n", f.PostID, f.Err)
+	}
+}
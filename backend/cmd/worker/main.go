@@ -2,23 +2,31 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"backend/internal/app"
 	"backend/internal/config"
-	"backend/internal/port/queue"
-	usecaseworker "backend/internal/usecase/worker"
 )
 
+// /healthz が返すワーカープールの稼働状況
+type healthResponse struct {
+	Status     string  `json:"status"`
+	Workers    int     `json:"workers"`
+	Busy       int     `json:"busy"`
+	Saturation float64 `json:"saturation"`
+}
+
 /**
- * 起動時にワーカーの依存を整えて停止指示が来るまでループを回す。
+ * 起動時にワーカーの依存を整えて停止指示が来るまでプールを回す。
  */
 func main() {
 	config.LoadDotEnv()
@@ -26,7 +34,9 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	startHealthServer(ctx)
+	// 依存の初期化より先にヘルスチェックへ応答できるよう、プールは後から差し込む
+	var pool atomic.Pointer[app.FormatPool]
+	startHealthServer(ctx, &pool)
 
 	container, err := app.NewWorkerContainer(ctx)
 	if err != nil {
@@ -38,14 +48,20 @@ func main() {
 		}
 	}()
 
-	log.Println("worker started (pending format)")
-	runLoop(ctx, container)
+	formatPool, err := app.NewFormatPool(container)
+	if err != nil {
+		log.Fatalf("failed to initialize worker pool: %v", err)
+	}
+	pool.Store(formatPool)
+
+	log.Printf("worker started (pending format, workers=%d)", formatPool.Stats().Workers)
+	formatPool.Run(ctx)
 }
 
 /**
- * Cloud Run のヘルスチェックに応答するHTTPサーバーを起動する。
+ * Cloud Run のヘルスチェックに応答し、プールの飽和度も返すHTTPサーバーを起動する。
  */
-func startHealthServer(ctx context.Context) {
+func startHealthServer(ctx context.Context, pool *atomic.Pointer[app.FormatPool]) {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
@@ -53,7 +69,19 @@ func startHealthServer(ctx context.Context) {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		resp := healthResponse{Status: "starting"}
+		if p := pool.Load(); p != nil {
+			stats := p.Stats()
+			resp = healthResponse{
+				Status:     "ok",
+				Workers:    stats.Workers,
+				Busy:       stats.Busy,
+				Saturation: stats.Saturation(),
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(resp)
 	})
 
 	srv := &http.Server{
@@ -74,50 +102,3 @@ func startHealthServer(ctx context.Context) {
 		}
 	}()
 }
-
-/**
- * 取り出した投稿を順に整形し、終了指示や取り出し失敗を監視しながら回し続ける。
- */
-func runLoop(ctx context.Context, container *app.WorkerContainer) {
-	for {
-		select {
-		case <-ctx.Done():
-			log.Printf("worker shutting down: %v", ctx.Err())
-			return
-		default:
-		}
-
-		postID, err := container.JobQueue.DequeueFormat(ctx)
-		if err != nil {
-			// 中断やキュー停止はそのまま終了する
-			if errors.Is(err, context.Canceled) ||
-				errors.Is(err, context.DeadlineExceeded) ||
-				errors.Is(err, queue.ErrQueueClosed) ||
-				errors.Is(err, queue.ErrContextClosed) {
-				return
-			}
-			// それ以外は短い待機後に再試行
-			log.Printf("dequeue error: %v", err)
-			time.Sleep(500 * time.Millisecond)
-			continue
-		}
-
-		// ジョブを処理し、失敗内容ごとにログの粒度を変える
-		if err := container.FormatPendingUsecase.Execute(ctx, string(postID)); err != nil {
-			switch {
-			// draw 保存に失敗したが再キュー済みのケース
-			case errors.Is(err, usecaseworker.ErrDrawCreationFailed):
-				log.Printf("draw creation failed (post=%s): %v (requeued)", postID, err)
-				// 再キューやロールバック自体が失敗した致命的ケース
-			case errors.Is(err, usecaseworker.ErrRequeueFailed):
-				log.Printf("draw creation rollback failed (post=%s): %v", postID, err)
-			default:
-				// LLM や投稿の整形問題はログに残して次のジョブへ
-				log.Printf("format error (post=%s): %v", postID, err)
-			}
-			continue
-		}
-
-		log.Printf("formatted post: %s", postID)
-	}
-}
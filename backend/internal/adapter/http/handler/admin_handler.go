@@ -0,0 +1,466 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+	adminusecase "backend/internal/usecase/admin"
+	drawusecase "backend/internal/usecase/draw"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	messageAdminUnauthorized   = "unauthorized"
+	messageAdminInvalidRequest = "invalid admin request"
+	messageAdminNoOpenReports  = "no open reports for draw"
+	messageAdminConflict       = "post cannot be changed in its current status"
+	messageAdminStale          = "post was changed by another operation"
+	messageAdminNoFormatted    = "draw has no formatted result to publish"
+	messageAdminJobQueued      = "format job already queued"
+
+	// bearerPrefix は管理 API のトークンを渡す Authorization ヘッダーの接頭辞。
+	bearerPrefix = "Bearer "
+)
+
+// 通報が届いているおみくじの一覧ユースケースの契約。
+type ListReportedDrawsExecutor interface {
+	Execute(ctx context.Context, limit int) ([]drawusecase.ReportedDraw, error)
+}
+
+// 通報の確認ユースケースの契約。
+type ResolveReportsExecutor interface {
+	Execute(ctx context.Context, in *adminusecase.ResolveReportsInput) (*adminusecase.PostDetail, error)
+}
+
+// 投稿一覧ユースケースの契約。
+type ListPostsExecutor interface {
+	Execute(ctx context.Context, in *adminusecase.ListPostsInput) (*repository.PostPage, error)
+}
+
+// 投稿詳細ユースケースの契約。
+type GetAdminPostExecutor interface {
+	Execute(ctx context.Context, postID string) (*adminusecase.PostDetail, error)
+}
+
+// 投稿の経緯（監査ログ）ユースケースの契約。
+type GetPostTimelineExecutor interface {
+	Execute(ctx context.Context, postID string) ([]*audit.Event, error)
+}
+
+// 強制承認ユースケースの契約。
+type ApproveDrawExecutor interface {
+	Execute(ctx context.Context, postID string) (*adminusecase.PostDetail, error)
+}
+
+// 強制却下ユースケースの契約。
+type RejectDrawExecutor interface {
+	Execute(ctx context.Context, in *adminusecase.RejectDrawInput) (*adminusecase.PostDetail, error)
+}
+
+// 投稿 ID だけを受け取る運用操作（再投入・削除）の契約。
+type AdminPostActionExecutor interface {
+	Execute(ctx context.Context, postID string) error
+}
+
+// AdminUsecases は /admin 配下で使うユースケースをまとめる。
+type AdminUsecases struct {
+	ListReports    ListReportedDrawsExecutor
+	ResolveReports ResolveReportsExecutor
+	ListPosts      ListPostsExecutor
+	GetPost        GetAdminPostExecutor
+	PostTimeline   GetPostTimelineExecutor
+	ApproveDraw    ApproveDrawExecutor
+	RejectDraw     RejectDrawExecutor
+	RequeuePost    AdminPostActionExecutor
+	DeletePost     AdminPostActionExecutor
+}
+
+// AdminHandler は運用者向けの /admin 配下の HTTP ハンドラをまとめる。
+type AdminHandler struct {
+	token    []byte
+	usecases AdminUsecases
+}
+
+// NewAdminHandler は Bearer トークンと各ユースケースを受け取って AdminHandler を生成する。
+func NewAdminHandler(token string, usecases AdminUsecases) *AdminHandler {
+	return &AdminHandler{token: []byte(token), usecases: usecases}
+}
+
+// 通報が届いているおみくじ 1 件。draw が削除済みなら result と status は空。
+type ReportedDrawResponse struct {
+	PostID         string         `json:"post_id"`
+	Result         string         `json:"result"`
+	Status         string         `json:"status"`
+	Reason         string         `json:"rejection_reason,omitempty"`
+	OpenReports    int            `json:"open_reports"`
+	Reasons        map[string]int `json:"reasons"`
+	LastReportedAt time.Time      `json:"last_reported_at"`
+}
+
+// GET /admin/reports のレスポンス。
+type ReportedDrawsResponse struct {
+	Items []ReportedDrawResponse `json:"items"`
+}
+
+// 運用者向けの投稿 1 件。
+type AdminPostResponse struct {
+	PostID    string    `json:"post_id"`
+	Status    string    `json:"status"`
+	Content   string    `json:"content"`
+	AuthorID  string    `json:"author_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GET /admin/posts のレスポンス。続きが無ければ next_cursor は省く。
+type AdminPostsResponse struct {
+	Items      []AdminPostResponse `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// 運用者向けの整形ジョブの状況。失敗理由も含める。
+type AdminJobResponse struct {
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// 投稿詳細と運用操作のレスポンス。draw は整形前なら null、job はジョブが無ければ省く。
+type AdminPostDetailResponse struct {
+	AdminPostResponse
+	Draw *AdminDrawResponse `json:"draw"`
+	Job  *AdminJobResponse  `json:"job,omitempty"`
+}
+
+// 監査ログの操作者。worker と admin は id を省く。
+type AuditActorResponse struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id,omitempty"`
+}
+
+// 監査ログの出来事 1 件。
+type AuditEventResponse struct {
+	Action     string             `json:"action"`
+	Actor      AuditActorResponse `json:"actor"`
+	Details    map[string]string  `json:"details,omitempty"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+// GET /admin/posts/:post_id/timeline のレスポンス。events は古い順。
+type PostTimelineResponse struct {
+	PostID string               `json:"post_id"`
+	Events []AuditEventResponse `json:"events"`
+}
+
+// POST /admin/draws/:post_id/reject の入力。reason は省略できる。
+type AdminRejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// 運用者向けのおみくじ結果。通報の確認結果としても返す。生成元が不明なら provenance は省く。
+type AdminDrawResponse struct {
+	PostID     string                   `json:"post_id"`
+	Result     string                   `json:"result,omitempty"`
+	Status     string                   `json:"status"`
+	Reason     string                   `json:"rejection_reason,omitempty"`
+	Provenance *AdminProvenanceResponse `json:"provenance,omitempty"`
+}
+
+// おみくじ結果を生成した LLM の記録。
+type AdminProvenanceResponse struct {
+	Provider         string `json:"provider"`
+	Model            string `json:"model"`
+	PromptVersion    string `json:"prompt_version"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	LatencyMS        int64  `json:"latency_ms"`
+}
+
+// Authenticate は Authorization: Bearer <token> を定数時間で照合し、合わなければ 401 で止める。
+func (h *AdminHandler) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), bearerPrefix)
+		if !ok || len(h.token) == 0 || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), h.token) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse{Message: messageAdminUnauthorized})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListReports は未確認の通報が届いているおみくじを、最後に通報された順に返す。
+func (h *AdminHandler) ListReports(c *gin.Context) {
+	limit, ok := queryLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageAdminInvalidRequest})
+		return
+	}
+
+	items, err := h.usecases.ListReports.Execute(c.Request.Context(), limit)
+	if err != nil {
+		log.Printf("%s %s 失敗: %v", c.Request.Method, c.FullPath(), err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
+		return
+	}
+	res := ReportedDrawsResponse{Items: make([]ReportedDrawResponse, 0, len(items))}
+	for _, item := range items {
+		reasons := make(map[string]int, len(item.Summary.Reasons))
+		for reason, n := range item.Summary.Reasons {
+			reasons[string(reason)] = n
+		}
+		entry := ReportedDrawResponse{
+			PostID:         string(item.Summary.PostID),
+			OpenReports:    item.Summary.OpenCount,
+			Reasons:        reasons,
+			LastReportedAt: item.Summary.LastReportedAt,
+		}
+		if item.Draw != nil {
+			entry.Result = string(item.Draw.Result())
+			entry.Status = string(item.Draw.Status())
+			entry.Reason = item.Draw.Reason()
+		}
+		res.Items = append(res.Items, entry)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ConfirmReports は通報を認め、おみくじの公開を止めたままにする。
+func (h *AdminHandler) ConfirmReports(c *gin.Context) {
+	h.resolve(c, report.ResolutionConfirmed)
+}
+
+// RestoreReports は通報を退け、おみくじを公開へ戻す。
+func (h *AdminHandler) RestoreReports(c *gin.Context) {
+	h.resolve(c, report.ResolutionRestored)
+}
+
+func (h *AdminHandler) resolve(c *gin.Context, resolution report.Resolution) {
+	detail, err := h.usecases.ResolveReports.Execute(c.Request.Context(), &adminusecase.ResolveReportsInput{
+		PostID:     c.Param("post_id"),
+		Resolution: string(resolution),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, adminusecase.ErrInvalidResolution):
+			c.JSON(http.StatusBadRequest, errorResponse{Message: messageAdminInvalidRequest})
+		case errors.Is(err, adminusecase.ErrPostNotFound):
+			// 投稿が無ければおみくじも無いため、おみくじが見つからない扱いにそろえる
+			c.JSON(http.StatusNotFound, errorResponse{Message: messageDrawNotFound})
+		case errors.Is(err, adminusecase.ErrNoOpenReports):
+			c.JSON(http.StatusConflict, errorResponse{Message: messageAdminNoOpenReports})
+		default:
+			handleAdminPostError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toAdminDrawResponse(detail.Draw))
+}
+
+// ListPosts は ?status= の投稿を新しい順に返す。続きは ?cursor= に next_cursor を渡して取得する。
+func (h *AdminHandler) ListPosts(c *gin.Context) {
+	limit, ok := queryLimit(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageAdminInvalidRequest})
+		return
+	}
+	page, err := h.usecases.ListPosts.Execute(c.Request.Context(), &adminusecase.ListPostsInput{
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	res := AdminPostsResponse{Items: make([]AdminPostResponse, 0, len(page.Posts)), NextCursor: page.NextCursor}
+	for _, p := range page.Posts {
+		res.Items = append(res.Items, toAdminPostResponse(p))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetPost は投稿と、そのおみくじ結果・公開不可の理由・整形ジョブの状況を返す。
+func (h *AdminHandler) GetPost(c *gin.Context) {
+	detail, err := h.usecases.GetPost.Execute(c.Request.Context(), c.Param("post_id"))
+	if err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminPostDetailResponse(detail))
+}
+
+// GetPostTimeline は投稿の作成から現在までに起きた出来事を古い順に返す。
+func (h *AdminHandler) GetPostTimeline(c *gin.Context) {
+	postID := c.Param("post_id")
+	events, err := h.usecases.PostTimeline.Execute(c.Request.Context(), postID)
+	if err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	res := PostTimelineResponse{PostID: postID, Events: make([]AuditEventResponse, 0, len(events))}
+	for _, e := range events {
+		res.Events = append(res.Events, toAuditEventResponse(e))
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// ApproveDraw は判定を覆しておみくじ結果を公開する。
+func (h *AdminHandler) ApproveDraw(c *gin.Context) {
+	detail, err := h.usecases.ApproveDraw.Execute(c.Request.Context(), c.Param("post_id"))
+	if err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminPostDetailResponse(detail))
+}
+
+// RejectDraw は判定を覆しておみくじ結果を公開不可にする。本文は省略でき、理由も任意。
+func (h *AdminHandler) RejectDraw(c *gin.Context) {
+	var req AdminRejectRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse{Message: messageAdminInvalidRequest})
+			return
+		}
+	}
+	detail, err := h.usecases.RejectDraw.Execute(c.Request.Context(), &adminusecase.RejectDrawInput{
+		PostID: c.Param("post_id"),
+		Reason: req.Reason,
+	})
+	if err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, toAdminPostDetailResponse(detail))
+}
+
+// RequeuePost は整形ジョブを積み直し、受け付けたことだけを 202 で返す。
+func (h *AdminHandler) RequeuePost(c *gin.Context) {
+	h.postAction(c, h.usecases.RequeuePost, http.StatusAccepted)
+}
+
+// DeletePost は投稿を論理削除し、おみくじ結果を抽選から外す。
+func (h *AdminHandler) DeletePost(c *gin.Context) {
+	h.postAction(c, h.usecases.DeletePost, http.StatusNoContent)
+}
+
+func (h *AdminHandler) postAction(c *gin.Context, action AdminPostActionExecutor, code int) {
+	if err := action.Execute(c.Request.Context(), c.Param("post_id")); err != nil {
+		handleAdminPostError(c, err)
+		return
+	}
+	c.Status(code)
+}
+
+/**
+ * 投稿まわりの運用操作のエラーを HTTP ステータスとメッセージへ写し替える。
+ */
+func handleAdminPostError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, adminusecase.ErrNilInput),
+		errors.Is(err, adminusecase.ErrEmptyPostID),
+		errors.Is(err, adminusecase.ErrInvalidStatus),
+		errors.Is(err, adminusecase.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageAdminInvalidRequest})
+	case errors.Is(err, adminusecase.ErrPostNotFound):
+		c.JSON(http.StatusNotFound, errorResponse{Message: messagePostNotFound})
+	case errors.Is(err, adminusecase.ErrDrawNotFound):
+		c.JSON(http.StatusNotFound, errorResponse{Message: messageDrawNotFound})
+	case errors.Is(err, adminusecase.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, errorResponse{Message: messageAdminConflict})
+	case errors.Is(err, adminusecase.ErrNoFormattedResult):
+		c.JSON(http.StatusConflict, errorResponse{Message: messageAdminNoFormatted})
+	case errors.Is(err, adminusecase.ErrConflict):
+		c.JSON(http.StatusConflict, errorResponse{Message: messageAdminStale})
+	case errors.Is(err, adminusecase.ErrJobAlreadyQueued):
+		c.JSON(http.StatusConflict, errorResponse{Message: messageAdminJobQueued})
+	default:
+		log.Printf("%s %s 失敗: %v", c.Request.Method, c.FullPath(), err)
+		c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
+	}
+}
+
+// queryLimit は ?limit= を読み取る。未指定なら 0 を返し、負数や数値以外は ok=false。
+func queryLimit(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+func toAdminPostResponse(p *postdomain.Post) AdminPostResponse {
+	return AdminPostResponse{
+		PostID:    string(p.ID()),
+		Status:    string(p.Status()),
+		Content:   string(p.Content()),
+		AuthorID:  string(p.Author()),
+		CreatedAt: p.CreatedAt(),
+	}
+}
+
+func toAdminDrawResponse(d *drawdomain.Draw) AdminDrawResponse {
+	res := AdminDrawResponse{
+		PostID: string(d.PostID()),
+		Result: string(d.Result()),
+		Status: string(d.Status()),
+		Reason: d.Reason(),
+	}
+	if p := d.Provenance(); !p.IsZero() {
+		res.Provenance = &AdminProvenanceResponse{
+			Provider:         p.Provider,
+			Model:            p.Model,
+			PromptVersion:    p.PromptVersion,
+			PromptTokens:     p.PromptTokens,
+			CompletionTokens: p.CompletionTokens,
+			LatencyMS:        p.Latency.Milliseconds(),
+		}
+	}
+	return res
+}
+
+func toAuditEventResponse(e *audit.Event) AuditEventResponse {
+	actor := e.Actor()
+	return AuditEventResponse{
+		Action:     string(e.Action()),
+		Actor:      AuditActorResponse{Kind: string(actor.Kind), ID: actor.ID},
+		Details:    e.Details(),
+		OccurredAt: e.OccurredAt(),
+	}
+}
+
+func toAdminPostDetailResponse(detail *adminusecase.PostDetail) AdminPostDetailResponse {
+	res := AdminPostDetailResponse{AdminPostResponse: toAdminPostResponse(detail.Post)}
+	if detail.Draw != nil {
+		draw := toAdminDrawResponse(detail.Draw)
+		res.Draw = &draw
+	}
+	if job := detail.Job; job != nil {
+		res.Job = &AdminJobResponse{
+			State:     string(job.State),
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+		}
+		if !job.NextAttemptAt.IsZero() {
+			next := job.NextAttemptAt
+			res.Job.NextAttemptAt = &next
+		}
+	}
+	return res
+}
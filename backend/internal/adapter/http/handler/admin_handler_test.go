@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+	adminusecase "backend/internal/usecase/admin"
+	drawusecase "backend/internal/usecase/draw"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testAdminToken = "admin-token-for-tests-0123456789"
+
+func TestAdminHandler_Authenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newAdminRouter(AdminUsecases{ListReports: &stubListReportedDraws{}})
+
+	for _, header := range []string{"", "Bearer wrong", testAdminToken, "Basic " + testAdminToken} {
+		rec := performAdminRequest(router, http.MethodGet, "/admin/reports", header)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("header %q: expected status %d but got %d", header, http.StatusUnauthorized, rec.Code)
+		}
+	}
+	if rec := performAdminRequest(router, http.MethodGet, "/admin/reports", "Bearer "+testAdminToken); rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+
+	// トークンが無ければ /admin は登録されない
+	disabled := NewRouter(NewDrawHandler(&stubFortuneUsecase{}, nil, nil, nil), NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
+	if rec := performAdminRequest(disabled, http.MethodGet, "/admin/reports", "Bearer "+testAdminToken); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestAdminHandler_ListReports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	reported := newVerifiedDraw(t, "post-1", "fortune")
+	if err := reported.TakeDown("通報が 3 件に達したため公開を停止しました"); err != nil {
+		t.Fatalf("TakeDown() error = %v", err)
+	}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := &stubListReportedDraws{items: []drawusecase.ReportedDraw{
+		{Summary: repository.ReportSummary{PostID: "post-1", OpenCount: 3, Reasons: map[report.Reason]int{report.ReasonAbusive: 3}, LastReportedAt: at}, Draw: reported},
+		{Summary: repository.ReportSummary{PostID: "post-gone", OpenCount: 1}},
+	}}
+	router := newAdminRouter(AdminUsecases{ListReports: list})
+
+	rec := performAdminRequest(router, http.MethodGet, "/admin/reports?limit=5", "Bearer "+testAdminToken)
+	if rec.Code != http.StatusOK || list.limit != 5 {
+		t.Fatalf("unexpected status %d with limit %d", rec.Code, list.limit)
+	}
+	var got ReportedDrawsResponse
+	decodeBody(t, rec.Body, &got)
+	if len(got.Items) != 2 {
+		t.Fatalf("unexpected items: %+v", got.Items)
+	}
+	first := got.Items[0]
+	if first.PostID != "post-1" || first.Status != string(drawdomain.StatusRejected) || first.OpenReports != 3 || first.Reasons["abusive"] != 3 || !first.LastReportedAt.Equal(at) {
+		t.Fatalf("unexpected first item: %+v", first)
+	}
+	if got.Items[1].Status != "" || got.Items[1].Result != "" {
+		t.Fatalf("deleted draw should have no body: %+v", got.Items[1])
+	}
+
+	if rec := performAdminRequest(router, http.MethodGet, "/admin/reports?limit=x", "Bearer "+testAdminToken); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d but got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestAdminHandler_ResolveReports(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("confirm and restore pass the resolution", func(t *testing.T) {
+		resolve := &stubResolveReports{detail: &adminusecase.PostDetail{Draw: newVerifiedDraw(t, "post-1", "fortune")}}
+		router := newAdminRouter(AdminUsecases{ResolveReports: resolve})
+
+		for path, want := range map[string]report.Resolution{
+			"/admin/reports/post-1/confirm": report.ResolutionConfirmed,
+			"/admin/reports/post-1/restore": report.ResolutionRestored,
+		} {
+			rec := performAdminRequest(router, http.MethodPost, path, "Bearer "+testAdminToken)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: expected status %d but got %d", path, http.StatusOK, rec.Code)
+			}
+			if resolve.in == nil || resolve.in.PostID != "post-1" || resolve.in.Resolution != string(want) {
+				t.Fatalf("%s: unexpected input %+v", path, resolve.in)
+			}
+		}
+	})
+
+	t.Run("maps errors", func(t *testing.T) {
+		for err, code := range map[error]int{
+			adminusecase.ErrDrawNotFound:      http.StatusNotFound,
+			adminusecase.ErrPostNotFound:      http.StatusNotFound,
+			adminusecase.ErrNoOpenReports:     http.StatusConflict,
+			adminusecase.ErrInvalidTransition: http.StatusConflict,
+			adminusecase.ErrConflict:          http.StatusConflict,
+			adminusecase.ErrNoFormattedResult: http.StatusConflict,
+			adminusecase.ErrEmptyPostID:       http.StatusBadRequest,
+			errors.New("boom"):                http.StatusInternalServerError,
+		} {
+			router := newAdminRouter(AdminUsecases{ResolveReports: &stubResolveReports{err: err}})
+			rec := performAdminRequest(router, http.MethodPost, "/admin/reports/post-1/restore", "Bearer "+testAdminToken)
+			if rec.Code != code {
+				t.Fatalf("%v: expected status %d but got %d", err, code, rec.Code)
+			}
+		}
+	})
+}
+
+func TestAdminHandler_ListPosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, _ := postdomain.Restore("post-1", "闇", postdomain.StatusPending)
+	p.RestoreCreatedAt(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	list := &stubListPosts{page: &repository.PostPage{Posts: []*postdomain.Post{p}, NextCursor: "post-1"}}
+	router := newAdminRouter(AdminUsecases{ListPosts: list})
+
+	rec := performAdminRequest(router, http.MethodGet, "/admin/posts?status=pending&cursor=abc&limit=1", "Bearer "+testAdminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+	if list.in == nil || list.in.Status != "pending" || list.in.Cursor != "abc" || list.in.Limit != 1 {
+		t.Fatalf("unexpected input: %+v", list.in)
+	}
+	var got AdminPostsResponse
+	decodeBody(t, rec.Body, &got)
+	if len(got.Items) != 1 || got.Items[0].PostID != "post-1" || got.Items[0].Content != "闇" || got.NextCursor != "post-1" {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+
+	for err, code := range map[error]int{
+		adminusecase.ErrInvalidStatus: http.StatusBadRequest,
+		adminusecase.ErrInvalidCursor: http.StatusBadRequest,
+		errors.New("boom"):            http.StatusInternalServerError,
+	} {
+		router := newAdminRouter(AdminUsecases{ListPosts: &stubListPosts{err: err}})
+		if rec := performAdminRequest(router, http.MethodGet, "/admin/posts?status=x", "Bearer "+testAdminToken); rec.Code != code {
+			t.Fatalf("%v: expected status %d but got %d", err, code, rec.Code)
+		}
+	}
+}
+
+func TestAdminHandler_GetPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, _ := postdomain.Restore("post-1", "闇", postdomain.StatusRejected)
+	d, _ := drawdomain.New("post-1", "おみくじ")
+	d.MarkRejected("攻撃的な表現")
+	d.AttachProvenance(drawdomain.Provenance{Provider: "gemini", Model: "gemini-2.5-flash", PromptVersion: "v1", PromptTokens: 300, CompletionTokens: 80, Latency: 1500 * time.Millisecond})
+	detail := &adminusecase.PostDetail{Post: p, Draw: d, Job: &queue.FormatJobStatus{State: queue.FormatJobDead, Attempts: 5, LastError: "llm down"}}
+	router := newAdminRouter(AdminUsecases{GetPost: &stubAdminPostUsecase{detail: detail}})
+
+	rec := performAdminRequest(router, http.MethodGet, "/admin/posts/post-1", "Bearer "+testAdminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+	var got AdminPostDetailResponse
+	decodeBody(t, rec.Body, &got)
+	if got.PostID != "post-1" || got.Status != "rejected" || got.Draw == nil || got.Draw.Reason != "攻撃的な表現" || got.Job == nil || got.Job.LastError != "llm down" {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	if pv := got.Draw.Provenance; pv == nil || pv.Provider != "gemini" || pv.Model != "gemini-2.5-flash" || pv.PromptTokens != 300 || pv.LatencyMS != 1500 {
+		t.Fatalf("unexpected provenance: %+v", got.Draw.Provenance)
+	}
+
+	router = newAdminRouter(AdminUsecases{GetPost: &stubAdminPostUsecase{err: adminusecase.ErrPostNotFound}})
+	if rec := performAdminRequest(router, http.MethodGet, "/admin/posts/missing", "Bearer "+testAdminToken); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestAdminHandler_GetPostTimeline(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	created, _ := audit.New("post-1", audit.ActionPostCreated, audit.Client("client-1"), nil)
+	created.RestoreOccurredAt(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	rejected, _ := audit.New("post-1", audit.ActionDrawRejected, audit.Admin(), map[string]string{"reason": "不適切"})
+	rejected.RestoreOccurredAt(time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC))
+	timeline := &stubPostTimeline{events: []*audit.Event{created, rejected}}
+	router := newAdminRouter(AdminUsecases{PostTimeline: timeline})
+
+	rec := performAdminRequest(router, http.MethodGet, "/admin/posts/post-1/timeline", "Bearer "+testAdminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+	}
+	if timeline.postID != "post-1" {
+		t.Fatalf("unexpected post id %q", timeline.postID)
+	}
+	var got PostTimelineResponse
+	decodeBody(t, rec.Body, &got)
+	if got.PostID != "post-1" || len(got.Events) != 2 {
+		t.Fatalf("unexpected response: %+v", got)
+	}
+	if e := got.Events[0]; e.Action != "post.created" || e.Actor.Kind != "client" || e.Actor.ID != "client-1" {
+		t.Fatalf("unexpected first event: %+v", e)
+	}
+	if e := got.Events[1]; e.Action != "draw.rejected" || e.Actor.Kind != "admin" || e.Details["reason"] != "不適切" {
+		t.Fatalf("unexpected second event: %+v", e)
+	}
+
+	router = newAdminRouter(AdminUsecases{PostTimeline: &stubPostTimeline{err: adminusecase.ErrPostNotFound}})
+	if rec := performAdminRequest(router, http.MethodGet, "/admin/posts/missing/timeline", "Bearer "+testAdminToken); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestAdminHandler_PostActions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p, _ := postdomain.Restore("post-1", "闇", postdomain.StatusReady)
+	d, _ := drawdomain.New("post-1", "おみくじ")
+	d.MarkVerified()
+	detail := &adminusecase.PostDetail{Post: p, Draw: d}
+
+	approve := &stubAdminPostUsecase{detail: detail}
+	reject := &stubRejectDraw{detail: detail}
+	requeue := &stubAdminPostAction{}
+	remove := &stubAdminPostAction{}
+	router := newAdminRouter(AdminUsecases{ApproveDraw: approve, RejectDraw: reject, RequeuePost: requeue, DeletePost: remove})
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		called func() string
+	}{
+		{method: http.MethodPost, path: "/admin/draws/post-1/approve", code: http.StatusOK, called: func() string { return approve.postID }},
+		{method: http.MethodPost, path: "/admin/draws/post-1/reject", code: http.StatusOK, called: func() string { return reject.in.PostID }},
+		{method: http.MethodPost, path: "/admin/posts/post-1/requeue", code: http.StatusAccepted, called: func() string { return requeue.postID }},
+		{method: http.MethodDelete, path: "/admin/posts/post-1", code: http.StatusNoContent, called: func() string { return remove.postID }},
+	}
+	for _, tc := range cases {
+		rec := performAdminRequest(router, tc.method, tc.path, "Bearer "+testAdminToken)
+		if rec.Code != tc.code {
+			t.Fatalf("%s %s: expected status %d but got %d", tc.method, tc.path, tc.code, rec.Code)
+		}
+		if got := tc.called(); got != "post-1" {
+			t.Fatalf("%s %s: unexpected post id %q", tc.method, tc.path, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/draws/post-1/reject", strings.NewReader(`{"reason":"個人名"}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || reject.in.Reason != "個人名" {
+		t.Fatalf("reason should be passed, got %d %+v", rec.Code, reject.in)
+	}
+
+	for err, code := range map[error]int{
+		adminusecase.ErrDrawNotFound:      http.StatusNotFound,
+		adminusecase.ErrInvalidTransition: http.StatusConflict,
+		adminusecase.ErrConflict:          http.StatusConflict,
+		adminusecase.ErrNoFormattedResult: http.StatusConflict,
+		adminusecase.ErrJobAlreadyQueued:  http.StatusConflict,
+	} {
+		router := newAdminRouter(AdminUsecases{ApproveDraw: &stubAdminPostUsecase{err: err}, RequeuePost: &stubAdminPostAction{err: err}})
+		if rec := performAdminRequest(router, http.MethodPost, "/admin/draws/post-1/approve", "Bearer "+testAdminToken); rec.Code != code {
+			t.Fatalf("approve %v: expected status %d but got %d", err, code, rec.Code)
+		}
+		if rec := performAdminRequest(router, http.MethodPost, "/admin/posts/post-1/requeue", "Bearer "+testAdminToken); rec.Code != code {
+			t.Fatalf("requeue %v: expected status %d but got %d", err, code, rec.Code)
+		}
+	}
+}
+
+func newAdminRouter(usecases AdminUsecases) *gin.Engine {
+	admin := NewAdminHandler(testAdminToken, usecases)
+	return NewRouter(NewDrawHandler(&stubFortuneUsecase{}, nil, nil, nil), NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, admin)
+}
+
+func performAdminRequest(router *gin.Engine, method, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+type stubListReportedDraws struct {
+	items []drawusecase.ReportedDraw
+	err   error
+	limit int
+}
+
+func (s *stubListReportedDraws) Execute(ctx context.Context, limit int) ([]drawusecase.ReportedDraw, error) {
+	s.limit = limit
+	return s.items, s.err
+}
+
+type stubResolveReports struct {
+	detail *adminusecase.PostDetail
+	err    error
+	in     *adminusecase.ResolveReportsInput
+}
+
+func (s *stubResolveReports) Execute(ctx context.Context, in *adminusecase.ResolveReportsInput) (*adminusecase.PostDetail, error) {
+	s.in = in
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.detail, nil
+}
+
+type stubListPosts struct {
+	page *repository.PostPage
+	err  error
+	in   *adminusecase.ListPostsInput
+}
+
+func (s *stubListPosts) Execute(ctx context.Context, in *adminusecase.ListPostsInput) (*repository.PostPage, error) {
+	s.in = in
+	return s.page, s.err
+}
+
+// stubAdminPostUsecase は投稿 ID を受け取って詳細を返す操作（詳細・承認）の代役。
+type stubAdminPostUsecase struct {
+	detail *adminusecase.PostDetail
+	err    error
+	postID string
+}
+
+func (s *stubAdminPostUsecase) Execute(ctx context.Context, postID string) (*adminusecase.PostDetail, error) {
+	s.postID = postID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.detail, nil
+}
+
+type stubRejectDraw struct {
+	detail *adminusecase.PostDetail
+	in     *adminusecase.RejectDrawInput
+}
+
+func (s *stubRejectDraw) Execute(ctx context.Context, in *adminusecase.RejectDrawInput) (*adminusecase.PostDetail, error) {
+	s.in = in
+	return s.detail, nil
+}
+
+type stubAdminPostAction struct {
+	err    error
+	postID string
+}
+
+func (s *stubAdminPostAction) Execute(ctx context.Context, postID string) error {
+	s.postID = postID
+	return s.err
+}
+
+type stubPostTimeline struct {
+	events []*audit.Event
+	err    error
+	postID string
+}
+
+func (s *stubPostTimeline) Execute(ctx context.Context, postID string) ([]*audit.Event, error) {
+	s.postID = postID
+	return s.events, s.err
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// headerClientToken は Cookie を送れないクライアント向けに匿名クライアント ID を受け渡すヘッダー。
+	headerClientToken = "X-Client-Token"
+	// cookieClientToken は匿名クライアント ID を保持する Cookie の名前。
+	cookieClientToken = "dark_client"
+	// contextKeyClientID は検証済みのクライアント ID を gin.Context に載せるキー。
+	contextKeyClientID = "client_id"
+
+	clientIDBytes       = 16
+	clientTokenMaxAge   = 365 * 24 * 60 * 60
+	clientTokenSplitter = "."
+)
+
+// ClientIdentity はログイン無しで同じ端末を見分けるための匿名 ID を署名付きで発行・検証する。
+type ClientIdentity struct {
+	secret []byte
+	secure bool
+}
+
+// NewClientIdentity は署名鍵と Cookie を Secure にするかを受け取って ClientIdentity を生成する。
+func NewClientIdentity(secret []byte, secure bool) *ClientIdentity {
+	return &ClientIdentity{secret: secret, secure: secure}
+}
+
+/**
+ * リクエストからクライアント ID を読み取り、無いか署名が合わなければ新しく発行して Cookie とヘッダーで返す。
+ * ヘッダーを Cookie より優先し、検証済みの ID は ClientIDFrom で取り出せる。
+ */
+func (ci *ClientIdentity) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := ci.verify(strings.TrimSpace(c.GetHeader(headerClientToken)))
+		if !ok {
+			if cookie, err := c.Cookie(cookieClientToken); err == nil {
+				id, ok = ci.verify(cookie)
+			}
+		}
+		if !ok {
+			var err error
+			id, err = newClientID()
+			if err != nil {
+				// ID を発行できなくても投稿や抽選は続けられるため、除外なしで通す
+				c.Next()
+				return
+			}
+			ci.issue(c, id)
+		}
+		c.Set(contextKeyClientID, id)
+		c.Next()
+	}
+}
+
+/**
+ * 発行した ID を署名付きで Cookie とレスポンスヘッダーへ書き出す。
+ */
+func (ci *ClientIdentity) issue(c *gin.Context, id string) {
+	token := id + clientTokenSplitter + ci.sign(id)
+	// 別オリジンのフロントエンドから Cookie を送らせるには SameSite=None と Secure が要る
+	sameSite := http.SameSiteLaxMode
+	if ci.secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     cookieClientToken,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   clientTokenMaxAge,
+		HttpOnly: true,
+		Secure:   ci.secure,
+		SameSite: sameSite,
+	})
+	c.Header(headerClientToken, token)
+}
+
+/**
+ * "<id>.<署名>" 形式のトークンを検証し、正しければ ID を返す。
+ */
+func (ci *ClientIdentity) verify(token string) (string, bool) {
+	id, sig, found := strings.Cut(token, clientTokenSplitter)
+	if !found || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(ci.sign(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+func (ci *ClientIdentity) sign(id string) string {
+	mac := hmac.New(sha256.New, ci.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newClientID() (string, error) {
+	b := make([]byte, clientIDBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ClientIDFrom はミドルウェアが検証したクライアント ID を返す。ミドルウェアを通っていなければ空文字。
+func ClientIDFrom(c *gin.Context) string {
+	return c.GetString(contextKeyClientID)
+}
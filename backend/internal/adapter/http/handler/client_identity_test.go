@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientIdentity_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(identity *ClientIdentity) (*gin.Engine, *stubFortuneUsecase) {
+		fortune := &stubFortuneUsecase{draw: newVerifiedDraw(t, "post-1", "fortune")}
+		router := NewRouter(NewDrawHandler(fortune, nil, nil, nil), NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{}), identity, nil)
+		return router, fortune
+	}
+	identity := NewClientIdentity([]byte("secret"), false)
+
+	t.Run("issues a signed id on first request", func(t *testing.T) {
+		router, fortune := newRouter(identity)
+		rec, _ := performRequest(router)
+
+		token := rec.Header().Get(headerClientToken)
+		id, ok := identity.verify(token)
+		if !ok {
+			t.Fatalf("issued token should verify: %q", token)
+		}
+		if fortune.in == nil || fortune.in.ClientID != id {
+			t.Fatalf("usecase should receive issued id %q, got %+v", id, fortune.in)
+		}
+		cookie := rec.Result().Cookies()
+		if len(cookie) != 1 || cookie[0].Name != cookieClientToken || cookie[0].Value != token || !cookie[0].HttpOnly {
+			t.Fatalf("unexpected cookie: %+v", cookie)
+		}
+		if cookie[0].SameSite != http.SameSiteLaxMode || cookie[0].Secure {
+			t.Fatalf("insecure identity should use lax cookie: %+v", cookie[0])
+		}
+	})
+
+	t.Run("reuses a valid header token before the cookie", func(t *testing.T) {
+		router, fortune := newRouter(identity)
+		req := httptest.NewRequest(http.MethodGet, "/draws/random", nil)
+		req.Header.Set(headerClientToken, "header-id."+identity.sign("header-id"))
+		req.AddCookie(&http.Cookie{Name: cookieClientToken, Value: "cookie-id." + identity.sign("cookie-id")})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if fortune.in == nil || fortune.in.ClientID != "header-id" {
+			t.Fatalf("header id should win, got %+v", fortune.in)
+		}
+		if rec.Header().Get(headerClientToken) != "" || len(rec.Result().Cookies()) != 0 {
+			t.Fatal("valid token should not be reissued")
+		}
+	})
+
+	t.Run("reuses a valid cookie", func(t *testing.T) {
+		router, fortune := newRouter(identity)
+		req := httptest.NewRequest(http.MethodGet, "/draws/random", nil)
+		req.AddCookie(&http.Cookie{Name: cookieClientToken, Value: "cookie-id." + identity.sign("cookie-id")})
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if fortune.in == nil || fortune.in.ClientID != "cookie-id" {
+			t.Fatalf("cookie id should be used, got %+v", fortune.in)
+		}
+	})
+
+	t.Run("reissues a tampered token", func(t *testing.T) {
+		router, fortune := newRouter(identity)
+		req := httptest.NewRequest(http.MethodGet, "/draws/random", nil)
+		forged := "victim." + NewClientIdentity([]byte("other"), false).sign("victim")
+		req.Header.Set(headerClientToken, forged)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if fortune.in == nil || fortune.in.ClientID == "victim" || fortune.in.ClientID == "" {
+			t.Fatalf("forged id should be replaced, got %+v", fortune.in)
+		}
+		if !strings.HasPrefix(rec.Header().Get(headerClientToken), fortune.in.ClientID+".") {
+			t.Fatalf("new token should be issued, got %q", rec.Header().Get(headerClientToken))
+		}
+	})
+
+	t.Run("secure identity uses cross-site cookie", func(t *testing.T) {
+		router, _ := newRouter(NewClientIdentity([]byte("secret"), true))
+		rec, _ := performRequest(router)
+
+		cookie := rec.Result().Cookies()
+		if len(cookie) != 1 || cookie[0].SameSite != http.SameSiteNoneMode || !cookie[0].Secure {
+			t.Fatalf("unexpected cookie: %+v", cookie)
+		}
+	})
+
+	t.Run("post records the client id", func(t *testing.T) {
+		create := &stubCreatePostUsecase{}
+		router := NewRouter(NewDrawHandler(&stubFortuneUsecase{}, nil, nil, nil), NewPostHandler(create, &stubPostStatusUsecase{}), identity, nil)
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{"content":"闇"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerClientToken, "author-id."+identity.sign("author-id"))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if create.received == nil || create.received.ClientID != "author-id" {
+			t.Fatalf("post should carry client id, got %+v", create.received)
+		}
+	})
+}
@@ -6,54 +6,178 @@ import (
 	"net/http"
 
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/reaction"
+	"backend/internal/domain/report"
+	drawusecase "backend/internal/usecase/draw"
 
 	"github.com/gin-gonic/gin"
 )
 
 const (
-	messageDrawsEmpty    = "no verified draws available"
-	messageInternalError = "internal server error"
+	messageDrawsEmpty             = "no verified draws available"
+	messageDrawNotFound           = "draw not found"
+	messageReactionInvalidRequest = "invalid reaction request"
+	messageReportInvalidRequest   = "invalid report request"
+	messageInternalError          = "internal server error"
 )
 
 // FortuneUsecase は検証済みのおみくじを 1 件返すユースケースの契約。
 type FortuneUsecase interface {
-	DrawFortune(ctx context.Context) (*drawdomain.Draw, error)
+	DrawFortune(ctx context.Context, in *drawusecase.DrawFortuneInput) (*drawusecase.DrawFortuneOutput, error)
+}
+
+// おみくじへのリアクション追加ユースケースの契約。
+type ReactDrawExecutor interface {
+	Execute(ctx context.Context, in *drawusecase.ReactDrawInput) (reaction.Counts, error)
+}
+
+// おみくじのリアクション件数参照ユースケースの契約。
+type GetDrawReactionsExecutor interface {
+	Execute(ctx context.Context, postID string) (reaction.Counts, error)
+}
+
+// おみくじへの通報ユースケースの契約。
+type ReportDrawExecutor interface {
+	Execute(ctx context.Context, in *drawusecase.ReportDrawInput) error
 }
 
 // DrawHandler はおみくじ関連の HTTP ハンドラをまとめる。
 type DrawHandler struct {
-	usecase FortuneUsecase
+	usecase          FortuneUsecase
+	reactUsecase     ReactDrawExecutor
+	reactionsUsecase GetDrawReactionsExecutor
+	reportUsecase    ReportDrawExecutor
 }
 
 // NewDrawHandler は DrawHandler を生成する。
-func NewDrawHandler(usecase FortuneUsecase) *DrawHandler {
-	return &DrawHandler{usecase: usecase}
+func NewDrawHandler(usecase FortuneUsecase, reactUsecase ReactDrawExecutor, reactionsUsecase GetDrawReactionsExecutor, reportUsecase ReportDrawExecutor) *DrawHandler {
+	return &DrawHandler{usecase: usecase, reactUsecase: reactUsecase, reactionsUsecase: reactionsUsecase, reportUsecase: reportUsecase}
 }
 
-// DrawResponse は GET /draws/random のレスポンス。
+// DrawResponse は GET /draws/random のレスポンス。reactions は種類ごとの件数で、まだ無い種類も 0 で返す。
 type DrawResponse struct {
+	PostID    string         `json:"post_id"`
+	Result    string         `json:"result"`
+	Status    string         `json:"status"`
+	Reactions map[string]int `json:"reactions"`
+}
+
+// POST /draws/:post_id/reactions の入力。kind は saved（救われた）/ laughed（笑った）/ pierced（刺さった）。
+type ReactionRequest struct {
+	Kind string `json:"kind"`
+}
+
+// POST /draws/:post_id/reports の入力。reason は abusive / personal_info / self_harm / spam / other、note は任意の補足。
+type ReportRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+// 通報を受け付けたことを返すレスポンス。件数は通報者へは返さない。
+type ReportResponse struct {
 	PostID string `json:"post_id"`
-	Result string `json:"result"`
-	Status string `json:"status"`
+}
+
+// リアクション件数のレスポンス。
+type ReactionsResponse struct {
+	PostID    string         `json:"post_id"`
+	Reactions map[string]int `json:"reactions"`
 }
 
 type errorResponse struct {
 	Message string `json:"message"`
 }
 
-// GetRandomDraw は Verified な結果を 1 件ランダムに返す。
+// GetRandomDraw は Verified な結果から、リクエスト元の投稿以外を 1 件ランダムに返す。
 func (h *DrawHandler) GetRandomDraw(c *gin.Context) {
-	draw, err := h.usecase.DrawFortune(c.Request.Context())
+	out, err := h.usecase.DrawFortune(c.Request.Context(), &drawusecase.DrawFortuneInput{
+		ClientID: ClientIDFrom(c),
+	})
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, DrawResponse{
-		PostID: string(draw.PostID()),
-		Result: string(draw.Result()),
-		Status: string(draw.Status()),
+		PostID:    string(out.Draw.PostID()),
+		Result:    string(out.Draw.Result()),
+		Status:    string(out.Draw.Status()),
+		Reactions: toReactionCounts(out.Reactions),
+	})
+}
+
+// AddReaction は公開中のおみくじへリアクションを 1 件加え、加えた後の件数を返す。
+// 同じクライアントが同じ種類を再送しても 1 回だけ数える。
+func (h *DrawHandler) AddReaction(c *gin.Context) {
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageReactionInvalidRequest})
+		return
+	}
+
+	postID := c.Param("post_id")
+	counts, err := h.reactUsecase.Execute(c.Request.Context(), &drawusecase.ReactDrawInput{
+		PostID:   postID,
+		Kind:     req.Kind,
+		ClientID: ClientIDFrom(c),
+	})
+	if err != nil {
+		h.handleReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ReactionsResponse{PostID: postID, Reactions: toReactionCounts(counts)})
+}
+
+// GetReactions は公開中のおみくじのリアクション件数を返す。
+func (h *DrawHandler) GetReactions(c *gin.Context) {
+	postID := c.Param("post_id")
+	counts, err := h.reactionsUsecase.Execute(c.Request.Context(), postID)
+	if err != nil {
+		h.handleReactionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ReactionsResponse{PostID: postID, Reactions: toReactionCounts(counts)})
+}
+
+// AddReport は公開中のおみくじへの通報を受け付ける。一定数のネットワークから通報が届いたおみくじは公開を停止する。
+// 通報元は信頼するプロキシを通した接続元の IP アドレスから決める。
+// 同じクライアントの再送も 202 を返す。
+func (h *DrawHandler) AddReport(c *gin.Context) {
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageReportInvalidRequest})
+		return
+	}
+
+	postID := c.Param("post_id")
+	err := h.reportUsecase.Execute(c.Request.Context(), &drawusecase.ReportDrawInput{
+		PostID:   postID,
+		Reason:   req.Reason,
+		Note:     req.Note,
+		ClientID: ClientIDFrom(c),
+		RemoteIP: c.ClientIP(),
 	})
+	if err != nil {
+		switch {
+		case errors.Is(err, report.ErrInvalidReason), errors.Is(err, report.ErrNoteTooLong), errors.Is(err, drawusecase.ErrEmptyPostID):
+			c.JSON(http.StatusBadRequest, errorResponse{Message: messageReportInvalidRequest})
+		case errors.Is(err, drawusecase.ErrDrawNotFound):
+			c.JSON(http.StatusNotFound, errorResponse{Message: messageDrawNotFound})
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
+		}
+		return
+	}
+	c.JSON(http.StatusAccepted, ReportResponse{PostID: postID})
+}
+
+// toReactionCounts は件数をレスポンス用に詰め替え、まだ無い種類を 0 で補う。
+func toReactionCounts(counts reaction.Counts) map[string]int {
+	out := make(map[string]int, len(reaction.Kinds()))
+	for _, k := range reaction.Kinds() {
+		out[string(k)] = counts[k]
+	}
+	return out
 }
 
 func (h *DrawHandler) handleError(c *gin.Context, err error) {
@@ -63,3 +187,14 @@ func (h *DrawHandler) handleError(c *gin.Context, err error) {
 	}
 	c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
 }
+
+func (h *DrawHandler) handleReactionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, reaction.ErrInvalidKind), errors.Is(err, drawusecase.ErrEmptyPostID):
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messageReactionInvalidRequest})
+	case errors.Is(err, drawusecase.ErrDrawNotFound):
+		c.JSON(http.StatusNotFound, errorResponse{Message: messageDrawNotFound})
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
+	}
+}
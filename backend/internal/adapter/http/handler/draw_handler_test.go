@@ -8,10 +8,15 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/domain/report"
+	drawusecase "backend/internal/usecase/draw"
 	postusecase "backend/internal/usecase/post"
 
 	"github.com/gin-gonic/gin"
@@ -22,8 +27,8 @@ func TestDrawHandler_GetRandomDraw(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		d := newVerifiedDraw(t, "post-success", "fortunes await")
-		handler := NewDrawHandler(&stubFortuneUsecase{draw: d})
-		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}))
+		handler := NewDrawHandler(&stubFortuneUsecase{draw: d, reactions: reaction.Counts{reaction.KindSaved: 3}}, nil, nil, nil)
+		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
 
 		rec, body := performRequest(router)
 
@@ -35,19 +40,20 @@ func TestDrawHandler_GetRandomDraw(t *testing.T) {
 		decodeBody(t, body, &got)
 
 		want := DrawResponse{
-			PostID: "post-success",
-			Result: "fortunes await",
-			Status: string(d.Status()),
+			PostID:    "post-success",
+			Result:    "fortunes await",
+			Status:    string(d.Status()),
+			Reactions: map[string]int{"saved": 3, "laughed": 0, "pierced": 0},
 		}
 
-		if got != want {
+		if !reflect.DeepEqual(got, want) {
 			t.Fatalf("unexpected response: %+v", got)
 		}
 	})
 
 	t.Run("draws depleted", func(t *testing.T) {
-		handler := NewDrawHandler(&stubFortuneUsecase{err: drawdomain.ErrEmptyResult})
-		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}))
+		handler := NewDrawHandler(&stubFortuneUsecase{err: drawdomain.ErrEmptyResult}, nil, nil, nil)
+		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
 
 		rec, body := performRequest(router)
 
@@ -64,8 +70,8 @@ func TestDrawHandler_GetRandomDraw(t *testing.T) {
 	})
 
 	t.Run("internal error", func(t *testing.T) {
-		handler := NewDrawHandler(&stubFortuneUsecase{err: errors.New("boom")})
-		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}))
+		handler := NewDrawHandler(&stubFortuneUsecase{err: errors.New("boom")}, nil, nil, nil)
+		router := NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
 
 		rec, body := performRequest(router)
 
@@ -82,13 +88,176 @@ func TestDrawHandler_GetRandomDraw(t *testing.T) {
 	})
 }
 
+func TestDrawHandler_Reactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(react *stubReactDrawUsecase, get *stubDrawReactionsUsecase) *gin.Engine {
+		handler := NewDrawHandler(&stubFortuneUsecase{}, react, get, nil)
+		return NewRouter(handler, NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
+	}
+	post := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/draws/post-1/reactions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("add returns updated counts", func(t *testing.T) {
+		react := &stubReactDrawUsecase{counts: reaction.Counts{reaction.KindLaughed: 2}}
+		rec := post(newRouter(react, nil), `{"kind":"laughed"}`)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d but got %d", http.StatusOK, rec.Code)
+		}
+		if react.in == nil || react.in.PostID != "post-1" || react.in.Kind != "laughed" {
+			t.Fatalf("unexpected input: %+v", react.in)
+		}
+		var got ReactionsResponse
+		decodeBody(t, rec.Body, &got)
+		want := ReactionsResponse{PostID: "post-1", Reactions: map[string]int{"saved": 0, "laughed": 2, "pierced": 0}}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected response: %+v", got)
+		}
+	})
+
+	t.Run("add maps errors", func(t *testing.T) {
+		cases := []struct {
+			name string
+			body string
+			err  error
+			code int
+		}{
+			{name: "malformed body", body: `{`, code: http.StatusBadRequest},
+			{name: "invalid kind", body: `{"kind":"angry"}`, err: reaction.ErrInvalidKind, code: http.StatusBadRequest},
+			{name: "unpublished draw", body: `{"kind":"saved"}`, err: drawusecase.ErrDrawNotFound, code: http.StatusNotFound},
+			{name: "storage failure", body: `{"kind":"saved"}`, err: errors.New("boom"), code: http.StatusInternalServerError},
+		}
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				rec := post(newRouter(&stubReactDrawUsecase{err: tc.err}, nil), tc.body)
+				if rec.Code != tc.code {
+					t.Fatalf("expected status %d but got %d", tc.code, rec.Code)
+				}
+			})
+		}
+	})
+
+	t.Run("get returns counts", func(t *testing.T) {
+		get := &stubDrawReactionsUsecase{counts: reaction.Counts{reaction.KindPierced: 5}}
+		rec := httptest.NewRecorder()
+		newRouter(nil, get).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/draws/post-1/reactions", nil))
+
+		if rec.Code != http.StatusOK || get.postID != "post-1" {
+			t.Fatalf("unexpected status %d for %q", rec.Code, get.postID)
+		}
+		var got ReactionsResponse
+		decodeBody(t, rec.Body, &got)
+		if got.Reactions["pierced"] != 5 || got.Reactions["saved"] != 0 {
+			t.Fatalf("unexpected response: %+v", got)
+		}
+	})
+
+	t.Run("get unknown draw", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newRouter(nil, &stubDrawReactionsUsecase{err: drawusecase.ErrDrawNotFound}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/draws/missing/reactions", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("expected status %d but got %d", http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestDrawHandler_AddReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name string
+		body string
+		err  error
+		code int
+	}{
+		{name: "accepted", body: `{"reason":"abusive","note":"ひどい"}`, code: http.StatusAccepted},
+		{name: "malformed body", body: `{`, code: http.StatusBadRequest},
+		{name: "invalid reason", body: `{"reason":"boring"}`, err: report.ErrInvalidReason, code: http.StatusBadRequest},
+		{name: "note too long", body: `{"reason":"other"}`, err: report.ErrNoteTooLong, code: http.StatusBadRequest},
+		{name: "unpublished draw", body: `{"reason":"spam"}`, err: drawusecase.ErrDrawNotFound, code: http.StatusNotFound},
+		{name: "storage failure", body: `{"reason":"spam"}`, err: errors.New("boom"), code: http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &stubReportDrawUsecase{err: tc.err}
+			router := NewRouter(NewDrawHandler(&stubFortuneUsecase{}, nil, nil, stub), NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
+			req := httptest.NewRequest(http.MethodPost, "/draws/post-1/reports", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			// TRUSTED_PROXIES が無ければクライアントが書いた X-Forwarded-For は使わない
+			req.Header.Set("X-Forwarded-For", "203.0.113.50")
+			req.RemoteAddr = "192.0.2.1:1234"
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tc.code {
+				t.Fatalf("expected status %d but got %d", tc.code, rec.Code)
+			}
+			if tc.code == http.StatusAccepted && (stub.in == nil || stub.in.PostID != "post-1" || stub.in.Reason != "abusive" || stub.in.Note != "ひどい" || stub.in.RemoteIP != "192.0.2.1") {
+				t.Fatalf("unexpected input: %+v", stub.in)
+			}
+		})
+	}
+}
+
+func TestDrawHandler_AddReportTrustsConfiguredProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8")
+
+	stub := &stubReportDrawUsecase{}
+	router := NewRouter(NewDrawHandler(&stubFortuneUsecase{}, nil, nil, stub), NewPostHandler(&stubPostUsecaseForRouter{}, &stubPostStatusUsecase{}), nil, nil)
+	req := httptest.NewRequest(http.MethodPost, "/draws/post-1/reports", strings.NewReader(`{"reason":"spam"}`))
+	req.Header.Set("Content-Type", "application/json")
+	// 先頭はクライアントが書いた値。信頼するプロキシが付けた右端から、信頼しない最初のアドレスを使う
+	req.Header.Set("X-Forwarded-For", "203.0.113.50, 198.51.100.7")
+	req.RemoteAddr = "10.1.2.3:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if stub.in == nil || stub.in.RemoteIP != "198.51.100.7" {
+		t.Fatalf("unexpected input: %+v", stub.in)
+	}
+}
+
 type stubFortuneUsecase struct {
-	draw *drawdomain.Draw
-	err  error
+	draw      *drawdomain.Draw
+	reactions reaction.Counts
+	err       error
+	in        *drawusecase.DrawFortuneInput
+}
+
+func (s *stubFortuneUsecase) DrawFortune(ctx context.Context, in *drawusecase.DrawFortuneInput) (*drawusecase.DrawFortuneOutput, error) {
+	s.in = in
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &drawusecase.DrawFortuneOutput{Draw: s.draw, Reactions: s.reactions}, nil
+}
+
+type stubReactDrawUsecase struct {
+	counts reaction.Counts
+	err    error
+	in     *drawusecase.ReactDrawInput
+}
+
+func (s *stubReactDrawUsecase) Execute(ctx context.Context, in *drawusecase.ReactDrawInput) (reaction.Counts, error) {
+	s.in = in
+	return s.counts, s.err
+}
+
+type stubDrawReactionsUsecase struct {
+	counts reaction.Counts
+	err    error
+	postID string
 }
 
-func (s *stubFortuneUsecase) DrawFortune(ctx context.Context) (*drawdomain.Draw, error) {
-	return s.draw, s.err
+func (s *stubDrawReactionsUsecase) Execute(ctx context.Context, postID string) (reaction.Counts, error) {
+	s.postID = postID
+	return s.counts, s.err
 }
 
 func newVerifiedDraw(t *testing.T, postID, result string) *drawdomain.Draw {
@@ -120,3 +289,13 @@ type stubPostUsecaseForRouter struct{}
 func (stubPostUsecaseForRouter) Execute(ctx context.Context, in *postusecase.CreatePostInput) (*postusecase.CreatePostOutput, error) {
 	return &postusecase.CreatePostOutput{DarkPostID: "noop"}, nil
 }
+
+type stubReportDrawUsecase struct {
+	err error
+	in  *drawusecase.ReportDrawInput
+}
+
+func (s *stubReportDrawUsecase) Execute(ctx context.Context, in *drawusecase.ReportDrawInput) error {
+	s.in = in
+	return s.err
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	postdomain "backend/internal/domain/post"
 	postusecase "backend/internal/usecase/post"
@@ -16,6 +17,15 @@ import (
 const (
 	messagePostInvalidRequest = "invalid post request"
 	messagePostConflict       = "post already exists"
+	messagePostNotFound       = "post not found"
+	messageIdempotencyReused  = "idempotency key was reused with a different request"
+
+	// headerIdempotencyKey は再送時に同じ結果を返すためのリクエストヘッダー。
+	headerIdempotencyKey = "Idempotency-Key"
+	// headerIdempotentReplayed は保存済みの結果を返したことを示すレスポンスヘッダー。
+	headerIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 256
 )
 
 // 投稿作成ユースケースの契約。
@@ -23,19 +33,29 @@ type CreatePostExecutor interface {
 	Execute(ctx context.Context, in *postusecase.CreatePostInput) (*postusecase.CreatePostOutput, error)
 }
 
+// 投稿状況参照ユースケースの契約。
+type GetPostStatusExecutor interface {
+	Execute(ctx context.Context, postID string) (*postusecase.GetPostStatusOutput, error)
+}
+
 type PostHandler struct {
 	createUsecase CreatePostExecutor
+	statusUsecase GetPostStatusExecutor
 }
 
 // PostHandler を生成する。
-func NewPostHandler(usecase CreatePostExecutor) *PostHandler {
-	return &PostHandler{createUsecase: usecase}
+func NewPostHandler(createUsecase CreatePostExecutor, statusUsecase GetPostStatusExecutor) *PostHandler {
+	return &PostHandler{createUsecase: createUsecase, statusUsecase: statusUsecase}
 }
 
 // POST /posts の入力。
+// 投稿 ID はサーバーで払い出すため、クライアントが送れるのは任意の冪等キーだけ。
+// 冪等キーは Idempotency-Key ヘッダーを優先し、無ければ idempotency_key、
+// さらに旧クライアント向けに post_id を冪等キーとして読み替える。
 type CreatePostRequest struct {
-	PostID  string `json:"post_id"`
-	Content string `json:"content"`
+	IdempotencyKey string `json:"idempotency_key"`
+	PostID         string `json:"post_id"`
+	Content        string `json:"content"`
 }
 
 // 作成結果を表す。
@@ -43,6 +63,27 @@ type CreatePostResponse struct {
 	PostID string `json:"post_id"`
 }
 
+// GET /posts/:id の結果。job と fortune は該当する状態のときだけ返す。
+type PostStatusResponse struct {
+	PostID  string               `json:"post_id"`
+	Status  string               `json:"status"`
+	Job     *FormatJobResponse   `json:"job,omitempty"`
+	Fortune *PostFortuneResponse `json:"fortune,omitempty"`
+}
+
+// 整形ジョブの進み具合。
+type FormatJobResponse struct {
+	State         string     `json:"state"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// 投稿から生成されたおみくじ。
+type PostFortuneResponse struct {
+	Result string `json:"result"`
+	Status string `json:"status"`
+}
+
 /**
  * POST /posts のリクエストを検証し、ユースケースへ委譲して結果を返す。
  */
@@ -53,24 +94,83 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, errorResponse{Message: messagePostInvalidRequest})
 		return
 	}
-	// ID も本文も空は受け付けない
-	if strings.TrimSpace(req.PostID) == "" || strings.TrimSpace(req.Content) == "" {
+	// 本文が空は受け付けない
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse{Message: messagePostInvalidRequest})
+		return
+	}
+	idempotencyKey := strings.TrimSpace(c.GetHeader(headerIdempotencyKey))
+	if idempotencyKey == "" {
+		idempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	}
+	if idempotencyKey == "" {
+		idempotencyKey = strings.TrimSpace(req.PostID)
+	}
+	// 冪等キーは保存するため長すぎるものは受け付けない
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
 		c.JSON(http.StatusBadRequest, errorResponse{Message: messagePostInvalidRequest})
 		return
 	}
 
 	out, err := h.createUsecase.Execute(c.Request.Context(), &postusecase.CreatePostInput{
-		DarkPostID: req.PostID,
-		Content:    req.Content,
+		IdempotencyKey: idempotencyKey,
+		Content:        req.Content,
+		ClientID:       ClientIDFrom(c),
 	})
 	if err != nil {
 		h.handleError(c, err)
 		return
 	}
 
+	// 同じ内容の再送には最初と同じ 201 を返し、保存済みの結果であることだけヘッダーで伝える
+	if out.Replayed {
+		c.Header(headerIdempotentReplayed, "true")
+	}
 	c.JSON(http.StatusCreated, CreatePostResponse{PostID: out.DarkPostID})
 }
 
+/**
+ * GET /posts/:id で投稿の状態と整形ジョブの状況、公開済みならおみくじを返す。
+ */
+func (h *PostHandler) GetPostStatus(c *gin.Context) {
+	out, err := h.statusUsecase.Execute(c.Request.Context(), strings.TrimSpace(c.Param("id")))
+	if err != nil {
+		switch {
+		case errors.Is(err, postusecase.ErrEmptyPostID):
+			c.JSON(http.StatusBadRequest, errorResponse{Message: messagePostInvalidRequest})
+		case errors.Is(err, postusecase.ErrPostNotFound):
+			c.JSON(http.StatusNotFound, errorResponse{Message: messagePostNotFound})
+		default:
+			log.Printf("GET /posts/:id 失敗: %v", err)
+			c.JSON(http.StatusInternalServerError, errorResponse{Message: messageInternalError})
+		}
+		return
+	}
+
+	resp := PostStatusResponse{
+		PostID: out.DarkPostID,
+		Status: string(out.Status),
+	}
+	// 失敗理由には内部のエラー内容が含まれるため投稿者には返さない
+	if out.Job != nil {
+		resp.Job = &FormatJobResponse{
+			State:    string(out.Job.State),
+			Attempts: out.Job.Attempts,
+		}
+		if !out.Job.NextAttemptAt.IsZero() {
+			next := out.Job.NextAttemptAt
+			resp.Job.NextAttemptAt = &next
+		}
+	}
+	if out.Fortune != nil {
+		resp.Fortune = &PostFortuneResponse{
+			Result: string(out.Fortune.Result()),
+			Status: string(out.Fortune.Status()),
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
 /**
  * ユースケースからのエラーを HTTP ステータスとメッセージへ写し替える。
  */
@@ -82,6 +182,9 @@ func (h *PostHandler) handleError(c *gin.Context, err error) {
 	// ドメインの空本文エラー
 	case errors.Is(err, postdomain.ErrEmptyContent):
 		c.JSON(http.StatusBadRequest, errorResponse{Message: messagePostInvalidRequest})
+	// 冪等キーを別の内容で使い回した
+	case errors.Is(err, postusecase.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, errorResponse{Message: messageIdempotencyReused})
 	// 投稿もしくは整形ジョブの重複
 	case errors.Is(err, postusecase.ErrPostAlreadyExists),
 		errors.Is(err, postusecase.ErrJobAlreadyScheduled):
@@ -7,9 +7,13 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	drawdomain "backend/internal/domain/draw"
 	postdomain "backend/internal/domain/post"
+	"backend/internal/port/queue"
 	postusecase "backend/internal/usecase/post"
 
 	"github.com/gin-gonic/gin"
@@ -22,12 +26,12 @@ func TestPostHandler_CreatePost(t *testing.T) {
 		stub := &stubCreatePostUsecase{
 			output: &postusecase.CreatePostOutput{DarkPostID: "dark-1"},
 		}
-		handler := NewPostHandler(stub)
+		handler := NewPostHandler(stub, &stubPostStatusUsecase{})
 		router := gin.New()
 		router.POST("/posts", handler.CreatePost)
 
 		rec := httptest.NewRecorder()
-		reqBody := bytes.NewBufferString(`{"post_id":"dark-1","content":"hello"}`)
+		reqBody := bytes.NewBufferString(`{"idempotency_key":"key-1","content":"hello"}`)
 		req := httptest.NewRequest(http.MethodPost, "/posts", reqBody)
 		req.Header.Set("Content-Type", "application/json")
 
@@ -37,7 +41,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
 		}
 
-		if stub.received.DarkPostID != "dark-1" || stub.received.Content != "hello" {
+		if stub.received.IdempotencyKey != "key-1" || stub.received.Content != "hello" {
 			t.Fatalf("unexpected input passed to usecase: %+v", stub.received)
 		}
 
@@ -50,22 +54,88 @@ func TestPostHandler_CreatePost(t *testing.T) {
 		}
 	})
 
+	t.Run("without idempotency key", func(t *testing.T) {
+		stub := &stubCreatePostUsecase{output: &postusecase.CreatePostOutput{DarkPostID: "generated"}}
+		rec, body := performPostRequest(NewPostHandler(stub, &stubPostStatusUsecase{}), `{"content":"hello"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+		if stub.received.IdempotencyKey != "" {
+			t.Fatalf("unexpected key: %q", stub.received.IdempotencyKey)
+		}
+		var resp CreatePostResponse
+		if err := json.Unmarshal(body, &resp); err != nil || resp.PostID != "generated" {
+			t.Fatalf("expected generated id, got %+v (%v)", resp, err)
+		}
+	})
+
+	t.Run("legacy post_id becomes idempotency key", func(t *testing.T) {
+		stub := &stubCreatePostUsecase{output: &postusecase.CreatePostOutput{DarkPostID: "generated", Replayed: true}}
+		rec, _ := performPostRequest(NewPostHandler(stub, &stubPostStatusUsecase{}), `{"post_id":"legacy-1","content":"hello"}`)
+		// 再送でも最初と同じ 201 を返す
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+		if rec.Header().Get(headerIdempotentReplayed) != "true" {
+			t.Fatalf("expected replayed header, got %q", rec.Header().Get(headerIdempotentReplayed))
+		}
+		if stub.received.IdempotencyKey != "legacy-1" {
+			t.Fatalf("expected legacy post_id as key, got %q", stub.received.IdempotencyKey)
+		}
+	})
+
+	t.Run("idempotency key header takes precedence", func(t *testing.T) {
+		stub := &stubCreatePostUsecase{}
+		router := gin.New()
+		router.POST("/posts", NewPostHandler(stub, &stubPostStatusUsecase{}).CreatePost)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/posts", bytes.NewBufferString(`{"idempotency_key":"body-key","content":"hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerIdempotencyKey, "header-key")
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+		}
+		if stub.received.IdempotencyKey != "header-key" {
+			t.Fatalf("expected header key, got %q", stub.received.IdempotencyKey)
+		}
+		if rec.Header().Get(headerIdempotentReplayed) != "" {
+			t.Fatalf("first request should not be marked as replayed")
+		}
+	})
+
+	t.Run("idempotency key reused", func(t *testing.T) {
+		handler := NewPostHandler(&stubCreatePostUsecase{
+			err: postusecase.ErrIdempotencyKeyReused,
+		}, &stubPostStatusUsecase{})
+		rec, resp := performPostRequest(handler, `{"idempotency_key":"key","content":"other"}`)
+		expectStatusAndMessage(t, rec, resp, http.StatusUnprocessableEntity, messageIdempotencyReused)
+	})
+
+	t.Run("too long idempotency key", func(t *testing.T) {
+		handler := NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{})
+		body := `{"idempotency_key":"` + strings.Repeat("k", maxIdempotencyKeyLength+1) + `","content":"hello"}`
+		rec, resp := performPostRequest(handler, body)
+		expectStatusAndMessage(t, rec, resp, http.StatusBadRequest, messagePostInvalidRequest)
+	})
+
 	t.Run("invalid json", func(t *testing.T) {
-		handler := NewPostHandler(&stubCreatePostUsecase{})
+		handler := NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":`)
 		expectStatusAndMessage(t, rec, resp, http.StatusBadRequest, messagePostInvalidRequest)
 	})
 
 	t.Run("empty fields", func(t *testing.T) {
-		handler := NewPostHandler(&stubCreatePostUsecase{})
-		rec, resp := performPostRequest(handler, `{"post_id":"","content":""}`)
+		handler := NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{})
+		rec, resp := performPostRequest(handler, `{"idempotency_key":"key","content":""}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusBadRequest, messagePostInvalidRequest)
 	})
 
 	t.Run("post already exists", func(t *testing.T) {
 		handler := NewPostHandler(&stubCreatePostUsecase{
 			err: postusecase.ErrPostAlreadyExists,
-		})
+		}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":"dup","content":"hello"}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusConflict, messagePostConflict)
 	})
@@ -73,7 +143,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	t.Run("domain validation error", func(t *testing.T) {
 		handler := NewPostHandler(&stubCreatePostUsecase{
 			err: postdomain.ErrEmptyContent,
-		})
+		}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":"dark","content":"hello"}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusBadRequest, messagePostInvalidRequest)
 	})
@@ -81,7 +151,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	t.Run("nil input error", func(t *testing.T) {
 		handler := NewPostHandler(&stubCreatePostUsecase{
 			err: postusecase.ErrNilInput,
-		})
+		}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":"dark","content":"hello"}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusBadRequest, messagePostInvalidRequest)
 	})
@@ -89,7 +159,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	t.Run("job already scheduled", func(t *testing.T) {
 		handler := NewPostHandler(&stubCreatePostUsecase{
 			err: postusecase.ErrJobAlreadyScheduled,
-		})
+		}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":"dark","content":"hello"}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusConflict, messagePostConflict)
 	})
@@ -97,7 +167,7 @@ func TestPostHandler_CreatePost(t *testing.T) {
 	t.Run("internal error", func(t *testing.T) {
 		handler := NewPostHandler(&stubCreatePostUsecase{
 			err: errors.New("boom"),
-		})
+		}, &stubPostStatusUsecase{})
 		rec, resp := performPostRequest(handler, `{"post_id":"dark","content":"hello"}`)
 		expectStatusAndMessage(t, rec, resp, http.StatusInternalServerError, messageInternalError)
 	})
@@ -141,5 +211,110 @@ func (s *stubCreatePostUsecase) Execute(ctx context.Context, in *postusecase.Cre
 	if s.output != nil {
 		return s.output, nil
 	}
-	return &postusecase.CreatePostOutput{DarkPostID: in.DarkPostID}, nil
+	return &postusecase.CreatePostOutput{DarkPostID: "generated"}, nil
+}
+
+func TestPostHandler_GetPostStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("pending with job", func(t *testing.T) {
+		next := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		stub := &stubPostStatusUsecase{output: &postusecase.GetPostStatusOutput{
+			DarkPostID: "dark-1",
+			Status:     postdomain.StatusPending,
+			Job: &queue.FormatJobStatus{
+				State:         queue.FormatJobRetrying,
+				Attempts:      2,
+				NextAttemptAt: next,
+				LastError:     "llm down",
+			},
+		}}
+		rec := performStatusRequest(NewPostHandler(&stubCreatePostUsecase{}, stub), "/posts/dark-1")
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+		}
+		if stub.received != "dark-1" {
+			t.Fatalf("unexpected id passed to usecase: %q", stub.received)
+		}
+		var resp PostStatusResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Status != "pending" || resp.Job == nil || resp.Job.State != "retrying" || resp.Job.Attempts != 2 {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+		if resp.Job.NextAttemptAt == nil || !resp.Job.NextAttemptAt.Equal(next) {
+			t.Fatalf("unexpected next attempt: %+v", resp.Job)
+		}
+		if resp.Fortune != nil {
+			t.Fatalf("fortune should be omitted: %+v", resp.Fortune)
+		}
+		// 内部のエラー内容は返さない
+		if bytes.Contains(rec.Body.Bytes(), []byte("llm down")) {
+			t.Fatalf("last error should not be exposed: %s", rec.Body.String())
+		}
+	})
+
+	t.Run("ready with fortune", func(t *testing.T) {
+		d, err := drawdomain.New(postdomain.DarkPostID("dark-1"), "大吉")
+		if err != nil {
+			t.Fatalf("new draw: %v", err)
+		}
+		d.MarkVerified()
+		stub := &stubPostStatusUsecase{output: &postusecase.GetPostStatusOutput{
+			DarkPostID: "dark-1",
+			Status:     postdomain.StatusReady,
+			Fortune:    d,
+		}}
+		rec := performStatusRequest(NewPostHandler(&stubCreatePostUsecase{}, stub), "/posts/dark-1")
+
+		var resp PostStatusResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if rec.Code != http.StatusOK || resp.Status != "ready" || resp.Job != nil {
+			t.Fatalf("unexpected response: %d %+v", rec.Code, resp)
+		}
+		if resp.Fortune == nil || resp.Fortune.Result != "大吉" || resp.Fortune.Status != "verified" {
+			t.Fatalf("unexpected fortune: %+v", resp.Fortune)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		handler := NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{err: postusecase.ErrPostNotFound})
+		rec := performStatusRequest(handler, "/posts/missing")
+		expectStatusAndMessage(t, rec, rec.Body.Bytes(), http.StatusNotFound, messagePostNotFound)
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		handler := NewPostHandler(&stubCreatePostUsecase{}, &stubPostStatusUsecase{err: errors.New("boom")})
+		rec := performStatusRequest(handler, "/posts/dark")
+		expectStatusAndMessage(t, rec, rec.Body.Bytes(), http.StatusInternalServerError, messageInternalError)
+	})
+}
+
+func performStatusRequest(handler *PostHandler, path string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/posts/:id", handler.GetPostStatus)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+type stubPostStatusUsecase struct {
+	output   *postusecase.GetPostStatusOutput
+	err      error
+	received string
+}
+
+func (s *stubPostStatusUsecase) Execute(ctx context.Context, postID string) (*postusecase.GetPostStatusOutput, error) {
+	s.received = postID
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.output != nil {
+		return s.output, nil
+	}
+	return &postusecase.GetPostStatusOutput{DarkPostID: postID, Status: postdomain.StatusPending}, nil
 }
@@ -11,15 +11,18 @@ import (
 )
 
 // NewRouter は HTTP ハンドラーを紐づけた gin.Engine を返す。
-func NewRouter(drawHandler *DrawHandler, postHandler *PostHandler) *gin.Engine {
+// identity が nil ならクライアント ID を発行せず、抽選で本人の投稿を除外しない。
+// admin が nil なら /admin 配下を登録しない。
+func NewRouter(drawHandler *DrawHandler, postHandler *PostHandler, identity *ClientIdentity, admin *AdminHandler) *gin.Engine {
 	router := gin.New()
 	router.Use(gin.Recovery())
+	configureTrustedProxies(router)
 
 	// CORS設定
 	config := cors.Config{
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", headerIdempotencyKey, headerClientToken},
+		ExposeHeaders:    []string{"Content-Length", headerIdempotentReplayed, headerClientToken},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -40,9 +43,50 @@ func NewRouter(drawHandler *DrawHandler, postHandler *PostHandler) *gin.Engine {
 	}
 
 	router.Use(cors.New(config))
+	if identity != nil {
+		router.Use(identity.Middleware())
+	}
 
 	router.GET("/draws/random", drawHandler.GetRandomDraw)
+	router.POST("/draws/:post_id/reactions", drawHandler.AddReaction)
+	router.GET("/draws/:post_id/reactions", drawHandler.GetReactions)
+	router.POST("/draws/:post_id/reports", drawHandler.AddReport)
 	router.POST("/posts", postHandler.CreatePost)
+	router.GET("/posts/:id", postHandler.GetPostStatus)
+
+	if admin != nil {
+		adminGroup := router.Group("/admin", admin.Authenticate())
+		adminGroup.GET("/reports", admin.ListReports)
+		adminGroup.POST("/reports/:post_id/confirm", admin.ConfirmReports)
+		adminGroup.POST("/reports/:post_id/restore", admin.RestoreReports)
+		adminGroup.GET("/posts", admin.ListPosts)
+		adminGroup.GET("/posts/:post_id", admin.GetPost)
+		adminGroup.GET("/posts/:post_id/timeline", admin.GetPostTimeline)
+		adminGroup.POST("/posts/:post_id/requeue", admin.RequeuePost)
+		adminGroup.DELETE("/posts/:post_id", admin.DeletePost)
+		adminGroup.POST("/draws/:post_id/approve", admin.ApproveDraw)
+		adminGroup.POST("/draws/:post_id/reject", admin.RejectDraw)
+	}
 
 	return router
 }
+
+// configureTrustedProxies は TRUSTED_PROXIES（カンマ区切りの IP か CIDR）のプロキシが付けた X-Forwarded-For だけを信じる。
+// 未設定なら接続元のアドレスをそのまま使い、クライアントが書いたヘッダーで通報元を偽れないようにする。
+func configureTrustedProxies(router *gin.Engine) {
+	raw := os.Getenv("TRUSTED_PROXIES")
+	if raw == "" {
+		log.Println("警告: TRUSTED_PROXIES が設定されていません。X-Forwarded-For を使わず接続元のアドレスを通報元とします")
+		if err := router.SetTrustedProxies(nil); err != nil {
+			log.Fatalf("信頼するプロキシを設定できません: %v", err)
+		}
+		return
+	}
+	proxies := strings.Split(raw, ",")
+	for i := range proxies {
+		proxies[i] = strings.TrimSpace(proxies[i])
+	}
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES の値が不正です: %v", err)
+	}
+}
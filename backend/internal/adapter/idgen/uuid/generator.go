@@ -0,0 +1,33 @@
+package uuid
+
+import (
+	"fmt"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/idgen"
+
+	googleuuid "github.com/google/uuid"
+)
+
+// 時刻順に並ぶ UUIDv7 で闇投稿 ID を払い出す。
+type V7Generator struct{}
+
+/**
+ * UUIDv7 を使う ID 生成器を返す。
+ */
+func NewV7Generator() *V7Generator {
+	return &V7Generator{}
+}
+
+/**
+ * 新しい UUIDv7 を文字列の闇投稿 ID として返す。
+ */
+func (V7Generator) NewPostID() (post.DarkPostID, error) {
+	id, err := googleuuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("uuidgenerator: UUIDv7 の生成に失敗しました: %w", err)
+	}
+	return post.DarkPostID(id.String()), nil
+}
+
+var _ idgen.PostIDGenerator = (*V7Generator)(nil)
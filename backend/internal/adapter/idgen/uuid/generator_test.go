@@ -0,0 +1,36 @@
+package uuid
+
+import (
+	"testing"
+
+	googleuuid "github.com/google/uuid"
+)
+
+func TestV7Generator_NewPostID(t *testing.T) {
+	t.Parallel()
+
+	gen := NewV7Generator()
+	first, err := gen.NewPostID()
+	if err != nil {
+		t.Fatalf("NewPostID: %v", err)
+	}
+	second, err := gen.NewPostID()
+	if err != nil {
+		t.Fatalf("NewPostID: %v", err)
+	}
+	if first == second {
+		t.Fatalf("expected unique ids, got %s twice", first)
+	}
+
+	parsed, err := googleuuid.Parse(string(first))
+	if err != nil {
+		t.Fatalf("expected uuid, got %q: %v", first, err)
+	}
+	if parsed.Version() != 7 {
+		t.Fatalf("expected version 7, got %d", parsed.Version())
+	}
+	// 時刻順に払い出されるため文字列としても昇順になる
+	if string(first) >= string(second) {
+		t.Fatalf("expected ascending ids, got %s then %s", first, second)
+	}
+}
@@ -5,27 +5,29 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"unicode/utf8"
+	"time"
 
+	"backend/internal/adapter/llm/prompt"
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/fortune"
 	"backend/internal/port/llm"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
+// 生成元の記録に残す提供元。
+const providerName = "gemini"
+
 const (
-	defaultModelName      = "gemini-2.5-flash"
-	maxFormattedLength    = 150
-	minFormattedLength    = 30
-	fortunePrefix         = "今日のきらくじ:"
-	expectedSentenceCount = 3
+	defaultModelName = "gemini-2.5-flash"
 )
 
-var rejectionKeywords = []string{"kill", "suicide", "die"}
-
 var newGeminiClient = genai.NewClient
 
+// 所要時間の計測に使う時計。テストで差し替える
+var now = time.Now
+
 // Gemini の生成モデルをテスト用に差し替えやすくしたインターフェース。
 type contentGenerator interface {
 	GenerateContent(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error)
@@ -36,13 +38,15 @@ type Formatter struct {
 	generator contentGenerator
 	closeFn   func() error
 	modelName string
+	template  *prompt.Template
 }
 
 /**
  * API キーなどの設定から Gemini への窓口を構築し、整形器を返す。
+ * tmpl が nil なら既定の版のプロンプトを使う。
  * 必須情報が欠けていたり、接続ができないときはその旨を伝えて終了する。
  */
-func NewFormatter(ctx context.Context, apiKey, modelName string, extraOpts ...option.ClientOption) (*Formatter, error) {
+func NewFormatter(ctx context.Context, apiKey, modelName string, tmpl *prompt.Template, extraOpts ...option.ClientOption) (*Formatter, error) {
 	apiKey = strings.TrimSpace(apiKey)
 	if apiKey == "" {
 		return nil, fmt.Errorf("gemini formatter: API キーが設定されていません")
@@ -65,6 +69,7 @@ func NewFormatter(ctx context.Context, apiKey, modelName string, extraOpts ...op
 		generator: configured,
 		closeFn:   makeCloseFn(client),
 		modelName: resolvedModel,
+		template:  tmpl,
 	}, nil
 }
 
@@ -94,8 +99,14 @@ func (f *Formatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.Fo
 		return nil, fmt.Errorf("%w: gemini formatter: 生成器が初期化されていません", llm.ErrFormatterUnavailable)
 	}
 
-	prompt := buildPrompt(string(req.DarkContent))
-	resp, err := f.generator.GenerateContent(ctx, genai.Text(prompt))
+	tmpl := f.promptTemplate()
+	rendered, err := tmpl.Render(string(req.DarkContent))
+	if err != nil {
+		return nil, err
+	}
+	startedAt := now()
+	resp, err := f.generator.GenerateContent(ctx, genai.Text(rendered))
+	latency := now().Sub(startedAt)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", llm.ErrFormatterUnavailable, err)
 	}
@@ -111,12 +122,30 @@ func (f *Formatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.Fo
 		DarkPostID:       req.DarkPostID,
 		FormattedContent: drawdomain.FormattedContent(text),
 		Status:           drawdomain.StatusPending,
+		Provenance:       f.provenance(resp, tmpl.Version(), latency),
 	}, nil
 }
 
 /**
- * 整形結果が投稿規約に沿っているかを再確認し、公開可否を決める。
- * 禁止語や文字数違反などが見つかったら拒否理由を付けて返す。
+ * 応答に含まれる消費トークン数と所要時間から、おみくじ結果の生成元の記録を組み立てる。
+ */
+func (f *Formatter) provenance(resp *genai.GenerateContentResponse, promptVersion string, latency time.Duration) drawdomain.Provenance {
+	p := drawdomain.Provenance{
+		Provider:      providerName,
+		Model:         f.modelName,
+		PromptVersion: promptVersion,
+		Latency:       latency,
+	}
+	if usage := resp.UsageMetadata; usage != nil {
+		p.PromptTokens = int(usage.PromptTokenCount)
+		p.CompletionTokens = int(usage.CandidatesTokenCount)
+	}
+	return p
+}
+
+/**
+ * 整形結果を公開判定に渡せる形へ整える。規則の検査はユースケースが提供元によらず行う。
+ * 空の結果を弾き、改行を除いた整形結果を検証済みとして返す。
  */
 func (f *Formatter) Validate(ctx context.Context, result *llm.FormatResult) (*llm.FormatResult, error) {
 	if result == nil || result.DarkPostID == "" {
@@ -130,13 +159,8 @@ func (f *Formatter) Validate(ctx context.Context, result *llm.FormatResult) (*ll
 		return result, llm.ErrInvalidFormat
 	}
 
-	normalized := normalizeFortuneText(trimmed)
-	if reason, rejected := shouldReject(normalized); rejected {
-		result.Status = drawdomain.StatusRejected
-		result.ValidationReason = reason
-		return result, llm.ErrContentRejected
-	}
-
+	// 文字数や構成などの規則は提供元によらずユースケースが fortune.Validator で検査する
+	normalized := fortune.Normalize(trimmed)
 	result.Status = drawdomain.StatusVerified
 	result.FormattedContent = drawdomain.FormattedContent(normalized)
 	result.ValidationReason = ""
@@ -168,29 +192,13 @@ func resolveModelName(name string) string {
 }
 
 /**
- * 整形時の言い回しや禁止事項を明記したガイド文を作り、投稿本文を差し込む。
+ * 設定されたプロンプトを返す。構築時に渡されなかった場合は既定の版を使う。
  */
-func buildPrompt(content string) string {
-	template := `
-あなたは他人の闇投稿をもとに、別の人が引く「きらくじ」を作る占い師です。出力は日本語のみで行い、次の指示を厳守してください。
-
-【文章ルール】
-1. 合計 30〜150 文字の 3 文構成で書く。
-2. 各文の内容: (1) 今の状況は少し重めに捉える (2) 賢明な行動は具体的で粘り強く、ねちねちした現実的な対処 (3) 結末は少しユーモアを含めつつ、癒しになるような余韻を残す。
-3. 3 文すべて「〜ます。」で終え、句点（。）で区切る。
-4. 固有名詞・URL・箇条書き・顔文字は禁止
-5. A さんへの直接メッセージにはせず、B さんが引くきらくじとして書く。
-
-【出力フォーマット】
-今日のきらくじ: 一文目。二文目。三文目。
-- 冒頭は必ず「今日のきらくじ:」ではじめ、余計な前置きや後書きは不要
-- 改行せず 1 行で書ききる
-
-上記ルールを完全に満たす文章だけを 1 行で返してください。
-
-元になった闇投稿:
-%s`
-	return fmt.Sprintf(strings.TrimSpace(template), strings.TrimSpace(content))
+func (f *Formatter) promptTemplate() *prompt.Template {
+	if f.template == nil {
+		return prompt.Default()
+	}
+	return f.template
 }
 
 /**
@@ -234,72 +242,6 @@ func extractFirstText(resp *genai.GenerateContentResponse) (string, error) {
 	return "", llm.ErrInvalidFormat
 }
 
-/**
- * 文字数・禁止語・URL などの検査を行い、違反が見つかったら拒否理由を返す。
- */
-func shouldReject(text string) (string, bool) {
-	length := utf8.RuneCountInString(text)
-	if length < minFormattedLength {
-		return "整形結果が短すぎます", true
-	}
-	if length > maxFormattedLength {
-		return "整形結果が長すぎます", true
-	}
-
-	lower := strings.ToLower(text)
-	for _, keyword := range rejectionKeywords {
-		if strings.Contains(lower, keyword) {
-			return fmt.Sprintf("不適切な語句(%s)が含まれています", keyword), true
-		}
-	}
-	if strings.Contains(lower, "http://") || strings.Contains(lower, "https://") {
-		return "URL は含めないでください", true
-	}
-	if !strings.HasPrefix(text, fortunePrefix) {
-		return fmt.Sprintf("冒頭は「%s」で始めてください", fortunePrefix), true
-	}
-	if reason, rejected := violatesFortuneStructure(text); rejected {
-		return reason, true
-	}
-	return "", false
-}
-
-func normalizeFortuneText(text string) string {
-	noCR := strings.ReplaceAll(text, "\r", "")
-	noLF := strings.ReplaceAll(noCR, "\n", "")
-	return strings.TrimSpace(noLF)
-}
-
-func violatesFortuneStructure(text string) (string, bool) {
-	body := strings.TrimSpace(strings.TrimPrefix(text, fortunePrefix))
-	sentences := splitSentences(body)
-	if len(sentences) != expectedSentenceCount {
-		return "お告げは3文構成で書いてください", true
-	}
-	for idx, sentence := range sentences {
-		if !strings.HasSuffix(sentence, "ます") {
-			return fmt.Sprintf("%d文目は「〜ます」で終えてください", idx+1), true
-		}
-	}
-	return "", false
-}
-
-/**
- * 句点で区切った文を抽出し、空の要素を除いて返す。
- */
-func splitSentences(body string) []string {
-	raw := strings.Split(body, "。")
-	sentences := make([]string, 0, len(raw))
-	for _, part := range raw {
-		trimmed := strings.TrimSpace(part)
-		if trimmed == "" {
-			continue
-		}
-		sentences = append(sentences, trimmed)
-	}
-	return sentences
-}
-
 /**
  * 候補数・文字数上限・温度などの設定を行い、生成器として扱えるようにする。
  */
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"backend/internal/adapter/llm/prompt"
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
 	"backend/internal/port/llm"
@@ -21,13 +23,8 @@ type fakeGenerator struct {
 }
 
 var (
-	fortuneShort         = "今日のきらくじ: つらいです。待ちます。笑えます。"
-	fortuneValid         = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
-	fortuneLong          = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続き、ため息が増えています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進め、証拠の順序も丁寧に整えます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒される余韻がしばらく長く残ります。"
-	fortuneKeyword       = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、killという語がちらついています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付けます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
-	fortuneURL           = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、https://example.comの通知が気になります。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付けます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
-	fortuneMissingPrefix = "心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も少し続いて眠りも浅くなっています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
-	fortuneTwoSentences  = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず黙々と進め、返信の時間も決め、痕跡を整え、最後は少し笑えて癒されます。"
+	fortuneValid   = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+	fortuneKeyword = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、killという語がちらついています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付けます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
 )
 
 func (f *fakeGenerator) GenerateContent(ctx context.Context, parts ...genai.Part) (*genai.GenerateContentResponse, error) {
@@ -126,22 +123,22 @@ func TestFormatter_ValidateSuccess(t *testing.T) {
 	}
 }
 
-func TestFormatter_ValidateRejectsUnsafeText(t *testing.T) {
+func TestFormatter_ValidateLeavesRulesToUsecase(t *testing.T) {
 	f := &Formatter{}
 	result := &llm.FormatResult{
-		DarkPostID:       post.DarkPostID("post-reject"),
-		FormattedContent: drawdomain.FormattedContent(fortuneKeyword),
+		DarkPostID:       post.DarkPostID("post-keyword"),
+		FormattedContent: drawdomain.FormattedContent(fortuneKeyword + "\r\n"),
 	}
 
 	validated, err := f.Validate(context.Background(), result)
-	if err == nil || !errors.Is(err, llm.ErrContentRejected) {
-		t.Fatalf("expected rejection error, got %v", err)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if validated.Status != drawdomain.StatusRejected {
-		t.Fatalf("expected rejected status, got %s", validated.Status)
+	if validated.Status != drawdomain.StatusVerified {
+		t.Fatalf("expected verified status, got %s", validated.Status)
 	}
-	if validated.ValidationReason == "" {
-		t.Fatalf("expected validation reason to be set")
+	if string(validated.FormattedContent) != fortuneKeyword {
+		t.Fatalf("expected normalized content, got %q", validated.FormattedContent)
 	}
 }
 
@@ -157,39 +154,6 @@ func TestFormatter_ValidateEmptyText(t *testing.T) {
 	}
 }
 
-func TestFormatter_ValidateRejectsMissingPrefix(t *testing.T) {
-	f := &Formatter{}
-	result := &llm.FormatResult{
-		DarkPostID:       post.DarkPostID("post-missing-prefix"),
-		FormattedContent: drawdomain.FormattedContent(fortuneMissingPrefix),
-	}
-
-	if _, err := f.Validate(context.Background(), result); err == nil || !errors.Is(err, llm.ErrContentRejected) {
-		t.Fatalf("expected rejection due to missing prefix, got %v", err)
-	}
-}
-
-func TestFormatter_ValidateRejectsSentenceCount(t *testing.T) {
-	f := &Formatter{}
-	result := &llm.FormatResult{
-		DarkPostID:       post.DarkPostID("post-missing-sentence"),
-		FormattedContent: drawdomain.FormattedContent(fortuneTwoSentences),
-	}
-
-	if _, err := f.Validate(context.Background(), result); err == nil || !errors.Is(err, llm.ErrContentRejected) {
-		t.Fatalf("expected rejection due to sentence count, got %v", err)
-	}
-}
-
-func TestFormatter_NormalizeFortuneText(t *testing.T) {
-	raw := "今日の闇みくじ:\r\n 一文目です。\n 二文目です。\n 三文目です。"
-	got := normalizeFortuneText(raw)
-	want := "今日の闇みくじ: 一文目です。 二文目です。 三文目です。"
-	if got != want {
-		t.Fatalf("normalizeFortuneText mismatch\ngot:  %q\nwant: %q", got, want)
-	}
-}
-
 func TestFormatter_FormatRequestValidation(t *testing.T) {
 	gen := &fakeGenerator{}
 	f := &Formatter{generator: gen}
@@ -296,7 +260,11 @@ func TestNewFormatter_Success(t *testing.T) {
 		return &genai.Client{}, nil
 	}
 
-	f, err := NewFormatter(context.Background(), " test-key ", "custom-model")
+	tmpl, err := prompt.Load(nil, "menhera-v1")
+	if err != nil {
+		t.Fatalf("load prompt: %v", err)
+	}
+	f, err := NewFormatter(context.Background(), " test-key ", "custom-model", tmpl)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -306,6 +274,9 @@ func TestNewFormatter_Success(t *testing.T) {
 	if f.modelName != "custom-model" {
 		t.Fatalf("unexpected model name: %s", f.modelName)
 	}
+	if f.promptTemplate() != tmpl {
+		t.Fatalf("expected configured prompt template")
+	}
 	if f.generator == nil {
 		t.Fatalf("generator should be configured")
 	}
@@ -323,7 +294,7 @@ func TestNewFormatter_NilContext(t *testing.T) {
 	}
 
 	var nilCtx context.Context
-	if _, err := NewFormatter(nilCtx, "key", ""); err != nil {
+	if _, err := NewFormatter(nilCtx, "key", "", nil); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
@@ -336,13 +307,13 @@ func TestNewFormatter_ClientError(t *testing.T) {
 		return nil, errors.New("boom")
 	}
 
-	if _, err := NewFormatter(context.Background(), "key", ""); err == nil || !errors.Is(err, llm.ErrFormatterUnavailable) {
+	if _, err := NewFormatter(context.Background(), "key", "", nil); err == nil || !errors.Is(err, llm.ErrFormatterUnavailable) {
 		t.Fatalf("expected formatter unavailable error, got %v", err)
 	}
 }
 
 func TestNewFormatter_MissingAPIKey(t *testing.T) {
-	if _, err := NewFormatter(context.Background(), "   ", ""); err == nil {
+	if _, err := NewFormatter(context.Background(), "   ", "", nil); err == nil {
 		t.Fatalf("expected error for missing api key")
 	}
 }
@@ -419,29 +390,64 @@ func TestExtractFirstTextErrors(t *testing.T) {
 	}
 }
 
-func TestShouldReject(t *testing.T) {
-	if reason, rejected := shouldReject(fortuneShort); !rejected || !strings.Contains(reason, "短すぎます") {
-		t.Fatalf("expected rejection for short text")
+func TestResolveModelName(t *testing.T) {
+	if got := resolveModelName(""); got != defaultModelName {
+		t.Fatalf("expected default model, got %s", got)
 	}
-	if reason, rejected := shouldReject(fortuneLong); !rejected || !strings.Contains(reason, "長すぎます") {
-		t.Fatalf("expected rejection for long text")
+	if got := resolveModelName(" custom "); got != " custom " {
+		t.Fatalf("expected custom value untouched")
 	}
-	if reason, rejected := shouldReject(fortuneKeyword); !rejected || !strings.Contains(reason, "不適切") {
-		t.Fatalf("expected rejection for keyword, got %v", reason)
+}
+
+func TestFormatter_FormatRecordsProvenance(t *testing.T) {
+	defer stubClock(t, 750*time.Millisecond)()
+	gen := &fakeGenerator{
+		response: &genai.GenerateContentResponse{
+			Candidates: []*genai.Candidate{
+				{Content: &genai.Content{Parts: []genai.Part{genai.Text(fortuneValid)}}},
+			},
+			UsageMetadata: &genai.UsageMetadata{PromptTokenCount: 320, CandidatesTokenCount: 85},
+		},
 	}
-	if reason, rejected := shouldReject(fortuneURL); !rejected || !strings.Contains(reason, "URL") {
-		t.Fatalf("expected rejection for url, got %v", reason)
+	f := &Formatter{generator: gen, modelName: "gemini-2.5-flash"}
+
+	result, err := f.Format(context.Background(), &llm.FormatRequest{DarkPostID: "post-1", DarkContent: "闇"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if reason, rejected := shouldReject(fortuneValid); rejected {
-		t.Fatalf("unexpected rejection: %v", reason)
+	want := drawdomain.Provenance{Provider: "gemini", Model: "gemini-2.5-flash", PromptVersion: prompt.DefaultVersion, PromptTokens: 320, CompletionTokens: 85, Latency: 750 * time.Millisecond}
+	if result.Provenance != want {
+		t.Fatalf("unexpected provenance: %+v", result.Provenance)
 	}
-}
 
-func TestResolveModelName(t *testing.T) {
-	if got := resolveModelName(""); got != defaultModelName {
-		t.Fatalf("expected default model, got %s", got)
+	// 検証は生成元の記録を引き継ぐ
+	validated, err := f.Validate(context.Background(), result)
+	if err != nil {
+		t.Fatalf("unexpected validate error: %v", err)
 	}
-	if got := resolveModelName(" custom "); got != " custom " {
-		t.Fatalf("expected custom value untouched")
+	if validated.Provenance != want {
+		t.Fatalf("validate should keep provenance: %+v", validated.Provenance)
+	}
+
+	// 使用量が返らない応答ではトークン数を 0 のままにする
+	gen.response.UsageMetadata = nil
+	result, err = f.Format(context.Background(), &llm.FormatRequest{DarkPostID: "post-1", DarkContent: "闇"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Provenance.PromptTokens != 0 || result.Provenance.CompletionTokens != 0 || result.Provenance.Model != "gemini-2.5-flash" {
+		t.Fatalf("unexpected provenance without usage: %+v", result.Provenance)
+	}
+}
+
+// stubClock は呼ぶたびに step ずつ進む時計へ差し替え、元に戻す関数を返す。
+func stubClock(t *testing.T, step time.Duration) func() {
+	t.Helper()
+	orig := now
+	current := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time {
+		current = current.Add(step)
+		return current
 	}
+	return func() { now = orig }
 }
@@ -5,24 +5,28 @@ import (
 	"fmt"
 	"log"
 	"strings"
-	"unicode/utf8"
+	"time"
 
+	"backend/internal/adapter/llm/prompt"
 	"backend/internal/config"
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/fortune"
 	"backend/internal/port/llm"
 
 	"github.com/sashabaranov/go-openai"
 )
 
+// 生成元の記録に残す提供元。
+const providerName = "openai"
+
 const (
-	maxOutputTokens       = 1024
-	temperature           = 0.4
-	maxFormattedLength    = 150
-	minFormattedLength    = 30
-	fortunePrefix         = "今日のきらくじ:"
-	expectedSentenceCount = 3
+	maxOutputTokens = 1024
+	temperature     = 0.4
 )
 
+// 所要時間の計測に使う時計。テストで差し替える
+var now = time.Now
+
 /**
  * OpenAI へ会話リクエストを送るのに必要な最小限の操作をまとめた窓口。
  */
@@ -34,14 +38,16 @@ type ChatClient interface {
  * OpenAI と会話して闇投稿を整形し、検証処理も担う本体。
  */
 type Formatter struct {
-	client ChatClient
-	model  string
+	client   ChatClient
+	model    string
+	template *prompt.Template
 }
 
 /**
  * API キーやモデル名を点検してから OpenAI との橋渡し役を組み立てる。
+ * tmpl が nil なら既定の版のプロンプトを使う。
  */
-func NewFormatter(apiKey, model, baseURL string) (*Formatter, error) {
+func NewFormatter(apiKey, model, baseURL string, tmpl *prompt.Template) (*Formatter, error) {
 	if strings.TrimSpace(apiKey) == "" {
 		return nil, fmt.Errorf("openai formatter: API キーが設定されていません")
 	}
@@ -54,8 +60,9 @@ func NewFormatter(apiKey, model, baseURL string) (*Formatter, error) {
 		model = config.DefaultOpenAIModel
 	}
 	return &Formatter{
-		client: client,
-		model:  model,
+		client:   client,
+		model:    model,
+		template: tmpl,
 	}, nil
 }
 
@@ -77,15 +84,21 @@ func (f *Formatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.Fo
 		ctx = context.Background()
 	}
 
-	prompt := buildPrompt(string(req.DarkContent))
+	tmpl := f.promptTemplate()
+	rendered, err := tmpl.Render(string(req.DarkContent))
+	if err != nil {
+		return nil, err
+	}
+	startedAt := now()
 	resp, err := f.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
 		Model:       f.model,
 		Temperature: temperature,
 		MaxTokens:   maxOutputTokens,
 		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleUser, Content: prompt},
+			{Role: openai.ChatMessageRoleUser, Content: rendered},
 		},
 	})
+	latency := now().Sub(startedAt)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", llm.ErrFormatterUnavailable, err)
 	}
@@ -101,11 +114,31 @@ func (f *Formatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.Fo
 		DarkPostID:       req.DarkPostID,
 		FormattedContent: drawdomain.FormattedContent(text),
 		Status:           drawdomain.StatusPending,
+		Provenance:       f.provenance(resp, tmpl.Version(), latency),
 	}, nil
 }
 
 /**
- * 整形済みの文章に禁止語が紛れていないか、空でないかを確認して公開可否を決める。
+ * 応答のモデル名と消費トークン数、所要時間から、おみくじ結果の生成元の記録を組み立てる。
+ * モデル名はエイリアスを指定しても実際に使われた版が分かるよう、応答の値を優先する。
+ */
+func (f *Formatter) provenance(resp openai.ChatCompletionResponse, promptVersion string, latency time.Duration) drawdomain.Provenance {
+	model := resp.Model
+	if model == "" {
+		model = f.model
+	}
+	return drawdomain.Provenance{
+		Provider:         providerName,
+		Model:            model,
+		PromptVersion:    promptVersion,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		Latency:          latency,
+	}
+}
+
+/**
+ * 整形済みの文章が空でないかを確認し、改行を除いて検証済みとして返す。
  */
 func (f *Formatter) Validate(ctx context.Context, result *llm.FormatResult) (*llm.FormatResult, error) {
 	if result == nil || result.DarkPostID == "" {
@@ -119,13 +152,8 @@ func (f *Formatter) Validate(ctx context.Context, result *llm.FormatResult) (*ll
 		return result, llm.ErrInvalidFormat
 	}
 
-	normalized := normalizeFortuneText(trimmed)
-	if reason, rejected := shouldReject(normalized); rejected {
-		result.Status = drawdomain.StatusRejected
-		result.ValidationReason = reason
-		return result, llm.ErrContentRejected
-	}
-
+	// 文字数や構成などの規則は提供元によらずユースケースが fortune.Validator で検査する
+	normalized := fortune.Normalize(trimmed)
 	result.Status = drawdomain.StatusVerified
 	result.FormattedContent = drawdomain.FormattedContent(normalized)
 	result.ValidationReason = ""
@@ -162,102 +190,12 @@ func validateFormatRequest(req *llm.FormatRequest) error {
 	return nil
 }
 
-var rejectionKeywords = []string{"kill", "suicide", "die"}
-
-/**
- * 文字数や構成、禁止語を確認し、問題があれば理由を返す。
- */
-func shouldReject(text string) (string, bool) {
-	length := utf8.RuneCountInString(text)
-	if length < minFormattedLength {
-		return "整形結果が短すぎます", true
-	}
-	if length > maxFormattedLength {
-		return "整形結果が長すぎます", true
-	}
-
-	lower := strings.ToLower(text)
-	for _, keyword := range rejectionKeywords {
-		if strings.Contains(lower, keyword) {
-			return fmt.Sprintf("不適切な語句(%s)が含まれています", keyword), true
-		}
-	}
-	if strings.Contains(lower, "http://") || strings.Contains(lower, "https://") {
-		return "URL は含めないでください", true
-	}
-	if !strings.HasPrefix(text, fortunePrefix) {
-		return fmt.Sprintf("冒頭は「%s」で始めてください", fortunePrefix), true
-	}
-	if reason, rejected := violatesFortuneStructure(text); rejected {
-		return reason, true
-	}
-	return "", false
-}
-
-/**
- * 闇投稿をおみくじへ変換するための指示をまとめ、投稿本文を差し込んだ文面を返す。
- */
-func buildPrompt(content string) string {
-	template := `
-あなたは他人の闇投稿をもとに、別の人が引く「きらくじ」を作るメンヘラ占い師です。出力は日本語のみで行い、次の指示を厳守してください。
-
-【文章ルール】
-1. 合計 30〜100 文字の 3 文構成で書く。
-2. 各文の内容: (1) 今の状況は少し重めに捉える (2) ねちねちした現実的なメンヘラ占い師の思想 (3) メンヘラの毒を出す。
-3. 3 文すべて「〜ます。」で終え、句点（。）で区切る。
-4. 固有名詞・URL・箇条書き・顔文字は禁止
-5. A さんへの直接メッセージにはせず、B さんが引くきらくじとして書く
-
-【出力フォーマット】
-今日のきらくじ: 一文目。二文目。三文目。
-- 余計な前置きや後書きは不要
-- 改行せず 1 行で書ききる
-
-上記ルールを完全に満たす文章だけを 1 行で返してください。
-
-元になった闇投稿:
-%s`
-	return fmt.Sprintf(strings.TrimSpace(template), strings.TrimSpace(content))
-}
-
 /**
- * 改行や余白を整え、検証しやすい形へ揃える。
+ * 設定されたプロンプトを返す。構築時に渡されなかった場合は既定の版を使う。
  */
-func normalizeFortuneText(text string) string {
-	noCR := strings.ReplaceAll(text, "\r", "")
-	noLF := strings.ReplaceAll(noCR, "\n", "")
-	return strings.TrimSpace(noLF)
-}
-
-/**
- * お告げ文の構成や語尾が条件を満たしているかを調べる。
- */
-func violatesFortuneStructure(text string) (string, bool) {
-	body := strings.TrimSpace(strings.TrimPrefix(text, fortunePrefix))
-	sentences := splitSentences(body)
-	if len(sentences) != expectedSentenceCount {
-		return "お告げは3文構成で書いてください", true
-	}
-	for idx, sentence := range sentences {
-		if !strings.HasSuffix(sentence, "ます") {
-			return fmt.Sprintf("%d文目は「〜ます」で終えてください", idx+1), true
-		}
-	}
-	return "", false
-}
-
-/**
- * 句点で区切った文を抽出し、空の要素を除いて返す。
- */
-func splitSentences(body string) []string {
-	raw := strings.Split(body, "。")
-	sentences := make([]string, 0, len(raw))
-	for _, part := range raw {
-		trimmed := strings.TrimSpace(part)
-		if trimmed == "" {
-			continue
-		}
-		sentences = append(sentences, trimmed)
+func (f *Formatter) promptTemplate() *prompt.Template {
+	if f.template == nil {
+		return prompt.Default()
 	}
-	return sentences
+	return f.template
 }
@@ -0,0 +1,99 @@
+package prompt
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// DefaultVersion は版の指定が無いときに使うテンプレート。
+const DefaultVersion = "kirakuji-v1"
+
+// templateExt はテンプレートファイルの拡張子。版はこれを除いたファイル名になる。
+const templateExt = ".tmpl"
+
+var (
+	// ErrUnknownVersion は指定された版のテンプレートが見つからない場合に返される。
+	ErrUnknownVersion = errors.New("prompt: 指定された版のテンプレートがありません")
+	// ErrInvalidVersion は版の名前にパス区切りなどが含まれる場合に返される。
+	ErrInvalidVersion = errors.New("prompt: 版の名前が不正です")
+)
+
+//go:embed templates/*.tmpl
+var embedded embed.FS
+
+// Template は版の付いた整形用プロンプト。
+type Template struct {
+	version string
+	tmpl    *template.Template
+}
+
+// data はテンプレートへ渡す値。テンプレートからは {{.Content}} で参照する。
+type data struct {
+	Content string
+}
+
+// Load は fsys から version のテンプレートを読み込む。fsys が nil ならバイナリに埋め込んだテンプレートを使い、
+// version が空なら DefaultVersion を使う。
+func Load(fsys fs.FS, version string) (*Template, error) {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		version = DefaultVersion
+	}
+	if strings.ContainsAny(version, `/\`) || !fs.ValidPath(version) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+	if fsys == nil {
+		sub, err := fs.Sub(embedded, "templates")
+		if err != nil {
+			return nil, fmt.Errorf("prompt: open embedded templates: %w", err)
+		}
+		fsys = sub
+	}
+
+	raw, err := fs.ReadFile(fsys, version+templateExt)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("prompt: read template %s: %w", version, err)
+	}
+	tmpl, err := template.New(version).Option("missingkey=error").Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("prompt: parse template %s: %w", version, err)
+	}
+	return &Template{version: version, tmpl: tmpl}, nil
+}
+
+var loadDefault = sync.OnceValue(func() *Template {
+	t, err := Load(nil, DefaultVersion)
+	if err != nil {
+		// 埋め込みのテンプレートはビルド時に確定するため、ここで失敗するのはテンプレート自体の不備
+		panic(err)
+	}
+	return t
+})
+
+// Default は埋め込みの DefaultVersion のテンプレートを返す。
+func Default() *Template {
+	return loadDefault()
+}
+
+// Version はテンプレートの版を返す。おみくじ結果の生成元として記録する。
+func (t *Template) Version() string {
+	return t.version
+}
+
+// Render は闇投稿の本文を差し込んだプロンプトを返す。前後の空白は取り除く。
+func (t *Template) Render(content string) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data{Content: strings.TrimSpace(content)}); err != nil {
+		return "", fmt.Errorf("prompt: render %s: %w", t.version, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,70 @@
+package prompt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad_Embedded(t *testing.T) {
+	for _, version := range []string{"", DefaultVersion, "menhera-v1"} {
+		tmpl, err := Load(nil, version)
+		if err != nil {
+			t.Fatalf("Load(%q) error = %v", version, err)
+		}
+		want := version
+		if want == "" {
+			want = DefaultVersion
+		}
+		if tmpl.Version() != want {
+			t.Fatalf("unexpected version: want %s, got %s", want, tmpl.Version())
+		}
+		got, err := tmpl.Render("  とてもつらかった \n")
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if !strings.HasPrefix(got, "あなたは") || !strings.HasSuffix(got, "元になった闇投稿:\nとてもつらかった") {
+			t.Fatalf("unexpected prompt for %s: %q", want, got)
+		}
+	}
+	if Default().Version() != DefaultVersion {
+		t.Fatalf("Default() should return %s", DefaultVersion)
+	}
+}
+
+func TestLoad_FromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"trial-v2.tmpl":  {Data: []byte("本文: {{.Content}}\n")},
+		"broken-v1.tmpl": {Data: []byte("{{.Content")},
+		"unknown.tmpl":   {Data: []byte("{{.Author}}")},
+	}
+
+	tmpl, err := Load(fsys, " trial-v2 ")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := tmpl.Render("闇"); got != "本文: 闇" || tmpl.Version() != "trial-v2" {
+		t.Fatalf("unexpected render: %q (%s)", got, tmpl.Version())
+	}
+
+	// ディレクトリを指定したら埋め込みの版には戻らない
+	if _, err := Load(fsys, DefaultVersion); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+	if _, err := Load(fsys, "broken-v1"); err == nil {
+		t.Fatal("expected parse error")
+	}
+	unknown, err := Load(fsys, "unknown")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, err := unknown.Render("闇"); err == nil {
+		t.Fatal("expected render error for unknown field")
+	}
+	for _, version := range []string{"../secret", "dir/v1", `dir\v1`} {
+		if _, err := Load(fsys, version); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("expected ErrInvalidVersion for %q, got %v", version, err)
+		}
+	}
+}
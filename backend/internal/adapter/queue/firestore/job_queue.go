@@ -2,9 +2,14 @@ package firestore
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"backend/internal/domain/post"
@@ -16,46 +21,126 @@ import (
 )
 
 const (
-	formatJobsCollection = "format_jobs"
-	jobStatusPending     = "pending"
-	pollIntervalMin      = 1 * time.Minute
-	pollIntervalMax      = 1 * time.Minute
+	formatJobsCollection     = "format_jobs"
+	formatJobsDeadCollection = "format_jobs_dead"
+	jobStatusPending         = "pending"
+	jobStatusLeased          = "leased"
+	jobStatusRetrying        = "retrying"
+	// 変更通知を取りこぼした場合や再試行待ち・リース切れを拾うための補助ポーリング間隔
+	pollIntervalMin = 5 * time.Second
+	pollIntervalMax = 1 * time.Minute
+	// 変更通知の購読が切れた際、張り直すまでに空ける時間
+	listenRetryDelay = 5 * time.Second
+	// 取得したジョブを他ワーカーから隠しておく既定の時間
+	defaultLeaseDuration = 5 * time.Minute
 )
 
 var (
 	errMissingClient   = errors.New("firestorejobqueue: Firestore クライアントが指定されていません")
 	errEmptyPostID     = errors.New("firestorejobqueue: 投稿 ID が指定されていません")
 	errNoJobAvailable  = errors.New("firestorejobqueue: キューが空です")
+	errJobReclaimDead  = errors.New("firestorejobqueue: リース切れのジョブを隔離しました")
 	errDecodeJobFailed = errors.New("firestorejobqueue: ドキュメントの復元に失敗しました")
 )
 
 // Firestore に記録する整形ジョブ 1 件分の姿
 type jobDocument struct {
-	PostID string    `firestore:"post_id"`
-	Status string    `firestore:"status"`
-	Queued time.Time `firestore:"created_at"`
+	PostID         string    `firestore:"post_id"`
+	Status         string    `firestore:"status"`
+	Queued         time.Time `firestore:"created_at"`
+	LeaseOwner     string    `firestore:"lease_owner,omitempty"`
+	LeaseExpiresAt time.Time `firestore:"lease_expires_at,omitempty"`
+	Attempts       int       `firestore:"attempts"`
+	NotBefore      time.Time `firestore:"not_before,omitempty"`
+	LastError      string    `firestore:"last_error,omitempty"`
+}
+
+// 再試行上限に達して format_jobs_dead へ隔離した整形ジョブ 1 件分の姿
+type deadJobDocument struct {
+	PostID    string    `firestore:"post_id"`
+	Attempts  int       `firestore:"attempts"`
+	LastError string    `firestore:"last_error"`
+	FailedAt  time.Time `firestore:"failed_at"`
 }
 
 // Firestore を永続化に使う整形待ちキュー
 type FirestoreJobQueue struct {
-	client     *firestore.Client
-	collection string
-	closeOnce  sync.Once
-	closedCh   chan struct{}
+	client         *firestore.Client
+	collection     string
+	deadCollection string
+	leaseOwner     string
+	leaseSeq       atomic.Uint64
+	leaseDuration  time.Duration
+	retryPolicy    queue.RetryPolicy
+	// リースの期限切れで隔離したときに呼ぶ関数
+	notifyMu      sync.Mutex
+	onExpiredDead func(ctx context.Context, job queue.DeadFormatJob)
+	now           func() time.Time
+	listenOnce    sync.Once
+	wakeMu        sync.Mutex
+	wakeCh        chan struct{}
+	closeOnce     sync.Once
+	closedCh      chan struct{}
+}
+
+// 整形キューの挙動を調整する設定
+type Option func(*FirestoreJobQueue)
+
+/**
+ * 取得したジョブのリース期間を変更する。0 以下は無視する。
+ */
+func WithLeaseDuration(d time.Duration) Option {
+	return func(q *FirestoreJobQueue) {
+		if d > 0 {
+			q.leaseDuration = d
+		}
+	}
+}
+
+/**
+ * リースの持ち主として記録する識別子を変更する。空文字は無視する。
+ */
+func WithLeaseOwner(owner string) Option {
+	return func(q *FirestoreJobQueue) {
+		if owner != "" {
+			q.leaseOwner = owner
+		}
+	}
+}
+
+/**
+ * 失敗したジョブの再試行方針を変更する。試行回数が 1 未満の方針は無視する。
+ */
+func WithRetryPolicy(policy queue.RetryPolicy) Option {
+	return func(q *FirestoreJobQueue) {
+		if policy.MaxAttempts > 0 {
+			q.retryPolicy = policy
+		}
+	}
 }
 
 /**
  * Firestore 接続を受け取り、format_jobs を背後に使う整形キューを組み立てる。
  */
-func NewFirestoreJobQueue(client *firestore.Client) (*FirestoreJobQueue, error) {
+func NewFirestoreJobQueue(client *firestore.Client, opts ...Option) (*FirestoreJobQueue, error) {
 	if client == nil {
 		return nil, errMissingClient
 	}
-	return &FirestoreJobQueue{
-		client:     client,
-		collection: formatJobsCollection,
-		closedCh:   make(chan struct{}),
-	}, nil
+	q := &FirestoreJobQueue{
+		client:         client,
+		collection:     formatJobsCollection,
+		deadCollection: formatJobsDeadCollection,
+		leaseOwner:     newLeaseOwner(),
+		leaseDuration:  defaultLeaseDuration,
+		retryPolicy:    queue.DefaultRetryPolicy(),
+		now:            time.Now,
+		wakeCh:         make(chan struct{}),
+		closedCh:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q, nil
 }
 
 /**
@@ -70,12 +155,7 @@ func (q *FirestoreJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostI
 	}
 
 	doc := q.client.Collection(q.collection).Doc(string(id))
-	payload := map[string]any{
-		"post_id":    string(id),
-		"status":     jobStatusPending,
-		"created_at": firestore.ServerTimestamp,
-	}
-	_, err := doc.Create(ctx, payload)
+	_, err := doc.Create(ctx, NewPendingFormatJob(id))
 	if status.Code(err) == codes.AlreadyExists {
 		return queue.ErrJobAlreadyScheduled
 	}
@@ -86,32 +166,272 @@ func (q *FirestoreJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostI
 }
 
 /**
- * Firestore 上で最も古い整形待ちを 1 件だけ取得し、見つかるまで待機を繰り返す。
+ * format_jobs 上で整形ジョブ id を保持するドキュメントを返す。
+ * 投稿と同じトランザクションでジョブを積むアダプターが参照する。
+ */
+func FormatJobRef(client *firestore.Client, id post.DarkPostID) *firestore.DocumentRef {
+	return client.Collection(formatJobsCollection).Doc(string(id))
+}
+
+/**
+ * 整形待ちとして format_jobs へ新規作成する内容を返す。
  */
-func (q *FirestoreJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
+func NewPendingFormatJob(id post.DarkPostID) map[string]any {
+	return map[string]any{
+		"post_id":    string(id),
+		"status":     jobStatusPending,
+		"attempts":   0,
+		"created_at": firestore.ServerTimestamp,
+	}
+}
+
+/**
+ * Firestore 上で最も古い整形待ち（またはリース切れ）を 1 件リースし、見つかるまで待機を繰り返す。
+ * 待機中は format_jobs の変更通知で即座に起き、通知が届かない場合に備えてポーリングも続ける。
+ */
+func (q *FirestoreJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return queue.Lease{}, err
+	}
+	q.listenOnce.Do(q.startListener)
+
 	waitInterval := pollIntervalMin
 	for {
 		if err := q.ensureReady(ctx); err != nil {
-			return "", err
+			return queue.Lease{}, err
 		}
-		id, err := q.dequeueOnce(ctx)
+		// 取り出しを試す前に通知を受け取る口を確保し、試行中に届いた通知も取りこぼさない
+		wakeCh := q.wakeSignal()
+		lease, err := q.dequeueOnce(ctx)
 		if err == nil {
-			return id, nil
+			return lease, nil
+		}
+		// リース切れのジョブを隔離しただけなので、続けて次の候補を探す
+		if errors.Is(err, errJobReclaimDead) {
+			continue
 		}
 		// ジョブがまだ用意されていない場合は停止指示を監視しながら待機して再試行する
 		if errors.Is(err, errNoJobAvailable) {
 			select {
 			case <-ctx.Done():
-				return "", fmt.Errorf("%w: %v", queue.ErrContextClosed, ctx.Err())
+				return queue.Lease{}, fmt.Errorf("%w: %v", queue.ErrContextClosed, ctx.Err())
 			case <-q.closedCh:
-				return "", queue.ErrQueueClosed
+				return queue.Lease{}, queue.ErrQueueClosed
+			case <-wakeCh:
+				// 新しいジョブが届いたので待ち間隔を戻してすぐ取りに行く
+				waitInterval = pollIntervalMin
+				continue
 			case <-time.After(waitInterval):
 				waitInterval = nextPollInterval(waitInterval)
 				continue
 			}
 		}
-		return "", err
+		return queue.Lease{}, err
+	}
+}
+
+/**
+ * 自身がリース中のジョブを処理済みとして削除する。
+ */
+func (q *FirestoreJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return q.releaseLease(ctx, lease, func(tx *firestore.Transaction, ref *firestore.DocumentRef, _ *jobDocument) error {
+		return tx.Delete(ref)
+	})
+}
+
+/**
+ * 自身がリース中のジョブを整形待ちへ戻し、すぐに再取得できるようにする。
+ */
+func (q *FirestoreJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return q.releaseLease(ctx, lease, func(tx *firestore.Transaction, ref *firestore.DocumentRef, _ *jobDocument) error {
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: jobStatusPending},
+			{Path: "lease_owner", Value: firestore.Delete},
+			{Path: "lease_expires_at", Value: firestore.Delete},
+		})
+	})
+}
+
+/**
+ * 自身がリース中のジョブの試行回数を数え、上限までは待機時間を置いて再取得可能にする。
+ * 上限に達したら format_jobs_dead へ移して ErrJobDeadLettered を返す。
+ */
+func (q *FirestoreJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	id := lease.PostID
+	lastError := ""
+	if cause != nil {
+		lastError = cause.Error()
+	}
+	deadLettered := false
+	err := q.releaseLease(ctx, lease, func(tx *firestore.Transaction, ref *firestore.DocumentRef, job *jobDocument) error {
+		attempts := job.Attempts + 1
+		now := q.now()
+		if q.retryPolicy.Exhausted(attempts) {
+			deadLettered = true
+			deadRef := q.client.Collection(q.deadCollection).Doc(string(id))
+			if err := tx.Set(deadRef, deadJobDocument{
+				PostID:    string(id),
+				Attempts:  attempts,
+				LastError: lastError,
+				FailedAt:  now,
+			}); err != nil {
+				return err
+			}
+			return tx.Delete(ref)
+		}
+		deadLettered = false
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: jobStatusRetrying},
+			{Path: "attempts", Value: attempts},
+			{Path: "not_before", Value: now.Add(q.retryPolicy.Backoff(attempts))},
+			{Path: "last_error", Value: lastError},
+			{Path: "lease_owner", Value: firestore.Delete},
+			{Path: "lease_expires_at", Value: firestore.Delete},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if deadLettered {
+		return queue.ErrJobDeadLettered
+	}
+	return nil
+}
+
+/**
+ * format_jobs を参照してジョブの状況を返し、無ければ format_jobs_dead を探す。
+ */
+func (q *FirestoreJobQueue) InspectFormat(ctx context.Context, id post.DarkPostID) (*queue.FormatJobStatus, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, errEmptyPostID
+	}
+
+	snap, err := q.client.Collection(q.collection).Doc(string(id)).Get(ctx)
+	if err == nil {
+		var job jobDocument
+		if err := snap.DataTo(&job); err != nil {
+			return nil, fmt.Errorf("%w: %v", errDecodeJobFailed, err)
+		}
+		info := &queue.FormatJobStatus{
+			PostID:    id,
+			State:     queue.FormatJobPending,
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+		}
+		switch job.Status {
+		case jobStatusLeased:
+			info.State = queue.FormatJobLeased
+		case jobStatusRetrying:
+			info.State = queue.FormatJobRetrying
+			info.NextAttemptAt = job.NotBefore
+		}
+		return info, nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, translateContextError(fmt.Errorf("get job: %w", err))
+	}
+
+	deadSnap, err := q.client.Collection(q.deadCollection).Doc(string(id)).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, queue.ErrJobNotFound
+		}
+		return nil, translateContextError(fmt.Errorf("get dead job: %w", err))
+	}
+	var dead deadJobDocument
+	if err := deadSnap.DataTo(&dead); err != nil {
+		return nil, fmt.Errorf("%w: %v", errDecodeJobFailed, err)
 	}
+	return &queue.FormatJobStatus{
+		PostID:    id,
+		State:     queue.FormatJobDead,
+		Attempts:  dead.Attempts,
+		LastError: dead.LastError,
+	}, nil
+}
+
+/**
+ * format_jobs_dead から新しく隔離された順に最大 limit 件を返す。
+ */
+func (q *FirestoreJobQueue) ListDeadFormat(ctx context.Context, limit int) ([]*queue.DeadFormatJob, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+
+	query := q.client.Collection(q.deadCollection).OrderBy("failed_at", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateContextError(fmt.Errorf("list dead jobs: %w", err))
+	}
+
+	jobs := make([]*queue.DeadFormatJob, 0, len(docs))
+	for _, doc := range docs {
+		var dead deadJobDocument
+		if err := doc.DataTo(&dead); err != nil {
+			return nil, fmt.Errorf("%w: %v", errDecodeJobFailed, err)
+		}
+		jobs = append(jobs, &queue.DeadFormatJob{
+			PostID:    post.DarkPostID(dead.PostID),
+			Attempts:  dead.Attempts,
+			LastError: dead.LastError,
+			FailedAt:  dead.FailedAt,
+		})
+	}
+	return jobs, nil
+}
+
+/**
+ * format_jobs_dead のジョブを試行回数 0 の整形待ちとして format_jobs へ戻す。
+ */
+func (q *FirestoreJobQueue) ReplayDeadFormat(ctx context.Context, id post.DarkPostID) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if id == "" {
+		return errEmptyPostID
+	}
+
+	deadRef := q.client.Collection(q.deadCollection).Doc(string(id))
+	jobRef := q.client.Collection(q.collection).Doc(string(id))
+	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(deadRef); err != nil {
+			if status.Code(err) == codes.NotFound {
+				return queue.ErrDeadJobNotFound
+			}
+			return err
+		}
+		// 同じ投稿のジョブが別途積まれていれば二重登録になるので触らない
+		if _, err := tx.Get(jobRef); err == nil {
+			return queue.ErrJobAlreadyScheduled
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err := tx.Create(jobRef, map[string]any{
+			"post_id":    string(id),
+			"status":     jobStatusPending,
+			"attempts":   0,
+			"created_at": firestore.ServerTimestamp,
+		}); err != nil {
+			return err
+		}
+		return tx.Delete(deadRef)
+	}, firestore.MaxAttempts(5))
+	if err != nil {
+		if errors.Is(err, queue.ErrDeadJobNotFound) || errors.Is(err, queue.ErrJobAlreadyScheduled) {
+			return err
+		}
+		if status.Code(err) == codes.AlreadyExists {
+			return queue.ErrJobAlreadyScheduled
+		}
+		return translateContextError(fmt.Errorf("replay dead job tx: %w", err))
+	}
+	return nil
 }
 
 /**
@@ -127,6 +447,78 @@ func (q *FirestoreJobQueue) Close() error {
 	return nil
 }
 
+/**
+ * キューが閉じられるまで format_jobs の整形待ちを購読するゴルーチンを起動する。
+ */
+func (q *FirestoreJobQueue) startListener() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-q.closedCh
+		cancel()
+	}()
+	go q.listen(ctx)
+}
+
+/**
+ * 整形待ちの追加・差し戻しを Snapshots で受け取り、待機中の取り出しを起こす。購読が切れたら張り直す。
+ */
+func (q *FirestoreJobQueue) listen(ctx context.Context) {
+	query := q.client.Collection(q.collection).Where("status", "==", jobStatusPending)
+	for {
+		it := query.Snapshots(ctx)
+		err := q.watch(it)
+		it.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+		// 購読できない間も補助ポーリングで取り出しは続くため、ログだけ残して張り直す
+		log.Printf("firestorejobqueue: 変更通知の購読が切れました: %v", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+/**
+ * スナップショットを読み続け、取り出し可能なドキュメントが増えたら通知する。
+ */
+func (q *FirestoreJobQueue) watch(it *firestore.QuerySnapshotIterator) error {
+	for {
+		snap, err := it.Next()
+		if err != nil {
+			return err
+		}
+		for _, change := range snap.Changes {
+			// 削除は他ワーカーの取得や Ack によるものなので起こさない
+			if change.Kind == firestore.DocumentAdded || change.Kind == firestore.DocumentModified {
+				q.wake()
+				break
+			}
+		}
+	}
+}
+
+/**
+ * 次の通知で閉じられるチャネルを返す。待機中の取り出しはこれを待つ。
+ */
+func (q *FirestoreJobQueue) wakeSignal() <-chan struct{} {
+	q.wakeMu.Lock()
+	defer q.wakeMu.Unlock()
+	return q.wakeCh
+}
+
+/**
+ * 待機中の取り出しをすべて起こす。チャネルを閉じて差し替えるため、同時に待つゴルーチンが何件あっても全員に届く。
+ */
+func (q *FirestoreJobQueue) wake() {
+	q.wakeMu.Lock()
+	defer q.wakeMu.Unlock()
+	close(q.wakeCh)
+	q.wakeCh = make(chan struct{})
+}
+
 /**
  * 呼び出し側の中断や自身の停止状態を確認し、継続可否を判定する。
  */
@@ -153,47 +545,196 @@ func (q *FirestoreJobQueue) ensureReady(ctx context.Context) error {
 }
 
 /**
- * Firestore の format_jobs から一番古いジョブをトランザクションで取得し、その場で削除する。
+ * Firestore の format_jobs から一番古いジョブをトランザクションで取得し、自身のリースを付ける。
  */
-func (q *FirestoreJobQueue) dequeueOnce(ctx context.Context) (post.DarkPostID, error) {
-	query := q.client.Collection(q.collection).OrderBy("created_at", firestore.Asc).Limit(1)
-	var dequeued post.DarkPostID
-	// トランザクションでドキュメント取得と削除をまとめ、複数ワーカーからの重複処理を避ける
+func (q *FirestoreJobQueue) dequeueOnce(ctx context.Context) (queue.Lease, error) {
+	var dequeued queue.Lease
+	var dead deadJobDocument
+	deadLettered := false
+	// 同じワーカー内の並列な取り出し同士でもリースを区別できるよう、取り出しごとにトークンを払い出す
+	token := q.newLeaseToken()
+	// トランザクションで取得とリース付与をまとめ、複数ワーカーからの重複処理を避ける
 	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		docs, err := tx.Documents(query).GetAll()
+		deadLettered = false
+		now := q.now()
+		doc, err := q.findClaimable(tx, now)
 		if err != nil {
 			return err
 		}
-		if len(docs) == 0 {
-			return errNoJobAvailable
-		}
 		var job jobDocument
-		if err := docs[0].DataTo(&job); err != nil {
+		if err := doc.DataTo(&job); err != nil {
 			return fmt.Errorf("%w: %v", errDecodeJobFailed, err)
 		}
 		if job.PostID == "" {
 			return fmt.Errorf("%w: post_id が空です", errDecodeJobFailed)
 		}
-		// 自身で削除に成功した時点でジョブ獲得とみなす
-		if err := tx.Delete(docs[0].Ref); err != nil {
+		updates := []firestore.Update{
+			{Path: "status", Value: jobStatusLeased},
+			{Path: "lease_owner", Value: token},
+			{Path: "lease_expires_at", Value: now.Add(q.leaseDuration)},
+		}
+		// リース切れの再取得は前の処理が確定しなかった試行として数え、上限なら隔離する
+		if job.Status == jobStatusLeased {
+			attempts := job.Attempts + 1
+			if q.retryPolicy.Exhausted(attempts) {
+				dead = deadJobDocument{
+					PostID:    job.PostID,
+					Attempts:  attempts,
+					LastError: queue.ErrLeaseExpired.Error(),
+					FailedAt:  now,
+				}
+				if err := tx.Set(q.client.Collection(q.deadCollection).Doc(job.PostID), dead); err != nil {
+					return err
+				}
+				deadLettered = true
+				return tx.Delete(doc.Ref)
+			}
+			updates = append(updates,
+				firestore.Update{Path: "attempts", Value: attempts},
+				firestore.Update{Path: "last_error", Value: queue.ErrLeaseExpired.Error()},
+			)
+		}
+		// 自身のリースを書き込めた時点でジョブ獲得とみなす
+		if err := tx.Update(doc.Ref, updates); err != nil {
 			if status.Code(err) == codes.NotFound {
 				return errNoJobAvailable
 			}
 			return err
 		}
-		dequeued = post.DarkPostID(job.PostID)
+		dequeued = queue.Lease{PostID: post.DarkPostID(job.PostID), Token: token}
 		return nil
 	}, firestore.MaxAttempts(5))
 	// トランザクション結果をキュー用のエラーへ丸める
 	if err != nil {
 		if errors.Is(err, errNoJobAvailable) {
-			return "", errNoJobAvailable
+			return queue.Lease{}, errNoJobAvailable
 		}
-		return "", translateContextError(fmt.Errorf("dequeue tx: %w", err))
+		return queue.Lease{}, translateContextError(fmt.Errorf("dequeue tx: %w", err))
+	}
+	if deadLettered {
+		q.notifyExpiredDead(ctx, dead)
+		return queue.Lease{}, errJobReclaimDead
 	}
 	return dequeued, nil
 }
 
+/**
+ * リースの期限切れで隔離したときに呼ぶ関数を登録する。
+ */
+func (q *FirestoreJobQueue) NotifyExpiredDeadLetters(fn func(ctx context.Context, job queue.DeadFormatJob)) {
+	q.notifyMu.Lock()
+	defer q.notifyMu.Unlock()
+	q.onExpiredDead = fn
+}
+
+/**
+ * 登録された関数へ期限切れで隔離したジョブを知らせる。取り出しの中断に巻き込まれないよう、キャンセルは引き継がない。
+ */
+func (q *FirestoreJobQueue) notifyExpiredDead(ctx context.Context, dead deadJobDocument) {
+	q.notifyMu.Lock()
+	notify := q.onExpiredDead
+	q.notifyMu.Unlock()
+	if notify == nil {
+		return
+	}
+	notify(context.WithoutCancel(ctx), queue.DeadFormatJob{
+		PostID:    post.DarkPostID(dead.PostID),
+		Attempts:  dead.Attempts,
+		LastError: dead.LastError,
+		FailedAt:  dead.FailedAt,
+	})
+}
+
+/**
+ * 整形待ちを優先し、次に待機時間を過ぎた再試行待ち、最後にリース期限切れのジョブを取得候補として返す。
+ */
+func (q *FirestoreJobQueue) findClaimable(tx *firestore.Transaction, now time.Time) (*firestore.DocumentSnapshot, error) {
+	collection := q.client.Collection(q.collection)
+	pending := collection.
+		Where("status", "==", jobStatusPending).
+		OrderBy("created_at", firestore.Asc).
+		Limit(1)
+	docs, err := tx.Documents(pending).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) > 0 {
+		return docs[0], nil
+	}
+
+	// 失敗後の待機時間を過ぎた再試行待ちを拾う
+	retrying := collection.
+		Where("status", "==", jobStatusRetrying).
+		Where("not_before", "<=", now).
+		OrderBy("not_before", firestore.Asc).
+		Limit(1)
+	docs, err = tx.Documents(retrying).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) > 0 {
+		return docs[0], nil
+	}
+
+	// ワーカー停止などで Ack されずに期限が切れたリースを拾い直す
+	expired := collection.
+		Where("status", "==", jobStatusLeased).
+		Where("lease_expires_at", "<=", now).
+		OrderBy("lease_expires_at", firestore.Asc).
+		Limit(1)
+	docs, err = tx.Documents(expired).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, errNoJobAvailable
+	}
+	return docs[0], nil
+}
+
+/**
+ * 渡されたリースがまだ有効であることを確かめてから、渡された更新でリースを解放する。
+ */
+func (q *FirestoreJobQueue) releaseLease(
+	ctx context.Context,
+	lease queue.Lease,
+	release func(tx *firestore.Transaction, ref *firestore.DocumentRef, job *jobDocument) error,
+) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if lease.PostID == "" {
+		return errEmptyPostID
+	}
+
+	ref := q.client.Collection(q.collection).Doc(string(lease.PostID))
+	err := q.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return queue.ErrJobNotLeased
+			}
+			return err
+		}
+		var job jobDocument
+		if err := doc.DataTo(&job); err != nil {
+			return fmt.Errorf("%w: %v", errDecodeJobFailed, err)
+		}
+		// 期限切れで他ワーカーに取られたジョブは触らない
+		if job.Status != jobStatusLeased || job.LeaseOwner != lease.Token {
+			return queue.ErrJobNotLeased
+		}
+		return release(tx, ref, &job)
+	}, firestore.MaxAttempts(5))
+	if err != nil {
+		if errors.Is(err, queue.ErrJobNotLeased) {
+			return queue.ErrJobNotLeased
+		}
+		return translateContextError(fmt.Errorf("release lease tx: %w", err))
+	}
+	return nil
+}
+
 /**
  * コンテキスト関連のエラーを共通の ErrContextClosed にそろえて返す。
  */
@@ -208,6 +749,9 @@ func translateContextError(err error) error {
 	return err
 }
 
+/**
+ * 補助ポーリングの待ち間隔を倍にし、上限と下限の範囲へ収める。
+ */
 func nextPollInterval(current time.Duration) time.Duration {
 	next := current * 2
 	if next > pollIntervalMax {
@@ -219,4 +763,36 @@ func nextPollInterval(current time.Duration) time.Duration {
 	return next
 }
 
-var _ queue.JobQueue = (*FirestoreJobQueue)(nil)
+/**
+ * リース所有者 ID に取り出しごとの連番と乱数を付け、lease_owner に書き込むトークンを作る。
+ */
+func (q *FirestoreJobQueue) newLeaseToken() string {
+	seq := q.leaseSeq.Add(1)
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%s/%d", q.leaseOwner, seq)
+	}
+	return fmt.Sprintf("%s/%d-%s", q.leaseOwner, seq, hex.EncodeToString(buf))
+}
+
+/**
+ * ホスト名と乱数からワーカーごとに一意なリース所有者 ID を作る。
+ */
+func newLeaseOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "worker"
+	}
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return host
+	}
+	return fmt.Sprintf("%s-%s", host, hex.EncodeToString(buf))
+}
+
+var (
+	_ queue.JobQueue             = (*FirestoreJobQueue)(nil)
+	_ queue.DeadLetterQueue      = (*FirestoreJobQueue)(nil)
+	_ queue.JobInspector         = (*FirestoreJobQueue)(nil)
+	_ queue.ExpiredLeaseNotifier = (*FirestoreJobQueue)(nil)
+)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,8 +35,8 @@ func TestFirestoreJobQueue_EnqueueAndDequeue(t *testing.T) {
 	if err != nil {
 		t.Fatalf("dequeue: %v", err)
 	}
-	if got != post.DarkPostID("post-firestore-1") {
-		t.Fatalf("unexpected id: %s", got)
+	if got.PostID != post.DarkPostID("post-firestore-1") || got.Token == "" {
+		t.Fatalf("unexpected lease: %+v", got)
 	}
 }
 
@@ -66,7 +67,8 @@ func TestFirestoreJobQueue_DequeueWaitsForNewJob(t *testing.T) {
 		t.Fatalf("NewFirestoreJobQueue: %v", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 補助ポーリングの間隔より短い時間で、変更通知によって取り出せることを確認する
+	ctx, cancel := context.WithTimeout(context.Background(), pollIntervalMin/2)
 	defer cancel()
 
 	type result struct {
@@ -75,8 +77,8 @@ func TestFirestoreJobQueue_DequeueWaitsForNewJob(t *testing.T) {
 	}
 	done := make(chan result)
 	go func() {
-		id, err := queue.DequeueFormat(ctx)
-		done <- result{id: id, err: err}
+		lease, err := queue.DequeueFormat(ctx)
+		done <- result{id: lease.PostID, err: err}
 	}()
 
 	time.Sleep(200 * time.Millisecond)
@@ -138,6 +140,269 @@ func TestFirestoreJobQueue_DequeueContextCanceled(t *testing.T) {
 	}
 }
 
+func TestFirestoreJobQueue_AckRemovesLeasedJob(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, formatJobsCollection)
+
+	queue, err := NewFirestoreJobQueue(client)
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-ack")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease, err := queue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+
+	// リース中は同一 ID を再登録できない
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-ack")); !errors.Is(err, portqueue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled while leased, got %v", err)
+	}
+	if err := queue.AckFormat(ctx, lease); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := queue.AckFormat(ctx, lease); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased on second ack, got %v", err)
+	}
+}
+
+func TestFirestoreJobQueue_NackMakesJobClaimableAgain(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, formatJobsCollection)
+
+	queue, err := NewFirestoreJobQueue(client)
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-nack")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease, err := queue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	if err := queue.NackFormat(ctx, lease); err != nil {
+		t.Fatalf("nack: %v", err)
+	}
+
+	got, err := queue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue after nack: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-nack") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+}
+
+func TestFirestoreJobQueue_ExpiredLeaseIsReclaimedByAnotherWorker(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, formatJobsCollection)
+
+	crashed, err := NewFirestoreJobQueue(client, WithLeaseOwner("worker-a"), WithLeaseDuration(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+	survivor, err := NewFirestoreJobQueue(client, WithLeaseOwner("worker-b"))
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := crashed.EnqueueFormat(ctx, post.DarkPostID("post-crash")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	stale, err := crashed.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	got, err := survivor.dequeueOnce(ctx)
+	if err != nil {
+		t.Fatalf("reclaim expired lease: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-crash") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+	// 期限切れで取られたジョブは元の持ち主から Ack できない
+	if err := crashed.AckFormat(ctx, stale); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased for stale owner, got %v", err)
+	}
+	if err := survivor.AckFormat(ctx, got); err != nil {
+		t.Fatalf("ack by new owner: %v", err)
+	}
+}
+
+func TestFirestoreJobQueue_ExpiredLeaseCountsAsAttempt(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, formatJobsCollection)
+	truncateCollection(t, client, formatJobsDeadCollection)
+
+	policy := portqueue.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	queue, err := NewFirestoreJobQueue(client, WithLeaseDuration(50*time.Millisecond), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+
+	ctx := context.Background()
+	id := post.DarkPostID("post-hang")
+	if err := queue.EnqueueFormat(ctx, id); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := queue.dequeueOnce(ctx); err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// 1 回目のリース切れは試行として数えて再取得させる
+	if _, err := queue.dequeueOnce(ctx); err != nil {
+		t.Fatalf("reclaim expired lease: %v", err)
+	}
+	info, err := queue.InspectFormat(ctx, id)
+	if err != nil || info.Attempts != 1 || info.LastError != portqueue.ErrLeaseExpired.Error() {
+		t.Fatalf("unexpected reclaimed job: %+v (%v)", info, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// 上限に達したリース切れは取り出さずに隔離する
+	if _, err := queue.dequeueOnce(ctx); !errors.Is(err, errJobReclaimDead) {
+		t.Fatalf("expected errJobReclaimDead, got %v", err)
+	}
+	if info, err := queue.InspectFormat(ctx, id); err != nil || info.State != portqueue.FormatJobDead || info.Attempts != 2 {
+		t.Fatalf("expected dead job, got %+v (%v)", info, err)
+	}
+}
+
+func TestFirestoreJobQueue_RetryUntilDeadLetterAndReplay(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, formatJobsCollection)
+	truncateCollection(t, client, formatJobsDeadCollection)
+
+	policy := portqueue.RetryPolicy{MaxAttempts: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	queue, err := NewFirestoreJobQueue(client, WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewFirestoreJobQueue: %v", err)
+	}
+
+	ctx := context.Background()
+	id := post.DarkPostID("post-dead")
+	if err := queue.EnqueueFormat(ctx, id); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease, err := queue.dequeueOnce(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	if err := queue.RetryFormat(ctx, lease, errors.New("first")); err != nil {
+		t.Fatalf("first retry: %v", err)
+	}
+	info, err := queue.InspectFormat(ctx, id)
+	if err != nil {
+		t.Fatalf("inspect retrying: %v", err)
+	}
+	if info.State != portqueue.FormatJobRetrying || info.Attempts != 1 || info.LastError != "first" {
+		t.Fatalf("unexpected retrying job: %+v", info)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// 待機時間を過ぎた再試行待ちは再び取り出せる
+	lease, err = queue.dequeueOnce(ctx)
+	if err != nil {
+		t.Fatalf("dequeue after backoff: %v", err)
+	}
+	if err := queue.RetryFormat(ctx, lease, errors.New("second")); !errors.Is(err, portqueue.ErrJobDeadLettered) {
+		t.Fatalf("expected ErrJobDeadLettered, got %v", err)
+	}
+
+	dead, err := queue.ListDeadFormat(ctx, 10)
+	if err != nil {
+		t.Fatalf("list dead: %v", err)
+	}
+	if len(dead) != 1 || dead[0].PostID != id || dead[0].Attempts != 2 || dead[0].LastError != "second" {
+		t.Fatalf("unexpected dead jobs: %+v", dead)
+	}
+	if info, err := queue.InspectFormat(ctx, id); err != nil || info.State != portqueue.FormatJobDead {
+		t.Fatalf("expected dead job, got %+v (%v)", info, err)
+	}
+
+	if err := queue.ReplayDeadFormat(ctx, id); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if err := queue.ReplayDeadFormat(ctx, id); !errors.Is(err, portqueue.ErrDeadJobNotFound) {
+		t.Fatalf("expected ErrDeadJobNotFound on second replay, got %v", err)
+	}
+	if _, err := queue.dequeueOnce(ctx); err != nil {
+		t.Fatalf("dequeue after replay: %v", err)
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		current time.Duration
+		want    time.Duration
+	}{
+		{current: 0, want: pollIntervalMin},
+		{current: pollIntervalMin, want: 2 * pollIntervalMin},
+		{current: pollIntervalMax, want: pollIntervalMax},
+	}
+	for _, tc := range cases {
+		if got := nextPollInterval(tc.current); got != tc.want {
+			t.Fatalf("nextPollInterval(%v) = %v, want %v", tc.current, got, tc.want)
+		}
+	}
+}
+
+func TestFirestoreJobQueue_WakeBroadcastsToAllWaiters(t *testing.T) {
+	t.Parallel()
+
+	queue := &FirestoreJobQueue{wakeCh: make(chan struct{})}
+	// 同時に待っている取り出しは 1 回の通知で全員起きる
+	waiters := []<-chan struct{}{queue.wakeSignal(), queue.wakeSignal(), queue.wakeSignal()}
+	queue.wake()
+	for i, ch := range waiters {
+		select {
+		case <-ch:
+		default:
+			t.Fatalf("waiter %d was not woken", i)
+		}
+	}
+
+	// 通知後に待ち始めた取り出しは次の通知まで待つ
+	next := queue.wakeSignal()
+	select {
+	case <-next:
+		t.Fatalf("new waiter should not see a past notification")
+	default:
+	}
+	queue.wake()
+	select {
+	case <-next:
+	default:
+		t.Fatalf("expected next notification to wake the new waiter")
+	}
+}
+
+func TestFirestoreJobQueue_LeaseTokenIsUniquePerDequeue(t *testing.T) {
+	t.Parallel()
+
+	queue := &FirestoreJobQueue{leaseOwner: "worker-a"}
+	first, second := queue.newLeaseToken(), queue.newLeaseToken()
+	if first == second {
+		t.Fatalf("expected distinct tokens, got %q twice", first)
+	}
+	if !strings.HasPrefix(first, "worker-a/") || !strings.HasPrefix(second, "worker-a/") {
+		t.Fatalf("tokens should carry the lease owner: %q, %q", first, second)
+	}
+}
+
 // newTestFirestoreClient は Firestore エミュレータに接続するクライアントを返す。
 func newTestFirestoreClient(t *testing.T) *firestore.Client {
 	t.Helper()
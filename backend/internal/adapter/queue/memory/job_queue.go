@@ -0,0 +1,495 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+)
+
+const (
+	// defaultCapacity はチャネルに溜めておける整形ジョブ数の既定値。
+	defaultCapacity = 1024
+	// defaultLeaseDuration は取得したジョブを他の取り出しから隠しておく既定の時間。
+	defaultLeaseDuration = 5 * time.Minute
+)
+
+var errEmptyPostID = errors.New("memoryjobqueue: 投稿 ID が指定されていません")
+
+// チャネルを背後に使うメモリ常駐の整形待ちキュー
+type InMemoryJobQueue struct {
+	jobs          chan post.DarkPostID
+	mu            sync.Mutex
+	scheduled     map[post.DarkPostID]struct{}
+	leases        map[post.DarkPostID]*lease
+	leaseDuration time.Duration
+	retryPolicy   queue.RetryPolicy
+	attempts      map[post.DarkPostID]int
+	lastErrors    map[post.DarkPostID]string
+	delayed       map[post.DarkPostID]*delayedJob
+	dead          map[post.DarkPostID]*queue.DeadFormatJob
+	leaseSeq      uint64
+	// リースの期限切れで隔離したときに呼ぶ関数
+	onExpiredDead func(ctx context.Context, job queue.DeadFormatJob)
+	now           func() time.Time
+	closeOnce     sync.Once
+	closedCh      chan struct{}
+}
+
+// 取り出し中のジョブ 1 件分のリース。期限が来たらタイマーがキューへ戻す。
+type lease struct {
+	token string
+	timer *time.Timer
+}
+
+// 再試行待ちのジョブ 1 件分。待機が明けたらタイマーがキューへ戻す。
+type delayedJob struct {
+	timer     *time.Timer
+	notBefore time.Time
+}
+
+// 整形キューの挙動を調整する設定
+type Option func(*InMemoryJobQueue)
+
+/**
+ * 取得したジョブのリース期間を変更する。0 以下は無視する。
+ */
+func WithLeaseDuration(d time.Duration) Option {
+	return func(q *InMemoryJobQueue) {
+		if d > 0 {
+			q.leaseDuration = d
+		}
+	}
+}
+
+/**
+ * 失敗したジョブの再試行方針を変更する。試行回数が 1 未満の方針は無視する。
+ */
+func WithRetryPolicy(policy queue.RetryPolicy) Option {
+	return func(q *InMemoryJobQueue) {
+		if policy.MaxAttempts > 0 {
+			q.retryPolicy = policy
+		}
+	}
+}
+
+/**
+ * 指定件数まで溜められるキューを組み立てる。0 以下なら既定値を使う。
+ */
+func NewInMemoryJobQueue(capacity int, opts ...Option) *InMemoryJobQueue {
+	if capacity <= 0 {
+		capacity = defaultCapacity
+	}
+	q := &InMemoryJobQueue{
+		jobs:          make(chan post.DarkPostID, capacity),
+		scheduled:     make(map[post.DarkPostID]struct{}),
+		leases:        make(map[post.DarkPostID]*lease),
+		leaseDuration: defaultLeaseDuration,
+		retryPolicy:   queue.DefaultRetryPolicy(),
+		attempts:      make(map[post.DarkPostID]int),
+		lastErrors:    make(map[post.DarkPostID]string),
+		delayed:       make(map[post.DarkPostID]*delayedJob),
+		dead:          make(map[post.DarkPostID]*queue.DeadFormatJob),
+		now:           time.Now,
+		closedCh:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+/**
+ * 整形待ち投稿の ID をチャネルへ積み、完了前の二重登録なら専用エラーを返す。
+ */
+func (q *InMemoryJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if id == "" {
+		return errEmptyPostID
+	}
+
+	// 取り出し前やリース中、再試行待ちの同一 ID は Firestore 実装と同じく重複扱いにする
+	q.mu.Lock()
+	if _, exists := q.scheduled[id]; exists {
+		q.mu.Unlock()
+		return queue.ErrJobAlreadyScheduled
+	}
+	q.scheduled[id] = struct{}{}
+	q.mu.Unlock()
+
+	return q.send(ctx, id)
+}
+
+/**
+ * 重複判定に登録済みの ID をチャネルへ積む。中断されたら登録を取り消す。
+ */
+func (q *InMemoryJobQueue) send(ctx context.Context, id post.DarkPostID) error {
+	// 満杯の場合は空きが出るか停止指示が来るまで待つ
+	select {
+	case q.jobs <- id:
+		return nil
+	case <-ctx.Done():
+		q.forget(id)
+		return fmt.Errorf("%w: %v", queue.ErrContextClosed, ctx.Err())
+	case <-q.closedCh:
+		q.forget(id)
+		return queue.ErrQueueClosed
+	}
+}
+
+/**
+ * 最も古い整形待ちを 1 件リースして取り出し、届くまで停止指示を監視しながら待機する。
+ */
+func (q *InMemoryJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return queue.Lease{}, err
+	}
+
+	select {
+	case id := <-q.jobs:
+		return queue.Lease{PostID: id, Token: q.lease(id)}, nil
+	case <-ctx.Done():
+		return queue.Lease{}, fmt.Errorf("%w: %v", queue.ErrContextClosed, ctx.Err())
+	case <-q.closedCh:
+		return queue.Lease{}, queue.ErrQueueClosed
+	}
+}
+
+/**
+ * リース中のジョブを処理済みとして取り除く。
+ */
+func (q *InMemoryJobQueue) AckFormat(ctx context.Context, l queue.Lease) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if err := q.release(l); err != nil {
+		return err
+	}
+	q.forget(l.PostID)
+	return nil
+}
+
+/**
+ * リース中のジョブの試行回数を数え、上限までは待機時間を置いてキューへ戻す。
+ * 上限に達したら隔離して ErrJobDeadLettered を返す。
+ */
+func (q *InMemoryJobQueue) RetryFormat(ctx context.Context, l queue.Lease, cause error) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if err := q.release(l); err != nil {
+		return err
+	}
+	id := l.PostID
+
+	q.mu.Lock()
+	attempts := q.attempts[id] + 1
+	if q.retryPolicy.Exhausted(attempts) {
+		q.dead[id] = &queue.DeadFormatJob{
+			PostID:    id,
+			Attempts:  attempts,
+			LastError: errorMessage(cause),
+			FailedAt:  q.now(),
+		}
+		delete(q.attempts, id)
+		delete(q.lastErrors, id)
+		delete(q.scheduled, id)
+		q.mu.Unlock()
+		return queue.ErrJobDeadLettered
+	}
+	q.attempts[id] = attempts
+	q.lastErrors[id] = errorMessage(cause)
+	backoff := q.retryPolicy.Backoff(attempts)
+	q.delayed[id] = &delayedJob{
+		notBefore: q.now().Add(backoff),
+		timer: time.AfterFunc(backoff, func() {
+			q.mu.Lock()
+			delete(q.delayed, id)
+			q.mu.Unlock()
+			q.push(id)
+		}),
+	}
+	q.mu.Unlock()
+	return nil
+}
+
+/**
+ * 登録済みか隔離済みのジョブについて、リースや再試行待ちの状況を返す。
+ */
+func (q *InMemoryJobQueue) InspectFormat(ctx context.Context, id post.DarkPostID) (*queue.FormatJobStatus, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job, ok := q.dead[id]; ok {
+		return &queue.FormatJobStatus{
+			PostID:    id,
+			State:     queue.FormatJobDead,
+			Attempts:  job.Attempts,
+			LastError: job.LastError,
+		}, nil
+	}
+	if _, ok := q.scheduled[id]; !ok {
+		return nil, queue.ErrJobNotFound
+	}
+
+	info := &queue.FormatJobStatus{
+		PostID:    id,
+		State:     queue.FormatJobPending,
+		Attempts:  q.attempts[id],
+		LastError: q.lastErrors[id],
+	}
+	if _, ok := q.leases[id]; ok {
+		info.State = queue.FormatJobLeased
+	} else if delayed, ok := q.delayed[id]; ok {
+		info.State = queue.FormatJobRetrying
+		info.NextAttemptAt = delayed.notBefore
+	}
+	return info, nil
+}
+
+/**
+ * 隔離済みのジョブを新しく隔離された順に最大 limit 件返す。
+ */
+func (q *InMemoryJobQueue) ListDeadFormat(ctx context.Context, limit int) ([]*queue.DeadFormatJob, error) {
+	if err := q.ensureReady(ctx); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	jobs := make([]*queue.DeadFormatJob, 0, len(q.dead))
+	for _, job := range q.dead {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	q.mu.Unlock()
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].FailedAt.After(jobs[j].FailedAt)
+	})
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+/**
+ * 隔離済みのジョブを試行回数 0 から整形待ちへ戻す。
+ */
+func (q *InMemoryJobQueue) ReplayDeadFormat(ctx context.Context, id post.DarkPostID) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	job, ok := q.dead[id]
+	if !ok {
+		q.mu.Unlock()
+		return queue.ErrDeadJobNotFound
+	}
+	if _, exists := q.scheduled[id]; exists {
+		q.mu.Unlock()
+		return queue.ErrJobAlreadyScheduled
+	}
+	delete(q.dead, id)
+	q.scheduled[id] = struct{}{}
+	q.mu.Unlock()
+
+	if err := q.send(ctx, id); err != nil {
+		// 再投入できなかった場合は隔離状態へ戻す
+		q.mu.Lock()
+		q.dead[id] = job
+		q.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+/**
+ * リース中のジョブを手放し、再び取り出せるようにキューへ戻す。
+ */
+func (q *InMemoryJobQueue) NackFormat(ctx context.Context, l queue.Lease) error {
+	if err := q.ensureReady(ctx); err != nil {
+		return err
+	}
+	if err := q.release(l); err != nil {
+		return err
+	}
+	q.push(l.PostID)
+	return nil
+}
+
+/**
+ * 以降の登録・取り出しを止めるため通知チャネルを閉じ、リースのタイマーも止める。
+ */
+func (q *InMemoryJobQueue) Close() error {
+	if q == nil {
+		return nil
+	}
+	q.closeOnce.Do(func() {
+		close(q.closedCh)
+		q.mu.Lock()
+		for id, l := range q.leases {
+			l.timer.Stop()
+			delete(q.leases, id)
+		}
+		for id, delayed := range q.delayed {
+			delayed.timer.Stop()
+			delete(q.delayed, id)
+		}
+		q.mu.Unlock()
+	})
+	return nil
+}
+
+/**
+ * 呼び出し側の中断や自身の停止状態を確認し、継続可否を判定する。
+ */
+func (q *InMemoryJobQueue) ensureReady(ctx context.Context) error {
+	if q == nil {
+		return queue.ErrQueueClosed
+	}
+	select {
+	case <-q.closedCh:
+		return queue.ErrQueueClosed
+	default:
+	}
+	if ctx == nil {
+		return fmt.Errorf("%w: context が nil です", queue.ErrContextClosed)
+	}
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", queue.ErrContextClosed, ctx.Err())
+	default:
+		return nil
+	}
+}
+
+/**
+ * 処理済みや登録取り消しとなった ID を重複判定と試行回数・失敗理由から外す。
+ */
+func (q *InMemoryJobQueue) forget(id post.DarkPostID) {
+	q.mu.Lock()
+	delete(q.scheduled, id)
+	delete(q.attempts, id)
+	delete(q.lastErrors, id)
+	q.mu.Unlock()
+}
+
+/**
+ * 取り出したジョブに取り出しごとの識別子を持つリースを付け、期限切れ時にキューへ戻すタイマーを仕掛ける。
+ */
+func (q *InMemoryJobQueue) lease(id post.DarkPostID) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.leaseSeq++
+	l := &lease{token: fmt.Sprintf("lease-%d", q.leaseSeq)}
+	l.timer = time.AfterFunc(q.leaseDuration, func() {
+		q.expire(id, l)
+	})
+	q.leases[id] = l
+	return l.token
+}
+
+/**
+ * 期限切れになったリースがまだ有効なら外し、試行 1 回として数えてジョブを再取得できるようにする。
+ * 試行回数が上限に達していれば ErrLeaseExpired を理由に隔離する。
+ */
+func (q *InMemoryJobQueue) expire(id post.DarkPostID, l *lease) {
+	q.mu.Lock()
+	// Ack / Nack 済みや張り直されたリースは対象外
+	if q.leases[id] != l {
+		q.mu.Unlock()
+		return
+	}
+	delete(q.leases, id)
+	attempts := q.attempts[id] + 1
+	if q.retryPolicy.Exhausted(attempts) {
+		dead := &queue.DeadFormatJob{
+			PostID:    id,
+			Attempts:  attempts,
+			LastError: queue.ErrLeaseExpired.Error(),
+			FailedAt:  q.now(),
+		}
+		q.dead[id] = dead
+		delete(q.attempts, id)
+		delete(q.lastErrors, id)
+		delete(q.scheduled, id)
+		notify := q.onExpiredDead
+		q.mu.Unlock()
+		if notify != nil {
+			notify(context.Background(), *dead)
+		}
+		return
+	}
+	q.attempts[id] = attempts
+	q.lastErrors[id] = queue.ErrLeaseExpired.Error()
+	q.mu.Unlock()
+	q.push(id)
+}
+
+/**
+ * リースの期限切れで隔離したときに呼ぶ関数を登録する。
+ */
+func (q *InMemoryJobQueue) NotifyExpiredDeadLetters(fn func(ctx context.Context, job queue.DeadFormatJob)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onExpiredDead = fn
+}
+
+/**
+ * 渡されたリースがまだ有効なら外す。期限切れで他の取り出しへ渡ったリースや未取得なら ErrJobNotLeased を返す。
+ */
+func (q *InMemoryJobQueue) release(held queue.Lease) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	l, ok := q.leases[held.PostID]
+	if !ok || l.token != held.Token {
+		return queue.ErrJobNotLeased
+	}
+	l.timer.Stop()
+	delete(q.leases, held.PostID)
+	return nil
+}
+
+/**
+ * ジョブをキューへ戻す。満杯の場合は空きが出るか停止するまで裏で待つ。
+ */
+func (q *InMemoryJobQueue) push(id post.DarkPostID) {
+	select {
+	case q.jobs <- id:
+	default:
+		go func() {
+			select {
+			case q.jobs <- id:
+			case <-q.closedCh:
+			}
+		}()
+	}
+}
+
+/**
+ * 隔離理由として残す文字列を取り出す。
+ */
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+var (
+	_ queue.JobQueue             = (*InMemoryJobQueue)(nil)
+	_ queue.DeadLetterQueue      = (*InMemoryJobQueue)(nil)
+	_ queue.JobInspector         = (*InMemoryJobQueue)(nil)
+	_ queue.ExpiredLeaseNotifier = (*InMemoryJobQueue)(nil)
+)
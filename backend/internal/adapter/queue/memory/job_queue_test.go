@@ -0,0 +1,471 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/domain/post"
+	portqueue "backend/internal/port/queue"
+)
+
+func TestInMemoryJobQueue_EnqueueAndDequeue(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+	ctx := context.Background()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-1")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-2")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// 登録順に取り出せる
+	for _, want := range []post.DarkPostID{"post-1", "post-2"} {
+		got, err := queue.DequeueFormat(ctx)
+		if err != nil {
+			t.Fatalf("dequeue: %v", err)
+		}
+		if got.PostID != want {
+			t.Fatalf("unexpected id: want %s, got %s", want, got.PostID)
+		}
+	}
+}
+
+func TestInMemoryJobQueue_DuplicateEnqueueReturnsError(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+	ctx := context.Background()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("dup-post")); err != nil {
+		t.Fatalf("first enqueue: %v", err)
+	}
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("dup-post")); !errors.Is(err, portqueue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled, got %v", err)
+	}
+
+	// リース中も重複扱いで、Ack 後は再登録できる
+	lease := mustDequeue(t, queue, ctx)
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("dup-post")); !errors.Is(err, portqueue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled while leased, got %v", err)
+	}
+	if err := queue.AckFormat(ctx, lease); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("dup-post")); err != nil {
+		t.Fatalf("re-enqueue after ack: %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_EmptyPostID(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+	if err := queue.EnqueueFormat(context.Background(), ""); !errors.Is(err, errEmptyPostID) {
+		t.Fatalf("expected errEmptyPostID, got %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_DequeueWaitsForNewJob(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type result struct {
+		id  post.DarkPostID
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		lease, err := queue.DequeueFormat(ctx)
+		done <- result{id: lease.PostID, err: err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := queue.EnqueueFormat(context.Background(), post.DarkPostID("delayed-post")); err != nil {
+		t.Fatalf("enqueue delayed: %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		t.Fatalf("context finished before job dequeued: %v", ctx.Err())
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("dequeue: %v", res.err)
+		}
+		if res.id != post.DarkPostID("delayed-post") {
+			t.Fatalf("unexpected id: %s", res.id)
+		}
+	}
+}
+
+func TestInMemoryJobQueue_CloseStopsOperations(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+	if err := queue.Close(); err != nil {
+		t.Fatalf("close returned error: %v", err)
+	}
+	// 二重 Close でも失敗しない
+	if err := queue.Close(); err != nil {
+		t.Fatalf("second close returned error: %v", err)
+	}
+
+	if err := queue.EnqueueFormat(context.Background(), post.DarkPostID("post-after-close")); !errors.Is(err, portqueue.ErrQueueClosed) {
+		t.Fatalf("expected ErrQueueClosed on enqueue, got %v", err)
+	}
+	if _, err := queue.DequeueFormat(context.Background()); !errors.Is(err, portqueue.ErrQueueClosed) {
+		t.Fatalf("expected ErrQueueClosed on dequeue, got %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_CloseWakesWaitingDequeue(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+
+	done := make(chan error)
+	go func() {
+		_, err := queue.DequeueFormat(context.Background())
+		done <- err
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	_ = queue.Close()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, portqueue.ErrQueueClosed) {
+			t.Fatalf("expected ErrQueueClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("dequeue did not return after close")
+	}
+}
+
+// Dequeue 中にコンテキストが閉じた場合に適切なエラーへ変換されるかを確認する
+func TestInMemoryJobQueue_DequeueContextCanceled(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := queue.DequeueFormat(ctx)
+	if err == nil || !errors.Is(err, portqueue.ErrContextClosed) {
+		t.Fatalf("expected ErrContextClosed, got %v", err)
+	}
+}
+
+// 満杯のまま待っている間にコンテキストが閉じたら登録を取り消す
+func TestInMemoryJobQueue_EnqueueContextCanceledWhenFull(t *testing.T) {
+	queue := NewInMemoryJobQueue(1)
+	if err := queue.EnqueueFormat(context.Background(), post.DarkPostID("post-1")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-2")); !errors.Is(err, portqueue.ErrContextClosed) {
+		t.Fatalf("expected ErrContextClosed, got %v", err)
+	}
+
+	// 取り消された ID は重複扱いにならない
+	lease := mustDequeue(t, queue, context.Background())
+	if err := queue.AckFormat(context.Background(), lease); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if err := queue.EnqueueFormat(context.Background(), post.DarkPostID("post-2")); err != nil {
+		t.Fatalf("re-enqueue: %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_NackMakesJobClaimableAgain(t *testing.T) {
+	queue := NewInMemoryJobQueue(0)
+	ctx := context.Background()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-nack")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease := mustDequeue(t, queue, ctx)
+	if err := queue.NackFormat(ctx, lease); err != nil {
+		t.Fatalf("nack: %v", err)
+	}
+
+	got, err := queue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue after nack: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-nack") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+}
+
+func TestInMemoryJobQueue_ExpiredLeaseIsReclaimed(t *testing.T) {
+	queue := NewInMemoryJobQueue(0, WithLeaseDuration(20*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-crash")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	mustDequeue(t, queue, ctx)
+
+	// Ack されないままリースが切れると再び取り出せる
+	got, err := queue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue after expiry: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-crash") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+}
+
+func TestInMemoryJobQueue_AckWithoutLease(t *testing.T) {
+	queue := NewInMemoryJobQueue(0, WithLeaseDuration(10*time.Millisecond))
+	ctx := context.Background()
+
+	unknown := portqueue.Lease{PostID: "unknown", Token: "lease-1"}
+	if err := queue.AckFormat(ctx, unknown); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased for unknown job, got %v", err)
+	}
+	if err := queue.NackFormat(ctx, unknown); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased on nack for unknown job, got %v", err)
+	}
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-late")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease := mustDequeue(t, queue, ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	// リースが切れた後の Ack は受け付けない
+	if err := queue.AckFormat(ctx, lease); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased after expiry, got %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_StaleLeaseCannotSettleReclaimedJob(t *testing.T) {
+	queue := NewInMemoryJobQueue(0, WithLeaseDuration(200*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-stale")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	stale := mustDequeue(t, queue, ctx)
+	// リースが切れて別の取り出しに渡る
+	current := mustDequeue(t, queue, ctx)
+	if current.PostID != stale.PostID || current.Token == stale.Token {
+		t.Fatalf("expected a new lease for the same job, got %+v and %+v", stale, current)
+	}
+
+	// 先の持ち主の Ack / Nack / Retry は後の持ち主のジョブを消さない
+	if err := queue.AckFormat(ctx, stale); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased for stale ack, got %v", err)
+	}
+	if err := queue.NackFormat(ctx, stale); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased for stale nack, got %v", err)
+	}
+	if err := queue.RetryFormat(ctx, stale, errors.New("late")); !errors.Is(err, portqueue.ErrJobNotLeased) {
+		t.Fatalf("expected ErrJobNotLeased for stale retry, got %v", err)
+	}
+	assertJobState(t, queue, current.PostID, portqueue.FormatJobLeased, 1)
+	if err := queue.AckFormat(ctx, current); err != nil {
+		t.Fatalf("ack by current holder: %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_ExpiredLeaseCountsAsAttempt(t *testing.T) {
+	policy := portqueue.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	queue := NewInMemoryJobQueue(0, WithLeaseDuration(20*time.Millisecond), WithRetryPolicy(policy))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	notified := make(chan portqueue.DeadFormatJob, 2)
+	queue.NotifyExpiredDeadLetters(func(ctx context.Context, job portqueue.DeadFormatJob) {
+		notified <- job
+	})
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-hang")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	mustDequeue(t, queue, ctx)
+	// 1 回目のリース切れは試行として数えて再取得させる
+	mustDequeue(t, queue, ctx)
+	info := assertJobState(t, queue, post.DarkPostID("post-hang"), portqueue.FormatJobLeased, 1)
+	if info.LastError != portqueue.ErrLeaseExpired.Error() {
+		t.Fatalf("expected lease expiry as last error, got %q", info.LastError)
+	}
+
+	// 上限に達したリース切れは取り出しへ戻さず隔離する
+	time.Sleep(50 * time.Millisecond)
+	assertJobState(t, queue, post.DarkPostID("post-hang"), portqueue.FormatJobDead, 2)
+	shortCtx, shortCancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer shortCancel()
+	if _, err := queue.DequeueFormat(shortCtx); !errors.Is(err, portqueue.ErrContextClosed) {
+		t.Fatalf("dead-lettered job must not be dequeued, got %v", err)
+	}
+	dead, err := queue.ListDeadFormat(ctx, 10)
+	if err != nil || len(dead) != 1 || dead[0].LastError != portqueue.ErrLeaseExpired.Error() {
+		t.Fatalf("unexpected dead jobs: %+v (%v)", dead, err)
+	}
+	// 期限切れでの隔離は 1 回だけ知らせる
+	select {
+	case job := <-notified:
+		if job.PostID != "post-hang" || job.Attempts != 2 || job.LastError != portqueue.ErrLeaseExpired.Error() {
+			t.Fatalf("unexpected notified job: %+v", job)
+		}
+	default:
+		t.Fatal("expired dead letter should be notified")
+	}
+	if len(notified) != 0 {
+		t.Fatalf("expected a single notification, got %d more", len(notified))
+	}
+}
+
+func TestInMemoryJobQueue_RetryWaitsForBackoff(t *testing.T) {
+	policy := portqueue.RetryPolicy{MaxAttempts: 3, BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second}
+	queue := NewInMemoryJobQueue(0, WithRetryPolicy(policy))
+	ctx := context.Background()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-retry")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease := mustDequeue(t, queue, ctx)
+	if err := queue.RetryFormat(ctx, lease, errors.New("temporary")); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	// 待機時間中は取り出せず、再試行待ちの間も重複扱いになる
+	shortCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := queue.DequeueFormat(shortCtx); !errors.Is(err, portqueue.ErrContextClosed) {
+		t.Fatalf("expected job to be delayed, got %v", err)
+	}
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-retry")); !errors.Is(err, portqueue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled while waiting, got %v", err)
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer waitCancel()
+	got, err := queue.DequeueFormat(waitCtx)
+	if err != nil {
+		t.Fatalf("dequeue after backoff: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-retry") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+}
+
+func TestInMemoryJobQueue_RetryExhaustedMovesToDeadLetter(t *testing.T) {
+	policy := portqueue.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	queue := NewInMemoryJobQueue(0, WithRetryPolicy(policy))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := queue.EnqueueFormat(ctx, post.DarkPostID("post-dead")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease := mustDequeue(t, queue, ctx)
+	if err := queue.RetryFormat(ctx, lease, errors.New("first")); err != nil {
+		t.Fatalf("first retry: %v", err)
+	}
+	lease = mustDequeue(t, queue, ctx)
+	if err := queue.RetryFormat(ctx, lease, errors.New("second")); !errors.Is(err, portqueue.ErrJobDeadLettered) {
+		t.Fatalf("expected ErrJobDeadLettered, got %v", err)
+	}
+
+	dead, err := queue.ListDeadFormat(ctx, 10)
+	if err != nil {
+		t.Fatalf("list dead: %v", err)
+	}
+	if len(dead) != 1 || dead[0].PostID != post.DarkPostID("post-dead") || dead[0].Attempts != 2 || dead[0].LastError != "second" {
+		t.Fatalf("unexpected dead jobs: %+v", dead)
+	}
+
+	// 再投入すると試行回数 0 から取り出せるようになる
+	if err := queue.ReplayDeadFormat(ctx, post.DarkPostID("post-dead")); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if err := queue.ReplayDeadFormat(ctx, post.DarkPostID("post-dead")); !errors.Is(err, portqueue.ErrDeadJobNotFound) {
+		t.Fatalf("expected ErrDeadJobNotFound on second replay, got %v", err)
+	}
+	lease = mustDequeue(t, queue, ctx)
+	if err := queue.RetryFormat(ctx, lease, errors.New("again")); err != nil {
+		t.Fatalf("retry after replay should not be exhausted: %v", err)
+	}
+}
+
+func TestInMemoryJobQueue_InspectFormatTracksState(t *testing.T) {
+	policy := portqueue.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	queue := NewInMemoryJobQueue(0, WithRetryPolicy(policy))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	id := post.DarkPostID("post-inspect")
+
+	if _, err := queue.InspectFormat(ctx, id); !errors.Is(err, portqueue.ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound before enqueue, got %v", err)
+	}
+	if err := queue.EnqueueFormat(ctx, id); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	assertJobState(t, queue, id, portqueue.FormatJobPending, 0)
+
+	lease := mustDequeue(t, queue, ctx)
+	assertJobState(t, queue, id, portqueue.FormatJobLeased, 0)
+
+	if err := queue.RetryFormat(ctx, lease, errors.New("llm down")); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	info := assertJobState(t, queue, id, portqueue.FormatJobRetrying, 1)
+	if info.LastError != "llm down" || info.NextAttemptAt.IsZero() {
+		t.Fatalf("expected retry details, got %+v", info)
+	}
+	queue.Close()
+}
+
+func TestInMemoryJobQueue_InspectFormatReportsDeadAndAcked(t *testing.T) {
+	policy := portqueue.RetryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	queue := NewInMemoryJobQueue(0, WithRetryPolicy(policy))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	dead := post.DarkPostID("post-dead")
+	if err := queue.EnqueueFormat(ctx, dead); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease := mustDequeue(t, queue, ctx)
+	if err := queue.RetryFormat(ctx, lease, errors.New("boom")); !errors.Is(err, portqueue.ErrJobDeadLettered) {
+		t.Fatalf("expected ErrJobDeadLettered, got %v", err)
+	}
+	assertJobState(t, queue, dead, portqueue.FormatJobDead, 1)
+
+	// 完了したジョブは参照できなくなる
+	done := post.DarkPostID("post-done")
+	if err := queue.EnqueueFormat(ctx, done); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease = mustDequeue(t, queue, ctx)
+	if err := queue.AckFormat(ctx, lease); err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if _, err := queue.InspectFormat(ctx, done); !errors.Is(err, portqueue.ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound after ack, got %v", err)
+	}
+}
+
+func assertJobState(t *testing.T, queue *InMemoryJobQueue, id post.DarkPostID, want portqueue.FormatJobState, attempts int) *portqueue.FormatJobStatus {
+	t.Helper()
+	info, err := queue.InspectFormat(context.Background(), id)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if info.State != want || info.Attempts != attempts {
+		t.Fatalf("expected %s with %d attempts, got %+v", want, attempts, info)
+	}
+	return info
+}
+
+// mustDequeue は整形ジョブを 1 件取り出し、リースを返す。
+func mustDequeue(t *testing.T, q *InMemoryJobQueue, ctx context.Context) portqueue.Lease {
+	t.Helper()
+	lease, err := q.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	return lease
+}
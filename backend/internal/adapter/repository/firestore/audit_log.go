@@ -0,0 +1,112 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/domain/audit"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// auditLogsCollection は投稿ごとの監査ログを置くコレクション名。
+	auditLogsCollection = "audit_logs"
+	// auditEventsCollection は出来事 1 件ずつを残すサブコレクション名。
+	auditEventsCollection = "events"
+)
+
+// errNilAuditEvent は nil を記録しようとした際のバリデーションエラー。
+var errNilAuditEvent = errors.New("firestorerepository: audit event is nil")
+
+// AuditLog は Firestore を利用した監査ログの実装。
+// audit_logs/{post_id}/events に自動採番の ID で追記し、更新も削除もしない。
+type AuditLog struct {
+	client *firestore.Client
+}
+
+// NewAuditLog は Firestore クライアントを受け取って AuditLog を作成する。
+func NewAuditLog(client *firestore.Client) (*AuditLog, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &AuditLog{client: client}, nil
+}
+
+// auditEventDocument は events のドキュメント。
+type auditEventDocument struct {
+	Action     string            `firestore:"action"`
+	ActorKind  string            `firestore:"actor_kind"`
+	ActorID    string            `firestore:"actor_id"`
+	Details    map[string]string `firestore:"details"`
+	OccurredAt time.Time         `firestore:"occurred_at"`
+}
+
+// Append は出来事を新しいドキュメントとして作成し、書き込み時刻を記録日時として e に反映する。
+func (l *AuditLog) Append(ctx context.Context, e *audit.Event) error {
+	if e == nil {
+		return errNilAuditEvent
+	}
+	ref := l.events(e.PostID()).NewDoc()
+	actor := e.Actor()
+	result, err := ref.Create(ctx, map[string]any{
+		"action":      string(e.Action()),
+		"actor_kind":  string(actor.Kind),
+		"actor_id":    actor.ID,
+		"details":     e.Details(),
+		"occurred_at": firestore.ServerTimestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("create audit event document: %w", err)
+	}
+	e.RestoreOccurredAt(result.UpdateTime)
+	return nil
+}
+
+// ListByPost は postID の出来事を記録日時の古い順に返す。
+func (l *AuditLog) ListByPost(ctx context.Context, postID postdomain.DarkPostID) ([]*audit.Event, error) {
+	if postID == "" {
+		return []*audit.Event{}, nil
+	}
+	iter := l.events(postID).OrderBy("occurred_at", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	events := []*audit.Event{}
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iterate audit events: %w", err)
+		}
+
+		var payload auditEventDocument
+		if err := doc.DataTo(&payload); err != nil {
+			return nil, fmt.Errorf("decode audit event document: %w", err)
+		}
+		e, err := audit.New(
+			postID,
+			audit.Action(payload.Action),
+			audit.Actor{Kind: audit.ActorKind(payload.ActorKind), ID: payload.ActorID},
+			payload.Details,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("restore audit event: %w", err)
+		}
+		e.RestoreOccurredAt(payload.OccurredAt)
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (l *AuditLog) events(postID postdomain.DarkPostID) *firestore.CollectionRef {
+	return l.client.Collection(auditLogsCollection).Doc(string(postID)).Collection(auditEventsCollection)
+}
+
+var _ repository.AuditLog = (*AuditLog)(nil)
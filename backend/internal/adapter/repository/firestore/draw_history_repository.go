@@ -0,0 +1,110 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// drawHistoriesCollection はクライアントごとに最近引いたおみくじを保持するコレクション名。
+const drawHistoriesCollection = "draw_histories"
+
+// drawHistoryDocument は Firestore の draw_histories ドキュメント構造を表す。post_ids は新しい順。
+type drawHistoryDocument struct {
+	PostIDs []string `firestore:"post_ids"`
+}
+
+// DrawHistoryRepository は Firestore を利用した抽選履歴のリポジトリ実装。
+// クライアント 1 件につき 1 ドキュメントへ、直近の闇投稿 ID だけを配列で持つ。
+type DrawHistoryRepository struct {
+	client *firestore.Client
+}
+
+// NewDrawHistoryRepository は Firestore クライアントを受け取って DrawHistoryRepository を作成する。
+func NewDrawHistoryRepository(client *firestore.Client) (*DrawHistoryRepository, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &DrawHistoryRepository{client: client}, nil
+}
+
+// Recent は client が最近引いた闇投稿 ID を新しい順に最大 limit 件返す。履歴が無ければ空。
+func (r *DrawHistoryRepository) Recent(ctx context.Context, client postdomain.ClientID, limit int) ([]postdomain.DarkPostID, error) {
+	if client == "" {
+		return nil, repository.ErrEmptyClientID
+	}
+	snap, err := r.client.Collection(drawHistoriesCollection).Doc(string(client)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get draw history: %w", err)
+	}
+	history, err := decodeDrawHistory(snap)
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history, nil
+}
+
+// Record は client の履歴の先頭に postID をトランザクション内で加え、keep 件までに切り詰める。
+func (r *DrawHistoryRepository) Record(ctx context.Context, client postdomain.ClientID, postID postdomain.DarkPostID, keep int) error {
+	if client == "" {
+		return repository.ErrEmptyClientID
+	}
+	if postID == "" {
+		return errEmptyPostID
+	}
+
+	doc := r.client.Collection(drawHistoriesCollection).Doc(string(client))
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var history []postdomain.DarkPostID
+		snap, err := tx.Get(doc)
+		switch {
+		case err == nil:
+			if history, err = decodeDrawHistory(snap); err != nil {
+				return err
+			}
+		case status.Code(err) != codes.NotFound:
+			return err
+		}
+
+		next := repository.PushDrawHistory(history, postID, keep)
+		ids := make([]string, len(next))
+		for i, id := range next {
+			ids[i] = string(id)
+		}
+		return tx.Set(doc, map[string]any{
+			"post_ids":   ids,
+			"updated_at": firestore.ServerTimestamp,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("record draw history: %w", err)
+	}
+	return nil
+}
+
+// decodeDrawHistory はスナップショットから闇投稿 ID の一覧を取り出す。
+func decodeDrawHistory(snap *firestore.DocumentSnapshot) ([]postdomain.DarkPostID, error) {
+	var payload drawHistoryDocument
+	if err := snap.DataTo(&payload); err != nil {
+		return nil, fmt.Errorf("decode draw history document: %w", err)
+	}
+	history := make([]postdomain.DarkPostID, len(payload.PostIDs))
+	for i, id := range payload.PostIDs {
+		history[i] = postdomain.DarkPostID(id)
+	}
+	return history, nil
+}
+
+var _ repository.DrawHistoryRepository = (*DrawHistoryRepository)(nil)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"time"
 
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
@@ -18,6 +20,15 @@ import (
 // drawsCollection は Firestore 上のコレクション名。
 const drawsCollection = "draws"
 
+// drawRandomKeyField は PickRandom の範囲検索に使う [0, 1) の乱数を保存するフィールド名。
+const drawRandomKeyField = "random_key"
+
+// pickRandomPageSize は除外条件がある抽選で 1 回に読み進める件数。
+const pickRandomPageSize = 10
+
+// drawRandomKey は作成時と抽選時に使う乱数源。テストで差し替える。
+var drawRandomKey = rand.Float64
+
 var (
 	// errNilDraw は nil を保存しようとした際のバリデーションエラー。
 	errNilDraw = errors.New("firestorerepository: draw is nil")
@@ -51,23 +62,71 @@ func (r *DrawRepository) Create(ctx context.Context, d *drawdomain.Draw) error {
 	}
 
 	doc := r.client.Collection(drawsCollection).Doc(string(postID))
+
+	//保存するときのエラーチェック
+	_, err := doc.Create(ctx, newDrawData(d))
+	if status.Code(err) == codes.AlreadyExists {
+		return repository.ErrDrawAlreadyExists
+	}
+	if err != nil {
+		return fmt.Errorf("create draw document: %w", err)
+	}
+	return nil
+}
+
+// newDrawData は新規作成時に draws へ書き込む内容を返す。
+func newDrawData(d *drawdomain.Draw) map[string]interface{} {
 	// Firestore に保存するフィールド群。
 	data := map[string]interface{}{
 		"post_id":    string(d.PostID()),
 		"result":     string(d.Result()),
 		"status":     string(d.Status()),
+		"author_id":  string(d.Author()),
 		"created_at": firestore.ServerTimestamp,
+		// 抽選時に乱数以上で最小のキーを引けるよう、作成時に一様な乱数を振っておく
+		drawRandomKeyField: drawRandomKey(),
+	}
+	// 公開不可と判定された理由はモデレーション用に残す
+	if d.Status() == drawdomain.StatusRejected {
+		data["rejection_reason"] = d.Reason()
 	}
+	// 生成元は作成時にだけ書き込み、公開停止などの更新では触らない
+	if p := d.Provenance(); !p.IsZero() {
+		data["provenance"] = newProvenanceDocument(p)
+	}
+	return data
+}
 
-	//保存するときのエラーチェック
-	_, err := doc.Create(ctx, data)
-	if status.Code(err) == codes.AlreadyExists {
-		return repository.ErrDrawAlreadyExists
+// provenanceDocument は draws.provenance に保存する LLM の生成元の記録。
+type provenanceDocument struct {
+	Provider         string `firestore:"provider"`
+	Model            string `firestore:"model"`
+	PromptVersion    string `firestore:"prompt_version"`
+	PromptTokens     int    `firestore:"prompt_tokens"`
+	CompletionTokens int    `firestore:"completion_tokens"`
+	LatencyMS        int64  `firestore:"latency_ms"`
+}
+
+func newProvenanceDocument(p drawdomain.Provenance) provenanceDocument {
+	return provenanceDocument{
+		Provider:         p.Provider,
+		Model:            p.Model,
+		PromptVersion:    p.PromptVersion,
+		PromptTokens:     p.PromptTokens,
+		CompletionTokens: p.CompletionTokens,
+		LatencyMS:        p.Latency.Milliseconds(),
 	}
-	if err != nil {
-		return fmt.Errorf("create draw document: %w", err)
+}
+
+func (doc provenanceDocument) toDomain() drawdomain.Provenance {
+	return drawdomain.Provenance{
+		Provider:         doc.Provider,
+		Model:            doc.Model,
+		PromptVersion:    doc.PromptVersion,
+		PromptTokens:     doc.PromptTokens,
+		CompletionTokens: doc.CompletionTokens,
+		Latency:          time.Duration(doc.LatencyMS) * time.Millisecond,
 	}
-	return nil
 }
 
 // GetByPostID は Firestore から Draw を取得する。
@@ -87,6 +146,40 @@ func (r *DrawRepository) GetByPostID(ctx context.Context, postID post.DarkPostID
 	return restoreDrawFromDoc(doc)
 }
 
+// Update は既存の Draw の状態と公開不可の理由を Firestore 上で更新する。
+// status で絞り込む ListReady と PickRandom には、書き込んだ時点から反映される。
+func (r *DrawRepository) Update(ctx context.Context, d *drawdomain.Draw) error {
+	if d == nil {
+		return errNilDraw
+	}
+	if d.PostID() == "" {
+		return repository.ErrDrawNotFound
+	}
+
+	doc := r.client.Collection(drawsCollection).Doc(string(d.PostID()))
+	_, err := doc.Update(ctx, drawUpdates(d))
+	if status.Code(err) == codes.NotFound {
+		return repository.ErrDrawNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("update draw document: %w", err)
+	}
+	return nil
+}
+
+// drawUpdates は既存の draws ドキュメントへ書き込む更新内容を返す。公開へ戻した場合は理由を消す。
+func drawUpdates(d *drawdomain.Draw) []firestore.Update {
+	reason := any(firestore.Delete)
+	if d.Status() == drawdomain.StatusRejected {
+		reason = d.Reason()
+	}
+	return []firestore.Update{
+		{Path: "status", Value: string(d.Status())},
+		{Path: "rejection_reason", Value: reason},
+		{Path: "updated_at", Value: firestore.ServerTimestamp},
+	}
+}
+
 // ListReady は Verified な Draw を Firestore から列挙する。
 func (r *DrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, error) {
 	// status が verified の個体のみ抽出するクエリ。
@@ -115,12 +208,139 @@ func (r *DrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, err
 	return draws, nil
 }
 
+// PickRandom は Verified な Draw から filter で除かれない 1 件を無作為に選ぶ。
+// 乱数以上で最小の random_key を持つものから順に探し、見つからなければ先頭から乱数未満までへ折り返す。
+// 除外に当たらなければ読み取りは 1〜2 件で済む。status と random_key の複合インデックスが必要。
+func (r *DrawRepository) PickRandom(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	return r.pickRandom(ctx, filter, drawRandomKey)
+}
+
+// PickRandomWith は PickRandom と同じ探し方で、探し始める random_key を rng から引く。
+func (r *DrawRepository) PickRandomWith(ctx context.Context, filter repository.DrawFilter, rng *rand.Rand) (*drawdomain.Draw, error) {
+	return r.pickRandom(ctx, filter, rng.Float64)
+}
+
+func (r *DrawRepository) pickRandom(ctx context.Context, filter repository.DrawFilter, randomKey func() float64) (*drawdomain.Draw, error) {
+	draws, err := r.sampleVerified(ctx, filter, 1, randomKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(draws) == 0 {
+		return nil, repository.ErrDrawNotFound
+	}
+	return draws[0], nil
+}
+
+// SampleVerified は Verified な Draw のうち filter を満たすものを、乱数以上の random_key から順に最大 n 件返す。
+// random_key は作成時の一様な乱数なので、連続した n 件もそのまま無作為な標本になる。
+// rng が渡されれば探し始める random_key をそこから引く。
+func (r *DrawRepository) SampleVerified(ctx context.Context, filter repository.DrawFilter, n int, rng *rand.Rand) ([]*drawdomain.Draw, error) {
+	if rng != nil {
+		return r.sampleVerified(ctx, filter, n, rng.Float64)
+	}
+	return r.sampleVerified(ctx, filter, n, drawRandomKey)
+}
+
+func (r *DrawRepository) sampleVerified(ctx context.Context, filter repository.DrawFilter, n int, randomKey func() float64) ([]*drawdomain.Draw, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	key := randomKey()
+	draws, err := r.verifiedBetween(ctx, key, 1, filter, n)
+	if err != nil || len(draws) == n {
+		return draws, err
+	}
+	wrapped, err := r.verifiedBetween(ctx, 0, key, filter, n-len(draws))
+	if err != nil {
+		return nil, err
+	}
+	return append(draws, wrapped...), nil
+}
+
+// verifiedBetween は random_key が [from, to) にある Verified な Draw のうち、filter を満たすものを random_key の小さい順に最大 n 件返す。
+// 除外に当たった分だけ pickRandomPageSize 件以上ずつ読み進める。
+func (r *DrawRepository) verifiedBetween(ctx context.Context, from, to float64, filter repository.DrawFilter, n int) ([]*drawdomain.Draw, error) {
+	query := r.client.Collection(drawsCollection).
+		Where("status", "==", string(drawdomain.StatusVerified)).
+		Where(drawRandomKeyField, ">=", from).
+		Where(drawRandomKeyField, "<", to).
+		OrderBy(drawRandomKeyField, firestore.Asc)
+
+	// 除外が無ければ必要な件数だけ読めば足りる
+	pageSize := n
+	if !filter.IsZero() {
+		pageSize = max(n, pickRandomPageSize)
+	}
+
+	var (
+		draws []*drawdomain.Draw
+		last  *firestore.DocumentSnapshot
+	)
+	for {
+		page := query.Limit(pageSize)
+		if last != nil {
+			page = page.StartAfter(last)
+		}
+		docs, err := page.Documents(ctx).GetAll()
+		if err != nil {
+			return nil, fmt.Errorf("sample verified draws: %w", err)
+		}
+		for _, doc := range docs {
+			d, err := restoreDrawFromDoc(doc)
+			if err != nil {
+				return nil, err
+			}
+			if !filter.Allows(d) {
+				continue
+			}
+			draws = append(draws, d)
+			if len(draws) == n {
+				return draws, nil
+			}
+		}
+		if len(docs) < pageSize {
+			return draws, nil
+		}
+		last = docs[len(docs)-1]
+	}
+}
+
+// BackfillRandomKeys は random_key を持たない既存の Draw へ乱数を振り、更新した件数を返す。
+// random_key が無いドキュメントは PickRandom の範囲検索に掛からないため、導入前のデータに 1 度だけ実行する。
+func (r *DrawRepository) BackfillRandomKeys(ctx context.Context) (int, error) {
+	iter := r.client.Collection(drawsCollection).Documents(ctx)
+	defer iter.Stop()
+
+	updated := 0
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return updated, fmt.Errorf("iterate draws: %w", err)
+		}
+		if _, err := doc.DataAt(drawRandomKeyField); err == nil {
+			continue
+		}
+		if _, err := doc.Ref.Update(ctx, []firestore.Update{{Path: drawRandomKeyField, Value: drawRandomKey()}}); err != nil {
+			return updated, fmt.Errorf("backfill draw random key: %w", err)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
 // restoreDrawFromDoc は Firestore ドキュメントをドメインオブジェクトに変換する。
 func restoreDrawFromDoc(doc *firestore.DocumentSnapshot) (*drawdomain.Draw, error) {
 	var payload struct {
-		PostID string `firestore:"post_id"`
-		Result string `firestore:"result"`
-		Status string `firestore:"status"`
+		PostID          string              `firestore:"post_id"`
+		Result          string              `firestore:"result"`
+		Status          string              `firestore:"status"`
+		RejectionReason string              `firestore:"rejection_reason"`
+		AuthorID        string              `firestore:"author_id"`
+		CreatedAt       time.Time           `firestore:"created_at"`
+		Provenance      *provenanceDocument `firestore:"provenance"`
 	}
 	if err := doc.DataTo(&payload); err != nil {
 		return nil, fmt.Errorf("decode draw document: %w", err)
@@ -130,5 +350,18 @@ func restoreDrawFromDoc(doc *firestore.DocumentSnapshot) (*drawdomain.Draw, erro
 	if err != nil {
 		return nil, fmt.Errorf("restore draw: %w", err)
 	}
+	if restored.Status() == drawdomain.StatusRejected {
+		restored.MarkRejected(payload.RejectionReason)
+	}
+	restored.AssignAuthor(post.ClientID(payload.AuthorID))
+	restored.RestoreCreatedAt(payload.CreatedAt)
+	if payload.Provenance != nil {
+		restored.AttachProvenance(payload.Provenance.toDomain())
+	}
 	return restored, nil
 }
+
+var (
+	_ repository.DrawRandomKeyBackfiller = (*DrawRepository)(nil)
+	_ repository.DrawSampler             = (*DrawRepository)(nil)
+)
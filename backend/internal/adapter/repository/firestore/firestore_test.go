@@ -2,11 +2,19 @@ package firestore
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
+	"time"
 
+	"backend/internal/domain/audit"
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/domain/report"
+	portqueue "backend/internal/port/queue"
+	"backend/internal/port/repository"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
@@ -92,4 +100,662 @@ func TestDrawRepository_Integration(t *testing.T) {
 	if len(list) != 1 {
 		t.Fatalf("expected 1 draw got %d", len(list))
 	}
+
+	// 公開不可の draw は理由ごと保存され、一覧には出ない
+	rejected, err := drawdomain.New(post.DarkPostID("post-2"), drawdomain.FormattedContent("too dark"))
+	if err != nil {
+		t.Fatalf("new draw: %v", err)
+	}
+	rejected.MarkRejected("個人情報を含む")
+	if err := repo.Create(ctx, rejected); err != nil {
+		t.Fatalf("create rejected draw: %v", err)
+	}
+	fetched, err = repo.GetByPostID(ctx, "post-2")
+	if err != nil {
+		t.Fatalf("get rejected draw: %v", err)
+	}
+	if fetched.Status() != drawdomain.StatusRejected || fetched.Reason() != "個人情報を含む" {
+		t.Fatalf("rejected draw mismatch: status=%s reason=%s", fetched.Status(), fetched.Reason())
+	}
+	list, err = repo.ListReady(ctx)
+	if err != nil {
+		t.Fatalf("list ready: %v", err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("rejected draw should not be listed, got %d", len(list))
+	}
+}
+
+func TestPostRepository_IntegrationLifecycle(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+
+	repo, err := NewPostRepository(client)
+	if err != nil {
+		t.Fatalf("new post repo: %v", err)
+	}
+
+	ctx := context.Background()
+	p, err := post.New(post.DarkPostID("post-life"), post.DarkContent("闇"))
+	if err != nil {
+		t.Fatalf("new post: %v", err)
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	// 各状態が保存・復元できる
+	steps := []struct {
+		mark func(*post.Post) error
+		want post.Status
+	}{
+		{mark: (*post.Post).MarkFormatting, want: post.StatusFormatting},
+		{mark: (*post.Post).MarkFailed, want: post.StatusFailed},
+		{mark: (*post.Post).Reopen, want: post.StatusPending},
+		{mark: (*post.Post).MarkReady, want: post.StatusReady},
+		{mark: (*post.Post).MarkArchived, want: post.StatusArchived},
+		{mark: (*post.Post).MarkDeleted, want: post.StatusDeleted},
+	}
+	for _, step := range steps {
+		got, err := repo.Get(ctx, p.ID())
+		if err != nil {
+			t.Fatalf("get post: %v", err)
+		}
+		if err := step.mark(got); err != nil {
+			t.Fatalf("transition to %s: %v", step.want, err)
+		}
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update post: %v", err)
+		}
+		stored, err := repo.Get(ctx, p.ID())
+		if err != nil {
+			t.Fatalf("get post: %v", err)
+		}
+		if stored.Status() != step.want {
+			t.Fatalf("expected %s got %s", step.want, stored.Status())
+		}
+	}
+}
+
+func TestPostRepository_IntegrationListPendingBeforeIncludesFormatting(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+
+	repo, err := NewPostRepository(client)
+	if err != nil {
+		t.Fatalf("new post repo: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, id := range []post.DarkPostID{"scan-pending", "scan-formatting", "scan-ready"} {
+		p, _ := post.New(id, "闇")
+		switch id {
+		case "scan-formatting":
+			_ = p.MarkFormatting()
+		case "scan-ready":
+			_ = p.MarkReady()
+		}
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+
+	// 整形中のまま止まった投稿も取り残しとして拾い、ready は含めない
+	got, err := repo.ListPendingBefore(ctx, time.Now().Add(time.Minute), 0)
+	if err != nil {
+		t.Fatalf("list pending: %v", err)
+	}
+	if len(got) != 2 || got[0].ID() != "scan-pending" || got[1].ID() != "scan-formatting" {
+		t.Fatalf("unexpected pending posts: %+v", got)
+	}
+}
+
+func TestPostRepository_IntegrationListByStatusPaginates(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+
+	repo, err := NewPostRepository(client)
+	if err != nil {
+		t.Fatalf("new post repo: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, id := range []post.DarkPostID{"list-1", "list-2", "list-3"} {
+		p, _ := post.New(id, "闇")
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+
+	first, err := repo.ListByStatus(ctx, post.StatusPending, "", 2)
+	if err != nil {
+		t.Fatalf("list first page: %v", err)
+	}
+	if len(first.Posts) != 2 || first.Posts[0].ID() != "list-3" || first.NextCursor != "list-2" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if first.Posts[0].CreatedAt().IsZero() {
+		t.Fatalf("created_at should be restored")
+	}
+	second, err := repo.ListByStatus(ctx, post.StatusPending, first.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("list second page: %v", err)
+	}
+	if len(second.Posts) != 1 || second.Posts[0].ID() != "list-1" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+	if _, err := repo.ListByStatus(ctx, post.StatusPending, "missing", 2); !errors.Is(err, repository.ErrInvalidPostCursor) {
+		t.Fatalf("expected ErrInvalidPostCursor, got %v", err)
+	}
+}
+
+func TestIdempotencyKeyRepository_Integration(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, idempotencyKeysCollection)
+
+	repo, err := NewIdempotencyKeyRepository(client)
+	if err != nil {
+		t.Fatalf("new idempotency key repo: %v", err)
+	}
+
+	ctx := context.Background()
+	first := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-1"), Fingerprint: "fp-1"}
+	got, err := repo.Reserve(ctx, "client-1", "key-1", first)
+	if err != nil || *got != *first {
+		t.Fatalf("first reserve: got %+v, %v", got, err)
+	}
+	// 同じキーでは最初に紐づけた内容を返す
+	got, err = repo.Reserve(ctx, "client-1", "key-1", &repository.IdempotencyRecord{PostID: post.DarkPostID("post-2"), Fingerprint: "fp-2"})
+	if err != nil || *got != *first {
+		t.Fatalf("second reserve: got %+v, %v", got, err)
+	}
+	// 別のクライアントのキーとは混ざらない
+	other := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-3"), Fingerprint: "fp-1"}
+	got, err = repo.Reserve(ctx, "client-2", "key-1", other)
+	if err != nil || got.PostID != other.PostID {
+		t.Fatalf("other client reserve: got %+v, %v", got, err)
+	}
+
+	// 期限切れの紐づけは新しいリクエストで上書きする
+	expiring := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-4"), ExpiresAt: time.Now().Add(time.Hour)}
+	if _, err := repo.Reserve(ctx, "client-1", "key-2", expiring); err != nil {
+		t.Fatalf("reserve expiring: %v", err)
+	}
+	repo.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	fresh := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-5")}
+	got, err = repo.Reserve(ctx, "client-1", "key-2", fresh)
+	if err != nil || got.PostID != fresh.PostID {
+		t.Fatalf("expired key should be replaced: got %+v, %v", got, err)
+	}
+}
+
+func TestDrawHistoryRepository_Integration(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, drawHistoriesCollection)
+
+	repo, err := NewDrawHistoryRepository(client)
+	if err != nil {
+		t.Fatalf("new draw history repo: %v", err)
+	}
+
+	ctx := context.Background()
+	if got, err := repo.Recent(ctx, "client-1", 3); err != nil || len(got) != 0 {
+		t.Fatalf("empty history: got %v, %v", got, err)
+	}
+	for _, id := range []post.DarkPostID{"a", "b", "c", "a"} {
+		if err := repo.Record(ctx, "client-1", id, 3); err != nil {
+			t.Fatalf("record %s: %v", id, err)
+		}
+	}
+	got, err := repo.Recent(ctx, "client-1", 3)
+	if err != nil {
+		t.Fatalf("recent: %v", err)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "c" || got[2] != "b" {
+		t.Fatalf("unexpected history: %v", got)
+	}
+}
+
+func TestReactionRepository_IntegrationShardedCounts(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	repo, err := NewReactionRepository(client)
+	if err != nil {
+		t.Fatalf("new reaction repo: %v", err)
+	}
+	origShard := reactionShard
+	t.Cleanup(func() { reactionShard = origShard })
+
+	// シャードは親ドキュメントを消しても残るため、実行ごとに別の投稿 ID を使う
+	ctx := context.Background()
+	postID := post.DarkPostID(fmt.Sprintf("post-%d", time.Now().UnixNano()))
+	for i, c := range []struct {
+		client post.ClientID
+		kind   reaction.Kind
+	}{{"a", reaction.KindSaved}, {"b", reaction.KindSaved}, {"", reaction.KindLaughed}, {"", reaction.KindLaughed}} {
+		shard := i
+		reactionShard = func(n int) int { return shard % n }
+		re, _ := reaction.New(postID, c.kind, c.client)
+		if err := repo.Add(ctx, re); err != nil {
+			t.Fatalf("add %+v: %v", c, err)
+		}
+	}
+	dup, _ := reaction.New(postID, reaction.KindSaved, "a")
+	if err := repo.Add(ctx, dup); !errors.Is(err, repository.ErrReactionAlreadyExists) {
+		t.Fatalf("expected ErrReactionAlreadyExists, got %v", err)
+	}
+
+	got, err := repo.Counts(ctx, []post.DarkPostID{postID, "post-none"})
+	if err != nil {
+		t.Fatalf("counts: %v", err)
+	}
+	if got[postID][reaction.KindSaved] != 2 || got[postID][reaction.KindLaughed] != 2 || got[postID][reaction.KindPierced] != 0 {
+		t.Fatalf("unexpected counts: %v", got[postID])
+	}
+	if got["post-none"].Total() != 0 {
+		t.Fatalf("post without reactions should be zero: %v", got["post-none"])
+	}
+}
+
+func TestDrawRepository_IntegrationUpdateTakesDownAndReinstates(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, drawsCollection)
+
+	repo, err := NewDrawRepository(client)
+	if err != nil {
+		t.Fatalf("new draw repo: %v", err)
+	}
+	ctx := context.Background()
+	draw, _ := drawdomain.New(post.DarkPostID("post-1"), drawdomain.FormattedContent("fortune"))
+	draw.MarkVerified()
+	if err := repo.Create(ctx, draw); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+
+	if err := draw.TakeDown("通報"); err != nil {
+		t.Fatalf("take down: %v", err)
+	}
+	if err := repo.Update(ctx, draw); err != nil {
+		t.Fatalf("update draw: %v", err)
+	}
+	if list, _ := repo.ListReady(ctx); len(list) != 0 {
+		t.Fatalf("taken down draw should leave ListReady, got %d", len(list))
+	}
+	fetched, _ := repo.GetByPostID(ctx, "post-1")
+	if fetched.Status() != drawdomain.StatusRejected || fetched.Reason() != "通報" {
+		t.Fatalf("unexpected draw: %s %q", fetched.Status(), fetched.Reason())
+	}
+
+	if err := draw.Reinstate(); err != nil {
+		t.Fatalf("reinstate: %v", err)
+	}
+	if err := repo.Update(ctx, draw); err != nil {
+		t.Fatalf("update draw: %v", err)
+	}
+	if picked, err := repo.PickRandom(ctx, repository.DrawFilter{}); err != nil || picked.PostID() != "post-1" {
+		t.Fatalf("reinstated draw should be picked, got %v, %v", picked, err)
+	}
+
+	missing, _ := drawdomain.New(post.DarkPostID("post-x"), drawdomain.FormattedContent("fortune"))
+	if err := repo.Update(ctx, missing); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+}
+
+func TestReportRepository_IntegrationCountsAndResolves(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	repo, err := NewReportRepository(client)
+	if err != nil {
+		t.Fatalf("new report repo: %v", err)
+	}
+
+	// entries は親ドキュメントを消しても残るため、実行ごとに別の投稿 ID を使う
+	ctx := context.Background()
+	postID := post.DarkPostID(fmt.Sprintf("post-%d", time.Now().UnixNano()))
+	// 同じネットワークからの通報は件数には入るが、ネットワークの数は増やさない
+	origins := []report.Origin{"192.0.2.1", "192.0.2.1", ""}
+	for i, c := range []post.ClientID{"a", "b", ""} {
+		re, _ := report.New(postID, report.ReasonAbusive, "ひどい", c, origins[i])
+		got, err := repo.Add(ctx, re)
+		if err != nil || got.OpenCount != i+1 || got.OpenOrigins != 1 {
+			t.Fatalf("add %q: %+v, %v", c, got, err)
+		}
+	}
+	dup, _ := report.New(postID, report.ReasonSpam, "", "a", "198.51.100.7")
+	if _, err := repo.Add(ctx, dup); !errors.Is(err, repository.ErrReportAlreadyExists) {
+		t.Fatalf("expected ErrReportAlreadyExists, got %v", err)
+	}
+
+	open, err := repo.ListOpen(ctx, 100)
+	if err != nil {
+		t.Fatalf("list open: %v", err)
+	}
+	var found *repository.ReportSummary
+	for i := range open {
+		if open[i].PostID == postID {
+			found = &open[i]
+		}
+	}
+	if found == nil || found.OpenCount != 3 || found.OpenOrigins != 1 || found.Reasons[report.ReasonAbusive] != 3 {
+		t.Fatalf("unexpected summary: %+v", found)
+	}
+
+	if got, err := repo.GetOpen(ctx, postID); err != nil || got.OpenCount != 3 {
+		t.Fatalf("get open: %+v, %v", got, err)
+	}
+	if err := repo.Resolve(ctx, postID, report.ResolutionConfirmed); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if _, err := repo.GetOpen(ctx, postID); !errors.Is(err, repository.ErrReportNotFound) {
+		t.Fatalf("expected ErrReportNotFound after resolve, got %v", err)
+	}
+	if err := repo.Resolve(ctx, postID, report.ResolutionConfirmed); !errors.Is(err, repository.ErrReportNotFound) {
+		t.Fatalf("expected ErrReportNotFound, got %v", err)
+	}
+	again, _ := report.New(postID, report.ReasonOther, "", "c", "192.0.2.1")
+	if got, err := repo.Add(ctx, again); err != nil || got.OpenCount != 1 || got.OpenOrigins != 1 {
+		t.Fatalf("report after resolve should restart the count: %+v, %v", got, err)
+	}
+}
+
+func TestAuditLog_IntegrationAppendsInOrder(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	log, err := NewAuditLog(client)
+	if err != nil {
+		t.Fatalf("new audit log: %v", err)
+	}
+
+	ctx := context.Background()
+	postID := post.DarkPostID(fmt.Sprintf("audit-%d", time.Now().UnixNano()))
+	for _, action := range []audit.Action{audit.ActionPostCreated, audit.ActionFormatStarted, audit.ActionDrawRejected} {
+		e, _ := audit.New(postID, action, audit.Worker(), map[string]string{"reason": "r"})
+		if err := log.Append(ctx, e); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if e.OccurredAt().IsZero() {
+			t.Fatalf("occurred_at should be set on append")
+		}
+	}
+
+	got, err := log.ListByPost(ctx, postID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 3 || got[0].Action() != audit.ActionPostCreated || got[2].Action() != audit.ActionDrawRejected || got[2].Details()["reason"] != "r" {
+		t.Fatalf("unexpected timeline: %+v", got)
+	}
+}
+
+func TestPostOutbox_IntegrationWritesPostAndJobAtomically(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+	truncateCollection(t, client, "format_jobs")
+
+	outbox, err := NewPostOutbox(client)
+	if err != nil {
+		t.Fatalf("new post outbox: %v", err)
+	}
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-outbox"), post.DarkContent("闇"))
+	if err := outbox.CreateWithFormatJob(ctx, p); err != nil {
+		t.Fatalf("create with job: %v", err)
+	}
+	if _, err := client.Collection(postsCollection).Doc("post-outbox").Get(ctx); err != nil {
+		t.Fatalf("post should be stored: %v", err)
+	}
+	if _, err := client.Collection("format_jobs").Doc("post-outbox").Get(ctx); err != nil {
+		t.Fatalf("job should be stored: %v", err)
+	}
+
+	// ジョブだけ先に存在する場合は投稿も作らない
+	if _, err := client.Collection("format_jobs").Doc("post-orphan").Set(ctx, map[string]any{"post_id": "post-orphan"}); err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+	orphan, _ := post.New(post.DarkPostID("post-orphan"), post.DarkContent("闇"))
+	if err := outbox.CreateWithFormatJob(ctx, orphan); !errors.Is(err, portqueue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled, got %v", err)
+	}
+	if _, err := client.Collection(postsCollection).Doc("post-orphan").Get(ctx); err == nil {
+		t.Fatalf("post should not be stored when job already exists")
+	}
+
+	if err := outbox.CreateWithFormatJob(ctx, p); !errors.Is(err, repository.ErrPostAlreadyExists) {
+		t.Fatalf("expected ErrPostAlreadyExists, got %v", err)
+	}
+}
+
+func TestFormatCompleter_IntegrationWritesDrawAndPost(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+	truncateCollection(t, client, drawsCollection)
+
+	postRepo, err := NewPostRepository(client)
+	if err != nil {
+		t.Fatalf("new post repo: %v", err)
+	}
+	drawRepo, err := NewDrawRepository(client)
+	if err != nil {
+		t.Fatalf("new draw repo: %v", err)
+	}
+	completer, err := NewFormatCompleter(client)
+	if err != nil {
+		t.Fatalf("new format completer: %v", err)
+	}
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-complete"), post.DarkContent("闇"))
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	provenance := drawdomain.Provenance{Provider: "gemini", Model: "gemini-2.5-flash", PromptVersion: "v1", PromptTokens: 210, CompletionTokens: 48, Latency: 1200 * time.Millisecond}
+	d.AttachProvenance(provenance)
+	_ = p.MarkReady()
+	if err := completer.Complete(ctx, p, d); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	stored, err := postRepo.Get(ctx, p.ID())
+	if err != nil || stored.Status() != post.StatusReady {
+		t.Fatalf("post should be ready: %+v, %v", stored, err)
+	}
+	storedDraw, err := drawRepo.GetByPostID(ctx, p.ID())
+	if err != nil {
+		t.Fatalf("draw should be stored: %v", err)
+	}
+	if got := storedDraw.Provenance(); got != provenance {
+		t.Fatalf("provenance should be stored with the draw: %+v", got)
+	}
+
+	// 再試行で同じおみくじ結果を書き込んでも成功扱いにする
+	if err := completer.Complete(ctx, p, d); err != nil {
+		t.Fatalf("second complete should succeed: %v", err)
+	}
+
+	// 保存済みの結果が verified でなければ公開待ちには進めない
+	staged, _ := post.New(post.DarkPostID("post-staged"), post.DarkContent("闇"))
+	if err := postRepo.Create(ctx, staged); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	pending, _ := drawdomain.New(staged.ID(), "大吉")
+	if err := drawRepo.Create(ctx, pending); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	verified, _ := drawdomain.New(staged.ID(), "大吉")
+	verified.MarkVerified()
+	_ = staged.MarkReady()
+	if err := completer.Complete(ctx, staged, verified); !errors.Is(err, repository.ErrDrawStatusConflict) {
+		t.Fatalf("expected ErrDrawStatusConflict, got %v", err)
+	}
+	if got, _ := postRepo.Get(ctx, staged.ID()); got.Status() != post.StatusPending {
+		t.Fatalf("post should stay pending, got %s", got.Status())
+	}
+
+	// 投稿が無ければおみくじ結果も書き込まない
+	missing, _ := post.New(post.DarkPostID("post-missing"), post.DarkContent("闇"))
+	orphan, _ := drawdomain.New(missing.ID(), "凶")
+	if err := completer.Complete(ctx, missing, orphan); !errors.Is(err, repository.ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := drawRepo.GetByPostID(ctx, missing.ID()); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("draw should not be stored without post, got %v", err)
+	}
+}
+
+func TestModerationWriter_IntegrationUpdatesDrawAndPost(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, postsCollection)
+	truncateCollection(t, client, drawsCollection)
+
+	postRepo, err := NewPostRepository(client)
+	if err != nil {
+		t.Fatalf("new post repo: %v", err)
+	}
+	drawRepo, err := NewDrawRepository(client)
+	if err != nil {
+		t.Fatalf("new draw repo: %v", err)
+	}
+	writer, err := NewModerationWriter(client)
+	if err != nil {
+		t.Fatalf("new moderation writer: %v", err)
+	}
+	ctx := context.Background()
+
+	p, _ := post.Restore(post.DarkPostID("post-moderate"), post.DarkContent("闇"), post.StatusReady)
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	if err := drawRepo.Create(ctx, d); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+
+	from := repository.ModerationState{Post: p.Status(), Draw: d.Status()}
+	_ = p.MarkRejected()
+	d.MarkRejected("通報")
+	if err := writer.Apply(ctx, p, d, from); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, p.ID()); stored.Status() != post.StatusRejected {
+		t.Fatalf("post should be rejected, got %s", stored.Status())
+	}
+	if stored, _ := drawRepo.GetByPostID(ctx, p.ID()); stored.Status() != drawdomain.StatusRejected || stored.Reason() != "通報" {
+		t.Fatalf("draw should be rejected with reason, got %s %q", stored.Status(), stored.Reason())
+	}
+
+	// おみくじ結果が無ければ投稿も書き換えない
+	bare, _ := post.Restore(post.DarkPostID("post-bare"), post.DarkContent("闇"), post.StatusReady)
+	if err := postRepo.Create(ctx, bare); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	rejected, _ := post.Restore(bare.ID(), bare.Content(), post.StatusRejected)
+	orphan, _ := drawdomain.New(bare.ID(), "凶")
+	if err := writer.Apply(ctx, rejected, orphan, repository.ModerationState{Post: post.StatusReady, Draw: drawdomain.StatusVerified}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, bare.ID()); stored.Status() != post.StatusReady {
+		t.Fatalf("post should stay ready, got %s", stored.Status())
+	}
+
+	// 読み取った後に状態が変わっていれば書き換えない
+	restored, _ := post.Restore(p.ID(), p.Content(), post.StatusReady)
+	verified, _ := drawdomain.New(p.ID(), "大吉")
+	verified.MarkVerified()
+	if err := writer.Apply(ctx, restored, verified, repository.ModerationState{Post: post.StatusReady, Draw: drawdomain.StatusVerified}); !errors.Is(err, repository.ErrModerationConflict) {
+		t.Fatalf("expected ErrModerationConflict, got %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, p.ID()); stored.Status() != post.StatusRejected {
+		t.Fatalf("post should stay rejected, got %s", stored.Status())
+	}
+}
+
+func TestDrawRepository_IntegrationPickRandomWrapsAround(t *testing.T) {
+	client := newTestFirestoreClient(t)
+	truncateCollection(t, client, drawsCollection)
+
+	repo, err := NewDrawRepository(client)
+	if err != nil {
+		t.Fatalf("new draw repo: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := repo.PickRandom(ctx, repository.DrawFilter{}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound on empty collection, got %v", err)
+	}
+
+	origKey := drawRandomKey
+	t.Cleanup(func() { drawRandomKey = origKey })
+
+	// キー 0.2 / 0.6 の 2 件を保存する
+	for _, c := range []struct {
+		id  string
+		key float64
+	}{{"post-low", 0.2}, {"post-high", 0.6}} {
+		key := c.key
+		drawRandomKey = func() float64 { return key }
+		d, _ := drawdomain.New(post.DarkPostID(c.id), "大吉")
+		d.MarkVerified()
+		d.AssignAuthor(post.ClientID("author-" + c.id))
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("create draw: %v", err)
+		}
+	}
+
+	cases := []struct {
+		key  float64
+		want post.DarkPostID
+	}{
+		{key: 0.1, want: "post-low"},
+		{key: 0.5, want: "post-high"},
+		// 最大のキーより大きい乱数は先頭へ折り返す
+		{key: 0.9, want: "post-low"},
+	}
+	for _, c := range cases {
+		key := c.key
+		drawRandomKey = func() float64 { return key }
+		got, err := repo.PickRandom(ctx, repository.DrawFilter{})
+		if err != nil {
+			t.Fatalf("pick random: %v", err)
+		}
+		if got.PostID() != c.want {
+			t.Fatalf("key %v: want %s, got %s", c.key, c.want, got.PostID())
+		}
+	}
+
+	// 自分の投稿から作られた draw は折り返しても選ばない
+	exclude := repository.DrawFilter{ExcludeAuthor: "author-post-low"}
+	for _, key := range []float64{0.1, 0.9} {
+		k := key
+		drawRandomKey = func() float64 { return k }
+		got, err := repo.PickRandom(ctx, exclude)
+		if err != nil {
+			t.Fatalf("pick random with filter: %v", err)
+		}
+		if got.PostID() != "post-high" {
+			t.Fatalf("key %v: own draw should be excluded, got %s", key, got.PostID())
+		}
+	}
+
+	// 直近に引いた draw も同じように飛ばし、先頭へ折り返す
+	drawRandomKey = func() float64 { return 0.5 }
+	got, err := repo.PickRandom(ctx, repository.DrawFilter{ExcludePostIDs: []post.DarkPostID{"post-high"}})
+	if err != nil {
+		t.Fatalf("pick random excluding recent: %v", err)
+	}
+	if got.PostID() != "post-low" {
+		t.Fatalf("recent draw should be excluded, got %s", got.PostID())
+	}
+
+	// 候補をまとめて取り出す場合も乱数の位置から折り返して並べる
+	sample, err := repo.SampleVerified(ctx, repository.DrawFilter{}, 5, nil)
+	if err != nil {
+		t.Fatalf("sample verified: %v", err)
+	}
+	if len(sample) != 2 || sample[0].PostID() != "post-high" || sample[1].PostID() != "post-low" {
+		t.Fatalf("unexpected sample: %v", sample)
+	}
+	if sample[0].CreatedAt().IsZero() {
+		t.Fatal("sampled draw should carry created_at")
+	}
 }
@@ -0,0 +1,93 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FormatCompleter は draws の作成と posts の状態更新を 1 つのトランザクションで行う実装。
+type FormatCompleter struct {
+	client *firestore.Client
+}
+
+// NewFormatCompleter は Firestore クライアントを受け取って FormatCompleter を作成する。
+func NewFormatCompleter(client *firestore.Client) (*FormatCompleter, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &FormatCompleter{client: client}, nil
+}
+
+// Complete はおみくじ結果を保存し、同じトランザクションで投稿の状態を更新する。
+// おみくじ結果がすでにある場合は保存済みの内容を残して投稿だけを更新する。
+// 整形中に削除などで投稿の状態が変わっていれば（同じ更新先の再書き込みを除き）何も書き込まず ErrPostStatusConflict を返す。
+// 保存済みの結果の状態が投稿の更新先を裏付けない場合は何も書き込まず ErrDrawStatusConflict を返す。
+func (c *FormatCompleter) Complete(ctx context.Context, p *postdomain.Post, d *drawdomain.Draw) error {
+	if p == nil {
+		return errNilPost
+	}
+	if d == nil {
+		return errNilDraw
+	}
+	if p.ID() == "" || d.PostID() != p.ID() {
+		return errEmptyPostID
+	}
+
+	postRef := c.client.Collection(postsCollection).Doc(string(p.ID()))
+	drawRef := c.client.Collection(drawsCollection).Doc(string(d.PostID()))
+	err := c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// トランザクション内の読み取りは書き込みより先に済ませる
+		postSnap, err := tx.Get(postRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return repository.ErrPostNotFound
+			}
+			return err
+		}
+		current, err := restorePostFromDoc(postSnap)
+		if err != nil {
+			return err
+		}
+		// 同じ結果の書き込みをやり直した場合は受け付ける
+		if !current.Status().AwaitsFormat() && current.Status() != p.Status() {
+			return fmt.Errorf("%w: stored=%s next=%s", repository.ErrPostStatusConflict, current.Status(), p.Status())
+		}
+		drawSnap, err := tx.Get(drawRef)
+		if err != nil {
+			if status.Code(err) != codes.NotFound {
+				return err
+			}
+			if err := tx.Create(drawRef, newDrawData(d)); err != nil {
+				return err
+			}
+			return tx.Update(postRef, postUpdates(p))
+		}
+
+		stored, err := restoreDrawFromDoc(drawSnap)
+		if err != nil {
+			return err
+		}
+		if !stored.Supports(p.Status()) {
+			return fmt.Errorf("%w: draw=%s post=%s", repository.ErrDrawStatusConflict, stored.Status(), p.Status())
+		}
+		return tx.Update(postRef, postUpdates(p))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPostNotFound) || errors.Is(err, repository.ErrDrawStatusConflict) || errors.Is(err, repository.ErrPostStatusConflict) {
+			return err
+		}
+		return fmt.Errorf("complete format: %w", err)
+	}
+	return nil
+}
+
+var _ repository.FormatCompleter = (*FormatCompleter)(nil)
@@ -0,0 +1,106 @@
+package firestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// idempotencyKeysCollection は冪等キーと投稿 ID の対応を保持するコレクション名。
+const idempotencyKeysCollection = "post_idempotency_keys"
+
+// errNilIdempotencyRecord は nil を紐づけようとした際のバリデーションエラー。
+var errNilIdempotencyRecord = errors.New("firestorerepository: idempotency record is nil")
+
+// idempotencyKeyDocument は Firestore の post_idempotency_keys ドキュメント構造を表す。
+// expires_at は Firestore の TTL ポリシーの対象にでき、期限切れのドキュメントは自動で削除させられる。
+type idempotencyKeyDocument struct {
+	ClientID    string    `firestore:"client_id"`
+	PostID      string    `firestore:"post_id"`
+	Fingerprint string    `firestore:"fingerprint"`
+	ExpiresAt   time.Time `firestore:"expires_at,omitempty"`
+}
+
+// IdempotencyKeyRepository は Firestore を利用した冪等キーのリポジトリ実装。
+type IdempotencyKeyRepository struct {
+	client *firestore.Client
+	now    func() time.Time
+}
+
+// NewIdempotencyKeyRepository は Firestore クライアントを受け取って IdempotencyKeyRepository を作成する。
+func NewIdempotencyKeyRepository(client *firestore.Client) (*IdempotencyKeyRepository, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &IdempotencyKeyRepository{client: client, now: time.Now}, nil
+}
+
+// Reserve は clientID ごとに分けた key に record をトランザクション内で紐づける。
+// 有効期限内の紐づけがあれば保存済みの内容を返し、期限切れ（TTL による削除待ちを含む）なら上書きする。
+func (r *IdempotencyKeyRepository) Reserve(ctx context.Context, clientID postdomain.ClientID, key string, record *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	if key == "" {
+		return nil, repository.ErrEmptyIdempotencyKey
+	}
+	if record == nil {
+		return nil, errNilIdempotencyRecord
+	}
+	if record.PostID == "" {
+		return nil, errEmptyPostID
+	}
+
+	doc := r.client.Collection(idempotencyKeysCollection).Doc(idempotencyKeyDocID(clientID, key))
+	var reserved repository.IdempotencyRecord
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(doc)
+		if err == nil {
+			var payload idempotencyKeyDocument
+			if err := snap.DataTo(&payload); err != nil {
+				return fmt.Errorf("decode idempotency key document: %w", err)
+			}
+			existing := repository.IdempotencyRecord{
+				PostID:      postdomain.DarkPostID(payload.PostID),
+				Fingerprint: payload.Fingerprint,
+				ExpiresAt:   payload.ExpiresAt,
+			}
+			if !existing.Expired(r.now()) {
+				reserved = existing
+				return nil
+			}
+		} else if status.Code(err) != codes.NotFound {
+			return err
+		}
+		reserved = *record
+		data := map[string]any{
+			"client_id":   string(clientID),
+			"post_id":     string(record.PostID),
+			"fingerprint": record.Fingerprint,
+			"created_at":  firestore.ServerTimestamp,
+		}
+		if !record.ExpiresAt.IsZero() {
+			data["expires_at"] = record.ExpiresAt
+		}
+		return tx.Set(doc, data)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reserve idempotency key: %w", err)
+	}
+	return &reserved, nil
+}
+
+// idempotencyKeyDocID はクライアント ID と冪等キーの組をハッシュ化し、クライアント由来の値をそのままドキュメント ID に使わない。
+func idempotencyKeyDocID(clientID postdomain.ClientID, key string) string {
+	sum := sha256.Sum256([]byte(string(clientID) + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ repository.IdempotencyKeyRepository = (*IdempotencyKeyRepository)(nil)
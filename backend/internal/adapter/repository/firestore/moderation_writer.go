@@ -0,0 +1,90 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ModerationWriter は draws と posts の状態の書き換えを 1 つのトランザクションで行う実装。
+type ModerationWriter struct {
+	client *firestore.Client
+}
+
+// NewModerationWriter は Firestore クライアントを受け取って ModerationWriter を作成する。
+func NewModerationWriter(client *firestore.Client) (*ModerationWriter, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &ModerationWriter{client: client}, nil
+}
+
+// Apply はおみくじ結果と投稿の状態を同じトランザクションで更新する。
+// d が nil なら投稿だけを更新する。どちらかのドキュメントが無いか、保存済みの状態が from と食い違えば何も書き込まない。
+func (w *ModerationWriter) Apply(ctx context.Context, p *postdomain.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	if p == nil {
+		return errNilPost
+	}
+	if p.ID() == "" || (d != nil && d.PostID() != p.ID()) {
+		return errEmptyPostID
+	}
+
+	postRef := w.client.Collection(postsCollection).Doc(string(p.ID()))
+	drawRef := w.client.Collection(drawsCollection).Doc(string(p.ID()))
+	err := w.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// トランザクション内の読み取りは書き込みより先に済ませる
+		postSnap, err := tx.Get(postRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return repository.ErrPostNotFound
+			}
+			return err
+		}
+		current, err := restorePostFromDoc(postSnap)
+		if err != nil {
+			return err
+		}
+		if current.Status() != from.Post {
+			return fmt.Errorf("%w: post stored=%s expected=%s", repository.ErrModerationConflict, current.Status(), from.Post)
+		}
+		if d == nil {
+			return tx.Update(postRef, postUpdates(p))
+		}
+		drawSnap, err := tx.Get(drawRef)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return repository.ErrDrawNotFound
+			}
+			return err
+		}
+		stored, err := restoreDrawFromDoc(drawSnap)
+		if err != nil {
+			return err
+		}
+		if stored.Status() != from.Draw {
+			return fmt.Errorf("%w: draw stored=%s expected=%s", repository.ErrModerationConflict, stored.Status(), from.Draw)
+		}
+
+		if err := tx.Update(drawRef, drawUpdates(d)); err != nil {
+			return err
+		}
+		return tx.Update(postRef, postUpdates(p))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPostNotFound) || errors.Is(err, repository.ErrDrawNotFound) || errors.Is(err, repository.ErrModerationConflict) {
+			return err
+		}
+		return fmt.Errorf("apply moderation: %w", err)
+	}
+	return nil
+}
+
+var _ repository.ModerationWriter = (*ModerationWriter)(nil)
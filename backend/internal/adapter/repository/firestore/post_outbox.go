@@ -0,0 +1,77 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	queuefirestore "backend/internal/adapter/queue/firestore"
+	postdomain "backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PostOutbox は posts と format_jobs を 1 つのトランザクションで書き込むアウトボックス実装。
+type PostOutbox struct {
+	client *firestore.Client
+}
+
+// NewPostOutbox は Firestore クライアントを受け取って PostOutbox を作成する。
+func NewPostOutbox(client *firestore.Client) (*PostOutbox, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &PostOutbox{client: client}, nil
+}
+
+// CreateWithFormatJob は投稿ドキュメントと整形待ちジョブを同じトランザクションで作成する。
+func (o *PostOutbox) CreateWithFormatJob(ctx context.Context, p *postdomain.Post) error {
+	if p == nil {
+		return errNilPost
+	}
+
+	postRef := o.client.Collection(postsCollection).Doc(string(p.ID()))
+	jobRef := queuefirestore.FormatJobRef(o.client, p.ID())
+	err := o.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		// どちらが重複しているかを呼び出し側へ伝えるため、書き込み前に存在を確かめる
+		if err := ensureNotExists(tx, postRef, repository.ErrPostAlreadyExists); err != nil {
+			return err
+		}
+		if err := ensureNotExists(tx, jobRef, queue.ErrJobAlreadyScheduled); err != nil {
+			return err
+		}
+		if err := tx.Create(postRef, newPostData(p)); err != nil {
+			return err
+		}
+		return tx.Create(jobRef, queuefirestore.NewPendingFormatJob(p.ID()))
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPostAlreadyExists) || errors.Is(err, queue.ErrJobAlreadyScheduled) {
+			return err
+		}
+		// 確認後に別のリクエストが先に作成した場合
+		if status.Code(err) == codes.AlreadyExists {
+			return repository.ErrPostAlreadyExists
+		}
+		return fmt.Errorf("create post with format job: %w", err)
+	}
+	return nil
+}
+
+// ensureNotExists は ref が存在すれば existsErr を返す。
+func ensureNotExists(tx *firestore.Transaction, ref *firestore.DocumentRef, existsErr error) error {
+	_, err := tx.Get(ref)
+	if err == nil {
+		return existsErr
+	}
+	if status.Code(err) != codes.NotFound {
+		return err
+	}
+	return nil
+}
+
+var _ repository.PostOutbox = (*PostOutbox)(nil)
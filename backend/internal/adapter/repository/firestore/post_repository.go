@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	postdomain "backend/internal/domain/post"
 	"backend/internal/port/repository"
@@ -29,6 +30,9 @@ type postDocument struct {
 	PostID  string `firestore:"post_id"`
 	Content string `firestore:"content"`
 	Status  string `firestore:"status"`
+	// 投稿した匿名クライアントの ID（導入前の投稿には無い）
+	AuthorID  string    `firestore:"author_id"`
+	CreatedAt time.Time `firestore:"created_at"`
 }
 
 // PostRepository は Firestore を利用した Post リポジトリ実装。
@@ -51,14 +55,7 @@ func (r *PostRepository) Create(ctx context.Context, p *postdomain.Post) error {
 	}
 
 	doc := r.client.Collection(postsCollection).Doc(string(p.ID()))
-	data := map[string]any{
-		"post_id":    string(p.ID()),
-		"content":    string(p.Content()),
-		"status":     string(p.Status()),
-		"created_at": firestore.ServerTimestamp,
-	}
-
-	_, err := doc.Create(ctx, data)
+	_, err := doc.Create(ctx, newPostData(p))
 	if status.Code(err) == codes.AlreadyExists {
 		return repository.ErrPostAlreadyExists
 	}
@@ -116,6 +113,89 @@ func (r *PostRepository) ListReady(ctx context.Context, limit int) ([]*postdomai
 	return posts, nil
 }
 
+// ListPendingBefore は before より前に作成された pending / formatting の Post を古い順に最大 limit 件取得する。
+// status と created_at の複合インデックスが必要。
+func (r *PostRepository) ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]*postdomain.Post, error) {
+	query := r.client.Collection(postsCollection).
+		Where("status", "in", []string{string(postdomain.StatusPending), string(postdomain.StatusFormatting)}).
+		Where("created_at", "<", before).
+		OrderBy("created_at", firestore.Asc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var posts []*postdomain.Post
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iterate pending posts: %w", err)
+		}
+
+		p, err := restorePostFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+
+	return posts, nil
+}
+
+// ListByStatus は postStatus の Post を作成日時の新しい順に最大 limit 件取得する。
+// カーソルは前のページの最後の投稿 ID で、status と created_at（降順）の複合インデックスが必要。
+func (r *PostRepository) ListByStatus(ctx context.Context, postStatus postdomain.Status, cursor string, limit int) (*repository.PostPage, error) {
+	query := r.client.Collection(postsCollection).
+		Where("status", "==", string(postStatus)).
+		OrderBy("created_at", firestore.Desc)
+	if cursor != "" {
+		// 続きの位置はカーソルの投稿のスナップショットで決めるため、状態が変わっていても使える
+		snap, err := r.client.Collection(postsCollection).Doc(cursor).Get(ctx)
+		if status.Code(err) == codes.NotFound {
+			return nil, repository.ErrInvalidPostCursor
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get cursor post document: %w", err)
+		}
+		query = query.StartAfter(snap)
+	}
+	if limit > 0 {
+		// 1 件多く読んで続きの有無を判断する
+		query = query.Limit(limit + 1)
+	}
+
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	page := &repository.PostPage{}
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iterate posts by status: %w", err)
+		}
+		if limit > 0 && len(page.Posts) == limit {
+			page.NextCursor = string(page.Posts[limit-1].ID())
+			break
+		}
+
+		p, err := restorePostFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		page.Posts = append(page.Posts, p)
+	}
+
+	return page, nil
+}
+
 // Update は既存の Post を Firestore 上で更新する。
 func (r *PostRepository) Update(ctx context.Context, p *postdomain.Post) error {
 	if p == nil {
@@ -126,13 +206,7 @@ func (r *PostRepository) Update(ctx context.Context, p *postdomain.Post) error {
 	}
 
 	doc := r.client.Collection(postsCollection).Doc(string(p.ID()))
-	updates := []firestore.Update{
-		{Path: "content", Value: string(p.Content())},
-		{Path: "status", Value: string(p.Status())},
-		{Path: "updated_at", Value: firestore.ServerTimestamp},
-	}
-
-	_, err := doc.Update(ctx, updates)
+	_, err := doc.Update(ctx, postUpdates(p))
 	if status.Code(err) == codes.NotFound {
 		return repository.ErrPostNotFound
 	}
@@ -142,6 +216,26 @@ func (r *PostRepository) Update(ctx context.Context, p *postdomain.Post) error {
 	return nil
 }
 
+// newPostData は新規作成時に posts へ書き込む内容を返す。
+func newPostData(p *postdomain.Post) map[string]any {
+	return map[string]any{
+		"post_id":    string(p.ID()),
+		"content":    string(p.Content()),
+		"status":     string(p.Status()),
+		"author_id":  string(p.Author()),
+		"created_at": firestore.ServerTimestamp,
+	}
+}
+
+// postUpdates は既存の posts ドキュメントへ書き込む更新内容を返す。
+func postUpdates(p *postdomain.Post) []firestore.Update {
+	return []firestore.Update{
+		{Path: "content", Value: string(p.Content())},
+		{Path: "status", Value: string(p.Status())},
+		{Path: "updated_at", Value: firestore.ServerTimestamp},
+	}
+}
+
 // restorePostFromDoc は Firestore ドキュメントから Post ドメインを復元する。
 func restorePostFromDoc(doc *firestore.DocumentSnapshot) (*postdomain.Post, error) {
 	var payload postDocument
@@ -157,5 +251,12 @@ func restorePostFromDoc(doc *firestore.DocumentSnapshot) (*postdomain.Post, erro
 	if err != nil {
 		return nil, fmt.Errorf("restore post: %w", err)
 	}
+	post.AssignAuthor(postdomain.ClientID(payload.AuthorID))
+	post.RestoreCreatedAt(payload.CreatedAt)
 	return post, nil
 }
+
+var (
+	_ repository.PendingPostScanner = (*PostRepository)(nil)
+	_ repository.PostBrowser        = (*PostRepository)(nil)
+)
@@ -0,0 +1,136 @@
+package firestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	postdomain "backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// drawReactionsCollection はおみくじごとのリアクションをまとめる親コレクション名。
+	drawReactionsCollection = "draw_reactions"
+	// reactionShardsCollection は件数を分散して数えるシャードのサブコレクション名。
+	reactionShardsCollection = "shards"
+	// reactorsCollection は同じクライアントの重複を見分けるためのサブコレクション名。
+	reactorsCollection = "reactors"
+	// reactionShardCount は 1 件のおみくじの件数を分ける数。1 ドキュメントへの書き込みは毎秒 1 回程度が上限のため分散する。
+	reactionShardCount = 8
+)
+
+var (
+	// errNilReaction は nil を数えようとした際のバリデーションエラー。
+	errNilReaction = errors.New("firestorerepository: reaction is nil")
+	// reactionShard は書き込むシャードを選ぶ乱数源。テストで差し替える。
+	reactionShard = rand.IntN
+)
+
+// ReactionRepository は Firestore を利用したリアクション集計のリポジトリ実装。
+// 件数は draw_reactions/{post_id}/shards/{0..N-1} に種類ごとのフィールドで分散して持ち、読むときに合計する。
+type ReactionRepository struct {
+	client *firestore.Client
+}
+
+// NewReactionRepository は Firestore クライアントを受け取って ReactionRepository を作成する。
+func NewReactionRepository(client *firestore.Client) (*ReactionRepository, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &ReactionRepository{client: client}, nil
+}
+
+// Add はリアクションを無作為に選んだシャードへ 1 件加える。
+// クライアントが分かる場合は reactors への記録と同じトランザクションで数え、同じ種類の重複は ErrReactionAlreadyExists を返す。
+func (r *ReactionRepository) Add(ctx context.Context, re *reaction.Reaction) error {
+	if re == nil {
+		return errNilReaction
+	}
+	parent := r.client.Collection(drawReactionsCollection).Doc(string(re.PostID()))
+	shardRef := parent.Collection(reactionShardsCollection).Doc(strconv.Itoa(reactionShard(reactionShardCount)))
+	increment := map[string]any{string(re.Kind()): firestore.Increment(1)}
+
+	if re.Client() == "" {
+		if _, err := shardRef.Set(ctx, increment, firestore.MergeAll); err != nil {
+			return fmt.Errorf("increment reaction shard: %w", err)
+		}
+		return nil
+	}
+
+	reactorRef := parent.Collection(reactorsCollection).Doc(reactorDocID(re))
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if err := ensureNotExists(tx, reactorRef, repository.ErrReactionAlreadyExists); err != nil {
+			return err
+		}
+		if err := tx.Create(reactorRef, map[string]any{
+			"kind":       string(re.Kind()),
+			"created_at": firestore.ServerTimestamp,
+		}); err != nil {
+			return err
+		}
+		return tx.Set(shardRef, increment, firestore.MergeAll)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrReactionAlreadyExists) || status.Code(err) == codes.AlreadyExists {
+			return repository.ErrReactionAlreadyExists
+		}
+		return fmt.Errorf("add reaction: %w", err)
+	}
+	return nil
+}
+
+// Counts は postIDs ごとに全シャードをまとめて読み、種類別に合計する。
+func (r *ReactionRepository) Counts(ctx context.Context, postIDs []postdomain.DarkPostID) (map[postdomain.DarkPostID]reaction.Counts, error) {
+	result := make(map[postdomain.DarkPostID]reaction.Counts, len(postIDs))
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	refs := make([]*firestore.DocumentRef, 0, len(postIDs)*reactionShardCount)
+	for _, id := range postIDs {
+		result[id] = reaction.NewCounts()
+		shards := r.client.Collection(drawReactionsCollection).Doc(string(id)).Collection(reactionShardsCollection)
+		for i := 0; i < reactionShardCount; i++ {
+			refs = append(refs, shards.Doc(strconv.Itoa(i)))
+		}
+	}
+	// 存在しないシャードも含めて 1 回の呼び出しで読む
+	snaps, err := r.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("get reaction shards: %w", err)
+	}
+	for _, snap := range snaps {
+		if !snap.Exists() {
+			continue
+		}
+		counts := result[postdomain.DarkPostID(snap.Ref.Parent.Parent.ID)]
+		for _, kind := range reaction.Kinds() {
+			v, err := snap.DataAt(string(kind))
+			if err != nil {
+				continue
+			}
+			if n, ok := v.(int64); ok {
+				counts[kind] += int(n)
+			}
+		}
+	}
+	return result, nil
+}
+
+// reactorDocID はクライアント ID と種類をそのままドキュメント ID に使わないようハッシュ化する。
+func reactorDocID(re *reaction.Reaction) string {
+	sum := sha256.Sum256([]byte(string(re.Client()) + "\x00" + string(re.Kind())))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ repository.ReactionRepository = (*ReactionRepository)(nil)
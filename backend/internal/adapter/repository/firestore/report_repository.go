@@ -0,0 +1,293 @@
+package firestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	postdomain "backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// drawReportsCollection はおみくじごとの通報のまとめを置くコレクション名。
+	drawReportsCollection = "draw_reports"
+	// reportEntriesCollection は通報 1 件ずつを残すサブコレクション名。
+	reportEntriesCollection = "entries"
+	// reportOriginsCollection は未確認の通報が届いたネットワークを残すサブコレクション名。
+	reportOriginsCollection = "origins"
+	// reportStatusOpen は未確認の通報があることを表す status の値。
+	reportStatusOpen = "open"
+)
+
+// errNilReport は nil を保存しようとした際のバリデーションエラー。
+var errNilReport = errors.New("firestorerepository: report is nil")
+
+// ReportRepository は Firestore を利用した通報のリポジトリ実装。
+// draw_reports/{post_id} に未確認の件数と理由ごとの件数を、entries に通報 1 件ずつを持つ。
+// origins には通報元のネットワークごとに最後に数えた round を残し、確認済みにするたび round を進めて数え直す。
+type ReportRepository struct {
+	client *firestore.Client
+}
+
+// NewReportRepository は Firestore クライアントを受け取って ReportRepository を作成する。
+func NewReportRepository(client *firestore.Client) (*ReportRepository, error) {
+	if client == nil {
+		return nil, errMissingClient
+	}
+	return &ReportRepository{client: client}, nil
+}
+
+// reportSummaryDocument は draw_reports のドキュメント。
+type reportSummaryDocument struct {
+	PostID         string         `firestore:"post_id"`
+	Status         string         `firestore:"status"`
+	OpenCount      int            `firestore:"open_count"`
+	OpenOrigins    int            `firestore:"open_origins"`
+	Round          int            `firestore:"round"`
+	Reasons        map[string]int `firestore:"reasons"`
+	LastReportedAt time.Time      `firestore:"last_reported_at"`
+}
+
+// Add は通報 1 件と未確認の件数を同じトランザクションで書き込み、加えた後のまとめを返す。
+// クライアントが分かる場合は entries のドキュメント ID をクライアントから決め、再送は ErrReportAlreadyExists を返す。
+// 通報元のネットワークは今の round でまだ数えていなければ open_origins に加える。
+func (r *ReportRepository) Add(ctx context.Context, re *report.Report) (*repository.ReportSummary, error) {
+	if re == nil {
+		return nil, errNilReport
+	}
+	summaryRef := r.client.Collection(drawReportsCollection).Doc(string(re.PostID()))
+	entries := summaryRef.Collection(reportEntriesCollection)
+	entryRef := entries.NewDoc()
+	if re.Client() != "" {
+		entryRef = entries.Doc(reporterDocID(re.Client()))
+	}
+	var originRef *firestore.DocumentRef
+	if re.Origin() != "" {
+		originRef = summaryRef.Collection(reportOriginsCollection).Doc(originDocID(re.Origin()))
+	}
+
+	var result repository.ReportSummary
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if re.Client() != "" {
+			if err := ensureNotExists(tx, entryRef, repository.ErrReportAlreadyExists); err != nil {
+				return err
+			}
+		}
+		summary, err := getReportSummary(tx, summaryRef)
+		if err != nil {
+			return err
+		}
+		// 確認済みのまとめは round を残して数え直す
+		if summary.Status != reportStatusOpen {
+			summary = reportSummaryDocument{PostID: string(re.PostID()), Round: summary.Round}
+		}
+		newOrigin := false
+		if originRef != nil {
+			round, seen, err := getOriginRound(tx, originRef)
+			if err != nil {
+				return err
+			}
+			newOrigin = !seen || round != summary.Round
+		}
+		if summary.Reasons == nil {
+			summary.Reasons = map[string]int{}
+		}
+		summary.Status = reportStatusOpen
+		summary.OpenCount++
+		summary.Reasons[string(re.Reason())]++
+		if newOrigin {
+			summary.OpenOrigins++
+		}
+
+		if err := tx.Create(entryRef, map[string]any{
+			"reason":     string(re.Reason()),
+			"note":       re.Note(),
+			"created_at": firestore.ServerTimestamp,
+		}); err != nil {
+			return err
+		}
+		if newOrigin {
+			if err := tx.Set(originRef, map[string]any{
+				"round":            summary.Round,
+				"last_reported_at": firestore.ServerTimestamp,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Set(summaryRef, map[string]any{
+			"post_id":          summary.PostID,
+			"status":           summary.Status,
+			"open_count":       summary.OpenCount,
+			"open_origins":     summary.OpenOrigins,
+			"round":            summary.Round,
+			"reasons":          summary.Reasons,
+			"last_reported_at": firestore.ServerTimestamp,
+		}); err != nil {
+			return err
+		}
+		result = summary.toSummary()
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrReportAlreadyExists) || status.Code(err) == codes.AlreadyExists {
+			return nil, repository.ErrReportAlreadyExists
+		}
+		return nil, fmt.Errorf("add report: %w", err)
+	}
+	return &result, nil
+}
+
+// GetOpen はおみくじ 1 件の未確認の通報のまとめを返す。
+func (r *ReportRepository) GetOpen(ctx context.Context, postID postdomain.DarkPostID) (*repository.ReportSummary, error) {
+	doc, err := r.client.Collection(drawReportsCollection).Doc(string(postID)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, repository.ErrReportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get report summary: %w", err)
+	}
+	summary, open, err := restoreReportSummary(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, repository.ErrReportNotFound
+	}
+	return &summary, nil
+}
+
+// ListOpen は未確認の通報があるおみくじを最後に通報された順に最大 limit 件返す。
+// status と last_reported_at の複合インデックスが必要。
+func (r *ReportRepository) ListOpen(ctx context.Context, limit int) ([]repository.ReportSummary, error) {
+	query := r.client.Collection(drawReportsCollection).
+		Where("status", "==", reportStatusOpen).
+		OrderBy("last_reported_at", firestore.Desc)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("list open reports: %w", err)
+	}
+
+	result := make([]repository.ReportSummary, 0, len(docs))
+	for _, doc := range docs {
+		summary, _, err := restoreReportSummary(doc)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summary)
+	}
+	return result, nil
+}
+
+// restoreReportSummary は draw_reports のドキュメントをまとめへ変換し、未確認の通報があるかも返す。
+func restoreReportSummary(doc *firestore.DocumentSnapshot) (repository.ReportSummary, bool, error) {
+	var payload reportSummaryDocument
+	if err := doc.DataTo(&payload); err != nil {
+		return repository.ReportSummary{}, false, fmt.Errorf("decode report summary: %w", err)
+	}
+	return payload.toSummary(), payload.Status == reportStatusOpen, nil
+}
+
+// toSummary は draw_reports のドキュメントをポートのまとめへ変換する。
+func (d reportSummaryDocument) toSummary() repository.ReportSummary {
+	reasons := make(map[report.Reason]int, len(d.Reasons))
+	for reason, n := range d.Reasons {
+		reasons[report.Reason(reason)] = n
+	}
+	return repository.ReportSummary{
+		PostID:         postdomain.DarkPostID(d.PostID),
+		OpenCount:      d.OpenCount,
+		OpenOrigins:    d.OpenOrigins,
+		Reasons:        reasons,
+		LastReportedAt: d.LastReportedAt,
+	}
+}
+
+// Resolve は未確認の通報をまとめて確認済みにし、件数を 0 へ戻す。entries は残すため、同じクライアントは再び通報できない。
+// round を進めるため、origins に残ったネットワークは次の通報から数え直す。
+func (r *ReportRepository) Resolve(ctx context.Context, postID postdomain.DarkPostID, resolution report.Resolution) error {
+	summaryRef := r.client.Collection(drawReportsCollection).Doc(string(postID))
+	err := r.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		summary, err := getReportSummary(tx, summaryRef)
+		if err != nil {
+			return err
+		}
+		if summary.Status != reportStatusOpen {
+			return repository.ErrReportNotFound
+		}
+		return tx.Update(summaryRef, []firestore.Update{
+			{Path: "status", Value: string(resolution)},
+			{Path: "open_count", Value: 0},
+			{Path: "open_origins", Value: 0},
+			{Path: "round", Value: summary.Round + 1},
+			{Path: "reasons", Value: map[string]int{}},
+			{Path: "resolved_at", Value: firestore.ServerTimestamp},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return err
+		}
+		return fmt.Errorf("resolve reports: %w", err)
+	}
+	return nil
+}
+
+// getReportSummary はトランザクション内でまとめを読む。まだ無ければゼロ値を返す。
+func getReportSummary(tx *firestore.Transaction, ref *firestore.DocumentRef) (reportSummaryDocument, error) {
+	var summary reportSummaryDocument
+	snap, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return summary, nil
+	}
+	if err != nil {
+		return summary, err
+	}
+	if err := snap.DataTo(&summary); err != nil {
+		return summary, fmt.Errorf("decode report summary: %w", err)
+	}
+	return summary, nil
+}
+
+// getOriginRound はトランザクション内で通報元のネットワークを最後に数えた round を読む。まだ無ければ false を返す。
+func getOriginRound(tx *firestore.Transaction, ref *firestore.DocumentRef) (int, bool, error) {
+	snap, err := tx.Get(ref)
+	if status.Code(err) == codes.NotFound {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	var payload struct {
+		Round int `firestore:"round"`
+	}
+	if err := snap.DataTo(&payload); err != nil {
+		return 0, false, fmt.Errorf("decode report origin: %w", err)
+	}
+	return payload.Round, true, nil
+}
+
+// originDocID は通報元のネットワークをそのままドキュメント ID に使わないようハッシュ化する。
+func originDocID(origin report.Origin) string {
+	sum := sha256.Sum256([]byte(origin))
+	return hex.EncodeToString(sum[:])
+}
+
+// reporterDocID はクライアント ID をそのままドキュメント ID に使わないようハッシュ化する。
+func reporterDocID(client postdomain.ClientID) string {
+	sum := sha256.Sum256([]byte(client))
+	return hex.EncodeToString(sum[:])
+}
+
+var _ repository.ReportRepository = (*ReportRepository)(nil)
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+var errNilAuditEvent = errors.New("memoryrepository: audit event is nil")
+
+// InMemoryAuditLog はメモリ上に投稿ごとの出来事を追記していく監査ログ。
+type InMemoryAuditLog struct {
+	mu     sync.RWMutex
+	events map[post.DarkPostID][]*audit.Event
+	now    func() time.Time
+}
+
+// NewInMemoryAuditLog は InMemoryAuditLog を生成する。
+func NewInMemoryAuditLog() *InMemoryAuditLog {
+	return &InMemoryAuditLog{
+		events: make(map[post.DarkPostID][]*audit.Event),
+		now:    time.Now,
+	}
+}
+
+// Append は出来事に記録日時を付けて末尾へ追記する。
+func (l *InMemoryAuditLog) Append(ctx context.Context, e *audit.Event) error {
+	if e == nil {
+		return errNilAuditEvent
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.RestoreOccurredAt(l.now())
+	stored := *e
+	l.events[e.PostID()] = append(l.events[e.PostID()], &stored)
+	return nil
+}
+
+// ListByPost は postID の出来事を追記した順に複製して返す。
+func (l *InMemoryAuditLog) ListByPost(ctx context.Context, postID post.DarkPostID) ([]*audit.Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := l.events[postID]
+	result := make([]*audit.Event, 0, len(events))
+	for _, e := range events {
+		clone := *e
+		result = append(result, &clone)
+	}
+	return result, nil
+}
+
+var _ repository.AuditLog = (*InMemoryAuditLog)(nil)
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/internal/domain/audit"
+)
+
+func TestInMemoryAuditLog_AppendsInOrder(t *testing.T) {
+	log := NewInMemoryAuditLog()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for i, action := range []audit.Action{audit.ActionPostCreated, audit.ActionFormatStarted, audit.ActionDrawVerified} {
+		log.now = func() time.Time { return base.Add(time.Duration(i) * time.Second) }
+		e, _ := audit.New("post-1", action, audit.Worker(), nil)
+		if err := log.Append(ctx, e); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if !e.OccurredAt().Equal(base.Add(time.Duration(i) * time.Second)) {
+			t.Fatalf("occurred_at should be set on append, got %v", e.OccurredAt())
+		}
+	}
+	other, _ := audit.New("post-2", audit.ActionPostCreated, audit.Client("c"), nil)
+	_ = log.Append(ctx, other)
+
+	got, err := log.ListByPost(ctx, "post-1")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 3 || got[0].Action() != audit.ActionPostCreated || got[2].Action() != audit.ActionDrawVerified {
+		t.Fatalf("unexpected timeline: %+v", got)
+	}
+	// 返した出来事を書き換えても記録は変わらない
+	got[0].RestoreOccurredAt(time.Time{})
+	if again, _ := log.ListByPost(ctx, "post-1"); again[0].OccurredAt().IsZero() {
+		t.Fatalf("stored events should not change")
+	}
+	if empty, _ := log.ListByPost(ctx, "missing"); len(empty) != 0 {
+		t.Fatalf("expected no events, got %d", len(empty))
+	}
+}
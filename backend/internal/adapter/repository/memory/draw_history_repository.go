@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// InMemoryDrawHistoryRepository はメモリ上でクライアントごとの抽選履歴を管理するリポジトリ。
+type InMemoryDrawHistoryRepository struct {
+	mu    sync.Mutex
+	store map[post.ClientID][]post.DarkPostID
+}
+
+// NewInMemoryDrawHistoryRepository は InMemoryDrawHistoryRepository を生成する。
+func NewInMemoryDrawHistoryRepository() *InMemoryDrawHistoryRepository {
+	return &InMemoryDrawHistoryRepository{
+		store: make(map[post.ClientID][]post.DarkPostID),
+	}
+}
+
+// Recent は client が最近引いた闇投稿 ID を新しい順に最大 limit 件返す。
+func (r *InMemoryDrawHistoryRepository) Recent(ctx context.Context, client post.ClientID, limit int) ([]post.DarkPostID, error) {
+	if client == "" {
+		return nil, repository.ErrEmptyClientID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	history := r.store[client]
+	if limit >= 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return slices.Clone(history), nil
+}
+
+// Record は client の履歴の先頭に postID を加え、keep 件までに切り詰める。
+func (r *InMemoryDrawHistoryRepository) Record(ctx context.Context, client post.ClientID, postID post.DarkPostID, keep int) error {
+	if client == "" {
+		return repository.ErrEmptyClientID
+	}
+	if postID == "" {
+		return errEmptyPostID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.store[client] = repository.PushDrawHistory(r.store[client], postID, keep)
+	return nil
+}
+
+var _ repository.DrawHistoryRepository = (*InMemoryDrawHistoryRepository)(nil)
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryDrawHistoryRepository_RecordAndRecent(t *testing.T) {
+	repo := NewInMemoryDrawHistoryRepository()
+	ctx := context.Background()
+
+	for _, id := range []post.DarkPostID{"a", "b", "c", "a"} {
+		if err := repo.Record(ctx, "client-1", id, 3); err != nil {
+			t.Fatalf("record %s: %v", id, err)
+		}
+	}
+
+	// 引き直した a は先頭へ移り、重複しない
+	got, err := repo.Recent(ctx, "client-1", 3)
+	if err != nil {
+		t.Fatalf("recent: %v", err)
+	}
+	if want := []post.DarkPostID{"a", "c", "b"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, _ := repo.Recent(ctx, "client-1", 1); !slices.Equal(got, []post.DarkPostID{"a"}) {
+		t.Fatalf("limit should trim history, got %v", got)
+	}
+	if got, _ := repo.Recent(ctx, "client-2", 3); len(got) != 0 {
+		t.Fatalf("other clients should have no history, got %v", got)
+	}
+
+	if _, err := repo.Recent(ctx, "", 3); !errors.Is(err, repository.ErrEmptyClientID) {
+		t.Fatalf("expected ErrEmptyClientID, got %v", err)
+	}
+	if err := repo.Record(ctx, "", "a", 3); !errors.Is(err, repository.ErrEmptyClientID) {
+		t.Fatalf("expected ErrEmptyClientID, got %v", err)
+	}
+}
@@ -3,7 +3,10 @@ package memory
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
+	"slices"
 	"sync"
+	"time"
 
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
@@ -19,12 +22,18 @@ var (
 type InMemoryDrawRepository struct {
 	mu    sync.RWMutex
 	store map[post.DarkPostID]*drawdomain.Draw
+	// PickRandom で添字から直接選ぶための verified な Draw の ID 一覧
+	verified []post.DarkPostID
+	intN     func(n int) int
+	now      func() time.Time
 }
 
 // NewInMemoryDrawRepository は InMemoryDrawRepository を生成する。
 func NewInMemoryDrawRepository() *InMemoryDrawRepository {
 	return &InMemoryDrawRepository{
 		store: make(map[post.DarkPostID]*drawdomain.Draw),
+		intN:  rand.IntN,
+		now:   time.Now,
 	}
 }
 
@@ -44,10 +53,22 @@ func (r *InMemoryDrawRepository) Create(ctx context.Context, d *drawdomain.Draw)
 	if _, exists := r.store[postID]; exists {
 		return repository.ErrDrawAlreadyExists
 	}
-	r.store[postID] = cloneDraw(d)
+	r.insertLocked(d)
 	return nil
 }
 
+// insertLocked はロック取得済みの呼び出し元から Draw を格納し、抽選対象にも加える。
+func (r *InMemoryDrawRepository) insertLocked(d *drawdomain.Draw) {
+	stored := cloneDraw(d)
+	if stored.CreatedAt().IsZero() {
+		stored.RestoreCreatedAt(r.now())
+	}
+	r.store[d.PostID()] = stored
+	if d.Status() == drawdomain.StatusVerified {
+		r.verified = append(r.verified, d.PostID())
+	}
+}
+
 // GetByPostID は指定した Post ID の Draw を返す。
 func (r *InMemoryDrawRepository) GetByPostID(ctx context.Context, postID post.DarkPostID) (*drawdomain.Draw, error) {
 	if postID == "" {
@@ -65,6 +86,40 @@ func (r *InMemoryDrawRepository) GetByPostID(ctx context.Context, postID post.Da
 	return cloneDraw(d), nil
 }
 
+// Update は既存の Draw を置き換え、verified かどうかに合わせて抽選対象を出し入れする。
+// 作成日時と生成元の記録は Firestore 実装と同じく作成時の値を保つ。
+func (r *InMemoryDrawRepository) Update(ctx context.Context, d *drawdomain.Draw) error {
+	if d == nil {
+		return errNilDraw
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateLocked(d)
+}
+
+// updateLocked は呼び出し側がロックを取った状態で Update と同じ更新を行う。
+func (r *InMemoryDrawRepository) updateLocked(d *drawdomain.Draw) error {
+	current, ok := r.store[d.PostID()]
+	if !ok || current == nil {
+		return repository.ErrDrawNotFound
+	}
+	stored := cloneDraw(d)
+	stored.RestoreCreatedAt(current.CreatedAt())
+	stored.AttachProvenance(current.Provenance())
+	r.store[d.PostID()] = stored
+
+	wasVerified := current.Status() == drawdomain.StatusVerified
+	isVerified := stored.Status() == drawdomain.StatusVerified
+	switch {
+	case wasVerified && !isVerified:
+		r.verified = slices.DeleteFunc(r.verified, func(id post.DarkPostID) bool { return id == d.PostID() })
+	case !wasVerified && isVerified:
+		r.verified = append(r.verified, d.PostID())
+	}
+	return nil
+}
+
 // ListReady は Verified な Draw をすべて返す。
 func (r *InMemoryDrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, error) {
 	r.mu.RLock()
@@ -87,6 +142,89 @@ func (r *InMemoryDrawRepository) ListReady(ctx context.Context) ([]*drawdomain.D
 	return result, nil
 }
 
+// PickRandom は Verified な Draw から 1 件を無作為に返す。
+// 候補を一様な無作為順に 1 件ずつ引いて filter を満たす最初のものを返すため、除外があっても偏らず、除外が少なければ O(1) で済む。
+func (r *InMemoryDrawRepository) PickRandom(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	return r.pickRandom(filter, r.intN)
+}
+
+// PickRandomWith は PickRandom と同じ引き方で、添字の乱数を rng から引く。
+func (r *InMemoryDrawRepository) PickRandomWith(ctx context.Context, filter repository.DrawFilter, rng *rand.Rand) (*drawdomain.Draw, error) {
+	return r.pickRandom(filter, rng.IntN)
+}
+
+func (r *InMemoryDrawRepository) pickRandom(filter repository.DrawFilter, intN func(n int) int) (*drawdomain.Draw, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	order := newRandomOrder(len(r.verified), intN)
+	for i, ok := order.next(); ok; i, ok = order.next() {
+		d := r.store[r.verified[i]]
+		if filter.Allows(d) {
+			return cloneDraw(d), nil
+		}
+	}
+	return nil, repository.ErrDrawNotFound
+}
+
+// SampleVerified は Verified な Draw のうち filter を満たすものを、一様な無作為順に最大 n 件返す。
+// rng が渡されれば添字の乱数をそこから引く。
+func (r *InMemoryDrawRepository) SampleVerified(ctx context.Context, filter repository.DrawFilter, n int, rng *rand.Rand) ([]*drawdomain.Draw, error) {
+	intN := r.intN
+	if rng != nil {
+		intN = rng.IntN
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := len(r.verified)
+	if total == 0 || n <= 0 {
+		return nil, nil
+	}
+	order := newRandomOrder(total, intN)
+	sample := make([]*drawdomain.Draw, 0, min(n, total))
+	for i, ok := order.next(); ok && len(sample) < n; i, ok = order.next() {
+		d := r.store[r.verified[i]]
+		if filter.Allows(d) {
+			sample = append(sample, cloneDraw(d))
+		}
+	}
+	return sample, nil
+}
+
+// randomOrder は 0..n-1 の添字を Fisher-Yates で 1 つずつ引く。
+// 入れ替えた位置だけを map に持つため、引いた件数ぶんの手間しかかからない。
+type randomOrder struct {
+	n       int
+	drawn   int
+	swapped map[int]int
+	intN    func(n int) int
+}
+
+func newRandomOrder(n int, intN func(n int) int) *randomOrder {
+	return &randomOrder{n: n, swapped: make(map[int]int), intN: intN}
+}
+
+func (o *randomOrder) at(i int) int {
+	if v, ok := o.swapped[i]; ok {
+		return v
+	}
+	return i
+}
+
+// next はまだ引いていない添字から一様に 1 つを返す。引き尽くしたら false。
+func (o *randomOrder) next() (int, bool) {
+	if o.drawn >= o.n {
+		return 0, false
+	}
+	j := o.drawn + o.intN(o.n-o.drawn)
+	picked := o.at(j)
+	o.swapped[j] = o.at(o.drawn)
+	o.drawn++
+	return picked, true
+}
+
 func cloneDraw(d *drawdomain.Draw) *drawdomain.Draw {
 	if d == nil {
 		return nil
@@ -94,3 +232,6 @@ func cloneDraw(d *drawdomain.Draw) *drawdomain.Draw {
 	clone := *d
 	return &clone
 }
+
+var _ repository.DrawSampler = (*InMemoryDrawRepository)(nil)
+var _ repository.SeededDrawPicker = (*InMemoryDrawRepository)(nil)
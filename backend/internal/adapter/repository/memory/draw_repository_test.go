@@ -3,7 +3,9 @@ package memory
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
 	"testing"
+	"time"
 
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
@@ -17,6 +19,8 @@ func TestInMemoryDrawRepository_CreateAndGet(t *testing.T) {
 	ctx := context.Background()
 
 	draw := newVerifiedDraw(t, "post-1", "fortune-1")
+	provenance := drawdomain.Provenance{Provider: "openai", Model: "gpt-4o-mini", PromptVersion: "v1", PromptTokens: 180, CompletionTokens: 52, Latency: 900 * time.Millisecond}
+	draw.AttachProvenance(provenance)
 	if err := repo.Create(ctx, draw); err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
@@ -33,6 +37,18 @@ func TestInMemoryDrawRepository_CreateAndGet(t *testing.T) {
 	if got.PostID() != draw.PostID() {
 		t.Fatalf("unexpected post id: want %s, got %s", draw.PostID(), got.PostID())
 	}
+	if got.Provenance() != provenance {
+		t.Fatalf("unexpected provenance: %+v", got.Provenance())
+	}
+
+	// 生成元を持たない値で更新しても作成時の記録は消えない
+	updated := newVerifiedDraw(t, "post-1", "fortune-1")
+	if err := repo.Update(ctx, updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repo.GetByPostID(ctx, draw.PostID()); got.Provenance() != provenance {
+		t.Fatalf("provenance should survive Update: %+v", got.Provenance())
+	}
 
 	// 存在しない ID は ErrDrawNotFound
 	if _, err := repo.GetByPostID(ctx, post.DarkPostID("post-x")); !errors.Is(err, repository.ErrDrawNotFound) {
@@ -82,3 +98,198 @@ func newVerifiedDraw(t *testing.T, postID, result string) *drawdomain.Draw {
 	d.MarkVerified()
 	return d
 }
+
+func TestInMemoryDrawRepository_UpdateMovesDrawInAndOutOfPool(t *testing.T) {
+	t.Parallel()
+
+	repo := NewInMemoryDrawRepository()
+	ctx := context.Background()
+
+	draw := newVerifiedDraw(t, "post-1", "fortune-1")
+	if err := repo.Create(ctx, draw); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := draw.TakeDown("通報"); err != nil {
+		t.Fatalf("TakeDown() error = %v", err)
+	}
+	if err := repo.Update(ctx, draw); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	ready, _ := repo.ListReady(ctx)
+	if len(ready) != 0 {
+		t.Fatalf("taken down draw should leave ListReady, got %d", len(ready))
+	}
+	if _, err := repo.PickRandom(ctx, repository.DrawFilter{}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("taken down draw should not be picked, got %v", err)
+	}
+	got, _ := repo.GetByPostID(ctx, "post-1")
+	if got.Status() != drawdomain.StatusRejected || got.Reason() != "通報" || got.CreatedAt().IsZero() {
+		t.Fatalf("unexpected stored draw: %s %q %v", got.Status(), got.Reason(), got.CreatedAt())
+	}
+
+	if err := draw.Reinstate(); err != nil {
+		t.Fatalf("Reinstate() error = %v", err)
+	}
+	if err := repo.Update(ctx, draw); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if picked, err := repo.PickRandom(ctx, repository.DrawFilter{}); err != nil || picked.PostID() != "post-1" {
+		t.Fatalf("reinstated draw should be picked again, got %v, %v", picked, err)
+	}
+
+	if err := repo.Update(ctx, newVerifiedDraw(t, "post-x", "fortune")); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryDrawRepository_PickRandom(t *testing.T) {
+	t.Parallel()
+
+	repo := NewInMemoryDrawRepository()
+	ctx := context.Background()
+
+	if _, err := repo.PickRandom(ctx, repository.DrawFilter{}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound on empty repository, got %v", err)
+	}
+
+	rejected, _ := drawdomain.New(post.DarkPostID("post-rejected"), "凶")
+	rejected.MarkRejected("不適切")
+	for _, d := range []*drawdomain.Draw{
+		newVerifiedDraw(t, "post-1", "fortune-1"),
+		rejected,
+		newVerifiedDraw(t, "post-2", "fortune-2"),
+	} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// 添字を固定して verified だけが抽選対象になっていることを確かめる
+	for i, want := range []post.DarkPostID{"post-1", "post-2"} {
+		repo.intN = func(n int) int {
+			if n != 2 {
+				t.Fatalf("only verified draws should be candidates, got %d", n)
+			}
+			return i
+		}
+		got, err := repo.PickRandom(ctx, repository.DrawFilter{})
+		if err != nil {
+			t.Fatalf("PickRandom() error = %v", err)
+		}
+		if got.PostID() != want {
+			t.Fatalf("want %s, got %s", want, got.PostID())
+		}
+	}
+}
+
+func TestInMemoryDrawRepository_PickRandomExcludesAuthor(t *testing.T) {
+	t.Parallel()
+
+	repo := NewInMemoryDrawRepository()
+	ctx := context.Background()
+
+	own := newVerifiedDraw(t, "post-own", "fortune-own")
+	own.AssignAuthor("client-a")
+	other := newVerifiedDraw(t, "post-other", "fortune-other")
+	other.AssignAuthor("client-b")
+	for _, d := range []*drawdomain.Draw{own, other} {
+		if err := repo.Create(ctx, d); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// 自分の投稿を先に引いても次の候補へ進む
+	repo.intN = func(n int) int { return 0 }
+	got, err := repo.PickRandom(ctx, repository.DrawFilter{ExcludeAuthor: "client-a"})
+	if err != nil {
+		t.Fatalf("PickRandom() error = %v", err)
+	}
+	if got.PostID() != "post-other" {
+		t.Fatalf("own draw should be skipped, got %s", got.PostID())
+	}
+
+	onlyOwn := NewInMemoryDrawRepository()
+	if err := onlyOwn.Create(ctx, own); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := onlyOwn.PickRandom(ctx, repository.DrawFilter{ExcludeAuthor: "client-a"}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound when only own draws remain, got %v", err)
+	}
+}
+
+func TestInMemoryDrawRepository_PickRandomIsUniformWithExclusions(t *testing.T) {
+	t.Parallel()
+
+	repo := NewInMemoryDrawRepository()
+	ctx := context.Background()
+	for _, id := range []string{"post-a", "post-excluded", "post-c"} {
+		if err := repo.Create(ctx, newVerifiedDraw(t, id, "fortune")); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// 除外した候補の直後だけが選ばれやすくなってはいけない
+	rng := rand.New(rand.NewPCG(1, 2))
+	repo.intN = rng.IntN
+	filter := repository.DrawFilter{ExcludePostIDs: []post.DarkPostID{"post-excluded"}}
+	counts := map[post.DarkPostID]int{}
+	const trials = 6000
+	for i := 0; i < trials; i++ {
+		got, err := repo.PickRandom(ctx, filter)
+		if err != nil {
+			t.Fatalf("PickRandom() error = %v", err)
+		}
+		counts[got.PostID()]++
+	}
+	for _, id := range []post.DarkPostID{"post-a", "post-c"} {
+		if c := counts[id]; c < trials/2-300 || c > trials/2+300 {
+			t.Fatalf("expected about %d picks of %s, got %v", trials/2, id, counts)
+		}
+	}
+}
+
+func TestInMemoryDrawRepository_SampleVerified(t *testing.T) {
+	t.Parallel()
+
+	repo := NewInMemoryDrawRepository()
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return createdAt }
+	ctx := context.Background()
+
+	for _, id := range []string{"post-a", "post-b", "post-c"} {
+		if err := repo.Create(ctx, newVerifiedDraw(t, id, "fortune")); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	// 引いた順に、除外したものを飛ばして返す（b → c（除外）→ a の順に引かせる）
+	repo.intN = func(n int) int { return min(1, n-1) }
+	got, err := repo.SampleVerified(ctx, repository.DrawFilter{ExcludePostIDs: []post.DarkPostID{"post-c"}}, 5, nil)
+	if err != nil {
+		t.Fatalf("SampleVerified() error = %v", err)
+	}
+	if len(got) != 2 || got[0].PostID() != "post-b" || got[1].PostID() != "post-a" {
+		t.Fatalf("unexpected sample: %v", got)
+	}
+	if !got[0].CreatedAt().Equal(createdAt) {
+		t.Fatalf("stored draw should carry created at, got %v", got[0].CreatedAt())
+	}
+
+	if got, _ := repo.SampleVerified(ctx, repository.DrawFilter{}, 1, nil); len(got) != 1 {
+		t.Fatalf("sample should be capped at n, got %d", len(got))
+	}
+
+	// 乱数を渡せばリポジトリ自身の乱数は使わない
+	repo.intN = func(n int) int { panic("repository rng should not be used") }
+	first, _ := repo.SampleVerified(ctx, repository.DrawFilter{}, 3, rand.New(rand.NewPCG(5, 5)))
+	second, _ := repo.SampleVerified(ctx, repository.DrawFilter{}, 3, rand.New(rand.NewPCG(5, 5)))
+	if len(first) != 3 || len(second) != 3 {
+		t.Fatalf("unexpected sample sizes: %d, %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].PostID() != second[i].PostID() {
+			t.Fatalf("same seed should give the same sample, got %v vs %v", first, second)
+		}
+	}
+}
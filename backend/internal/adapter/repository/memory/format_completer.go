@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// メモリ上の draws と posts を両方のロックを取った上でまとめて書き込む。
+type InMemoryFormatCompleter struct {
+	posts *InMemoryPostRepository
+	draws *InMemoryDrawRepository
+}
+
+/**
+ * 投稿とおみくじ結果の保存先を受け取って書き込み口を返す。
+ */
+func NewInMemoryFormatCompleter(posts *InMemoryPostRepository, draws *InMemoryDrawRepository) *InMemoryFormatCompleter {
+	return &InMemoryFormatCompleter{posts: posts, draws: draws}
+}
+
+/**
+ * 投稿が整形待ち・整形中のまま残っていることを確かめてから、未保存ならおみくじ結果を保存し投稿を更新する。
+ * 整形中に削除などで状態が変わっていれば（同じ更新先の再書き込みを除き）何も書き込まず ErrPostStatusConflict を返す。
+ * 保存済みの結果の状態が投稿の更新先を裏付けない場合は何も書き込まず ErrDrawStatusConflict を返す。
+ */
+func (c *InMemoryFormatCompleter) Complete(ctx context.Context, p *post.Post, d *drawdomain.Draw) error {
+	if p == nil {
+		return errNilPost
+	}
+	if d == nil {
+		return errNilDraw
+	}
+	if p.ID() == "" || d.PostID() != p.ID() {
+		return errEmptyPostID
+	}
+
+	// ロックは常に draws → posts の順で取る
+	c.draws.mu.Lock()
+	defer c.draws.mu.Unlock()
+	c.posts.mu.Lock()
+	defer c.posts.mu.Unlock()
+
+	current, ok := c.posts.store[p.ID()]
+	if !ok {
+		return repository.ErrPostNotFound
+	}
+	// 同じ結果の書き込みをやり直した場合は受け付ける
+	if !current.Status().AwaitsFormat() && current.Status() != p.Status() {
+		return fmt.Errorf("%w: stored=%s next=%s", repository.ErrPostStatusConflict, current.Status(), p.Status())
+	}
+	if stored, exists := c.draws.store[d.PostID()]; exists {
+		if !stored.Supports(p.Status()) {
+			return fmt.Errorf("%w: draw=%s post=%s", repository.ErrDrawStatusConflict, stored.Status(), p.Status())
+		}
+	} else {
+		c.draws.insertLocked(d)
+	}
+	c.posts.store[p.ID()] = clonePost(p)
+	return nil
+}
+
+var _ repository.FormatCompleter = (*InMemoryFormatCompleter)(nil)
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryFormatCompleter_WritesDrawAndPost(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	completer := NewInMemoryFormatCompleter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	_ = p.MarkReady()
+
+	if err := completer.Complete(ctx, p, d); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	stored, _ := posts.Get(ctx, p.ID())
+	if stored.Status() != post.StatusReady {
+		t.Fatalf("post should be ready, got %s", stored.Status())
+	}
+	if _, err := draws.GetByPostID(ctx, p.ID()); err != nil {
+		t.Fatalf("draw should be stored: %v", err)
+	}
+
+	// 保存済みのおみくじ結果は上書きせず成功扱いにする
+	other, _ := drawdomain.New(p.ID(), "凶")
+	if err := completer.Complete(ctx, p, other); err != nil {
+		t.Fatalf("second complete: %v", err)
+	}
+	got, _ := draws.GetByPostID(ctx, p.ID())
+	if got.Result() != "大吉" {
+		t.Fatalf("stored draw should be kept, got %s", got.Result())
+	}
+}
+
+func TestInMemoryFormatCompleter_StoredDrawMustSupportPostStatus(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	completer := NewInMemoryFormatCompleter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// 検証前のおみくじ結果だけが残っている
+	pending, _ := drawdomain.New(p.ID(), "大吉")
+	if err := draws.Create(ctx, pending); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+
+	ready, _ := post.New(p.ID(), post.DarkContent("闇"))
+	_ = ready.MarkReady()
+	verified, _ := drawdomain.New(p.ID(), "大吉")
+	verified.MarkVerified()
+	if err := completer.Complete(ctx, ready, verified); !errors.Is(err, repository.ErrDrawStatusConflict) {
+		t.Fatalf("expected ErrDrawStatusConflict, got %v", err)
+	}
+	stored, _ := posts.Get(ctx, p.ID())
+	if stored.Status() != post.StatusPending {
+		t.Fatalf("post should not be promoted, got %s", stored.Status())
+	}
+
+	// 公開不可の結果が残っていれば rejected への更新だけを受け付ける
+	rejected, _ := drawdomain.New(p.ID(), "大吉")
+	rejected.MarkRejected("NG")
+	if err := draws.Update(ctx, rejected); err != nil {
+		t.Fatalf("update draw: %v", err)
+	}
+	if err := completer.Complete(ctx, ready, verified); !errors.Is(err, repository.ErrDrawStatusConflict) {
+		t.Fatalf("expected ErrDrawStatusConflict for ready, got %v", err)
+	}
+	rejectedPost, _ := post.New(p.ID(), post.DarkContent("闇"))
+	_ = rejectedPost.MarkRejected()
+	if err := completer.Complete(ctx, rejectedPost, rejected); err != nil {
+		t.Fatalf("rejected post should be accepted: %v", err)
+	}
+	stored, _ = posts.Get(ctx, p.ID())
+	if stored.Status() != post.StatusRejected {
+		t.Fatalf("post should be rejected, got %s", stored.Status())
+	}
+}
+
+func TestInMemoryFormatCompleter_MissingPostWritesNothing(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	completer := NewInMemoryFormatCompleter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("missing"), post.DarkContent("闇"))
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	if err := completer.Complete(ctx, p, d); !errors.Is(err, repository.ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := draws.GetByPostID(ctx, p.ID()); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("draw should not be stored, got %v", err)
+	}
+}
+
+func TestInMemoryFormatCompleter_DeletedPostIsNotOverwritten(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	completer := NewInMemoryFormatCompleter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// 整形の途中で削除された
+	deleted, _ := posts.Get(ctx, p.ID())
+	_ = deleted.MarkDeleted()
+	if err := posts.Update(ctx, deleted); err != nil {
+		t.Fatalf("update post: %v", err)
+	}
+
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	_ = p.MarkReady()
+	if err := completer.Complete(ctx, p, d); !errors.Is(err, repository.ErrPostStatusConflict) {
+		t.Fatalf("expected ErrPostStatusConflict, got %v", err)
+	}
+	stored, _ := posts.Get(ctx, p.ID())
+	if stored.Status() != post.StatusDeleted {
+		t.Fatalf("post should stay deleted, got %s", stored.Status())
+	}
+	if _, err := draws.GetByPostID(ctx, p.ID()); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("draw should not be stored, got %v", err)
+	}
+}
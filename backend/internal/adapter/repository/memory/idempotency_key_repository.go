@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+var errNilIdempotencyRecord = errors.New("memoryrepository: idempotency record is nil")
+
+// InMemoryIdempotencyKeyRepository はメモリ上で冪等キーと最初のリクエストの対応を管理するリポジトリ。
+type InMemoryIdempotencyKeyRepository struct {
+	mu    sync.Mutex
+	store map[idempotencyScope]repository.IdempotencyRecord
+	now   func() time.Time
+}
+
+// idempotencyScope はクライアントごとに冪等キーを分けて保持するためのキー。
+type idempotencyScope struct {
+	clientID post.ClientID
+	key      string
+}
+
+// NewInMemoryIdempotencyKeyRepository は InMemoryIdempotencyKeyRepository を生成する。
+func NewInMemoryIdempotencyKeyRepository() *InMemoryIdempotencyKeyRepository {
+	return &InMemoryIdempotencyKeyRepository{
+		store: make(map[idempotencyScope]repository.IdempotencyRecord),
+		now:   time.Now,
+	}
+}
+
+// Reserve は clientID ごとに分けた key に record を紐づける。有効期限内の紐づけがあれば保存済みの内容を返す。
+func (r *InMemoryIdempotencyKeyRepository) Reserve(ctx context.Context, clientID post.ClientID, key string, record *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	if key == "" {
+		return nil, repository.ErrEmptyIdempotencyKey
+	}
+	if record == nil {
+		return nil, errNilIdempotencyRecord
+	}
+	if record.PostID == "" {
+		return nil, errEmptyPostID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	scope := idempotencyScope{clientID: clientID, key: key}
+	// 期限切れの紐づけは新しいリクエストで上書きする
+	if existing, ok := r.store[scope]; ok && !existing.Expired(r.now()) {
+		return &existing, nil
+	}
+	r.store[scope] = *record
+	reserved := *record
+	return &reserved, nil
+}
+
+var _ repository.IdempotencyKeyRepository = (*InMemoryIdempotencyKeyRepository)(nil)
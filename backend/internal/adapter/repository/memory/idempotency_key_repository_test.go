@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryIdempotencyKeyRepository_Reserve(t *testing.T) {
+	repo := NewInMemoryIdempotencyKeyRepository()
+	ctx := context.Background()
+
+	first := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-1"), Fingerprint: "fp-1"}
+	got, err := repo.Reserve(ctx, "client-1", "key-1", first)
+	if err != nil || *got != *first {
+		t.Fatalf("first reserve: got %+v, %v", got, err)
+	}
+
+	// 同じキーでは最初に紐づけた内容を返す
+	got, err = repo.Reserve(ctx, "client-1", "key-1", &repository.IdempotencyRecord{PostID: post.DarkPostID("post-2"), Fingerprint: "fp-2"})
+	if err != nil || *got != *first {
+		t.Fatalf("second reserve: got %+v, %v", got, err)
+	}
+
+	// 別のクライアントが同じキーを使っても互いの紐づけは見えない
+	other := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-3"), Fingerprint: "fp-1"}
+	got, err = repo.Reserve(ctx, "client-2", "key-1", other)
+	if err != nil || *got != *other {
+		t.Fatalf("other client reserve: got %+v, %v", got, err)
+	}
+
+	if _, err := repo.Reserve(ctx, "client-1", "", first); !errors.Is(err, repository.ErrEmptyIdempotencyKey) {
+		t.Fatalf("expected ErrEmptyIdempotencyKey, got %v", err)
+	}
+}
+
+func TestInMemoryIdempotencyKeyRepository_ExpiredKeyIsReplaced(t *testing.T) {
+	repo := NewInMemoryIdempotencyKeyRepository()
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	first := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-1"), ExpiresAt: now.Add(time.Hour)}
+	if _, err := repo.Reserve(ctx, "client-1", "key-1", first); err != nil {
+		t.Fatalf("first reserve: %v", err)
+	}
+
+	// 期限内は最初の紐づけを返す
+	second := &repository.IdempotencyRecord{PostID: post.DarkPostID("post-2"), ExpiresAt: now.Add(2 * time.Hour)}
+	if got, err := repo.Reserve(ctx, "client-1", "key-1", second); err != nil || got.PostID != first.PostID {
+		t.Fatalf("expected first record within TTL, got %+v, %v", got, err)
+	}
+
+	// 期限を過ぎたら新しいリクエストとして紐づけ直す
+	now = now.Add(time.Hour)
+	if got, err := repo.Reserve(ctx, "client-1", "key-1", second); err != nil || got.PostID != second.PostID {
+		t.Fatalf("expected expired key to be replaced, got %+v, %v", got, err)
+	}
+}
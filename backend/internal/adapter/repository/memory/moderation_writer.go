@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// メモリ上の draws と posts の状態を両方のロックを取った上でまとめて書き換える。
+type InMemoryModerationWriter struct {
+	posts *InMemoryPostRepository
+	draws *InMemoryDrawRepository
+}
+
+/**
+ * 投稿とおみくじ結果の保存先を受け取って書き込み口を返す。
+ */
+func NewInMemoryModerationWriter(posts *InMemoryPostRepository, draws *InMemoryDrawRepository) *InMemoryModerationWriter {
+	return &InMemoryModerationWriter{posts: posts, draws: draws}
+}
+
+/**
+ * 投稿とおみくじ結果が from の状態のまま残っていることを確かめてから、まとめて更新する。d が nil なら投稿だけを更新する。
+ */
+func (w *InMemoryModerationWriter) Apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	if p == nil {
+		return errNilPost
+	}
+	if p.ID() == "" || (d != nil && d.PostID() != p.ID()) {
+		return errEmptyPostID
+	}
+
+	// ロックは常に draws → posts の順で取る
+	w.draws.mu.Lock()
+	defer w.draws.mu.Unlock()
+	w.posts.mu.Lock()
+	defer w.posts.mu.Unlock()
+
+	current, ok := w.posts.store[p.ID()]
+	if !ok {
+		return repository.ErrPostNotFound
+	}
+	if current.Status() != from.Post {
+		return fmt.Errorf("%w: post stored=%s expected=%s", repository.ErrModerationConflict, current.Status(), from.Post)
+	}
+	if d != nil {
+		stored, ok := w.draws.store[d.PostID()]
+		if !ok || stored == nil {
+			return repository.ErrDrawNotFound
+		}
+		if stored.Status() != from.Draw {
+			return fmt.Errorf("%w: draw stored=%s expected=%s", repository.ErrModerationConflict, stored.Status(), from.Draw)
+		}
+		if err := w.draws.updateLocked(d); err != nil {
+			return err
+		}
+	}
+	w.posts.store[p.ID()] = clonePost(p)
+	return nil
+}
+
+var _ repository.ModerationWriter = (*InMemoryModerationWriter)(nil)
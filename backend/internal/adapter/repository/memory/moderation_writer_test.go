@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryModerationWriter_UpdatesDrawAndPost(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	writer := NewInMemoryModerationWriter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.Restore("post-1", "闇", post.StatusReady)
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	if err := draws.Create(ctx, d); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+
+	from := repository.ModerationState{Post: p.Status(), Draw: d.Status()}
+	_ = p.MarkRejected()
+	d.MarkRejected("通報")
+	if err := writer.Apply(ctx, p, d, from); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if stored, _ := posts.Get(ctx, p.ID()); stored.Status() != post.StatusRejected {
+		t.Fatalf("post should be rejected, got %s", stored.Status())
+	}
+	// 抽選の対象からも外れる
+	if _, err := draws.PickRandom(ctx, repository.DrawFilter{}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("rejected draw should leave the pool, got %v", err)
+	}
+}
+
+func TestInMemoryModerationWriter_MissingWritesNothing(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	writer := NewInMemoryModerationWriter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.Restore("post-1", "闇", post.StatusReady)
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	rejected, _ := post.Restore(p.ID(), "闇", post.StatusRejected)
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	// おみくじ結果が無ければ投稿も書き換えない
+	if err := writer.Apply(ctx, rejected, d, repository.ModerationState{Post: post.StatusReady, Draw: drawdomain.StatusPending}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+	if stored, _ := posts.Get(ctx, p.ID()); stored.Status() != post.StatusReady {
+		t.Fatalf("post should stay ready, got %s", stored.Status())
+	}
+
+	missing, _ := post.New("missing", "闇")
+	orphan, _ := drawdomain.New(missing.ID(), "凶")
+	if err := writer.Apply(ctx, missing, orphan, repository.ModerationState{Post: post.StatusPending, Draw: drawdomain.StatusPending}); !errors.Is(err, repository.ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryModerationWriter_PostOnly(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	writer := NewInMemoryModerationWriter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	_ = p.MarkDeleted()
+	// おみくじ結果がまだ無い投稿は投稿だけを書き換える
+	if err := writer.Apply(ctx, p, nil, repository.ModerationState{Post: post.StatusPending}); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if stored, _ := posts.Get(ctx, p.ID()); stored.Status() != post.StatusDeleted {
+		t.Fatalf("post should be deleted, got %s", stored.Status())
+	}
+	missing, _ := post.New(post.DarkPostID("missing"), post.DarkContent("闇"))
+	if err := writer.Apply(ctx, missing, nil, repository.ModerationState{Post: post.StatusPending}); !errors.Is(err, repository.ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryModerationWriter_StaleStateWritesNothing(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	draws := NewInMemoryDrawRepository()
+	writer := NewInMemoryModerationWriter(posts, draws)
+	ctx := context.Background()
+
+	p, _ := post.Restore("post-1", "闇", post.StatusReady)
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	d, _ := drawdomain.New(p.ID(), "大吉")
+	d.MarkVerified()
+	if err := draws.Create(ctx, d); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+
+	// 読み取った後に別の操作で公開が止められていた
+	approved, _ := post.Restore(p.ID(), "闇", post.StatusReady)
+	verified, _ := drawdomain.New(p.ID(), "大吉")
+	verified.MarkVerified()
+	if err := writer.Apply(ctx, approved, verified, repository.ModerationState{Post: post.StatusRejected, Draw: drawdomain.StatusRejected}); !errors.Is(err, repository.ErrModerationConflict) {
+		t.Fatalf("expected ErrModerationConflict for post, got %v", err)
+	}
+	if err := writer.Apply(ctx, approved, verified, repository.ModerationState{Post: post.StatusReady, Draw: drawdomain.StatusRejected}); !errors.Is(err, repository.ErrModerationConflict) {
+		t.Fatalf("expected ErrModerationConflict for draw, got %v", err)
+	}
+
+	// 整形の途中で結果が書き込まれた投稿を、結果が無い前提では書き換えない
+	pending, _ := post.New("post-2", "闇")
+	if err := posts.Create(ctx, pending); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	deleted, _ := post.Restore(pending.ID(), "闇", post.StatusDeleted)
+	if err := writer.Apply(ctx, deleted, nil, repository.ModerationState{Post: post.StatusFormatting}); !errors.Is(err, repository.ErrModerationConflict) {
+		t.Fatalf("expected ErrModerationConflict for post only, got %v", err)
+	}
+	if stored, _ := posts.Get(ctx, pending.ID()); stored.Status() != post.StatusPending {
+		t.Fatalf("post should stay pending, got %s", stored.Status())
+	}
+}
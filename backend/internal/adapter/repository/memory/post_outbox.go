@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var errNilPost = errors.New("memoryrepository: post is nil")
+
+// メモリ上の投稿リポジトリとジョブキューをまとめて書き込むアウトボックス。
+type InMemoryPostOutbox struct {
+	posts *InMemoryPostRepository
+	jobs  queue.JobQueue
+}
+
+/**
+ * 投稿の保存先と整形ジョブの投入先を受け取ってアウトボックスを返す。
+ */
+func NewInMemoryPostOutbox(posts *InMemoryPostRepository, jobs queue.JobQueue) *InMemoryPostOutbox {
+	return &InMemoryPostOutbox{posts: posts, jobs: jobs}
+}
+
+/**
+ * 投稿を保存してから整形ジョブを積み、ジョブを積めなければ投稿を取り除いて元に戻す。
+ */
+func (o *InMemoryPostOutbox) CreateWithFormatJob(ctx context.Context, p *post.Post) error {
+	if p == nil {
+		return errNilPost
+	}
+	if err := o.posts.Create(ctx, p); err != nil {
+		return err
+	}
+	if err := o.jobs.EnqueueFormat(ctx, p.ID()); err != nil {
+		o.posts.remove(p.ID())
+		return err
+	}
+	return nil
+}
+
+var _ repository.PostOutbox = (*InMemoryPostOutbox)(nil)
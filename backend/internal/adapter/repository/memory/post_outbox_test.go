@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryPostOutbox_CreatesPostAndJob(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	jobs := queueMemory.NewInMemoryJobQueue(0)
+	defer jobs.Close()
+	outbox := NewInMemoryPostOutbox(posts, jobs)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := outbox.CreateWithFormatJob(ctx, p); err != nil {
+		t.Fatalf("create with job: %v", err)
+	}
+	if _, err := posts.Get(ctx, p.ID()); err != nil {
+		t.Fatalf("post should be stored: %v", err)
+	}
+	got, err := jobs.DequeueFormat(ctx)
+	if err != nil || got.PostID != p.ID() {
+		t.Fatalf("job should be enqueued: %q, %v", got.PostID, err)
+	}
+
+	// 投稿が重複していればジョブも積まない
+	if err := outbox.CreateWithFormatJob(ctx, p); !errors.Is(err, repository.ErrPostAlreadyExists) {
+		t.Fatalf("expected ErrPostAlreadyExists, got %v", err)
+	}
+}
+
+func TestInMemoryPostOutbox_RollsBackPostWhenEnqueueFails(t *testing.T) {
+	posts := NewInMemoryPostRepository()
+	jobs := queueMemory.NewInMemoryJobQueue(0)
+	defer jobs.Close()
+	outbox := NewInMemoryPostOutbox(posts, jobs)
+	ctx := context.Background()
+
+	// 先にジョブだけ積んでおき、重複で失敗させる
+	if err := jobs.EnqueueFormat(ctx, post.DarkPostID("post-1")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := outbox.CreateWithFormatJob(ctx, p); !errors.Is(err, queue.ErrJobAlreadyScheduled) {
+		t.Fatalf("expected ErrJobAlreadyScheduled, got %v", err)
+	}
+	if _, err := posts.Get(ctx, p.ID()); !errors.Is(err, repository.ErrPostNotFound) {
+		t.Fatalf("post should be rolled back, got %v", err)
+	}
+}
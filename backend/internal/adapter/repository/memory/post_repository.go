@@ -2,7 +2,9 @@ package memory
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"backend/internal/domain/post"
 	"backend/internal/port/repository"
@@ -10,8 +12,10 @@ import (
 
 // 簡易なメモリ常駐版の投稿リポジトリ。
 type InMemoryPostRepository struct {
-	mu    sync.RWMutex
-	store map[post.DarkPostID]*post.Post
+	mu        sync.RWMutex
+	store     map[post.DarkPostID]*post.Post
+	createdAt map[post.DarkPostID]time.Time
+	now       func() time.Time
 }
 
 /**
@@ -19,7 +23,9 @@ type InMemoryPostRepository struct {
  */
 func NewInMemoryPostRepository() *InMemoryPostRepository {
 	return &InMemoryPostRepository{
-		store: make(map[post.DarkPostID]*post.Post),
+		store:     make(map[post.DarkPostID]*post.Post),
+		createdAt: make(map[post.DarkPostID]time.Time),
+		now:       time.Now,
 	}
 }
 
@@ -34,10 +40,21 @@ func (r *InMemoryPostRepository) Create(ctx context.Context, p *post.Post) error
 	if _, ok := r.store[p.ID()]; ok {
 		return repository.ErrPostAlreadyExists
 	}
-	r.store[p.ID()] = p
+	r.store[p.ID()] = clonePost(p)
+	r.createdAt[p.ID()] = r.now()
 	return nil
 }
 
+/**
+ * アウトボックスの巻き戻し用に投稿を取り除く。
+ */
+func (r *InMemoryPostRepository) remove(id post.DarkPostID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.store, id)
+	delete(r.createdAt, id)
+}
+
 /**
  * ID で検索し、存在しなければ NotFound を返す。
  */
@@ -45,11 +62,10 @@ func (r *InMemoryPostRepository) Get(ctx context.Context, id post.DarkPostID) (*
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	p, ok := r.store[id]
-	if !ok {
+	if _, ok := r.store[id]; !ok {
 		return nil, repository.ErrPostNotFound
 	}
-	return p, nil
+	return r.load(id), nil
 }
 
 func (r *InMemoryPostRepository) ListReady(ctx context.Context, limit int) ([]*post.Post, error) {
@@ -61,7 +77,7 @@ func (r *InMemoryPostRepository) ListReady(ctx context.Context, limit int) ([]*p
 	for _, p := range r.store {
 		// 公開待ちのみ返す
 		if p != nil && p.IsReady() {
-			result = append(result, p)
+			result = append(result, r.load(p.ID()))
 			count++
 			if limit > 0 && count >= limit {
 				break
@@ -71,6 +87,91 @@ func (r *InMemoryPostRepository) ListReady(ctx context.Context, limit int) ([]*p
 	return result, nil
 }
 
+/**
+ * before より前に作成された pending / formatting 投稿を作成順に返す。
+ */
+func (r *InMemoryPostRepository) ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]*post.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []post.DarkPostID
+	for id, p := range r.store {
+		unfinished := p.Status() == post.StatusPending || p.Status() == post.StatusFormatting
+		if unfinished && r.createdAt[id].Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return r.createdAt[ids[i]].Before(r.createdAt[ids[j]])
+	})
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	result := make([]*post.Post, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, r.load(id))
+	}
+	return result, nil
+}
+
+/**
+ * status の投稿を作成日時の新しい順に返す。カーソルは前のページの最後の投稿 ID。
+ */
+func (r *InMemoryPostRepository) ListByStatus(ctx context.Context, status post.Status, cursor string, limit int) (*repository.PostPage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	after := post.DarkPostID(cursor)
+	if cursor != "" {
+		// カーソルの投稿が別の状態へ移っていても、作成日時の位置から続ける
+		if _, ok := r.createdAt[after]; !ok {
+			return nil, repository.ErrInvalidPostCursor
+		}
+	}
+
+	var ids []post.DarkPostID
+	for id, p := range r.store {
+		if p.Status() == status && (cursor == "" || r.newer(after, id)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return r.newer(ids[i], ids[j])
+	})
+
+	page := &repository.PostPage{}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+		page.NextCursor = string(ids[limit-1])
+	}
+	page.Posts = make([]*post.Post, 0, len(ids))
+	for _, id := range ids {
+		page.Posts = append(page.Posts, r.load(id))
+	}
+	return page, nil
+}
+
+/**
+ * 一覧の並び（作成日時の新しい順、同時刻は ID の降順）で a が b より前に来るかを返す。
+ */
+func (r *InMemoryPostRepository) newer(a, b post.DarkPostID) bool {
+	ta, tb := r.createdAt[a], r.createdAt[b]
+	if !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	return a > b
+}
+
+/**
+ * 保存済みの投稿を作成日時付きで複製して返す。呼び出し側でロックを取っておくこと。
+ */
+func (r *InMemoryPostRepository) load(id post.DarkPostID) *post.Post {
+	p := clonePost(r.store[id])
+	p.RestoreCreatedAt(r.createdAt[id])
+	return p
+}
+
 /**
  * 既存エントリのみ更新し、未登録なら NotFound を返す。
  */
@@ -81,6 +182,22 @@ func (r *InMemoryPostRepository) Update(ctx context.Context, p *post.Post) error
 	if _, ok := r.store[p.ID()]; !ok {
 		return repository.ErrPostNotFound
 	}
-	r.store[p.ID()] = p
+	r.store[p.ID()] = clonePost(p)
 	return nil
 }
+
+/**
+ * 呼び出し側の状態遷移が Update を経ずに反映されないよう、保存時と取得時に複製する。
+ */
+func clonePost(p *post.Post) *post.Post {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	return &clone
+}
+
+var (
+	_ repository.PendingPostScanner = (*InMemoryPostRepository)(nil)
+	_ repository.PostBrowser        = (*InMemoryPostRepository)(nil)
+)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"backend/internal/domain/post"
 	"backend/internal/port/repository"
@@ -87,3 +88,141 @@ func TestInMemoryPostRepository_Update(t *testing.T) {
 		t.Fatalf("expected ErrPostNotFound, got %v", err)
 	}
 }
+
+func TestInMemoryPostRepository_PersistsLifecycleStates(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	ctx := context.Background()
+	p, _ := post.New("post-life", "content")
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("create returned error: %v", err)
+	}
+
+	steps := []struct {
+		mark func(*post.Post) error
+		want post.Status
+	}{
+		{mark: (*post.Post).MarkFormatting, want: post.StatusFormatting},
+		{mark: (*post.Post).MarkReady, want: post.StatusReady},
+		{mark: (*post.Post).MarkArchived, want: post.StatusArchived},
+		{mark: (*post.Post).MarkDeleted, want: post.StatusDeleted},
+	}
+	for _, step := range steps {
+		got, err := repo.Get(ctx, p.ID())
+		if err != nil {
+			t.Fatalf("get returned error: %v", err)
+		}
+		if err := step.mark(got); err != nil {
+			t.Fatalf("transition to %s: %v", step.want, err)
+		}
+		if err := repo.Update(ctx, got); err != nil {
+			t.Fatalf("update returned error: %v", err)
+		}
+
+		stored, err := repo.Get(ctx, p.ID())
+		if err != nil {
+			t.Fatalf("get returned error: %v", err)
+		}
+		if stored.Status() != step.want {
+			t.Fatalf("expected %s, got %s", step.want, stored.Status())
+		}
+	}
+}
+
+func TestInMemoryPostRepository_ChangesRequireUpdate(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	ctx := context.Background()
+	p, _ := post.New("post-copy", "content")
+	_ = repo.Create(ctx, p)
+
+	got, _ := repo.Get(ctx, p.ID())
+	if err := got.MarkFormatting(); err != nil {
+		t.Fatalf("transition: %v", err)
+	}
+
+	// Update を呼ぶまで保存内容は変わらない
+	stored, _ := repo.Get(ctx, p.ID())
+	if stored.Status() != post.StatusPending {
+		t.Fatalf("expected stored post to stay pending, got %s", stored.Status())
+	}
+}
+
+func TestInMemoryPostRepository_ListPendingBefore(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	// 作成時刻を 1 分ずつずらして登録する
+	for i, id := range []post.DarkPostID{"old-2", "old-1", "ready", "stuck", "new"} {
+		repo.now = func() time.Time { return base.Add(time.Duration(i) * time.Minute) }
+		p, _ := post.New(id, "content")
+		switch id {
+		case "ready":
+			_ = p.MarkReady()
+		case "stuck":
+			_ = p.MarkFormatting()
+		}
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+
+	// 整形中のまま止まった投稿も取り残しとして拾う
+	got, err := repo.ListPendingBefore(ctx, base.Add(4*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("list pending: %v", err)
+	}
+	if len(got) != 3 || got[0].ID() != "old-2" || got[1].ID() != "old-1" || got[2].ID() != "stuck" {
+		t.Fatalf("unexpected pending posts: %+v", got)
+	}
+
+	limited, err := repo.ListPendingBefore(ctx, base.Add(4*time.Minute), 1)
+	if err != nil || len(limited) != 1 || limited[0].ID() != "old-2" {
+		t.Fatalf("limit should keep the oldest: %+v, %v", limited, err)
+	}
+}
+
+func TestInMemoryPostRepository_ListByStatusPaginates(t *testing.T) {
+	repo := NewInMemoryPostRepository()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for i, id := range []post.DarkPostID{"p1", "p2", "ready", "p3"} {
+		repo.now = func() time.Time { return base.Add(time.Duration(i) * time.Minute) }
+		p, _ := post.New(id, "content")
+		if id == "ready" {
+			_ = p.MarkReady()
+		}
+		if err := repo.Create(ctx, p); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+
+	first, err := repo.ListByStatus(ctx, post.StatusPending, "", 2)
+	if err != nil {
+		t.Fatalf("list first page: %v", err)
+	}
+	if len(first.Posts) != 2 || first.Posts[0].ID() != "p3" || first.Posts[1].ID() != "p2" || first.NextCursor != "p2" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	if !first.Posts[0].CreatedAt().Equal(base.Add(3 * time.Minute)) {
+		t.Fatalf("created_at should be restored, got %v", first.Posts[0].CreatedAt())
+	}
+
+	// カーソルの投稿が状態を変えても続きから取得できる
+	moved, _ := repo.Get(ctx, "p2")
+	_ = moved.MarkDeleted()
+	if err := repo.Update(ctx, moved); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	second, err := repo.ListByStatus(ctx, post.StatusPending, first.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("list second page: %v", err)
+	}
+	if len(second.Posts) != 1 || second.Posts[0].ID() != "p1" || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+
+	if _, err := repo.ListByStatus(ctx, post.StatusPending, "missing", 2); !errors.Is(err, repository.ErrInvalidPostCursor) {
+		t.Fatalf("expected ErrInvalidPostCursor, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+)
+
+var errNilReaction = errors.New("memoryrepository: reaction is nil")
+
+// reactorKey は同じクライアントの同じリアクションを見分けるキー。
+type reactorKey struct {
+	postID post.DarkPostID
+	client post.ClientID
+	kind   reaction.Kind
+}
+
+// InMemoryReactionRepository はメモリ上でおみくじへのリアクションを集計するリポジトリ。
+type InMemoryReactionRepository struct {
+	mu       sync.Mutex
+	counts   map[post.DarkPostID]reaction.Counts
+	reactors map[reactorKey]struct{}
+}
+
+// NewInMemoryReactionRepository は InMemoryReactionRepository を生成する。
+func NewInMemoryReactionRepository() *InMemoryReactionRepository {
+	return &InMemoryReactionRepository{
+		counts:   make(map[post.DarkPostID]reaction.Counts),
+		reactors: make(map[reactorKey]struct{}),
+	}
+}
+
+// Add はリアクションを 1 件数える。同じクライアントの同じ種類は ErrReactionAlreadyExists を返す。
+func (r *InMemoryReactionRepository) Add(ctx context.Context, re *reaction.Reaction) error {
+	if re == nil {
+		return errNilReaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if re.Client() != "" {
+		key := reactorKey{postID: re.PostID(), client: re.Client(), kind: re.Kind()}
+		if _, exists := r.reactors[key]; exists {
+			return repository.ErrReactionAlreadyExists
+		}
+		r.reactors[key] = struct{}{}
+	}
+	counts, ok := r.counts[re.PostID()]
+	if !ok {
+		counts = reaction.NewCounts()
+		r.counts[re.PostID()] = counts
+	}
+	counts[re.Kind()]++
+	return nil
+}
+
+// Counts は postIDs ごとに種類別の件数を返す。
+func (r *InMemoryReactionRepository) Counts(ctx context.Context, postIDs []post.DarkPostID) (map[post.DarkPostID]reaction.Counts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make(map[post.DarkPostID]reaction.Counts, len(postIDs))
+	for _, id := range postIDs {
+		counts := reaction.NewCounts()
+		for k, n := range r.counts[id] {
+			counts[k] = n
+		}
+		result[id] = counts
+	}
+	return result, nil
+}
+
+var _ repository.ReactionRepository = (*InMemoryReactionRepository)(nil)
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryReactionRepository_AddAndCounts(t *testing.T) {
+	repo := NewInMemoryReactionRepository()
+	ctx := context.Background()
+
+	add := func(client post.ClientID, kind reaction.Kind) error {
+		re, err := reaction.New("post-1", kind, client)
+		if err != nil {
+			t.Fatalf("reaction.New: %v", err)
+		}
+		return repo.Add(ctx, re)
+	}
+	for _, c := range []struct {
+		client post.ClientID
+		kind   reaction.Kind
+	}{{"a", reaction.KindSaved}, {"b", reaction.KindSaved}, {"a", reaction.KindLaughed}, {"", reaction.KindPierced}, {"", reaction.KindPierced}} {
+		if err := add(c.client, c.kind); err != nil {
+			t.Fatalf("add %+v: %v", c, err)
+		}
+	}
+	// 同じクライアントの同じ種類は 1 回だけ数える
+	if err := add("a", reaction.KindSaved); !errors.Is(err, repository.ErrReactionAlreadyExists) {
+		t.Fatalf("expected ErrReactionAlreadyExists, got %v", err)
+	}
+
+	got, err := repo.Counts(ctx, []post.DarkPostID{"post-1", "post-2"})
+	if err != nil {
+		t.Fatalf("counts: %v", err)
+	}
+	want := reaction.Counts{reaction.KindSaved: 2, reaction.KindLaughed: 1, reaction.KindPierced: 2}
+	for k, n := range want {
+		if got["post-1"][k] != n {
+			t.Fatalf("post-1 %s: want %d, got %d", k, n, got["post-1"][k])
+		}
+	}
+	if c := got["post-2"]; len(c) != len(reaction.Kinds()) || c.Total() != 0 {
+		t.Fatalf("post without reactions should have zero counts, got %v", c)
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+var errNilReport = errors.New("memoryrepository: report is nil")
+
+// reporterKey は同じクライアントからの同じおみくじへの通報を見分けるキー。
+type reporterKey struct {
+	postID post.DarkPostID
+	client post.ClientID
+}
+
+// InMemoryReportRepository はメモリ上でおみくじへの通報を扱うリポジトリ。
+type InMemoryReportRepository struct {
+	mu        sync.Mutex
+	open      map[post.DarkPostID]*repository.ReportSummary
+	reporters map[reporterKey]struct{}
+	// 未確認の通報が届いたネットワーク。確認済みにしたら数え直す
+	origins map[post.DarkPostID]map[report.Origin]struct{}
+	now     func() time.Time
+}
+
+// NewInMemoryReportRepository は InMemoryReportRepository を生成する。
+func NewInMemoryReportRepository() *InMemoryReportRepository {
+	return &InMemoryReportRepository{
+		open:      make(map[post.DarkPostID]*repository.ReportSummary),
+		reporters: make(map[reporterKey]struct{}),
+		origins:   make(map[post.DarkPostID]map[report.Origin]struct{}),
+		now:       time.Now,
+	}
+}
+
+// Add は通報を保存し、加えた後の未確認の通報のまとめを返す。同じクライアントからの再送は ErrReportAlreadyExists を返す。
+func (r *InMemoryReportRepository) Add(ctx context.Context, re *report.Report) (*repository.ReportSummary, error) {
+	if re == nil {
+		return nil, errNilReport
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if re.Client() != "" {
+		key := reporterKey{postID: re.PostID(), client: re.Client()}
+		if _, exists := r.reporters[key]; exists {
+			return nil, repository.ErrReportAlreadyExists
+		}
+		r.reporters[key] = struct{}{}
+	}
+
+	summary, ok := r.open[re.PostID()]
+	if !ok {
+		summary = &repository.ReportSummary{PostID: re.PostID(), Reasons: make(map[report.Reason]int)}
+		r.open[re.PostID()] = summary
+	}
+	summary.OpenCount++
+	summary.Reasons[re.Reason()]++
+	summary.LastReportedAt = r.now()
+	if re.Origin() != "" {
+		origins, ok := r.origins[re.PostID()]
+		if !ok {
+			origins = make(map[report.Origin]struct{})
+			r.origins[re.PostID()] = origins
+		}
+		origins[re.Origin()] = struct{}{}
+		summary.OpenOrigins = len(origins)
+	}
+	return cloneReportSummary(summary), nil
+}
+
+// GetOpen はおみくじ 1 件の未確認の通報のまとめを返す。
+func (r *InMemoryReportRepository) GetOpen(ctx context.Context, postID post.DarkPostID) (*repository.ReportSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary, ok := r.open[postID]
+	if !ok {
+		return nil, repository.ErrReportNotFound
+	}
+	return cloneReportSummary(summary), nil
+}
+
+// ListOpen は未確認の通報があるおみくじを最後に通報された順に最大 limit 件返す。
+func (r *InMemoryReportRepository) ListOpen(ctx context.Context, limit int) ([]repository.ReportSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]repository.ReportSummary, 0, len(r.open))
+	for _, summary := range r.open {
+		result = append(result, *cloneReportSummary(summary))
+	}
+	slices.SortFunc(result, func(a, b repository.ReportSummary) int {
+		return b.LastReportedAt.Compare(a.LastReportedAt)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// Resolve は未確認の通報をまとめて確認済みにする。通報した記録は残すため、同じクライアントは再び通報できない。
+func (r *InMemoryReportRepository) Resolve(ctx context.Context, postID post.DarkPostID, resolution report.Resolution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.open[postID]; !ok {
+		return repository.ErrReportNotFound
+	}
+	delete(r.open, postID)
+	delete(r.origins, postID)
+	return nil
+}
+
+// cloneReportSummary は呼び出し元が書き換えても保存中のまとめに影響しないよう複製する。
+func cloneReportSummary(summary *repository.ReportSummary) *repository.ReportSummary {
+	copied := *summary
+	copied.Reasons = maps.Clone(summary.Reasons)
+	return &copied
+}
+
+var _ repository.ReportRepository = (*InMemoryReportRepository)(nil)
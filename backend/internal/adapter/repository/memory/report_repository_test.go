@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+func TestInMemoryReportRepository_AddListResolve(t *testing.T) {
+	repo := NewInMemoryReportRepository()
+	ctx := context.Background()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+
+	add := func(postID post.DarkPostID, client post.ClientID, reason report.Reason) (int, error) {
+		re, err := report.New(postID, reason, "", client, "")
+		if err != nil {
+			t.Fatalf("report.New: %v", err)
+		}
+		summary, err := repo.Add(ctx, re)
+		if err != nil {
+			return 0, err
+		}
+		return summary.OpenCount, nil
+	}
+	if n, err := add("post-1", "a", report.ReasonAbusive); err != nil || n != 1 {
+		t.Fatalf("first report: %d, %v", n, err)
+	}
+	if _, err := add("post-1", "a", report.ReasonSpam); !errors.Is(err, repository.ErrReportAlreadyExists) {
+		t.Fatalf("expected ErrReportAlreadyExists, got %v", err)
+	}
+	if n, err := add("post-1", "", report.ReasonSpam); err != nil || n != 2 {
+		t.Fatalf("anonymous report: %d, %v", n, err)
+	}
+	if _, err := add("post-2", "a", report.ReasonOther); err != nil {
+		t.Fatalf("other post: %v", err)
+	}
+
+	open, err := repo.ListOpen(ctx, 10)
+	if err != nil || len(open) != 2 {
+		t.Fatalf("ListOpen() = %v, %v", open, err)
+	}
+	if open[0].PostID != "post-2" || open[1].OpenCount != 2 || open[1].Reasons[report.ReasonSpam] != 1 {
+		t.Fatalf("unexpected summaries: %+v", open)
+	}
+	if got, err := repo.GetOpen(ctx, "post-1"); err != nil || got.OpenCount != 2 {
+		t.Fatalf("GetOpen() = %+v, %v", got, err)
+	}
+	if limited, _ := repo.ListOpen(ctx, 1); len(limited) != 1 {
+		t.Fatalf("limit should apply, got %d", len(limited))
+	}
+
+	if err := repo.Resolve(ctx, "post-1", report.ResolutionRestored); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if err := repo.Resolve(ctx, "post-1", report.ResolutionRestored); !errors.Is(err, repository.ErrReportNotFound) {
+		t.Fatalf("expected ErrReportNotFound, got %v", err)
+	}
+	if _, err := repo.GetOpen(ctx, "post-1"); !errors.Is(err, repository.ErrReportNotFound) {
+		t.Fatalf("expected ErrReportNotFound, got %v", err)
+	}
+	// 確認後の通報は 1 件目から数え直し、確認前に通報したクライアントは再び通報できない
+	if n, err := add("post-1", "b", report.ReasonAbusive); err != nil || n != 1 {
+		t.Fatalf("report after resolve: %d, %v", n, err)
+	}
+	if _, err := add("post-1", "a", report.ReasonAbusive); !errors.Is(err, repository.ErrReportAlreadyExists) {
+		t.Fatalf("expected ErrReportAlreadyExists, got %v", err)
+	}
+}
+
+func TestInMemoryReportRepository_CountsOriginsUntilResolved(t *testing.T) {
+	repo := NewInMemoryReportRepository()
+	ctx := context.Background()
+
+	add := func(client post.ClientID, origin report.Origin) *repository.ReportSummary {
+		re, err := report.New("post-1", report.ReasonAbusive, "", client, origin)
+		if err != nil {
+			t.Fatalf("report.New: %v", err)
+		}
+		summary, err := repo.Add(ctx, re)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		return summary
+	}
+	// クライアント ID を作り直しても同じネットワークからは 1 つと数える
+	add("a", "192.0.2.1")
+	add("b", "192.0.2.1")
+	if got := add("c", ""); got.OpenCount != 3 || got.OpenOrigins != 1 {
+		t.Fatalf("unexpected summary: %+v", got)
+	}
+	if got := add("d", "198.51.100.7"); got.OpenOrigins != 2 {
+		t.Fatalf("expected 2 origins, got %+v", got)
+	}
+	if got, _ := repo.GetOpen(ctx, "post-1"); got.OpenOrigins != 2 {
+		t.Fatalf("GetOpen() = %+v", got)
+	}
+
+	if err := repo.Resolve(ctx, "post-1", report.ResolutionRestored); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	// 確認後は確認前に通報したネットワークも数え直す
+	if got := add("e", "192.0.2.1"); got.OpenCount != 1 || got.OpenOrigins != 1 {
+		t.Fatalf("unexpected summary after resolve: %+v", got)
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"fmt"
+)
+
+// API とワーカーを 1 プロセスで動かすための依存をまとめた器。
+// リポジトリとジョブキューは両者で同じインスタンスを共有する。
+type AllInOneContainer struct {
+	API    *Container
+	Worker *WorkerContainer
+}
+
+/**
+ * インフラ・リポジトリ・キューを 1 度だけ初期化し、API とワーカーの双方へ配る。
+ */
+func NewAllInOneContainer(ctx context.Context) (*AllInOneContainer, error) {
+	// Firestore を使う場合は、ワーカーと同じ環境変数を要求する
+	if err := ensureWorkerFirestoreEnv(); err != nil {
+		return nil, err
+	}
+
+	infra, err := infraFactory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("init infra: %w", err)
+	}
+
+	postRepo, err := postRepositoryFactory(ctx, infra)
+	if err != nil {
+		return nil, err
+	}
+
+	drawRepo, err := drawRepositoryFactory(ctx, infra)
+	if err != nil {
+		return nil, fmt.Errorf("init draw repository: %w", err)
+	}
+
+	completer, err := formatCompleterFactory(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init format completer: %w", err)
+	}
+
+	keys, err := newIdempotencyKeys(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init idempotency key repository: %w", err)
+	}
+
+	// 同一プロセス内で受け渡すため、JOB_QUEUE_MODE=memory でも投稿がワーカーへ届く
+	jobQueue, err := jobQueueFactory(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init job queue: %w", err)
+	}
+
+	outbox, err := postOutboxFactory(infra, postRepo, jobQueue)
+	if err != nil {
+		return nil, fmt.Errorf("init post outbox: %w", err)
+	}
+
+	identity, err := clientIdentityFactory()
+	if err != nil {
+		return nil, fmt.Errorf("init client identity: %w", err)
+	}
+	fortune, err := newFortuneSettings(infra, drawRepo)
+	if err != nil {
+		return nil, fmt.Errorf("init fortune settings: %w", err)
+	}
+	moderation, err := newModerationSettings(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init moderation settings: %w", err)
+	}
+
+	// 投稿の受け付けから整形までを 1 つの経緯として残すため、API とワーカーで同じ監査ログを使う
+	auditLog, err := provideAuditLog(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init audit log: %w", err)
+	}
+
+	validator, err := newFortuneValidator()
+	if err != nil {
+		return nil, fmt.Errorf("init fortune validator: %w", err)
+	}
+
+	formatter, closeFormatter, err := formatterFactory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("init formatter: %w", err)
+	}
+
+	return &AllInOneContainer{
+		API:    newContainer(infra, drawRepo, postRepo, keys, outbox, jobQueue, identity, fortune, moderation, auditLog),
+		Worker: newWorkerContainer(infra, postRepo, drawRepo, completer, jobQueue, auditLog, validator, formatter, closeFormatter),
+	}, nil
+}
+
+/**
+ * 共有リソースはワーカー側がまとめて閉じるため、ワーカーの Close のみ呼び出す。
+ */
+func (c *AllInOneContainer) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.Worker.Close()
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/port/llm"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+	workertestutil "backend/internal/usecase/worker/testutil"
+)
+
+func TestNewAllInOneContainer_SharesDependencies(t *testing.T) {
+	setRequiredFirestoreEnv(t)
+	defer stubJobQueueFactory(t)()
+	defer stubFormatCompleterFactory(t)()
+
+	stubFormatter := &stubFormatter{}
+	origFormatterFactory := formatterFactory
+	formatterFactory = func(ctx context.Context) (llm.Formatter, func() error, error) {
+		return stubFormatter, stubFormatter.Close, nil
+	}
+	defer func() { formatterFactory = origFormatterFactory }()
+
+	stubRepo := &workerStubPostRepository{}
+	origRepoFactory := postRepositoryFactory
+	postRepositoryFactory = func(ctx context.Context, infra *Infra) (repository.PostRepository, error) {
+		return stubRepo, nil
+	}
+	defer func() { postRepositoryFactory = origRepoFactory }()
+
+	stubDrawRepo := &workertestutil.StubDrawRepository{}
+	defer stubDrawRepositoryFactory(t, stubDrawRepo, nil)()
+	defer stubDrawHistoryRepositoryFactory(t, repoMemory.NewInMemoryDrawHistoryRepository())()
+	defer stubReactionRepositoryFactory(t, repoMemory.NewInMemoryReactionRepository())()
+	defer stubReportRepositoryFactory(t, repoMemory.NewInMemoryReportRepository())()
+	defer stubModerationWriterFactory(t, repoMemory.NewInMemoryModerationWriter(repoMemory.NewInMemoryPostRepository(), repoMemory.NewInMemoryDrawRepository()))()
+	defer stubAuditLogFactory(t, repoMemory.NewInMemoryAuditLog())()
+
+	origKeyRepoFactory := idempotencyKeyRepositoryFactory
+	idempotencyKeyRepositoryFactory = func(infra *Infra) (repository.IdempotencyKeyRepository, error) {
+		return repoMemory.NewInMemoryIdempotencyKeyRepository(), nil
+	}
+	defer func() { idempotencyKeyRepositoryFactory = origKeyRepoFactory }()
+
+	origOutboxFactory := postOutboxFactory
+	postOutboxFactory = func(infra *Infra, postRepo repository.PostRepository, jobQueue queue.JobQueue) (repository.PostOutbox, error) {
+		return &sequentialPostOutbox{postRepo: postRepo, jobQueue: jobQueue}, nil
+	}
+	defer func() { postOutboxFactory = origOutboxFactory }()
+
+	origInfraFactory := infraFactory
+	infra := &Infra{}
+	infraFactory = func(ctx context.Context) (*Infra, error) {
+		return infra, nil
+	}
+	defer func() { infraFactory = origInfraFactory }()
+
+	container, err := NewAllInOneContainer(context.Background())
+	if err != nil {
+		t.Fatalf("NewAllInOneContainer returned error: %v", err)
+	}
+	if container.API.Infra != infra || container.Worker.Infra != infra {
+		t.Fatalf("expected infra to be shared")
+	}
+	if container.Worker.PostRepo != stubRepo || container.Worker.DrawRepo != stubDrawRepo {
+		t.Fatalf("expected worker to use shared repositories")
+	}
+	if container.API.CreatePostUsecase == nil || container.API.DrawHandler == nil || container.API.PostHandler == nil {
+		t.Fatalf("expected API dependencies to be initialized")
+	}
+	if err := container.Close(); err != nil {
+		t.Fatalf("close returned error: %v", err)
+	}
+	if !stubFormatter.closed {
+		t.Fatalf("formatter should be closed via all-in-one Close")
+	}
+}
+
+func TestNewAllInOneContainer_FirestoreEnvMissing(t *testing.T) {
+	if _, err := NewAllInOneContainer(context.Background()); !errors.Is(err, errWorkerFirestoreEnvMissing) {
+		t.Fatalf("expected missing env error, got %v", err)
+	}
+}
+
+func TestAllInOneContainerClose_Nil(t *testing.T) {
+	var container *AllInOneContainer
+	if err := container.Close(); err != nil {
+		t.Fatalf("expected nil error for nil receiver")
+	}
+}
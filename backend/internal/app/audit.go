@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	firestoreadapter "backend/internal/adapter/repository/firestore"
+	"backend/internal/domain/audit"
+	"backend/internal/port/repository"
+)
+
+var auditLogFactory = newAuditLog
+
+/**
+ * 監査ログを構築し、記録の失敗がログに残るよう包んで返す。
+ * ユースケースは記録に失敗しても操作を続けるため、失敗に気付けるのはこのログだけになる。
+ */
+func provideAuditLog(infra *Infra) (repository.AuditLog, error) {
+	auditLog, err := auditLogFactory(infra)
+	if err != nil {
+		return nil, err
+	}
+	return loggingAuditLog{AuditLog: auditLog}, nil
+}
+
+/**
+ * 監査ログを構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
+ */
+func newAuditLog(infra *Infra) (repository.AuditLog, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.auditLog, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	auditLog, err := firestoreadapter.NewAuditLog(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore audit log: %w", err)
+	}
+	return auditLog, nil
+}
+
+// loggingAuditLog は追記に失敗した出来事をログへ書き出す監査ログ。
+type loggingAuditLog struct {
+	repository.AuditLog
+}
+
+func (l loggingAuditLog) Append(ctx context.Context, e *audit.Event) error {
+	err := l.AuditLog.Append(ctx, e)
+	if err != nil && e != nil {
+		log.Printf("監査ログの記録に失敗しました (post_id=%s action=%s): %v", e.PostID(), e.Action(), err)
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestProvideAuditLog(t *testing.T) {
+	if _, err := provideAuditLog(&Infra{}); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+
+	inner := repoMemory.NewInMemoryAuditLog()
+	defer stubAuditLogFactory(t, inner)()
+	auditLog, err := provideAuditLog(&Infra{})
+	if err != nil {
+		t.Fatalf("provideAuditLog returned error: %v", err)
+	}
+
+	e, _ := audit.New("post-1", audit.ActionPostCreated, audit.Worker(), nil)
+	if err := auditLog.Append(context.Background(), e); err != nil {
+		t.Fatalf("append returned error: %v", err)
+	}
+	events, err := auditLog.ListByPost(context.Background(), "post-1")
+	if err != nil || len(events) != 1 {
+		t.Fatalf("expected the wrapped log to be used: %v, %v", events, err)
+	}
+
+	// 記録の失敗はログに残しつつ呼び出し元へもそのまま返す
+	failing := loggingAuditLog{AuditLog: failingAuditLog{err: errors.New("firestore down")}}
+	if err := failing.Append(context.Background(), e); err == nil {
+		t.Fatal("expected append error to be returned")
+	}
+}
+
+// stubAuditLogFactory は監査ログのファクトリを差し替え、元に戻す関数を返す。
+func stubAuditLogFactory(t *testing.T, auditLog repository.AuditLog) func() {
+	t.Helper()
+	orig := auditLogFactory
+	auditLogFactory = func(infra *Infra) (repository.AuditLog, error) {
+		return auditLog, nil
+	}
+	return func() { auditLogFactory = orig }
+}
+
+type failingAuditLog struct {
+	err error
+}
+
+func (l failingAuditLog) Append(ctx context.Context, e *audit.Event) error {
+	return l.err
+}
+
+func (l failingAuditLog) ListByPost(ctx context.Context, postID post.DarkPostID) ([]*audit.Event, error) {
+	return nil, l.err
+}
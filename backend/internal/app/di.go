@@ -5,12 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"backend/internal/adapter/http/handler"
+	uuidgen "backend/internal/adapter/idgen/uuid"
 	firestoreadapter "backend/internal/adapter/repository/firestore"
+	"backend/internal/config"
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
+	"backend/internal/port/queue"
 	"backend/internal/port/repository"
+	adminusecase "backend/internal/usecase/admin"
 	drawusecase "backend/internal/usecase/draw"
 	postusecase "backend/internal/usecase/post"
 	"cloud.google.com/go/firestore"
@@ -20,13 +25,23 @@ import (
 type Container struct {
 	Infra              *Infra
 	DrawFortuneUsecase *drawusecase.FortuneUsecase
+	ReactDrawUsecase   *drawusecase.ReactDrawUsecase
+	DrawReactions      *drawusecase.GetDrawReactionsUsecase
+	ReportDrawUsecase  *drawusecase.ReportDrawUsecase
 	DrawHandler        *handler.DrawHandler
 	CreatePostUsecase  *postusecase.CreatePostUsecase
+	PostStatusUsecase  *postusecase.GetPostStatusUsecase
 	PostHandler        *handler.PostHandler
+	ClientIdentity     *handler.ClientIdentity
+	AdminHandler       *handler.AdminHandler // ADMIN_API_TOKEN が未設定なら nil
 }
 
 // NewContainer は依存を初期化して返す。
 func NewContainer(ctx context.Context) (*Container, error) {
+	// メモリ上の保存先はワーカーのプロセスと共有できないため、単独の API では使わない
+	if err := ensureSharedMemoryStore(); err != nil {
+		return nil, err
+	}
 	infra, err := NewInfra(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("init infra: %w", err)
@@ -37,29 +52,108 @@ func NewContainer(ctx context.Context) (*Container, error) {
 		return nil, fmt.Errorf("provide draw repository: %w", err)
 	}
 
-	usecase := drawusecase.NewFortuneUsecase(repo)
-	drawHandler := handler.NewDrawHandler(usecase)
-
-	// API では Firestore へ統一するため、メモリ実装へは切り替えない
+	// JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う
 	postRepo, err := newAPIPostRepository(infra)
 	if err != nil {
 		return nil, fmt.Errorf("init post repository: %w", err)
 	}
-	// 投稿整形キューは Firestore 固定の format_jobs を利用する
+	keys, err := newIdempotencyKeys(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init idempotency key repository: %w", err)
+	}
+	// 投稿整形キューは JOB_QUEUE_MODE で Firestore / メモリを切り替える
 	jobQueue, err := jobQueueFactory(infra)
 	if err != nil {
 		return nil, fmt.Errorf("init job queue: %w", err)
 	}
-	createPostUsecase := postusecase.NewCreatePostUsecase(postRepo, jobQueue)
-	postHandler := handler.NewPostHandler(createPostUsecase)
+
+	outbox, err := postOutboxFactory(infra, postRepo, jobQueue)
+	if err != nil {
+		return nil, fmt.Errorf("init post outbox: %w", err)
+	}
+
+	identity, err := clientIdentityFactory()
+	if err != nil {
+		return nil, fmt.Errorf("init client identity: %w", err)
+	}
+	fortune, err := newFortuneSettings(infra, repo)
+	if err != nil {
+		return nil, fmt.Errorf("init fortune settings: %w", err)
+	}
+	moderation, err := newModerationSettings(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init moderation settings: %w", err)
+	}
+	auditLog, err := provideAuditLog(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init audit log: %w", err)
+	}
+
+	return newContainer(infra, repo, postRepo, keys, outbox, jobQueue, identity, fortune, moderation, auditLog), nil
+}
+
+/**
+ * 初期化済みのリポジトリとキューからユースケースとハンドラーを組み立てる。
+ */
+func newContainer(
+	infra *Infra,
+	drawRepo repository.DrawRepository,
+	postRepo repository.PostRepository,
+	keys idempotencyKeys,
+	outbox repository.PostOutbox,
+	jobQueue queue.JobQueue,
+	identity *handler.ClientIdentity,
+	fortune fortuneSettings,
+	moderation moderationSettings,
+	auditLog repository.AuditLog,
+) *Container {
+	usecase := drawusecase.NewFortuneUsecase(fortune.strategy, fortune.history.repo, fortune.history.window, fortune.reactions)
+	reactUsecase := drawusecase.NewReactDrawUsecase(drawRepo, fortune.reactions)
+	reactionsUsecase := drawusecase.NewGetDrawReactionsUsecase(drawRepo, fortune.reactions)
+	reportUsecase := drawusecase.NewReportDrawUsecase(postRepo, drawRepo, moderation.reports, moderation.writer, auditLog, moderation.threshold)
+	drawHandler := handler.NewDrawHandler(usecase, reactUsecase, reactionsUsecase, reportUsecase)
+
+	// 投稿 ID はクライアントに選ばせず UUIDv7 で払い出す
+	createPostUsecase := postusecase.NewCreatePostUsecase(postRepo, outbox, uuidgen.NewV7Generator(), keys.repo, keys.ttl, auditLog)
+	// ジョブ状況を参照できないキュー実装なら投稿の状態だけを返す
+	jobInspector, _ := jobQueue.(queue.JobInspector)
+	postStatusUsecase := postusecase.NewGetPostStatusUsecase(postRepo, drawRepo, jobInspector)
+	postHandler := handler.NewPostHandler(createPostUsecase, postStatusUsecase)
+
+	var adminHandler *handler.AdminHandler
+	if moderation.adminToken != "" {
+		// 状態ごとの一覧や隔離ジョブの再投入に対応しない実装では、その操作だけが使えない
+		postBrowser, _ := postRepo.(repository.PostBrowser)
+		deadLetters, _ := jobQueue.(queue.DeadLetterQueue)
+		// 通報の確認も強制承認・強制却下と同じ手順で draw と投稿をまとめて書き換える
+		approveDraw := adminusecase.NewApproveDrawUsecase(postRepo, drawRepo, moderation.writer, auditLog)
+		rejectDraw := adminusecase.NewRejectDrawUsecase(postRepo, drawRepo, moderation.writer, auditLog)
+		adminHandler = handler.NewAdminHandler(moderation.adminToken, handler.AdminUsecases{
+			ListReports:    drawusecase.NewListReportedDrawsUsecase(drawRepo, moderation.reports),
+			ResolveReports: adminusecase.NewResolveReportsUsecase(moderation.reports, approveDraw, rejectDraw, auditLog),
+			ListPosts:      adminusecase.NewListPostsUsecase(postBrowser),
+			GetPost:        adminusecase.NewGetPostUsecase(postRepo, drawRepo, jobInspector),
+			PostTimeline:   adminusecase.NewGetPostTimelineUsecase(postRepo, auditLog),
+			ApproveDraw:    approveDraw,
+			RejectDraw:     rejectDraw,
+			RequeuePost:    adminusecase.NewRequeuePostUsecase(postRepo, jobQueue, deadLetters, auditLog),
+			DeletePost:     adminusecase.NewDeletePostUsecase(postRepo, drawRepo, moderation.writer, auditLog),
+		})
+	}
 
 	return &Container{
 		Infra:              infra,
 		DrawFortuneUsecase: usecase,
+		ReactDrawUsecase:   reactUsecase,
+		DrawReactions:      reactionsUsecase,
+		ReportDrawUsecase:  reportUsecase,
 		DrawHandler:        drawHandler,
 		CreatePostUsecase:  createPostUsecase,
+		PostStatusUsecase:  postStatusUsecase,
 		PostHandler:        postHandler,
-	}, nil
+		ClientIdentity:     identity,
+		AdminHandler:       adminHandler,
+	}
 }
 
 // Close は保持している外部リソースをクローズする。
@@ -75,12 +169,28 @@ var (
 	apiPostRepositoryFactory      = func(client *firestore.Client) (repository.PostRepository, error) {
 		return firestoreadapter.NewPostRepository(client)
 	}
+	idempotencyKeyRepositoryFactory = newIdempotencyKeyRepository
+	clientIdentityFactory           = newClientIdentity
 )
 
 /**
- * API 用に Firestore 固定の投稿リポジトリを構築する。
+ * 環境変数の署名鍵で匿名クライアント ID の発行・検証を組み立てる。
+ */
+func newClientIdentity() (*handler.ClientIdentity, error) {
+	cfg, err := config.LoadClientIdentityConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return handler.NewClientIdentity(cfg.Secret, cfg.CookieSecure), nil
+}
+
+/**
+ * API 用の投稿リポジトリを構築する。JOB_QUEUE_MODE=memory ならワーカーと共有するメモリ実装を返す。
  */
 func newAPIPostRepository(infra *Infra) (repository.PostRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.posts, nil
+	}
 	client := infra.Firestore()
 	if client == nil {
 		return nil, errFirestoreClientUnavailable
@@ -93,11 +203,53 @@ func newAPIPostRepository(infra *Infra) (repository.PostRepository, error) {
 	return repo, nil
 }
 
+// 冪等キーのリポジトリと、キーと投稿の紐づけを保持する期間。
+type idempotencyKeys struct {
+	repo repository.IdempotencyKeyRepository
+	ttl  time.Duration
+}
+
+/**
+ * IDEMPOTENCY_KEY_TTL を読み、冪等キーのリポジトリと合わせて返す。
+ */
+func newIdempotencyKeys(infra *Infra) (idempotencyKeys, error) {
+	ttl, err := config.LoadIdempotencyKeyTTLFromEnv()
+	if err != nil {
+		return idempotencyKeys{}, err
+	}
+	repo, err := idempotencyKeyRepositoryFactory(infra)
+	if err != nil {
+		return idempotencyKeys{}, err
+	}
+	return idempotencyKeys{repo: repo, ttl: ttl}, nil
+}
+
+/**
+ * 投稿と同じ Firestore に冪等キーの対応を保存するリポジトリを構築する。
+ */
+func newIdempotencyKeyRepository(infra *Infra) (repository.IdempotencyKeyRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.keys, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	repo, err := firestoreadapter.NewIdempotencyKeyRepository(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore idempotency key repository: %w", err)
+	}
+	return repo, nil
+}
+
 func provideDrawRepository(infra *Infra) (repository.DrawRepository, error) {
 	mode := os.Getenv("DRAW_REPOSITORY_MODE")
 	if mode == "error" {
 		return newFailingDrawRepository(), nil
 	}
+	if store := infra.inMemory(); store != nil {
+		return store.draws, nil
+	}
 	return newFirestoreDrawRepository(infra)
 }
 
@@ -128,6 +280,14 @@ func (f *failingDrawRepository) GetByPostID(ctx context.Context, postID post.Dar
 	return nil, f.err
 }
 
+func (f *failingDrawRepository) Update(ctx context.Context, d *drawdomain.Draw) error {
+	return f.err
+}
+
 func (f *failingDrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, error) {
 	return nil, f.err
 }
+
+func (f *failingDrawRepository) PickRandom(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	return nil, f.err
+}
@@ -50,6 +50,17 @@ func TestNewAPIPostRepository_Success(t *testing.T) {
 	}
 }
 
+func TestNewIdempotencyKeyRepository_FailsWithoutFirestoreClient(t *testing.T) {
+	t.Parallel()
+	repo, err := newIdempotencyKeyRepository(&Infra{})
+	if err != errFirestoreClientUnavailable {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo != nil {
+		t.Fatalf("expected repo to be nil when Firestore client is missing")
+	}
+}
+
 type stubPostRepository struct{}
 
 func (stubPostRepository) Create(context.Context, *post.Post) error {
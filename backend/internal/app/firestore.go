@@ -23,10 +23,21 @@ type FirestoreConfig struct {
 // Infra は外部リソースへの接続をまとめて保持する。
 type Infra struct {
 	firestoreClient *firestore.Client
+	// JOB_QUEUE_MODE=memory のときだけ設定され、各リポジトリは Firestore の代わりにこれを使う
+	memory *memoryStore
 }
 
 // NewInfra は Firestore を含む外部依存を初期化して返す。
+// JOB_QUEUE_MODE=memory なら Firestore へは接続せず、メモリ上の保存先だけを用意する。
 func NewInfra(ctx context.Context) (*Infra, error) {
+	memory, err := useMemoryStore()
+	if err != nil {
+		return nil, err
+	}
+	if memory {
+		return &Infra{memory: newMemoryStore()}, nil
+	}
+
 	cfg, err := loadFirestoreConfigFromEnv()
 	if err != nil {
 		if errors.Is(err, errFirestoreProjectIDBlank) {
@@ -53,6 +64,14 @@ func (i *Infra) Firestore() *firestore.Client {
 	return i.firestoreClient
 }
 
+// inMemory はメモリ上の保存先を返す（Firestore を使う場合は nil）。
+func (i *Infra) inMemory() *memoryStore {
+	if i == nil {
+		return nil
+	}
+	return i.memory
+}
+
 // Close は保持しているリソースを順次クローズする。
 func (i *Infra) Close() error {
 	if i == nil || i.firestoreClient == nil {
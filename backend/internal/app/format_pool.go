@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/port/queue"
+	usecaseworker "backend/internal/usecase/worker"
+)
+
+// 取り出しに失敗した際、次の取り出しまで空ける時間
+var dequeueRetryDelay = 500 * time.Millisecond
+
+// 整形ジョブを複数のゴルーチンで並行して処理するプール
+type FormatPool struct {
+	container    *WorkerContainer
+	concurrency  int
+	jobTimeout   time.Duration
+	drainTimeout time.Duration
+	busy         atomic.Int64
+}
+
+// プールの稼働状況
+type FormatPoolStats struct {
+	Workers int
+	Busy    int
+}
+
+/**
+ * 稼働中のワーカーに対する処理中ワーカーの割合を 0〜1 で返す。
+ */
+func (s FormatPoolStats) Saturation() float64 {
+	if s.Workers <= 0 {
+		return 0
+	}
+	return float64(s.Busy) / float64(s.Workers)
+}
+
+/**
+ * 環境変数の並列数・制限時間を読み込み、ワーカー依存を使う整形プールを組み立てる。
+ */
+func NewFormatPool(container *WorkerContainer) (*FormatPool, error) {
+	cfg, err := config.LoadWorkerPoolConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("load worker pool config: %w", err)
+	}
+	return newFormatPool(container, cfg), nil
+}
+
+func newFormatPool(container *WorkerContainer, cfg *config.WorkerPoolConfig) *FormatPool {
+	return &FormatPool{
+		container:    container,
+		concurrency:  cfg.Concurrency,
+		jobTimeout:   cfg.JobTimeout,
+		drainTimeout: cfg.DrainTimeout,
+	}
+}
+
+/**
+ * 現在のワーカー数と処理中の件数を返す。
+ */
+func (p *FormatPool) Stats() FormatPoolStats {
+	return FormatPoolStats{
+		Workers: p.concurrency,
+		Busy:    int(p.busy.Load()),
+	}
+}
+
+/**
+ * 並列数ぶんのワーカーで整形を回し、停止指示後は処理中のジョブを猶予時間まで待ってから戻る。
+ */
+func (p *FormatPool) Run(ctx context.Context) {
+	// 処理中のジョブは停止指示では止めず、猶予時間を過ぎたときだけ中断する
+	jobBase, abortJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer abortJobs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx, jobBase)
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-ctx.Done():
+	}
+
+	log.Printf("worker draining (busy=%d, grace=%s)", p.busy.Load(), p.drainTimeout)
+	select {
+	case <-done:
+	case <-time.After(p.drainTimeout):
+		// 猶予を過ぎたジョブは中断し、Nack で他のワーカーへ戻す
+		log.Printf("worker drain timed out; aborting %d in-flight job(s)", p.busy.Load())
+		abortJobs()
+		<-done
+	}
+	log.Printf("worker shutting down: %v", ctx.Err())
+}
+
+/**
+ * 停止指示かキュー停止まで、取り出し→整形→リース確定を 1 件ずつ繰り返す。
+ */
+func (p *FormatPool) work(ctx, jobBase context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		lease, err := p.container.JobQueue.DequeueFormat(ctx)
+		if err != nil {
+			// 中断やキュー停止はそのまま終了する
+			if errors.Is(err, context.Canceled) ||
+				errors.Is(err, context.DeadlineExceeded) ||
+				errors.Is(err, queue.ErrQueueClosed) ||
+				errors.Is(err, queue.ErrContextClosed) {
+				return
+			}
+			// それ以外は短い待機後に再試行する。待機中に止められたらすぐ抜ける
+			log.Printf("dequeue error: %v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(dequeueRetryDelay):
+			}
+			continue
+		}
+
+		p.container.FormatPendingUsecase.RecordJobLeased(jobBase, string(lease.PostID))
+		p.busy.Add(1)
+		p.process(jobBase, lease)
+		p.busy.Add(-1)
+	}
+}
+
+/**
+ * 1 件のジョブを制限時間付きで整形し、結果に応じてリースを確定する。
+ */
+func (p *FormatPool) process(jobBase context.Context, lease queue.Lease) {
+	postID := lease.PostID
+	jobCtx, cancel := context.WithTimeout(jobBase, p.jobTimeout)
+	defer cancel()
+
+	// ジョブを処理し、失敗内容ごとにログの粒度を変える
+	execErr := p.container.FormatPendingUsecase.Execute(jobCtx, string(postID))
+	if execErr != nil {
+		switch {
+		// LLM などが制限時間内に応答しなかったケース
+		case errors.Is(jobCtx.Err(), context.DeadlineExceeded) && jobBase.Err() == nil:
+			log.Printf("format timed out (post=%s, timeout=%s): %v", postID, p.jobTimeout, execErr)
+		// draw 保存に失敗したため再試行で再取得させるケース
+		case errors.Is(execErr, usecaseworker.ErrDrawCreationFailed):
+			log.Printf("draw creation failed (post=%s): %v (retry scheduled)", postID, execErr)
+		default:
+			// LLM や投稿の整形問題はログに残して次のジョブへ
+			log.Printf("format error (post=%s): %v", postID, execErr)
+		}
+	} else {
+		log.Printf("formatted post: %s", postID)
+	}
+
+	// 制限時間切れは再試行扱いにし、猶予切れによる中断だけを Nack にする
+	settleFormatJob(jobBase, p.container.JobQueue, p.container.FormatPendingUsecase, lease, execErr)
+}
+
+// リースの確定に合わせて投稿を終端状態へ移し、ジョブの移り変わりを監査ログへ残す処理
+type formatJobSettler interface {
+	MarkFailed(ctx context.Context, postID string) error
+	RecordJobReleased(ctx context.Context, postID string)
+	RecordJobRetried(ctx context.Context, postID string, cause error)
+	RecordJobDeadLettered(ctx context.Context, postID string, cause error)
+}
+
+// 整形結果に応じたリースの確定方法
+type settleAction int
+
+const (
+	// 処理完了または再試行しても結果が変わらないため削除する
+	settleAck settleAction = iota
+	// 停止指示で中断されたため試行回数を増やさず戻す
+	settleNack
+	// 一時的な失敗のため待機時間を置いて再試行する
+	settleRetry
+)
+
+/**
+ * 整形結果に応じてリースを完了させるか、中断として戻すか、再試行へ回す。
+ */
+func settleFormatJob(ctx context.Context, jobQueue queue.JobQueue, settler formatJobSettler, lease queue.Lease, execErr error) {
+	postID := lease.PostID
+	// 停止指示の後でもリースを確定できるよう、キャンセルは引き継がない
+	settleCtx := context.WithoutCancel(ctx)
+	switch classifyFormatResult(ctx, execErr) {
+	case settleNack:
+		if err := jobQueue.NackFormat(settleCtx, lease); err != nil {
+			log.Printf("nack error (post=%s): %v", postID, err)
+			return
+		}
+		settler.RecordJobReleased(settleCtx, string(postID))
+	case settleRetry:
+		err := jobQueue.RetryFormat(settleCtx, lease, execErr)
+		switch {
+		case errors.Is(err, queue.ErrJobDeadLettered):
+			log.Printf("format job dead-lettered (post=%s): %v", postID, execErr)
+			settler.RecordJobDeadLettered(settleCtx, string(postID), execErr)
+			// pending のまま取り残さないよう投稿も failed で確定させる
+			if failErr := settler.MarkFailed(settleCtx, string(postID)); failErr != nil {
+				log.Printf("mark post failed error (post=%s): %v", postID, failErr)
+			}
+		case err != nil:
+			log.Printf("retry error (post=%s): %v", postID, err)
+		default:
+			settler.RecordJobRetried(settleCtx, string(postID), execErr)
+		}
+	default:
+		if err := jobQueue.AckFormat(settleCtx, lease); err != nil {
+			log.Printf("ack error (post=%s): %v", postID, err)
+		}
+	}
+}
+
+/**
+ * 停止指示による中断は Nack、投稿側の問題で結果が変わらない失敗は Ack、それ以外の一時的な失敗は再試行とする。
+ */
+func classifyFormatResult(ctx context.Context, execErr error) settleAction {
+	if execErr == nil {
+		return settleAck
+	}
+	if ctx.Err() != nil {
+		return settleNack
+	}
+	switch {
+	case errors.Is(execErr, usecaseworker.ErrEmptyPostID),
+		errors.Is(execErr, usecaseworker.ErrPostNotFound),
+		errors.Is(execErr, usecaseworker.ErrPostNotPending),
+		errors.Is(execErr, usecaseworker.ErrContentRejected):
+		return settleAck
+	default:
+		// LLM 接続失敗・応答形式の崩れ・保存失敗などは時間を置けば解消し得る
+		return settleRetry
+	}
+}
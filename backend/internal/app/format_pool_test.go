@@ -0,0 +1,493 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/config"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/llm"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+	usecaseworker "backend/internal/usecase/worker"
+	workertestutil "backend/internal/usecase/worker/testutil"
+)
+
+func TestFormatPool_ProcessesJobsUntilQueueClosed(t *testing.T) {
+	p, err := post.New(post.DarkPostID("post-loop"), post.DarkContent("闇"))
+	if err != nil {
+		t.Fatalf("post.New: %v", err)
+	}
+	postRepo := &notifyingPostRepository{
+		StubPostRepository: workertestutil.NewStubPostRepository(p),
+		updated:            make(chan struct{}, 1),
+	}
+	drawRepo := &workertestutil.StubDrawRepository{}
+	formatter := &workertestutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID(), Status: drawdomain.StatusPending, FormattedContent: "formatted"},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "formatted",
+		},
+	}
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	container := newTestWorkerContainer(postRepo, drawRepo, jobQueue, formatter)
+	pool := newFormatPool(container, testPoolConfig(1))
+
+	if err := jobQueue.EnqueueFormat(context.Background(), p.ID()); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(context.Background())
+	}()
+
+	// 整形が終わるまで待ってからキューを閉じる
+	select {
+	case <-postRepo.updated:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("job was not processed")
+	}
+	_ = jobQueue.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop after queue close")
+	}
+	if len(drawRepo.Created) != 1 {
+		t.Fatalf("expected draw to be created once, got %d", len(drawRepo.Created))
+	}
+}
+
+func TestFormatPool_StopsOnContextCancel(t *testing.T) {
+	container := newTestWorkerContainer(workertestutil.NewStubPostRepository(nil), &workertestutil.StubDrawRepository{},
+		queueMemory.NewInMemoryJobQueue(0), &workertestutil.StubFormatter{})
+	pool := newFormatPool(container, testPoolConfig(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop after context cancel")
+	}
+}
+
+func TestFormatPool_StopsOnContextCancelWhileWaitingToRetryDequeue(t *testing.T) {
+	orig := dequeueRetryDelay
+	dequeueRetryDelay = time.Hour
+	defer func() { dequeueRetryDelay = orig }()
+
+	jobQueue := &failingDequeueQueue{called: make(chan struct{}, 1)}
+	container := newTestWorkerContainer(workertestutil.NewStubPostRepository(nil), &workertestutil.StubDrawRepository{},
+		jobQueue, &workertestutil.StubFormatter{})
+	pool := newFormatPool(container, testPoolConfig(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+	select {
+	case <-jobQueue.called:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not dequeue")
+	}
+	// 取り出しに失敗して待機している最中でも、止められたら待ち切らずに終わる
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop while waiting to retry dequeue")
+	}
+}
+
+// failingDequeueQueue は取り出しのたびに一時的なエラーを返すキュー。
+type failingDequeueQueue struct {
+	noopJobQueue
+	called chan struct{}
+}
+
+func (q *failingDequeueQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	select {
+	case q.called <- struct{}{}:
+	default:
+	}
+	return queue.Lease{}, errors.New("unavailable")
+}
+
+func TestFormatPool_RunsJobsConcurrently(t *testing.T) {
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	formatter := newBlockingFormatter()
+	container := newTestWorkerContainer(newPostRepositoryWith(t, "post-a", "post-b", "post-c"),
+		repoMemory.NewInMemoryDrawRepository(), jobQueue, formatter)
+	pool := newFormatPool(container, testPoolConfig(3))
+
+	for _, id := range []post.DarkPostID{"post-a", "post-b", "post-c"} {
+		if err := jobQueue.EnqueueFormat(context.Background(), id); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(context.Background())
+	}()
+
+	// 3 件とも同時に整形へ入れば並行に動いている
+	for i := 0; i < 3; i++ {
+		select {
+		case <-formatter.entered:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d job(s) started concurrently", i)
+		}
+	}
+	stats := pool.Stats()
+	if stats.Workers != 3 || stats.Busy != 3 || stats.Saturation() != 1 {
+		t.Fatalf("unexpected stats while saturated: %+v", stats)
+	}
+
+	close(formatter.release)
+	_ = jobQueue.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop after queue close")
+	}
+	if busy := pool.Stats().Busy; busy != 0 {
+		t.Fatalf("expected no busy workers after stop, got %d", busy)
+	}
+}
+
+func TestFormatPool_JobTimeoutSchedulesRetry(t *testing.T) {
+	// 1 回の失敗で隔離させ、再試行に回ったことを隔離結果から確認する
+	jobQueue := queueMemory.NewInMemoryJobQueue(0, queueMemory.WithRetryPolicy(queue.RetryPolicy{MaxAttempts: 1}))
+	formatter := newBlockingFormatter()
+	container := newTestWorkerContainer(newPostRepositoryWith(t, "post-hang"),
+		repoMemory.NewInMemoryDrawRepository(), jobQueue, formatter)
+	cfg := testPoolConfig(1)
+	cfg.JobTimeout = 20 * time.Millisecond
+	pool := newFormatPool(container, cfg)
+
+	if err := jobQueue.EnqueueFormat(context.Background(), post.DarkPostID("post-hang")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+
+	<-formatter.entered
+	deadline := time.After(2 * time.Second)
+	for {
+		dead, err := jobQueue.ListDeadFormat(context.Background(), 10)
+		if err != nil {
+			t.Fatalf("list dead: %v", err)
+		}
+		if len(dead) == 1 {
+			if dead[0].PostID != post.DarkPostID("post-hang") || !strings.Contains(dead[0].LastError, context.DeadlineExceeded.Error()) {
+				t.Fatalf("unexpected dead job: %+v", dead[0])
+			}
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out job was not retried")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+func TestFormatPool_DrainWaitsForInFlightJob(t *testing.T) {
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	formatter := newBlockingFormatter()
+	container := newTestWorkerContainer(newPostRepositoryWith(t, "post-drain"),
+		repoMemory.NewInMemoryDrawRepository(), jobQueue, formatter)
+	pool := newFormatPool(container, testPoolConfig(1))
+
+	if err := jobQueue.EnqueueFormat(context.Background(), post.DarkPostID("post-drain")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+
+	<-formatter.entered
+	cancel()
+
+	// 停止指示の後も処理中のジョブが終わるまでは戻らない
+	select {
+	case <-done:
+		t.Fatalf("pool stopped before in-flight job finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(formatter.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop after in-flight job finished")
+	}
+
+	// 処理を終えたジョブは Ack 済みなので再登録できる
+	if err := jobQueue.EnqueueFormat(context.Background(), post.DarkPostID("post-drain")); err != nil {
+		t.Fatalf("expected job to be acked, got %v", err)
+	}
+}
+
+func TestFormatPool_DrainTimeoutNacksInFlightJob(t *testing.T) {
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	formatter := newBlockingFormatter()
+	container := newTestWorkerContainer(newPostRepositoryWith(t, "post-abort"),
+		repoMemory.NewInMemoryDrawRepository(), jobQueue, formatter)
+	cfg := testPoolConfig(1)
+	cfg.DrainTimeout = 20 * time.Millisecond
+	pool := newFormatPool(container, cfg)
+
+	if err := jobQueue.EnqueueFormat(context.Background(), post.DarkPostID("post-abort")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+
+	<-formatter.entered
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("pool did not stop after drain timeout")
+	}
+
+	// 猶予切れで中断したジョブは Nack され、すぐ取り出せる
+	dequeueCtx, dequeueCancel := context.WithTimeout(context.Background(), time.Second)
+	defer dequeueCancel()
+	got, err := jobQueue.DequeueFormat(dequeueCtx)
+	if err != nil {
+		t.Fatalf("expected nacked job to be claimable: %v", err)
+	}
+	if got.PostID != post.DarkPostID("post-abort") {
+		t.Fatalf("unexpected id: %s", got.PostID)
+	}
+}
+
+func TestFormatPoolStats_Saturation(t *testing.T) {
+	if got := (FormatPoolStats{Workers: 4, Busy: 1}).Saturation(); got != 0.25 {
+		t.Fatalf("unexpected saturation: %v", got)
+	}
+	if got := (FormatPoolStats{}).Saturation(); got != 0 {
+		t.Fatalf("expected zero saturation without workers, got %v", got)
+	}
+}
+
+func testPoolConfig(concurrency int) *config.WorkerPoolConfig {
+	return &config.WorkerPoolConfig{
+		Concurrency:  concurrency,
+		JobTimeout:   time.Second,
+		DrainTimeout: time.Second,
+	}
+}
+
+// 指定 ID の整形待ち投稿を持つ、並行アクセスに耐えるリポジトリを返す
+// newTestWorkerContainer はリポジトリへ順に書き込む FormatCompleter でワーカーの器を組み立てる。
+func newTestWorkerContainer(postRepo repository.PostRepository, drawRepo repository.DrawRepository, jobQueue queue.JobQueue, formatter llm.Formatter) *WorkerContainer {
+	return newWorkerContainer(nil, postRepo, drawRepo, workertestutil.NewStubFormatCompleter(postRepo, drawRepo), jobQueue, nil, nil, formatter, nil)
+}
+
+func newPostRepositoryWith(t *testing.T, ids ...post.DarkPostID) *repoMemory.InMemoryPostRepository {
+	t.Helper()
+	repo := repoMemory.NewInMemoryPostRepository()
+	for _, id := range ids {
+		p, err := post.New(id, post.DarkContent("闇"))
+		if err != nil {
+			t.Fatalf("post.New: %v", err)
+		}
+		if err := repo.Create(context.Background(), p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	return repo
+}
+
+// 解放されるかコンテキストが終わるまで整形を止めておく整形器。解放後は拒否として返す
+type blockingFormatter struct {
+	entered chan post.DarkPostID
+	release chan struct{}
+}
+
+func newBlockingFormatter() *blockingFormatter {
+	return &blockingFormatter{
+		entered: make(chan post.DarkPostID, 8),
+		release: make(chan struct{}),
+	}
+}
+
+func (f *blockingFormatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.FormatResult, error) {
+	f.entered <- req.DarkPostID
+	select {
+	case <-f.release:
+		return &llm.FormatResult{DarkPostID: req.DarkPostID, Status: drawdomain.StatusPending}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *blockingFormatter) Validate(ctx context.Context, result *llm.FormatResult) (*llm.FormatResult, error) {
+	return nil, llm.ErrContentRejected
+}
+
+// 投稿更新を通知して、ループの処理完了を待てるようにする
+type notifyingPostRepository struct {
+	*workertestutil.StubPostRepository
+	updated chan struct{}
+}
+
+func (r *notifyingPostRepository) Update(ctx context.Context, p *post.Post) error {
+	if err := r.StubPostRepository.Update(ctx, p); err != nil {
+		return err
+	}
+	r.updated <- struct{}{}
+	return nil
+}
+
+func TestSettleFormatJob(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name       string
+		ctx        context.Context
+		execErr    error
+		wantAck    bool
+		wantNack   bool
+		wantRetry  bool
+		retryErr   error
+		wantFail   bool
+		wantEvents []string
+	}{
+		{name: "成功は Ack", ctx: context.Background(), wantAck: true},
+		{name: "draw 保存失敗は再試行", ctx: context.Background(), execErr: usecaseworker.ErrDrawCreationFailed, wantRetry: true, wantEvents: []string{"retried"}},
+		{name: "LLM 接続失敗は再試行", ctx: context.Background(), execErr: usecaseworker.ErrFormatterUnavailable, wantRetry: true, wantEvents: []string{"retried"}},
+		{name: "応答形式の崩れは再試行", ctx: context.Background(), execErr: llm.ErrInvalidFormat, wantRetry: true, wantEvents: []string{"retried"}},
+		{name: "拒否は Ack して破棄", ctx: context.Background(), execErr: usecaseworker.ErrContentRejected, wantAck: true},
+		{name: "投稿が無ければ Ack して破棄", ctx: context.Background(), execErr: usecaseworker.ErrPostNotFound, wantAck: true},
+		{name: "停止指示で中断したら Nack", ctx: canceled, execErr: errors.New("interrupted"), wantNack: true, wantEvents: []string{"released"}},
+		{name: "隔離されたら投稿を failed にする", ctx: context.Background(), execErr: usecaseworker.ErrFormatterUnavailable,
+			wantRetry: true, retryErr: queue.ErrJobDeadLettered, wantFail: true, wantEvents: []string{"dead_lettered"}},
+		{name: "リースを失った再試行は記録しない", ctx: context.Background(), execErr: usecaseworker.ErrFormatterUnavailable,
+			wantRetry: true, retryErr: queue.ErrJobNotLeased},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := &settleRecordingQueue{retryErr: tc.retryErr}
+			settler := &recordingJobSettler{}
+			settleFormatJob(tc.ctx, q, settler, queue.Lease{PostID: post.DarkPostID("post-1"), Token: "lease-1"}, tc.execErr)
+			if (len(settler.failed) == 1) != tc.wantFail {
+				t.Fatalf("unexpected mark failed calls: %v", settler.failed)
+			}
+			// Ack 以外の移り変わりは 1 件ずつ記録する
+			if !reflect.DeepEqual(settler.events, tc.wantEvents) {
+				t.Fatalf("unexpected job events: %v, want %v", settler.events, tc.wantEvents)
+			}
+			if (q.acked == 1) != tc.wantAck || (q.nacked == 1) != tc.wantNack || (q.retried == 1) != tc.wantRetry {
+				t.Fatalf("unexpected settle: acked=%d nacked=%d retried=%d", q.acked, q.nacked, q.retried)
+			}
+			if tc.wantRetry && !errors.Is(q.retryCause, tc.execErr) {
+				t.Fatalf("retry cause should be execErr, got %v", q.retryCause)
+			}
+			// キャンセル済みでもリース確定はキャンセルを引き継がない
+			if q.ctxErr != nil {
+				t.Fatalf("settle context should not be canceled: %v", q.ctxErr)
+			}
+		})
+	}
+}
+
+// Ack / Nack / Retry の呼び出し回数を記録するキュー
+type settleRecordingQueue struct {
+	noopJobQueue
+	acked      int
+	nacked     int
+	retried    int
+	retryCause error
+	retryErr   error
+	ctxErr     error
+}
+
+func (q *settleRecordingQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	q.acked++
+	q.ctxErr = ctx.Err()
+	return nil
+}
+
+func (q *settleRecordingQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	q.nacked++
+	q.ctxErr = ctx.Err()
+	return nil
+}
+
+func (q *settleRecordingQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	q.retried++
+	q.retryCause = cause
+	q.ctxErr = ctx.Err()
+	return q.retryErr
+}
+
+// failed へ移された投稿と、記録を求められたジョブの移り変わりを残す
+type recordingJobSettler struct {
+	failed []string
+	events []string
+}
+
+func (f *recordingJobSettler) MarkFailed(ctx context.Context, postID string) error {
+	f.failed = append(f.failed, postID)
+	return nil
+}
+
+func (f *recordingJobSettler) RecordJobReleased(ctx context.Context, postID string) {
+	f.events = append(f.events, "released")
+}
+
+func (f *recordingJobSettler) RecordJobRetried(ctx context.Context, postID string, cause error) {
+	f.events = append(f.events, "retried")
+}
+
+func (f *recordingJobSettler) RecordJobDeadLettered(ctx context.Context, postID string, cause error) {
+	f.events = append(f.events, "dead_lettered")
+}
+
+var _ queue.JobQueue = (*settleRecordingQueue)(nil)
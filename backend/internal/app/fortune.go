@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	firestoreadapter "backend/internal/adapter/repository/firestore"
+	"backend/internal/config"
+	"backend/internal/port/repository"
+	drawusecase "backend/internal/usecase/draw"
+)
+
+var (
+	errDrawSamplerUnsupported    = errors.New("draw selection: おみくじのリポジトリが候補の取り出しに対応していません")
+	drawHistoryRepositoryFactory = newDrawHistoryRepository
+	reactionRepositoryFactory    = newReactionRepository
+)
+
+// おみくじを引く際の選び方と抽選履歴、リアクションの件数の保存先。
+type fortuneSettings struct {
+	strategy  drawusecase.SelectionStrategy
+	history   drawHistory
+	reactions repository.ReactionRepository
+}
+
+/**
+ * DRAW_SELECTION_* と DRAW_HISTORY_WINDOW を読み、おみくじの選び方と抽選履歴を組み立てる。
+ * rating ではリアクションの合計件数を評価として使う。
+ */
+func newFortuneSettings(infra *Infra, drawRepo repository.DrawRepository) (fortuneSettings, error) {
+	cfg, err := config.LoadDrawSelectionConfigFromEnv()
+	if err != nil {
+		return fortuneSettings{}, err
+	}
+	reactions, err := reactionRepositoryFactory(infra)
+	if err != nil {
+		return fortuneSettings{}, err
+	}
+	strategy, err := newSelectionStrategy(cfg, drawRepo, drawusecase.NewReactionRatings(reactions))
+	if err != nil {
+		return fortuneSettings{}, err
+	}
+	history, err := newDrawHistory(infra)
+	if err != nil {
+		return fortuneSettings{}, err
+	}
+	return fortuneSettings{strategy: strategy, history: history, reactions: reactions}, nil
+}
+
+/**
+ * 設定された方針で SelectionStrategy を生成する。重み付きの方針は候補を取り出せるリポジトリでのみ使える。
+ */
+func newSelectionStrategy(cfg *config.DrawSelectionConfig, drawRepo repository.DrawRepository, ratings drawusecase.RatingSource) (drawusecase.SelectionStrategy, error) {
+	// 種を指定すれば同じ候補に対して同じ順で選ぶため、検証環境で結果を再現できる
+	seed := rand.Uint64()
+	if cfg.Seeded {
+		seed = cfg.Seed
+	}
+	src := rand.NewPCG(seed, seed)
+
+	if cfg.Strategy == config.DrawSelectionUniform {
+		// 種が無ければリポジトリ自身の乱数に任せる
+		if !cfg.Seeded {
+			return drawusecase.NewUniformStrategy(drawRepo), nil
+		}
+		return drawusecase.NewSeededUniformStrategy(drawRepo, src)
+	}
+
+	sampler, ok := drawRepo.(repository.DrawSampler)
+	if !ok {
+		return nil, errDrawSamplerUnsupported
+	}
+
+	switch cfg.Strategy {
+	case config.DrawSelectionRecency:
+		return drawusecase.NewRecencyWeightedStrategy(sampler, cfg.SampleSize, cfg.HalfLife, src, nil)
+	case config.DrawSelectionRating:
+		return drawusecase.NewRatingWeightedStrategy(sampler, cfg.SampleSize, ratings, src)
+	default:
+		return nil, fmt.Errorf("draw selection: 未対応の方針です: %s", cfg.Strategy)
+	}
+}
+
+// 抽選履歴のリポジトリと、同じクライアントに続けて出さない直近の件数。
+type drawHistory struct {
+	repo   repository.DrawHistoryRepository
+	window int
+}
+
+/**
+ * DRAW_HISTORY_WINDOW を読み、履歴を使う場合だけリポジトリを用意する。
+ */
+func newDrawHistory(infra *Infra) (drawHistory, error) {
+	window, err := config.LoadDrawHistoryWindowFromEnv()
+	if err != nil {
+		return drawHistory{}, err
+	}
+	if window == 0 {
+		return drawHistory{}, nil
+	}
+	repo, err := drawHistoryRepositoryFactory(infra)
+	if err != nil {
+		return drawHistory{}, err
+	}
+	return drawHistory{repo: repo, window: window}, nil
+}
+
+/**
+ * 抽選履歴リポジトリを構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
+ */
+func newDrawHistoryRepository(infra *Infra) (repository.DrawHistoryRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.history, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	repo, err := firestoreadapter.NewDrawHistoryRepository(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore draw history repository: %w", err)
+	}
+	return repo, nil
+}
+
+/**
+ * リアクションリポジトリを構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
+ */
+func newReactionRepository(infra *Infra) (repository.ReactionRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.reactions, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	repo, err := firestoreadapter.NewReactionRepository(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore reaction repository: %w", err)
+	}
+	return repo, nil
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/config"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+	drawusecase "backend/internal/usecase/draw"
+	workertestutil "backend/internal/usecase/worker/testutil"
+)
+
+func TestNewSelectionStrategy(t *testing.T) {
+	sampler := repoMemory.NewInMemoryDrawRepository()
+	cfg := &config.DrawSelectionConfig{SampleSize: 10, HalfLife: config.DefaultDrawRecencyHalfLife, Seed: 1, Seeded: true}
+
+	cfg.Strategy = config.DrawSelectionUniform
+	strategy, err := newSelectionStrategy(cfg, sampler, nil)
+	if _, ok := strategy.(*drawusecase.UniformStrategy); err != nil || !ok {
+		t.Fatalf("expected uniform strategy, got %T, %v", strategy, err)
+	}
+	// 種を指定した一様な抽選は乱数を受け取れるリポジトリでのみ組み立てる
+	if _, err := newSelectionStrategy(cfg, &workertestutil.StubDrawRepository{}, nil); !errors.Is(err, drawusecase.ErrSeededPickerUnsupported) {
+		t.Fatalf("expected ErrSeededPickerUnsupported, got %v", err)
+	}
+	unseeded := *cfg
+	unseeded.Seeded = false
+	if _, err := newSelectionStrategy(&unseeded, &workertestutil.StubDrawRepository{}, nil); err != nil {
+		t.Fatalf("unseeded uniform strategy should accept any repository: %v", err)
+	}
+
+	for _, name := range []string{config.DrawSelectionRecency, config.DrawSelectionRating} {
+		cfg.Strategy = name
+		if strategy, err := newSelectionStrategy(cfg, sampler, nil); err != nil || strategy == nil {
+			t.Fatalf("%s: unexpected result %T, %v", name, strategy, err)
+		}
+		// 候補を取り出せないリポジトリでは重み付きの方針を組み立てない
+		if _, err := newSelectionStrategy(cfg, &workertestutil.StubDrawRepository{}, nil); !errors.Is(err, errDrawSamplerUnsupported) {
+			t.Fatalf("%s: expected errDrawSamplerUnsupported, got %v", name, err)
+		}
+	}
+}
+
+func TestNewSelectionStrategy_SameSeedSameSequence(t *testing.T) {
+	ctx := context.Background()
+	for _, name := range []string{config.DrawSelectionRecency, config.DrawSelectionRating} {
+		cfg := &config.DrawSelectionConfig{Strategy: name, SampleSize: 3, HalfLife: config.DefaultDrawRecencyHalfLife, Seed: 42, Seeded: true}
+		// 候補の取り出しも種から引くため、全件より少ない標本でも同じ順で選ぶ
+		first, err := newSelectionStrategy(cfg, newSeededDrawPool(t, 12), nil)
+		if err != nil {
+			t.Fatalf("%s: newSelectionStrategy() error = %v", name, err)
+		}
+		second, _ := newSelectionStrategy(cfg, newSeededDrawPool(t, 12), nil)
+
+		seen := map[post.DarkPostID]bool{}
+		for i := 0; i < 50; i++ {
+			a, err := first.Select(ctx, repository.DrawFilter{})
+			if err != nil {
+				t.Fatalf("%s: Select() error = %v", name, err)
+			}
+			b, _ := second.Select(ctx, repository.DrawFilter{})
+			if a.PostID() != b.PostID() {
+				t.Fatalf("%s: selection %d diverged: %s vs %s", name, i, a.PostID(), b.PostID())
+			}
+			seen[a.PostID()] = true
+		}
+		if len(seen) < 4 {
+			t.Fatalf("%s: seeded selection should still reach beyond one sample, got %v", name, seen)
+		}
+	}
+}
+
+func newSeededDrawPool(t *testing.T, n int) *repoMemory.InMemoryDrawRepository {
+	t.Helper()
+	repo := repoMemory.NewInMemoryDrawRepository()
+	for i := 0; i < n; i++ {
+		d, _ := drawdomain.New(post.DarkPostID(fmt.Sprintf("post-%02d", i)), "大吉")
+		d.MarkVerified()
+		if err := repo.Create(context.Background(), d); err != nil {
+			t.Fatalf("create draw: %v", err)
+		}
+	}
+	return repo
+}
+
+func TestNewDrawHistory(t *testing.T) {
+	t.Setenv("DRAW_HISTORY_WINDOW", "0")
+	history, err := newDrawHistory(&Infra{})
+	if err != nil || history.repo != nil {
+		t.Fatalf("window 0 should disable history without Firestore: %+v, %v", history, err)
+	}
+
+	t.Setenv("DRAW_HISTORY_WINDOW", "")
+	if _, err := newDrawHistory(&Infra{}); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+
+	stubRepo := repoMemory.NewInMemoryDrawHistoryRepository()
+	defer stubDrawHistoryRepositoryFactory(t, stubRepo)()
+	history, err = newDrawHistory(&Infra{})
+	if err != nil || history.repo != stubRepo || history.window != config.DefaultDrawHistoryWindow {
+		t.Fatalf("unexpected history: %+v, %v", history, err)
+	}
+}
+
+func TestNewFortuneSettings_UsesReactions(t *testing.T) {
+	t.Setenv("DRAW_HISTORY_WINDOW", "0")
+	t.Setenv("DRAW_SELECTION_STRATEGY", config.DrawSelectionRating)
+	if _, err := newFortuneSettings(&Infra{}, repoMemory.NewInMemoryDrawRepository()); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+
+	reactions := repoMemory.NewInMemoryReactionRepository()
+	defer stubReactionRepositoryFactory(t, reactions)()
+	settings, err := newFortuneSettings(&Infra{}, repoMemory.NewInMemoryDrawRepository())
+	if err != nil || settings.reactions != reactions || settings.strategy == nil {
+		t.Fatalf("unexpected settings: %+v, %v", settings, err)
+	}
+}
+
+// stubDrawHistoryRepositoryFactory は抽選履歴リポジトリのファクトリを差し替え、元に戻す関数を返す。
+func stubDrawHistoryRepositoryFactory(t *testing.T, repo repository.DrawHistoryRepository) func() {
+	t.Helper()
+	orig := drawHistoryRepositoryFactory
+	drawHistoryRepositoryFactory = func(infra *Infra) (repository.DrawHistoryRepository, error) {
+		return repo, nil
+	}
+	return func() { drawHistoryRepositoryFactory = orig }
+}
+
+// stubReactionRepositoryFactory はリアクションリポジトリのファクトリを差し替え、元に戻す関数を返す。
+func stubReactionRepositoryFactory(t *testing.T, repo repository.ReactionRepository) func() {
+	t.Helper()
+	orig := reactionRepositoryFactory
+	reactionRepositoryFactory = func(infra *Infra) (repository.ReactionRepository, error) {
+		return repo, nil
+	}
+	return func() { reactionRepositoryFactory = orig }
+}
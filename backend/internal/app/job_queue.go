@@ -3,8 +3,11 @@ package app
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	queueFirestore "backend/internal/adapter/queue/firestore"
+	queueMemory "backend/internal/adapter/queue/memory"
+	"backend/internal/config"
 	"backend/internal/port/queue"
 
 	"cloud.google.com/go/firestore"
@@ -12,23 +15,64 @@ import (
 
 var (
 	jobQueueFactory          = newJobQueue
-	firestoreJobQueueFactory = func(client *firestore.Client) (queue.JobQueue, error) {
-		return queueFirestore.NewFirestoreJobQueue(client)
+	firestoreJobQueueFactory = func(client *firestore.Client, policy queue.RetryPolicy, lease time.Duration) (queue.JobQueue, error) {
+		return queueFirestore.NewFirestoreJobQueue(client, queueFirestore.WithRetryPolicy(policy), queueFirestore.WithLeaseDuration(lease))
+	}
+	memoryJobQueueFactory = func(policy queue.RetryPolicy, lease time.Duration) queue.JobQueue {
+		return queueMemory.NewInMemoryJobQueue(0, queueMemory.WithRetryPolicy(policy), queueMemory.WithLeaseDuration(lease))
 	}
 )
 
 var errFirestoreQueueRequiresClient = errors.New("job queue: Firestore クライアントが初期化されていません")
 
 /**
- * Firestore 固定の整形ジョブキューを構築する。
+ * JOB_QUEUE_MODE に応じて整形ジョブキューを構築する（既定は Firestore の format_jobs）。
+ * リース期間は FORMAT_JOB_LEASE_DURATION から読み、整形プールの制限時間と同じ値を使う。
  */
 func newJobQueue(infra *Infra) (queue.JobQueue, error) {
+	policy, err := loadRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+	lease, err := config.LoadFormatJobLeaseDuration()
+	if err != nil {
+		return nil, fmt.Errorf("load job lease duration: %w", err)
+	}
+	mode, err := config.LoadJobQueueMode()
+	if err != nil {
+		return nil, err
+	}
+	// メモリ実装は Firestore クライアントを必要としない
+	if mode == config.JobQueueModeMemory {
+		return memoryJobQueueFactory(policy, lease), nil
+	}
 	if infra == nil || infra.Firestore() == nil {
 		return nil, errFirestoreQueueRequiresClient
 	}
-	jobQueue, err := firestoreJobQueueFactory(infra.Firestore())
+	jobQueue, err := firestoreJobQueueFactory(infra.Firestore(), policy, lease)
 	if err != nil {
 		return nil, fmt.Errorf("new firestore job queue: %w", err)
 	}
 	return jobQueue, nil
 }
+
+/**
+ * 既定の再試行方針に環境変数で指定された項目だけを上書きする。
+ */
+func loadRetryPolicy() (queue.RetryPolicy, error) {
+	policy := queue.DefaultRetryPolicy()
+	cfg, err := config.LoadJobRetryConfigFromEnv()
+	if err != nil {
+		return policy, fmt.Errorf("load job retry config: %w", err)
+	}
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.BaseDelay > 0 {
+		policy.BaseDelay = cfg.BaseDelay
+	}
+	if cfg.MaxDelay > 0 {
+		policy.MaxDelay = cfg.MaxDelay
+	}
+	return policy, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"backend/internal/domain/post"
 	"backend/internal/port/queue"
@@ -20,7 +21,7 @@ func TestNewJobQueue_RequiresFirestoreClient(t *testing.T) {
 func TestNewJobQueue_FirestoreSuccess(t *testing.T) {
 	origFactory := firestoreJobQueueFactory
 	stub := &fakeJobQueue{}
-	firestoreJobQueueFactory = func(client *firestore.Client) (queue.JobQueue, error) {
+	firestoreJobQueueFactory = func(client *firestore.Client, policy queue.RetryPolicy, lease time.Duration) (queue.JobQueue, error) {
 		return stub, nil
 	}
 	defer func() { firestoreJobQueueFactory = origFactory }()
@@ -35,11 +36,83 @@ func TestNewJobQueue_FirestoreSuccess(t *testing.T) {
 	}
 }
 
+func TestNewJobQueue_MemoryModeWithoutFirestore(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+
+	origFactory := memoryJobQueueFactory
+	stub := &fakeJobQueue{}
+	memoryJobQueueFactory = func(policy queue.RetryPolicy, lease time.Duration) queue.JobQueue {
+		return stub
+	}
+	defer func() { memoryJobQueueFactory = origFactory }()
+
+	queue, err := newJobQueue(&Infra{})
+	if err != nil {
+		t.Fatalf("newJobQueue returned error: %v", err)
+	}
+	if queue != stub {
+		t.Fatalf("expected memory queue instance")
+	}
+}
+
+func TestNewJobQueue_RetryPolicyFromEnv(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+	t.Setenv("FORMAT_JOB_MAX_ATTEMPTS", "2")
+
+	origFactory := memoryJobQueueFactory
+	var got queue.RetryPolicy
+	memoryJobQueueFactory = func(policy queue.RetryPolicy, lease time.Duration) queue.JobQueue {
+		got = policy
+		return &fakeJobQueue{}
+	}
+	defer func() { memoryJobQueueFactory = origFactory }()
+
+	if _, err := newJobQueue(&Infra{}); err != nil {
+		t.Fatalf("newJobQueue returned error: %v", err)
+	}
+	// 指定した項目だけ上書きし、残りは既定値のまま
+	want := queue.DefaultRetryPolicy()
+	want.MaxAttempts = 2
+	if got != want {
+		t.Fatalf("unexpected policy: want %+v, got %+v", want, got)
+	}
+
+	t.Setenv("FORMAT_JOB_MAX_ATTEMPTS", "many")
+	if _, err := newJobQueue(&Infra{}); err == nil {
+		t.Fatalf("expected error for invalid retry config")
+	}
+}
+
+func TestNewJobQueue_LeaseDurationFromEnv(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+	t.Setenv("FORMAT_JOB_LEASE_DURATION", "90s")
+
+	origFactory := memoryJobQueueFactory
+	var got time.Duration
+	memoryJobQueueFactory = func(policy queue.RetryPolicy, lease time.Duration) queue.JobQueue {
+		got = lease
+		return &fakeJobQueue{}
+	}
+	defer func() { memoryJobQueueFactory = origFactory }()
+
+	if _, err := newJobQueue(&Infra{}); err != nil {
+		t.Fatalf("newJobQueue returned error: %v", err)
+	}
+	if got != 90*time.Second {
+		t.Fatalf("unexpected lease duration: %v", got)
+	}
+
+	t.Setenv("FORMAT_JOB_LEASE_DURATION", "soon")
+	if _, err := newJobQueue(&Infra{}); err == nil {
+		t.Fatalf("expected error for invalid lease duration")
+	}
+}
+
 func TestNewJobQueue_FactoryError(t *testing.T) {
 	origFactory := firestoreJobQueueFactory
 	defer func() { firestoreJobQueueFactory = origFactory }()
 
-	firestoreJobQueueFactory = func(client *firestore.Client) (queue.JobQueue, error) {
+	firestoreJobQueueFactory = func(client *firestore.Client, policy queue.RetryPolicy, lease time.Duration) (queue.JobQueue, error) {
 		return nil, errors.New("factory error")
 	}
 
@@ -55,8 +128,20 @@ func (fakeJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error
 	return nil
 }
 
-func (fakeJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", nil
+func (fakeJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	return queue.Lease{}, nil
+}
+
+func (fakeJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (fakeJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (fakeJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	return nil
 }
 
 func (fakeJobQueue) Close() error {
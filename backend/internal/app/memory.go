@@ -0,0 +1,69 @@
+package app
+
+import (
+	"errors"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/config"
+)
+
+var errMemoryModeRequiresAllInOne = errors.New("JOB_QUEUE_MODE=memory は API とワーカーを 1 プロセスで動かす cmd/allinone でのみ使えます")
+
+// JOB_QUEUE_MODE=memory で使うメモリ上の保存先。
+// 同じ Infra を受け取った API とワーカーは同じインスタンスを共有するため、1 プロセス内なら投稿から整形まで通して動く。
+type memoryStore struct {
+	posts      *repoMemory.InMemoryPostRepository
+	draws      *repoMemory.InMemoryDrawRepository
+	completer  *repoMemory.InMemoryFormatCompleter
+	moderation *repoMemory.InMemoryModerationWriter
+	keys       *repoMemory.InMemoryIdempotencyKeyRepository
+	history    *repoMemory.InMemoryDrawHistoryRepository
+	reactions  *repoMemory.InMemoryReactionRepository
+	reports    *repoMemory.InMemoryReportRepository
+	auditLog   *repoMemory.InMemoryAuditLog
+}
+
+/**
+ * 空のメモリ実装一式を用意する。投稿とおみくじ結果は FormatCompleter・ModerationWriter と同じインスタンスを使う。
+ */
+func newMemoryStore() *memoryStore {
+	posts := repoMemory.NewInMemoryPostRepository()
+	draws := repoMemory.NewInMemoryDrawRepository()
+	return &memoryStore{
+		posts:      posts,
+		draws:      draws,
+		completer:  repoMemory.NewInMemoryFormatCompleter(posts, draws),
+		moderation: repoMemory.NewInMemoryModerationWriter(posts, draws),
+		keys:       repoMemory.NewInMemoryIdempotencyKeyRepository(),
+		history:    repoMemory.NewInMemoryDrawHistoryRepository(),
+		reactions:  repoMemory.NewInMemoryReactionRepository(),
+		reports:    repoMemory.NewInMemoryReportRepository(),
+		auditLog:   repoMemory.NewInMemoryAuditLog(),
+	}
+}
+
+/**
+ * JOB_QUEUE_MODE=memory ならリポジトリもメモリ実装に揃え、GCP に一切接続しない。JOB_QUEUE_MODE が未知の値ならエラー。
+ */
+func useMemoryStore() (bool, error) {
+	mode, err := config.LoadJobQueueMode()
+	if err != nil {
+		return false, err
+	}
+	return mode == config.JobQueueModeMemory, nil
+}
+
+/**
+ * API とワーカーを別々のプロセスで起動するときに JOB_QUEUE_MODE=memory を拒む。
+ * 保存先とキューがプロセスごとに別々になり、API で受け付けた投稿がワーカーへ届かないため。
+ */
+func ensureSharedMemoryStore() error {
+	memory, err := useMemoryStore()
+	if err != nil {
+		return err
+	}
+	if memory {
+		return errMemoryModeRequiresAllInOne
+	}
+	return nil
+}
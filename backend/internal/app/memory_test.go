@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/llm"
+	postusecase "backend/internal/usecase/post"
+)
+
+func TestNewAllInOneContainer_MemoryModeNeedsNoGCP(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	defer stubMemoryFormatterFactory(t)()
+
+	container, err := NewAllInOneContainer(context.Background())
+	if err != nil {
+		t.Fatalf("NewAllInOneContainer returned error: %v", err)
+	}
+	defer container.Close()
+	if container.API.Infra.Firestore() != nil {
+		t.Fatalf("memory mode must not connect to Firestore")
+	}
+
+	// API で受け付けた投稿がワーカーの保存先とキューから見える
+	ctx := context.Background()
+	out, err := container.API.CreatePostUsecase.Execute(ctx, &postusecase.CreatePostInput{Content: "闇", ClientID: "client-1"})
+	if err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	lease, err := container.Worker.JobQueue.DequeueFormat(ctx)
+	if err != nil || lease.PostID != post.DarkPostID(out.DarkPostID) {
+		t.Fatalf("expected job for %s, got %s, %v", out.DarkPostID, lease.PostID, err)
+	}
+	if _, err := container.Worker.PostRepo.Get(ctx, lease.PostID); err != nil {
+		t.Fatalf("worker should see the post stored by the API: %v", err)
+	}
+}
+
+func TestSplitContainers_RejectMemoryMode(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	defer stubMemoryFormatterFactory(t)()
+
+	// 別々のプロセスではメモリ上の保存先を共有できず、投稿がワーカーへ届かない
+	if _, err := NewContainer(context.Background()); !errors.Is(err, errMemoryModeRequiresAllInOne) {
+		t.Fatalf("api: expected errMemoryModeRequiresAllInOne, got %v", err)
+	}
+	if _, err := NewWorkerContainer(context.Background()); !errors.Is(err, errMemoryModeRequiresAllInOne) {
+		t.Fatalf("worker: expected errMemoryModeRequiresAllInOne, got %v", err)
+	}
+}
+
+func TestContainers_RejectUnknownJobQueueMode(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memroy")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	defer stubMemoryFormatterFactory(t)()
+
+	if _, err := NewContainer(context.Background()); err == nil {
+		t.Fatal("api: expected error for unknown JOB_QUEUE_MODE")
+	}
+	if _, err := NewWorkerContainer(context.Background()); err == nil {
+		t.Fatal("worker: expected error for unknown JOB_QUEUE_MODE")
+	}
+	if _, err := NewAllInOneContainer(context.Background()); err == nil {
+		t.Fatal("allinone: expected error for unknown JOB_QUEUE_MODE")
+	}
+}
+
+func stubMemoryFormatterFactory(t *testing.T) func() {
+	t.Helper()
+	orig := formatterFactory
+	formatterFactory = func(ctx context.Context) (llm.Formatter, func() error, error) {
+		f := &stubFormatter{}
+		return f, f.Close, nil
+	}
+	return func() { formatterFactory = orig }
+}
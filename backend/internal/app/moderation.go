@@ -0,0 +1,82 @@
+package app
+
+import (
+	"fmt"
+
+	firestoreadapter "backend/internal/adapter/repository/firestore"
+	"backend/internal/config"
+	"backend/internal/port/repository"
+)
+
+var (
+	reportRepositoryFactory = newReportRepository
+	moderationWriterFactory = newModerationWriter
+)
+
+// 通報の保存先と自動で取り下げる件数、判定を変えるときの書き込み口、管理 API のトークン。
+type moderationSettings struct {
+	reports   repository.ReportRepository
+	threshold int
+	writer    repository.ModerationWriter
+	// 空なら /admin を公開しない
+	adminToken string
+}
+
+/**
+ * REPORT_TAKEDOWN_THRESHOLD と ADMIN_API_TOKEN を読み、通報と管理 API の設定を組み立てる。
+ */
+func newModerationSettings(infra *Infra) (moderationSettings, error) {
+	threshold, err := config.LoadReportTakedownThresholdFromEnv()
+	if err != nil {
+		return moderationSettings{}, err
+	}
+	token, err := config.LoadAdminTokenFromEnv()
+	if err != nil {
+		return moderationSettings{}, err
+	}
+	reports, err := reportRepositoryFactory(infra)
+	if err != nil {
+		return moderationSettings{}, err
+	}
+	writer, err := moderationWriterFactory(infra)
+	if err != nil {
+		return moderationSettings{}, err
+	}
+	return moderationSettings{reports: reports, threshold: threshold, writer: writer, adminToken: token}, nil
+}
+
+/**
+ * おみくじ結果と投稿をまとめて書き換える口を構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
+ */
+func newModerationWriter(infra *Infra) (repository.ModerationWriter, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.moderation, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	writer, err := firestoreadapter.NewModerationWriter(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore moderation writer: %w", err)
+	}
+	return writer, nil
+}
+
+/**
+ * 通報リポジトリを構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
+ */
+func newReportRepository(infra *Infra) (repository.ReportRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.reports, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	repo, err := firestoreadapter.NewReportRepository(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore report repository: %w", err)
+	}
+	return repo, nil
+}
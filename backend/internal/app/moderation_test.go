@@ -0,0 +1,65 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/config"
+	"backend/internal/port/repository"
+)
+
+func TestNewModerationSettings(t *testing.T) {
+	t.Setenv("REPORT_TAKEDOWN_THRESHOLD", "")
+	t.Setenv("ADMIN_API_TOKEN", "")
+	if _, err := newModerationSettings(&Infra{}); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+
+	reports := repoMemory.NewInMemoryReportRepository()
+	defer stubReportRepositoryFactory(t, reports)()
+	// 判定を変える書き込み口も Firestore が無ければ用意できない
+	if _, err := newModerationSettings(&Infra{}); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable for moderation writer, got %v", err)
+	}
+	writer := repoMemory.NewInMemoryModerationWriter(repoMemory.NewInMemoryPostRepository(), repoMemory.NewInMemoryDrawRepository())
+	defer stubModerationWriterFactory(t, writer)()
+	settings, err := newModerationSettings(&Infra{})
+	if err != nil || settings.reports != reports || settings.writer != writer || settings.threshold != config.DefaultReportTakedownThreshold || settings.adminToken != "" {
+		t.Fatalf("unexpected settings: %+v, %v", settings, err)
+	}
+
+	token := strings.Repeat("t", 32)
+	t.Setenv("ADMIN_API_TOKEN", token)
+	t.Setenv("REPORT_TAKEDOWN_THRESHOLD", "0")
+	settings, err = newModerationSettings(&Infra{})
+	if err != nil || settings.adminToken != token || settings.threshold != 0 {
+		t.Fatalf("unexpected settings: %+v, %v", settings, err)
+	}
+
+	t.Setenv("REPORT_TAKEDOWN_THRESHOLD", "-1")
+	if _, err := newModerationSettings(&Infra{}); err == nil {
+		t.Fatal("expected error for negative threshold")
+	}
+}
+
+// stubReportRepositoryFactory は通報リポジトリのファクトリを差し替え、元に戻す関数を返す。
+func stubReportRepositoryFactory(t *testing.T, repo repository.ReportRepository) func() {
+	t.Helper()
+	orig := reportRepositoryFactory
+	reportRepositoryFactory = func(infra *Infra) (repository.ReportRepository, error) {
+		return repo, nil
+	}
+	return func() { reportRepositoryFactory = orig }
+}
+
+// stubModerationWriterFactory は判定を変える書き込み口のファクトリを差し替え、元に戻す関数を返す。
+func stubModerationWriterFactory(t *testing.T, writer repository.ModerationWriter) func() {
+	t.Helper()
+	orig := moderationWriterFactory
+	moderationWriterFactory = func(infra *Infra) (repository.ModerationWriter, error) {
+		return writer, nil
+	}
+	return func() { moderationWriterFactory = orig }
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	firestoreadapter "backend/internal/adapter/repository/firestore"
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/config"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var postOutboxFactory = newPostOutbox
+
+/**
+ * 投稿と整形ジョブを書き込むアウトボックスを構築する。
+ * 既定の Firestore キューでは posts と format_jobs を 1 つのトランザクションで書き込む。
+ */
+func newPostOutbox(infra *Infra, postRepo repository.PostRepository, jobQueue queue.JobQueue) (repository.PostOutbox, error) {
+	// メモリ上の保存先なら、ジョブを積めなかった投稿を取り除いて元に戻すメモリ実装を使う
+	if store := infra.inMemory(); store != nil {
+		return repoMemory.NewInMemoryPostOutbox(store.posts, jobQueue), nil
+	}
+	mode, err := config.LoadJobQueueMode()
+	if err != nil {
+		return nil, err
+	}
+	// メモリキューは Firestore のトランザクションに載せられないため、保存→投入の順に書く
+	if mode == config.JobQueueModeMemory {
+		return &sequentialPostOutbox{postRepo: postRepo, jobQueue: jobQueue}, nil
+	}
+	client := infra.Firestore()
+	if client == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	outbox, err := firestoreadapter.NewPostOutbox(client)
+	if err != nil {
+		return nil, fmt.Errorf("new firestore post outbox: %w", err)
+	}
+	return outbox, nil
+}
+
+// JOB_QUEUE_MODE=memory の開発用に、投稿の保存とジョブの投入を順に行うアウトボックス。
+// 投入に失敗すると整形待ちの投稿が残るが、メモリキュー自体がプロセス停止で消えるため許容する。
+type sequentialPostOutbox struct {
+	postRepo repository.PostRepository
+	jobQueue queue.JobQueue
+}
+
+func (o *sequentialPostOutbox) CreateWithFormatJob(ctx context.Context, p *post.Post) error {
+	if err := o.postRepo.Create(ctx, p); err != nil {
+		return err
+	}
+	return o.jobQueue.EnqueueFormat(ctx, p.ID())
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/post"
+)
+
+func TestNewPostOutbox_MemoryModeWritesSequentially(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	jobQueue := &recordingEnqueueQueue{}
+	outbox, err := newPostOutbox(&Infra{}, postRepo, jobQueue)
+	if err != nil {
+		t.Fatalf("newPostOutbox: %v", err)
+	}
+
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+	if err := outbox.CreateWithFormatJob(context.Background(), p); err != nil {
+		t.Fatalf("create with job: %v", err)
+	}
+	if _, err := postRepo.Get(context.Background(), p.ID()); err != nil {
+		t.Fatalf("post should be stored: %v", err)
+	}
+	if len(jobQueue.enqueued) != 1 || jobQueue.enqueued[0] != p.ID() {
+		t.Fatalf("job should be enqueued: %v", jobQueue.enqueued)
+	}
+}
+
+func TestNewPostOutbox_FirestoreModeRequiresClient(t *testing.T) {
+	t.Setenv("JOB_QUEUE_MODE", "firestore")
+
+	if _, err := newPostOutbox(&Infra{}, nil, nil); err != errFirestoreClientUnavailable {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+}
+
+// recordingEnqueueQueue は投入された ID だけを覚える。
+type recordingEnqueueQueue struct {
+	noopJobQueue
+	enqueued []post.DarkPostID
+}
+
+func (q *recordingEnqueueQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error {
+	q.enqueued = append(q.enqueued, id)
+	return nil
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+	"backend/internal/usecase/worker"
+)
+
+var (
+	errReconcileMemoryQueue        = errors.New("reconcile: JOB_QUEUE_MODE=memory ではプロセス外のジョブを確認できません")
+	errReconcileScannerUnsupported = errors.New("reconcile: 投稿リポジトリが整形待ち投稿の走査に対応していません")
+	errReconcileInspectUnsupported = errors.New("reconcile: ジョブキューがジョブの参照に対応していません")
+)
+
+// 整合性確認コマンドで使う依存をまとめた器。
+type ReconcileContainer struct {
+	Infra                   *Infra
+	JobQueue                queue.JobQueue
+	ReconcilePendingUsecase *worker.ReconcilePendingUsecase
+	// 抽選キーを保存しない実装では nil
+	DrawKeyBackfiller repository.DrawRandomKeyBackfiller
+	closeInfra        func() error
+}
+
+/**
+ * posts / draws / format_jobs を突き合わせるための依存をワーカーと同じ手順で整えて返す。
+ */
+func NewReconcileContainer(ctx context.Context) (*ReconcileContainer, error) {
+	if err := ensureWorkerFirestoreEnv(); err != nil {
+		return nil, err
+	}
+	// メモリキューは API / ワーカーのプロセス内にしか無いため突き合わせようがない
+	memory, err := useMemoryStore()
+	if err != nil {
+		return nil, err
+	}
+	if memory {
+		return nil, errReconcileMemoryQueue
+	}
+
+	infra, err := infraFactory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("init infra: %w", err)
+	}
+	container := &ReconcileContainer{Infra: infra}
+	if infra != nil {
+		container.closeInfra = infra.Close
+	}
+
+	postRepo, err := postRepositoryFactory(ctx, infra)
+	if err != nil {
+		return nil, closeOnError(container, err)
+	}
+	scanner, ok := postRepo.(repository.PendingPostScanner)
+	if !ok {
+		return nil, closeOnError(container, errReconcileScannerUnsupported)
+	}
+
+	drawRepo, err := drawRepositoryFactory(ctx, infra)
+	if err != nil {
+		return nil, closeOnError(container, fmt.Errorf("init draw repository: %w", err))
+	}
+	container.DrawKeyBackfiller, _ = drawRepo.(repository.DrawRandomKeyBackfiller)
+
+	jobQueue, err := jobQueueFactory(infra)
+	if err != nil {
+		return nil, closeOnError(container, fmt.Errorf("init job queue: %w", err))
+	}
+	container.JobQueue = jobQueue
+	inspector, ok := jobQueue.(queue.JobInspector)
+	if !ok {
+		return nil, closeOnError(container, errReconcileInspectUnsupported)
+	}
+
+	container.ReconcilePendingUsecase = worker.NewReconcilePendingUsecase(scanner, postRepo, drawRepo, jobQueue, inspector)
+	return container, nil
+}
+
+/**
+ * 初期化途中で失敗した場合に開いた分だけ閉じ、元のエラーを返す。
+ */
+func closeOnError(c *ReconcileContainer, err error) error {
+	_ = c.Close()
+	return err
+}
+
+/**
+ * 生成時に開いたリソースを順に閉じる。
+ */
+func (c *ReconcileContainer) Close() error {
+	if c == nil {
+		return nil
+	}
+	var retErr error
+	if c.JobQueue != nil {
+		retErr = mergeCloseError(retErr, "job queue", c.JobQueue.Close)
+	}
+	return mergeCloseError(retErr, "infra", c.closeInfra)
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+	workertestutil "backend/internal/usecase/worker/testutil"
+)
+
+// stubReconcileDeps は整合性確認に必要なファクトリを差し替え、元に戻す関数を返す。
+func stubReconcileDeps(t *testing.T, postRepo repository.PostRepository, jobQueue queue.JobQueue) {
+	t.Helper()
+	setRequiredFirestoreEnv(t)
+	t.Setenv("JOB_QUEUE_MODE", "firestore")
+
+	origInfra := infraFactory
+	origPostRepo := postRepositoryFactory
+	origJobQueue := jobQueueFactory
+	infraFactory = func(ctx context.Context) (*Infra, error) {
+		return &Infra{}, nil
+	}
+	postRepositoryFactory = func(ctx context.Context, infra *Infra) (repository.PostRepository, error) {
+		return postRepo, nil
+	}
+	jobQueueFactory = func(infra *Infra) (queue.JobQueue, error) {
+		return jobQueue, nil
+	}
+	restoreDraw := stubDrawRepositoryFactory(t, &workertestutil.StubDrawRepository{}, nil)
+	t.Cleanup(func() {
+		infraFactory = origInfra
+		postRepositoryFactory = origPostRepo
+		jobQueueFactory = origJobQueue
+		restoreDraw()
+	})
+}
+
+func TestNewReconcileContainer_BuildsUsecase(t *testing.T) {
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	stubReconcileDeps(t, repoMemory.NewInMemoryPostRepository(), jobQueue)
+
+	container, err := NewReconcileContainer(context.Background())
+	if err != nil {
+		t.Fatalf("NewReconcileContainer returned error: %v", err)
+	}
+	if container.ReconcilePendingUsecase == nil {
+		t.Fatalf("usecase should be built")
+	}
+	if err := container.Close(); err != nil {
+		t.Fatalf("close returned error: %v", err)
+	}
+	if err := jobQueue.EnqueueFormat(context.Background(), "post-1"); !errors.Is(err, queue.ErrQueueClosed) {
+		t.Fatalf("job queue should be closed, got %v", err)
+	}
+}
+
+func TestNewReconcileContainer_RejectsMemoryQueue(t *testing.T) {
+	setRequiredFirestoreEnv(t)
+	t.Setenv("JOB_QUEUE_MODE", "memory")
+
+	if _, err := NewReconcileContainer(context.Background()); !errors.Is(err, errReconcileMemoryQueue) {
+		t.Fatalf("expected errReconcileMemoryQueue, got %v", err)
+	}
+}
+
+func TestNewReconcileContainer_RequiresScannerAndInspector(t *testing.T) {
+	stubReconcileDeps(t, &workerStubPostRepository{}, queueMemory.NewInMemoryJobQueue(0))
+	if _, err := NewReconcileContainer(context.Background()); !errors.Is(err, errReconcileScannerUnsupported) {
+		t.Fatalf("expected errReconcileScannerUnsupported, got %v", err)
+	}
+
+	stubReconcileDeps(t, repoMemory.NewInMemoryPostRepository(), &noopJobQueue{})
+	if _, err := NewReconcileContainer(context.Background()); !errors.Is(err, errReconcileInspectUnsupported) {
+		t.Fatalf("expected errReconcileInspectUnsupported, got %v", err)
+	}
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"strings"
 
 	"backend/internal/adapter/llm/gemini"
 	openaiFormatter "backend/internal/adapter/llm/openai"
+	"backend/internal/adapter/llm/prompt"
 	repoFirestore "backend/internal/adapter/repository/firestore"
 	"backend/internal/config"
+	"backend/internal/domain/fortune"
 	"backend/internal/port/llm"
 	"backend/internal/port/queue"
 	"backend/internal/port/repository"
@@ -33,8 +36,8 @@ type WorkerContainer struct {
 var formatterCtor = gemini.NewFormatter
 
 // OpenAI 用の整形器を作り、後片付け手順もあわせて返す
-var openaiFormatterFactory = func(apiKey, model, baseURL string) (llm.Formatter, func() error, error) {
-	formatter, err := openaiFormatter.NewFormatter(apiKey, model, baseURL)
+var openaiFormatterFactory = func(apiKey, model, baseURL string, tmpl *prompt.Template) (llm.Formatter, func() error, error) {
+	formatter, err := openaiFormatter.NewFormatter(apiKey, model, baseURL, tmpl)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -54,6 +57,7 @@ var formatterFactory = func(ctx context.Context) (llm.Formatter, func() error, e
 }
 var postRepositoryFactory = newPostRepository
 var drawRepositoryFactory = newDrawRepository
+var formatCompleterFactory = newFormatCompleter
 var infraFactory = NewInfra
 var errWorkerFirestoreEnvMissing = errors.New("worker: Firestore 環境変数が未設定です")
 
@@ -61,6 +65,10 @@ var errWorkerFirestoreEnvMissing = errors.New("worker: Firestore 環境変数が
  * ワーカー稼働に必要なインフラ、LLM、キューなどを整えて返す。
  */
 func NewWorkerContainer(ctx context.Context) (*WorkerContainer, error) {
+	// メモリ上の保存先は API のプロセスと共有できないため、単独のワーカーでは使わない
+	if err := ensureSharedMemoryStore(); err != nil {
+		return nil, err
+	}
 	// Firestore 必須の環境変数が欠けていないかを最初に確認する
 	if err := ensureWorkerFirestoreEnv(); err != nil {
 		return nil, err
@@ -82,19 +90,61 @@ func NewWorkerContainer(ctx context.Context) (*WorkerContainer, error) {
 		return nil, fmt.Errorf("init draw repository: %w", err)
 	}
 
-	// ジョブキューは Firestore 固定で共有する
+	// おみくじ結果の保存と投稿の状態更新は 1 つのトランザクションで行う
+	completer, err := formatCompleterFactory(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init format completer: %w", err)
+	}
+
+	// ジョブキューは JOB_QUEUE_MODE に応じた実装を使う
 	jobQueue, err := jobQueueFactory(infra)
 	if err != nil {
 		return nil, fmt.Errorf("init job queue: %w", err)
 	}
 
+	auditLog, err := provideAuditLog(infra)
+	if err != nil {
+		return nil, fmt.Errorf("init audit log: %w", err)
+	}
+
+	validator, err := newFortuneValidator()
+	if err != nil {
+		return nil, fmt.Errorf("init fortune validator: %w", err)
+	}
+
 	// どの LLM プロバイダを使うかは formatterFactory が環境変数から判断する
 	formatter, closeFormatter, err := formatterFactory(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("init formatter: %w", err)
 	}
 
-	usecase := worker.NewFormatPendingUsecase(postRepo, drawRepo, formatter, jobQueue)
+	return newWorkerContainer(infra, postRepo, drawRepo, completer, jobQueue, auditLog, validator, formatter, closeFormatter), nil
+}
+
+/**
+ * 初期化済みの依存から整形ユースケースを組み立て、ワーカー用の器へまとめる。
+ */
+func newWorkerContainer(
+	infra *Infra,
+	postRepo repository.PostRepository,
+	drawRepo repository.DrawRepository,
+	completer repository.FormatCompleter,
+	jobQueue queue.JobQueue,
+	auditLog repository.AuditLog,
+	validator *fortune.Validator,
+	formatter llm.Formatter,
+	closeFormatter func() error,
+) *WorkerContainer {
+	usecase := worker.NewFormatPendingUsecase(postRepo, completer, formatter, auditLog, validator)
+	// Retry を経ずにリース切れで隔離されたジョブも、Retry での隔離と同じく記録して投稿を failed で確定させる
+	if notifier, ok := jobQueue.(queue.ExpiredLeaseNotifier); ok {
+		notifier.NotifyExpiredDeadLetters(func(ctx context.Context, job queue.DeadFormatJob) {
+			usecase.RecordJobDeadLettered(ctx, string(job.PostID), queue.ErrLeaseExpired)
+			if err := usecase.MarkFailed(ctx, string(job.PostID)); err != nil {
+				log.Printf("mark post failed error (post=%s): %v", job.PostID, err)
+			}
+		})
+	}
 
 	container := &WorkerContainer{
 		Infra:                infra,
@@ -108,7 +158,7 @@ func NewWorkerContainer(ctx context.Context) (*WorkerContainer, error) {
 	if infra != nil {
 		container.closeInfra = infra.Close
 	}
-	return container, nil
+	return container
 }
 
 /**
@@ -153,8 +203,12 @@ func newGeminiFormatter(ctx context.Context) (llm.Formatter, func() error, error
 	if err != nil {
 		return nil, nil, fmt.Errorf("load gemini config: %w", err)
 	}
+	tmpl, err := loadPromptTemplate()
+	if err != nil {
+		return nil, nil, err
+	}
 	// 構築済みクライアントを整形器として扱い、Close をそのまま返す
-	formatter, err := formatterCtor(ctx, cfg.APIKey, cfg.Model)
+	formatter, err := formatterCtor(ctx, cfg.APIKey, cfg.Model, tmpl)
 	if err != nil {
 		return nil, nil, fmt.Errorf("new gemini formatter: %w", err)
 	}
@@ -170,8 +224,12 @@ func newOpenAIFormatter() (llm.Formatter, func() error, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("load openai config: %w", err)
 	}
+	tmpl, err := loadPromptTemplate()
+	if err != nil {
+		return nil, nil, err
+	}
 	// SDK から生成した整形器とクローズ処理を返す
-	formatter, closeFn, err := openaiFormatterFactory(cfg.APIKey, cfg.Model, cfg.BaseURL)
+	formatter, closeFn, err := openaiFormatterFactory(cfg.APIKey, cfg.Model, cfg.BaseURL, tmpl)
 	if err != nil {
 		return nil, nil, fmt.Errorf("new openai formatter: %w", err)
 	}
@@ -179,9 +237,67 @@ func newOpenAIFormatter() (llm.Formatter, func() error, error) {
 }
 
 /**
- * Firestore 固定の投稿リポジトリを構築する。
+ * PROMPT_VERSION の版のプロンプトを読み込む。PROMPT_DIR があればそのディレクトリから、無ければ埋め込みから読む。
+ * 版の指定誤りは整形のたびに失敗するより起動時に止めたほうが気付きやすいため、ここでエラーにする。
+ */
+func loadPromptTemplate() (*prompt.Template, error) {
+	cfg, err := config.LoadPromptConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("load prompt config: %w", err)
+	}
+	var fsys fs.FS
+	if cfg.Dir != "" {
+		fsys = os.DirFS(cfg.Dir)
+	}
+	tmpl, err := prompt.Load(fsys, cfg.Version)
+	if err != nil {
+		return nil, fmt.Errorf("load prompt template: %w", err)
+	}
+	log.Printf("prompt template: version=%s", tmpl.Version())
+	return tmpl, nil
+}
+
+/**
+ * 既定のお告げ規則に FORTUNE_* 環境変数の上書きを重ね、提供元によらない検査器を作る。
+ * プロンプトと規則の食い違いは整形のたびに拒否が続く形で表れるため、採用した規則を起動時に記録しておく。
+ */
+func newFortuneValidator() (*fortune.Validator, error) {
+	cfg, err := config.LoadFortuneRulesConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("load fortune rules config: %w", err)
+	}
+	rules := fortune.DefaultRules()
+	if cfg.MinLength != nil {
+		rules.MinLength = *cfg.MinLength
+	}
+	if cfg.MaxLength != nil {
+		rules.MaxLength = *cfg.MaxLength
+	}
+	if cfg.Prefix != nil {
+		rules.Prefix = *cfg.Prefix
+	}
+	if cfg.SentenceCount != nil {
+		rules.SentenceCount = *cfg.SentenceCount
+	}
+	if cfg.SentenceEndings != nil {
+		rules.SentenceEndings = cfg.SentenceEndings
+	}
+	validator, err := fortune.NewValidator(rules)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("fortune rules: length=%d-%d prefix=%q sentences=%d endings=%v",
+		rules.MinLength, rules.MaxLength, rules.Prefix, rules.SentenceCount, rules.SentenceEndings)
+	return validator, nil
+}
+
+/**
+ * 投稿リポジトリを構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
  */
 func newPostRepository(ctx context.Context, infra *Infra) (repository.PostRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.posts, nil
+	}
 	// Firestore を使うためクライアントが初期化済みかを先に検証する
 	if infra == nil || infra.Firestore() == nil {
 		return nil, errFirestoreClientUnavailable
@@ -195,9 +311,12 @@ func newPostRepository(ctx context.Context, infra *Infra) (repository.PostReposi
 }
 
 /**
- * Firestore 固定の DrawRepository を構築する。
+ * DrawRepository を構築する。JOB_QUEUE_MODE=memory ならメモリ実装、それ以外は Firestore を使う。
  */
 func newDrawRepository(ctx context.Context, infra *Infra) (repository.DrawRepository, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.draws, nil
+	}
 	// DrawRepository も Firestore を利用するためクライアント初期化を必須にする
 	if infra == nil || infra.Firestore() == nil {
 		return nil, errFirestoreClientUnavailable
@@ -210,9 +329,30 @@ func newDrawRepository(ctx context.Context, infra *Infra) (repository.DrawReposi
 }
 
 /**
- * Worker 起動に必須な Firestore 環境変数を検証する。
+ * draws と posts をまとめて書き込む FormatCompleter を構築する。JOB_QUEUE_MODE=memory ならメモリ実装を使う。
+ */
+func newFormatCompleter(infra *Infra) (repository.FormatCompleter, error) {
+	if store := infra.inMemory(); store != nil {
+		return store.completer, nil
+	}
+	if infra == nil || infra.Firestore() == nil {
+		return nil, errFirestoreClientUnavailable
+	}
+	completer, err := repoFirestore.NewFormatCompleter(infra.Firestore())
+	if err != nil {
+		return nil, fmt.Errorf("new firestore format completer: %w", err)
+	}
+	return completer, nil
+}
+
+/**
+ * Worker 起動に必須な Firestore 環境変数を検証する。JOB_QUEUE_MODE=memory なら Firestore を使わないため検証しない。
  */
 func ensureWorkerFirestoreEnv() error {
+	memory, err := useMemoryStore()
+	if err != nil || memory {
+		return err
+	}
 	var missing []string
 	if strings.TrimSpace(os.Getenv("GOOGLE_CLOUD_PROJECT")) == "" {
 		missing = append(missing, "GOOGLE_CLOUD_PROJECT")
@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"google.golang.org/api/option"
 
 	"backend/internal/adapter/llm/gemini"
+	"backend/internal/adapter/llm/prompt"
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
 	"backend/internal/domain/post"
 	"backend/internal/port/llm"
 	"backend/internal/port/queue"
@@ -19,6 +24,7 @@ import (
 func TestNewWorkerContainer_UsesFirestoreRepository(t *testing.T) {
 	setRequiredFirestoreEnv(t)
 	defer stubJobQueueFactory(t)()
+	defer stubFormatCompleterFactory(t)()
 
 	stubFormatter := &stubFormatter{}
 	origFormatterFactory := formatterFactory
@@ -36,6 +42,7 @@ func TestNewWorkerContainer_UsesFirestoreRepository(t *testing.T) {
 
 	stubDrawRepo := &workertestutil.StubDrawRepository{}
 	defer stubDrawRepositoryFactory(t, stubDrawRepo, nil)()
+	defer stubAuditLogFactory(t, repoMemory.NewInMemoryAuditLog())()
 
 	origInfraFactory := infraFactory
 	infraFactory = func(ctx context.Context) (*Infra, error) {
@@ -64,13 +71,18 @@ func TestNewWorkerContainer_UsesFirestoreRepository(t *testing.T) {
 func TestFormatterFactory_UsesCtor(t *testing.T) {
 	origCtor := formatterCtor
 	stub := &gemini.Formatter{}
-	formatterCtor = func(ctx context.Context, apiKey, model string, opts ...option.ClientOption) (*gemini.Formatter, error) {
+	formatterCtor = func(ctx context.Context, apiKey, model string, tmpl *prompt.Template, opts ...option.ClientOption) (*gemini.Formatter, error) {
+		if tmpl == nil || tmpl.Version() != prompt.DefaultVersion {
+			t.Fatalf("expected default prompt template, got %v", tmpl)
+		}
 		return stub, nil
 	}
 	defer func() { formatterCtor = origCtor }()
 
 	t.Setenv("GEMINI_API_KEY", "key")
 	t.Setenv("GEMINI_MODEL", "model")
+	t.Setenv("PROMPT_VERSION", "")
+	t.Setenv("PROMPT_DIR", "")
 
 	f, closer, err := newGeminiFormatter(context.Background())
 	if err != nil {
@@ -203,6 +215,7 @@ func TestNewWorkerContainer_FirestoreEnvMissing(t *testing.T) {
 
 func TestNewWorkerContainer_JobQueueFactoryError(t *testing.T) {
 	setRequiredFirestoreEnv(t)
+	defer stubFormatCompleterFactory(t)()
 	defer stubDrawRepositoryFactory(t, &workertestutil.StubDrawRepository{}, nil)()
 
 	origInfra := infraFactory
@@ -254,6 +267,61 @@ func TestNewDrawRepository_FirestoreSuccess(t *testing.T) {
 	}
 }
 
+func TestNewFormatCompleter_FirestoreRequiresClient(t *testing.T) {
+	if _, err := newFormatCompleter(&Infra{}); !errors.Is(err, errFirestoreClientUnavailable) {
+		t.Fatalf("expected errFirestoreClientUnavailable, got %v", err)
+	}
+}
+
+func TestNewFormatCompleter_FirestoreSuccess(t *testing.T) {
+	infra := &Infra{firestoreClient: &firestore.Client{}}
+	if _, err := newFormatCompleter(infra); err != nil {
+		t.Fatalf("expected firestore format completer, got error: %v", err)
+	}
+}
+
+func TestNewWorkerContainer_ExpiredDeadLetterFailsPost(t *testing.T) {
+	p, _ := post.Restore(post.DarkPostID("post-hang"), post.DarkContent("闇"), post.StatusFormatting)
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	if err := postRepo.Create(context.Background(), p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	jobQueue := queueMemory.NewInMemoryJobQueue(0,
+		queueMemory.WithLeaseDuration(10*time.Millisecond),
+		queueMemory.WithRetryPolicy(queue.RetryPolicy{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	newWorkerContainer(nil, postRepo, drawRepo, workertestutil.NewStubFormatCompleter(postRepo, drawRepo), jobQueue, auditLog, nil, &stubFormatter{}, nil)
+
+	ctx := context.Background()
+	if err := jobQueue.EnqueueFormat(ctx, p.ID()); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := jobQueue.DequeueFormat(ctx); err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+
+	// リースを確定しないまま期限が切れると隔離され、投稿も failed で確定する
+	deadline := time.Now().Add(2 * time.Second)
+	var events []*audit.Event
+	for len(events) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected dead-letter and failure events, got %+v", events)
+		}
+		time.Sleep(5 * time.Millisecond)
+		events, _ = auditLog.ListByPost(ctx, p.ID())
+	}
+	if stored, _ := postRepo.Get(ctx, p.ID()); stored.Status() != post.StatusFailed {
+		t.Fatalf("post should be marked failed, got %s", stored.Status())
+	}
+	if len(events) != 2 || events[0].Action() != audit.ActionJobDeadLettered || events[1].Action() != audit.ActionFormatFailed {
+		t.Fatalf("unexpected audit events: %+v", events)
+	}
+	if events[0].Details()["error"] != queue.ErrLeaseExpired.Error() {
+		t.Fatalf("dead-letter event should name lease expiry, got %v", events[0].Details())
+	}
+}
+
 func TestWorkerContainerClose_ReturnsFirstError(t *testing.T) {
 	queueStub := &stubJobQueue{closeErr: errors.New("queue close")}
 	formatter := &stubFormatter{closeErr: errors.New("formatter close")}
@@ -307,10 +375,15 @@ func TestNewOpenAIFormatter_Success(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "test-key")
 	t.Setenv("OPENAI_MODEL", "")
 	t.Setenv("OPENAI_BASE_URL", "")
+	t.Setenv("PROMPT_VERSION", "menhera-v1")
+	t.Setenv("PROMPT_DIR", "")
 
 	stub := &stubFormatter{}
 	origFactory := openaiFormatterFactory
-	openaiFormatterFactory = func(apiKey, model, baseURL string) (llm.Formatter, func() error, error) {
+	openaiFormatterFactory = func(apiKey, model, baseURL string, tmpl *prompt.Template) (llm.Formatter, func() error, error) {
+		if tmpl == nil || tmpl.Version() != "menhera-v1" {
+			t.Fatalf("expected configured prompt template, got %v", tmpl)
+		}
 		if apiKey != "test-key" {
 			t.Fatalf("unexpected api key: %s", apiKey)
 		}
@@ -340,6 +413,15 @@ func TestNewOpenAIFormatter_MissingConfig(t *testing.T) {
 	}
 }
 
+func TestNewOpenAIFormatter_UnknownPromptVersion(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("PROMPT_VERSION", "missing-v9")
+	t.Setenv("PROMPT_DIR", "")
+	if _, _, err := newOpenAIFormatter(); !errors.Is(err, prompt.ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+}
+
 func setRequiredFirestoreEnv(t *testing.T) {
 	t.Helper()
 	t.Setenv("GOOGLE_CLOUD_PROJECT", "test-project")
@@ -355,6 +437,15 @@ func stubJobQueueFactory(t *testing.T) func() {
 	return func() { jobQueueFactory = orig }
 }
 
+func stubFormatCompleterFactory(t *testing.T) func() {
+	t.Helper()
+	orig := formatCompleterFactory
+	formatCompleterFactory = func(infra *Infra) (repository.FormatCompleter, error) {
+		return workertestutil.NewStubFormatCompleter(&workerStubPostRepository{}, &workertestutil.StubDrawRepository{}), nil
+	}
+	return func() { formatCompleterFactory = orig }
+}
+
 func stubDrawRepositoryFactory(t *testing.T, repo repository.DrawRepository, retErr error) func() {
 	t.Helper()
 	orig := drawRepositoryFactory
@@ -394,8 +485,20 @@ func (s *stubJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) er
 	return nil
 }
 
-func (s *stubJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", nil
+func (s *stubJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	return queue.Lease{}, nil
+}
+
+func (s *stubJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (s *stubJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (s *stubJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	return nil
 }
 
 func (s *stubJobQueue) Close() error {
@@ -412,8 +515,20 @@ func (noopJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error
 	return nil
 }
 
-func (noopJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", nil
+func (noopJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	return queue.Lease{}, nil
+}
+
+func (noopJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (noopJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (noopJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	return nil
 }
 
 func (noopJobQueue) Close() error {
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	// 管理 API のトークンに求める最短の長さ
+	minAdminTokenLength = 32
+
+	envAdminAPIToken = "ADMIN_API_TOKEN"
+)
+
+/**
+ * ADMIN_API_TOKEN 環境変数から管理 API の Bearer トークンを読み込む。
+ * 未設定なら空文字を返し、管理 API を公開しない。推測されにくいよう minAdminTokenLength 文字未満はエラーにする。
+ */
+func LoadAdminTokenFromEnv() (string, error) {
+	token := strings.TrimSpace(os.Getenv(envAdminAPIToken))
+	if token == "" {
+		log.Printf("警告: %s が設定されていません。/admin は無効になります", envAdminAPIToken)
+		return "", nil
+	}
+	if len(token) < minAdminTokenLength {
+		return "", fmt.Errorf("config: %s must be at least %d characters", envAdminAPIToken, minAdminTokenLength)
+	}
+	return token, nil
+}
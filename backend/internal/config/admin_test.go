@@ -0,0 +1,24 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadAdminTokenFromEnv(t *testing.T) {
+	t.Setenv(envAdminAPIToken, "")
+	if got, err := LoadAdminTokenFromEnv(); err != nil || got != "" {
+		t.Fatalf("expected admin API to be disabled, got %q, %v", got, err)
+	}
+
+	token := strings.Repeat("k", minAdminTokenLength)
+	t.Setenv(envAdminAPIToken, " "+token+" ")
+	if got, err := LoadAdminTokenFromEnv(); err != nil || got != token {
+		t.Fatalf("expected trimmed token, got %q, %v", got, err)
+	}
+
+	t.Setenv(envAdminAPIToken, "short")
+	if _, err := LoadAdminTokenFromEnv(); err == nil {
+		t.Fatal("expected error for short token")
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 署名鍵を自動生成する場合の長さ（バイト）
+	generatedClientIDSecretBytes = 32
+
+	envClientIDSecret       = "CLIENT_ID_SECRET"
+	envClientIDCookieSecure = "CLIENT_ID_COOKIE_SECURE"
+)
+
+// 匿名クライアント ID の署名鍵と Cookie の属性
+type ClientIdentityConfig struct {
+	Secret       []byte
+	CookieSecure bool
+}
+
+/**
+ * 環境変数から匿名クライアント ID の署名鍵と Cookie を Secure にするかを読み込む。
+ * 署名鍵が未設定なら起動ごとに生成するため、再起動すると発行済みの ID は無効になる。
+ */
+func LoadClientIdentityConfigFromEnv() (*ClientIdentityConfig, error) {
+	cfg := &ClientIdentityConfig{}
+
+	if secret := strings.TrimSpace(os.Getenv(envClientIDSecret)); secret != "" {
+		cfg.Secret = []byte(secret)
+	} else {
+		log.Printf("警告: %s が設定されていません。起動ごとに署名鍵を生成するため、再起動でクライアント ID が振り直されます", envClientIDSecret)
+		cfg.Secret = make([]byte, generatedClientIDSecretBytes)
+		if _, err := rand.Read(cfg.Secret); err != nil {
+			return nil, fmt.Errorf("config: generate %s: %w", envClientIDSecret, err)
+		}
+	}
+
+	if raw := strings.TrimSpace(os.Getenv(envClientIDCookieSecure)); raw != "" {
+		secure, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s must be a boolean: %q", envClientIDCookieSecure, raw)
+		}
+		cfg.CookieSecure = secure
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestLoadClientIdentityConfigFromEnv(t *testing.T) {
+	t.Setenv(envClientIDSecret, "shh")
+	t.Setenv(envClientIDCookieSecure, "true")
+
+	cfg, err := LoadClientIdentityConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(cfg.Secret) != "shh" || !cfg.CookieSecure {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadClientIdentityConfigFromEnv_GeneratesSecret(t *testing.T) {
+	t.Setenv(envClientIDSecret, "")
+	t.Setenv(envClientIDCookieSecure, "")
+
+	first, err := LoadClientIdentityConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := LoadClientIdentityConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(first.Secret) != generatedClientIDSecretBytes || string(first.Secret) == string(second.Secret) {
+		t.Fatalf("secret should be generated per load: %x / %x", first.Secret, second.Secret)
+	}
+	if first.CookieSecure {
+		t.Fatal("cookie should not be secure by default")
+	}
+}
+
+func TestLoadClientIdentityConfigFromEnv_InvalidSecure(t *testing.T) {
+	t.Setenv(envClientIDSecret, "shh")
+	t.Setenv(envClientIDCookieSecure, "sometimes")
+
+	if _, err := LoadClientIdentityConfigFromEnv(); err == nil {
+		t.Fatal("expected error for invalid boolean")
+	}
+}
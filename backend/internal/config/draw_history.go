@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultDrawHistoryWindow = 20
+
+	envDrawHistoryWindow = "DRAW_HISTORY_WINDOW"
+)
+
+/**
+ * DRAW_HISTORY_WINDOW 環境変数から、同じクライアントに続けて出さない直近のおみくじの件数を読み込む。
+ * 未設定時は DefaultDrawHistoryWindow、0 なら履歴を使わない。
+ */
+func LoadDrawHistoryWindowFromEnv() (int, error) {
+	raw := strings.TrimSpace(os.Getenv(envDrawHistoryWindow))
+	if raw == "" {
+		return DefaultDrawHistoryWindow, nil
+	}
+	window, err := strconv.Atoi(raw)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("config: %s must be a non-negative integer: %q", envDrawHistoryWindow, raw)
+	}
+	return window, nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestLoadDrawHistoryWindowFromEnv(t *testing.T) {
+	t.Setenv(envDrawHistoryWindow, "")
+	if got, err := LoadDrawHistoryWindowFromEnv(); err != nil || got != DefaultDrawHistoryWindow {
+		t.Fatalf("expected default window, got %d, %v", got, err)
+	}
+
+	t.Setenv(envDrawHistoryWindow, " 5 ")
+	if got, err := LoadDrawHistoryWindowFromEnv(); err != nil || got != 5 {
+		t.Fatalf("expected 5, got %d, %v", got, err)
+	}
+
+	t.Setenv(envDrawHistoryWindow, "0")
+	if got, err := LoadDrawHistoryWindowFromEnv(); err != nil || got != 0 {
+		t.Fatalf("expected 0 to disable history, got %d, %v", got, err)
+	}
+
+	for _, raw := range []string{"-1", "many"} {
+		t.Setenv(envDrawHistoryWindow, raw)
+		if _, err := LoadDrawHistoryWindowFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DrawSelectionUniform = "uniform"
+	DrawSelectionRecency = "recency"
+	DrawSelectionRating  = "rating"
+
+	DefaultDrawSelectionSampleSize = 20
+	DefaultDrawRecencyHalfLife     = 7 * 24 * time.Hour
+
+	envDrawSelectionStrategy   = "DRAW_SELECTION_STRATEGY"
+	envDrawSelectionSampleSize = "DRAW_SELECTION_SAMPLE_SIZE"
+	envDrawRecencyHalfLife     = "DRAW_RECENCY_HALF_LIFE"
+	envDrawSelectionSeed       = "DRAW_SELECTION_SEED"
+)
+
+/**
+ * おみくじの選び方
+ * Strategy: uniform / recency / rating
+ * SampleSize: 重み付きの方針で比べる候補の件数
+ * HalfLife: recency で重みが半分になるまでの経過時間
+ * Seed: 抽選の乱数の種（uniform を含むすべての方針で使う）。Seeded が false なら起動ごとに変わる
+ */
+type DrawSelectionConfig struct {
+	Strategy   string
+	SampleSize int
+	HalfLife   time.Duration
+	Seed       uint64
+	Seeded     bool
+}
+
+/**
+ * 環境変数からおみくじの選び方を読み込む。未設定時は一様に選ぶ。
+ */
+func LoadDrawSelectionConfigFromEnv() (*DrawSelectionConfig, error) {
+	cfg := &DrawSelectionConfig{
+		Strategy:   DrawSelectionUniform,
+		SampleSize: DefaultDrawSelectionSampleSize,
+		HalfLife:   DefaultDrawRecencyHalfLife,
+	}
+
+	switch strategy := strings.ToLower(strings.TrimSpace(os.Getenv(envDrawSelectionStrategy))); strategy {
+	case "":
+	case DrawSelectionUniform, DrawSelectionRecency, DrawSelectionRating:
+		cfg.Strategy = strategy
+	default:
+		return nil, fmt.Errorf("config: %s must be one of %s / %s / %s: %q",
+			envDrawSelectionStrategy, DrawSelectionUniform, DrawSelectionRecency, DrawSelectionRating, strategy)
+	}
+
+	if raw := strings.TrimSpace(os.Getenv(envDrawSelectionSampleSize)); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("config: %s must be a positive integer: %q", envDrawSelectionSampleSize, raw)
+		}
+		cfg.SampleSize = size
+	}
+
+	halfLife, err := loadPositiveDuration(envDrawRecencyHalfLife)
+	if err != nil {
+		return nil, err
+	}
+	if halfLife > 0 {
+		cfg.HalfLife = halfLife
+	}
+
+	if raw := strings.TrimSpace(os.Getenv(envDrawSelectionSeed)); raw != "" {
+		seed, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s must be an unsigned integer: %q", envDrawSelectionSeed, raw)
+		}
+		cfg.Seed = seed
+		cfg.Seeded = true
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadDrawSelectionConfigFromEnv_Defaults(t *testing.T) {
+	t.Setenv(envDrawSelectionStrategy, "")
+	t.Setenv(envDrawSelectionSampleSize, "")
+	t.Setenv(envDrawRecencyHalfLife, "")
+	t.Setenv(envDrawSelectionSeed, "")
+
+	cfg, err := LoadDrawSelectionConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := DrawSelectionConfig{
+		Strategy:   DrawSelectionUniform,
+		SampleSize: DefaultDrawSelectionSampleSize,
+		HalfLife:   DefaultDrawRecencyHalfLife,
+	}
+	if *cfg != want {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadDrawSelectionConfigFromEnv_Custom(t *testing.T) {
+	t.Setenv(envDrawSelectionStrategy, " Recency ")
+	t.Setenv(envDrawSelectionSampleSize, "50")
+	t.Setenv(envDrawRecencyHalfLife, "36h")
+	t.Setenv(envDrawSelectionSeed, "42")
+
+	cfg, err := LoadDrawSelectionConfigFromEnv()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := DrawSelectionConfig{
+		Strategy:   DrawSelectionRecency,
+		SampleSize: 50,
+		HalfLife:   36 * time.Hour,
+		Seed:       42,
+		Seeded:     true,
+	}
+	if *cfg != want {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadDrawSelectionConfigFromEnv_Invalid(t *testing.T) {
+	cases := map[string]string{
+		envDrawSelectionStrategy:   "popular",
+		envDrawSelectionSampleSize: "0",
+		envDrawRecencyHalfLife:     "-1h",
+		envDrawSelectionSeed:       "-5",
+	}
+	for key, raw := range cases {
+		t.Run(key, func(t *testing.T) {
+			for k := range cases {
+				t.Setenv(k, "")
+			}
+			t.Setenv(key, raw)
+			if _, err := LoadDrawSelectionConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	envFortuneMinLength       = "FORTUNE_MIN_LENGTH"
+	envFortuneMaxLength       = "FORTUNE_MAX_LENGTH"
+	envFortunePrefix          = "FORTUNE_PREFIX"
+	envFortuneSentenceCount   = "FORTUNE_SENTENCE_COUNT"
+	envFortuneSentenceEndings = "FORTUNE_SENTENCE_ENDINGS"
+)
+
+/**
+ * お告げの検査規則を上書きする設定。nil の項目は既定の規則をそのまま使う
+ * MinLength / MaxLength: 冒頭の決まり文句を含めた文字数の範囲。0 ならその側は検査しない
+ * Prefix: お告げの冒頭に置く決まり文句
+ * SentenceCount: 決まり文句を除いた本文の文の数。0 なら検査しない
+ * SentenceEndings: 各文の末尾として認める語
+ */
+type FortuneRulesConfig struct {
+	MinLength       *int
+	MaxLength       *int
+	Prefix          *string
+	SentenceCount   *int
+	SentenceEndings []string
+}
+
+/**
+ * FORTUNE_* 環境変数からお告げの検査規則の上書きを読み込む。
+ * FORTUNE_SENTENCE_ENDINGS はカンマ区切りで複数指定できる。未設定の項目は nil のまま返す。
+ */
+func LoadFortuneRulesConfigFromEnv() (*FortuneRulesConfig, error) {
+	cfg := &FortuneRulesConfig{}
+	var err error
+	if cfg.MinLength, err = loadOptionalNonNegativeInt(envFortuneMinLength); err != nil {
+		return nil, err
+	}
+	if cfg.MaxLength, err = loadOptionalNonNegativeInt(envFortuneMaxLength); err != nil {
+		return nil, err
+	}
+	if cfg.SentenceCount, err = loadOptionalNonNegativeInt(envFortuneSentenceCount); err != nil {
+		return nil, err
+	}
+	if prefix := strings.TrimSpace(os.Getenv(envFortunePrefix)); prefix != "" {
+		cfg.Prefix = &prefix
+	}
+	if raw := strings.TrimSpace(os.Getenv(envFortuneSentenceEndings)); raw != "" {
+		for _, ending := range strings.Split(raw, ",") {
+			if trimmed := strings.TrimSpace(ending); trimmed != "" {
+				cfg.SentenceEndings = append(cfg.SentenceEndings, trimmed)
+			}
+		}
+		if len(cfg.SentenceEndings) == 0 {
+			return nil, fmt.Errorf("config: %s must contain at least one ending: %q", envFortuneSentenceEndings, raw)
+		}
+	}
+	return cfg, nil
+}
+
+/**
+ * 指定した環境変数を 0 以上の整数として読み込む。未設定なら nil を返す。
+ */
+func loadOptionalNonNegativeInt(key string) (*int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return nil, fmt.Errorf("config: %s must be a non-negative integer: %q", key, raw)
+	}
+	return &value, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoadFortuneRulesConfigFromEnv_Unset(t *testing.T) {
+	for _, key := range []string{envFortuneMinLength, envFortuneMaxLength, envFortunePrefix, envFortuneSentenceCount, envFortuneSentenceEndings} {
+		t.Setenv(key, "")
+	}
+	cfg, err := LoadFortuneRulesConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MinLength != nil || cfg.MaxLength != nil || cfg.Prefix != nil || cfg.SentenceCount != nil || cfg.SentenceEndings != nil {
+		t.Fatalf("expected no overrides, got %+v", cfg)
+	}
+}
+
+func TestLoadFortuneRulesConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(envFortuneMinLength, " 20 ")
+	t.Setenv(envFortuneMaxLength, "200")
+	t.Setenv(envFortunePrefix, "今日のお告げ:")
+	t.Setenv(envFortuneSentenceCount, "0")
+	t.Setenv(envFortuneSentenceEndings, "ます, でしょう,")
+
+	cfg, err := LoadFortuneRulesConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MinLength == nil || *cfg.MinLength != 20 {
+		t.Fatalf("expected min length 20, got %v", cfg.MinLength)
+	}
+	if cfg.MaxLength == nil || *cfg.MaxLength != 200 {
+		t.Fatalf("expected max length 200, got %v", cfg.MaxLength)
+	}
+	if cfg.Prefix == nil || *cfg.Prefix != "今日のお告げ:" {
+		t.Fatalf("unexpected prefix: %v", cfg.Prefix)
+	}
+	if cfg.SentenceCount == nil || *cfg.SentenceCount != 0 {
+		t.Fatalf("expected sentence count 0 to disable the check, got %v", cfg.SentenceCount)
+	}
+	if !slices.Equal(cfg.SentenceEndings, []string{"ます", "でしょう"}) {
+		t.Fatalf("unexpected sentence endings: %v", cfg.SentenceEndings)
+	}
+}
+
+func TestLoadFortuneRulesConfigFromEnv_Invalid(t *testing.T) {
+	cases := map[string]string{
+		envFortuneMinLength:       "-1",
+		envFortuneMaxLength:       "many",
+		envFortuneSentenceCount:   "three",
+		envFortuneSentenceEndings: " , ",
+	}
+	for key, raw := range cases {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, raw)
+			if _, err := LoadFortuneRulesConfigFromEnv(); err == nil {
+				t.Fatalf("expected error for %s=%q", key, raw)
+			}
+		})
+	}
+}
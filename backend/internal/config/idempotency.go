@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+const (
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	envIdempotencyKeyTTL = "IDEMPOTENCY_KEY_TTL"
+)
+
+/**
+ * IDEMPOTENCY_KEY_TTL 環境変数から、冪等キーと投稿の紐づけを保持する期間を読み込む。
+ * 未設定時は DefaultIdempotencyKeyTTL。期間を過ぎたキーは新しいリクエストとして扱う。
+ */
+func LoadIdempotencyKeyTTLFromEnv() (time.Duration, error) {
+	ttl, err := loadPositiveDuration(envIdempotencyKeyTTL)
+	if err != nil {
+		return 0, err
+	}
+	if ttl == 0 {
+		return DefaultIdempotencyKeyTTL, nil
+	}
+	return ttl, nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadIdempotencyKeyTTLFromEnv(t *testing.T) {
+	t.Setenv(envIdempotencyKeyTTL, "")
+	ttl, err := LoadIdempotencyKeyTTLFromEnv()
+	if err != nil || ttl != DefaultIdempotencyKeyTTL {
+		t.Fatalf("expected default, got %v, %v", ttl, err)
+	}
+
+	t.Setenv(envIdempotencyKeyTTL, "2h")
+	ttl, err = LoadIdempotencyKeyTTLFromEnv()
+	if err != nil || ttl != 2*time.Hour {
+		t.Fatalf("expected 2h, got %v, %v", ttl, err)
+	}
+
+	for _, raw := range []string{"0s", "-1h", "tomorrow"} {
+		t.Setenv(envIdempotencyKeyTTL, raw)
+		if _, err := LoadIdempotencyKeyTTLFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	JobQueueModeFirestore = "firestore"
+	JobQueueModeMemory    = "memory"
+
+	envJobQueueMode = "JOB_QUEUE_MODE"
+)
+
+/**
+ * JOB_QUEUE_MODE 環境変数から整形ジョブキューの実装を取得し、未設定時は firestore を返す。
+ * 綴りの誤りで意図しない保存先へ書き込まないよう、未知の値はエラーにする。
+ */
+func LoadJobQueueMode() (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv(envJobQueueMode))); mode {
+	case "", JobQueueModeFirestore:
+		return JobQueueModeFirestore, nil
+	case JobQueueModeMemory:
+		return JobQueueModeMemory, nil
+	default:
+		return "", fmt.Errorf("config: %s must be one of %s / %s: %q", envJobQueueMode, JobQueueModeFirestore, JobQueueModeMemory, mode)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestLoadJobQueueMode(t *testing.T) {
+	for value, want := range map[string]string{
+		"memory":    JobQueueModeMemory,
+		" Memory ":  JobQueueModeMemory,
+		"firestore": JobQueueModeFirestore,
+		"":          JobQueueModeFirestore,
+	} {
+		t.Setenv(envJobQueueMode, value)
+		if got, err := LoadJobQueueMode(); err != nil || got != want {
+			t.Fatalf("%q: expected %s, got %s, %v", value, want, got, err)
+		}
+	}
+
+	// 未知の値は firestore に読み替えずエラーにする
+	t.Setenv(envJobQueueMode, "unknown")
+	if got, err := LoadJobQueueMode(); err == nil {
+		t.Fatalf("expected error for unknown mode, got %s", got)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envFormatJobMaxAttempts    = "FORMAT_JOB_MAX_ATTEMPTS"
+	envFormatJobRetryBaseDelay = "FORMAT_JOB_RETRY_BASE_DELAY"
+	envFormatJobRetryMaxDelay  = "FORMAT_JOB_RETRY_MAX_DELAY"
+)
+
+// 整形ジョブの再試行設定。0 の項目は呼び出し側の既定値を使う。
+type JobRetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+/**
+ * 環境変数から整形ジョブの再試行回数と待機時間（"30s" などの Duration 表記）を読み込む。
+ */
+func LoadJobRetryConfigFromEnv() (*JobRetryConfig, error) {
+	cfg := &JobRetryConfig{}
+
+	if raw := strings.TrimSpace(os.Getenv(envFormatJobMaxAttempts)); raw != "" {
+		attempts, err := strconv.Atoi(raw)
+		if err != nil || attempts < 1 {
+			return nil, fmt.Errorf("config: %s must be a positive integer: %q", envFormatJobMaxAttempts, raw)
+		}
+		cfg.MaxAttempts = attempts
+	}
+
+	var err error
+	if cfg.BaseDelay, err = loadPositiveDuration(envFormatJobRetryBaseDelay); err != nil {
+		return nil, err
+	}
+	if cfg.MaxDelay, err = loadPositiveDuration(envFormatJobRetryMaxDelay); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func loadPositiveDuration(key string) (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("config: %s must be a positive duration: %q", key, raw)
+	}
+	return d, nil
+}
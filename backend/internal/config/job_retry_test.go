@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadJobRetryConfigFromEnv(t *testing.T) {
+	t.Setenv(envFormatJobMaxAttempts, "")
+	t.Setenv(envFormatJobRetryBaseDelay, "")
+	t.Setenv(envFormatJobRetryMaxDelay, "")
+	cfg, err := LoadJobRetryConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error when unset: %v", err)
+	}
+	if *cfg != (JobRetryConfig{}) {
+		t.Fatalf("expected zero config when unset, got %+v", cfg)
+	}
+
+	t.Setenv(envFormatJobMaxAttempts, "3")
+	t.Setenv(envFormatJobRetryBaseDelay, "10s")
+	t.Setenv(envFormatJobRetryMaxDelay, "5m")
+	cfg, err = LoadJobRetryConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxAttempts != 3 || cfg.BaseDelay != 10*time.Second || cfg.MaxDelay != 5*time.Minute {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadJobRetryConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv(envFormatJobMaxAttempts, "0")
+	if _, err := LoadJobRetryConfigFromEnv(); err == nil {
+		t.Fatalf("expected error for zero attempts")
+	}
+
+	t.Setenv(envFormatJobMaxAttempts, "")
+	t.Setenv(envFormatJobRetryBaseDelay, "soon")
+	if _, err := LoadJobRetryConfigFromEnv(); err == nil {
+		t.Fatalf("expected error for invalid duration")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	envPromptVersion = "PROMPT_VERSION"
+	envPromptDir     = "PROMPT_DIR"
+)
+
+/**
+ * 整形に使うプロンプトの設定
+ * Version: テンプレートの版。空なら既定の版を使う
+ * Dir: テンプレートを読み込むディレクトリ。空ならバイナリに埋め込んだテンプレートを使う
+ */
+type PromptConfig struct {
+	Version string
+	Dir     string
+}
+
+/**
+ * PROMPT_VERSION と PROMPT_DIR 環境変数からプロンプトの設定を読み込む。
+ * PROMPT_DIR を指定した場合は、ディレクトリとして存在するかまで確かめる。
+ */
+func LoadPromptConfigFromEnv() (*PromptConfig, error) {
+	cfg := &PromptConfig{
+		Version: strings.TrimSpace(os.Getenv(envPromptVersion)),
+		Dir:     strings.TrimSpace(os.Getenv(envPromptDir)),
+	}
+	if cfg.Dir != "" {
+		info, err := os.Stat(cfg.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("config: %s cannot be opened: %w", envPromptDir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("config: %s must be a directory: %q", envPromptDir, cfg.Dir)
+		}
+	}
+	return cfg, nil
+}
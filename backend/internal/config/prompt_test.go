@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPromptConfigFromEnv(t *testing.T) {
+	t.Setenv(envPromptVersion, "")
+	t.Setenv(envPromptDir, "")
+	cfg, err := LoadPromptConfigFromEnv()
+	if err != nil || cfg.Version != "" || cfg.Dir != "" {
+		t.Fatalf("expected embedded default, got %+v, %v", cfg, err)
+	}
+
+	dir := t.TempDir()
+	t.Setenv(envPromptVersion, " menhera-v1 ")
+	t.Setenv(envPromptDir, dir)
+	cfg, err = LoadPromptConfigFromEnv()
+	if err != nil || cfg.Version != "menhera-v1" || cfg.Dir != dir {
+		t.Fatalf("unexpected config: %+v, %v", cfg, err)
+	}
+
+	file := filepath.Join(dir, "kirakuji-v1.tmpl")
+	if err := os.WriteFile(file, []byte("{{.Content}}"), 0o600); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+	for _, raw := range []string{file, filepath.Join(dir, "missing")} {
+		t.Setenv(envPromptDir, raw)
+		if _, err := LoadPromptConfigFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultReportTakedownThreshold = 3
+
+	envReportTakedownThreshold = "REPORT_TAKEDOWN_THRESHOLD"
+)
+
+/**
+ * REPORT_TAKEDOWN_THRESHOLD 環境変数から、公開中のおみくじを自動で取り下げる未確認の通報元のネットワークの数を読み込む。
+ * 未設定時は DefaultReportTakedownThreshold、0 なら自動では取り下げない。
+ */
+func LoadReportTakedownThresholdFromEnv() (int, error) {
+	raw := strings.TrimSpace(os.Getenv(envReportTakedownThreshold))
+	if raw == "" {
+		return DefaultReportTakedownThreshold, nil
+	}
+	threshold, err := strconv.Atoi(raw)
+	if err != nil || threshold < 0 {
+		return 0, fmt.Errorf("config: %s must be a non-negative integer: %q", envReportTakedownThreshold, raw)
+	}
+	return threshold, nil
+}
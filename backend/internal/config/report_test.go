@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestLoadReportTakedownThresholdFromEnv(t *testing.T) {
+	t.Setenv(envReportTakedownThreshold, "")
+	if got, err := LoadReportTakedownThresholdFromEnv(); err != nil || got != DefaultReportTakedownThreshold {
+		t.Fatalf("expected default threshold, got %d, %v", got, err)
+	}
+
+	t.Setenv(envReportTakedownThreshold, " 10 ")
+	if got, err := LoadReportTakedownThresholdFromEnv(); err != nil || got != 10 {
+		t.Fatalf("expected 10, got %d, %v", got, err)
+	}
+
+	t.Setenv(envReportTakedownThreshold, "0")
+	if got, err := LoadReportTakedownThresholdFromEnv(); err != nil || got != 0 {
+		t.Fatalf("expected 0 to disable takedown, got %d, %v", got, err)
+	}
+
+	for _, raw := range []string{"-1", "few"} {
+		t.Setenv(envReportTakedownThreshold, raw)
+		if _, err := LoadReportTakedownThresholdFromEnv(); err == nil {
+			t.Fatalf("expected error for %q", raw)
+		}
+	}
+}
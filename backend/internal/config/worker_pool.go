@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultWorkerConcurrency  = 4
+	DefaultFormatJobTimeout   = 2 * time.Minute
+	DefaultWorkerDrainTimeout = 30 * time.Second
+	// 取り出したジョブを他ワーカーから隠しておく時間。FORMAT_JOB_TIMEOUT より長くなければならない
+	DefaultFormatJobLeaseDuration = 5 * time.Minute
+
+	envWorkerConcurrency      = "WORKER_CONCURRENCY"
+	envFormatJobTimeout       = "FORMAT_JOB_TIMEOUT"
+	envWorkerDrainTimeout     = "WORKER_DRAIN_TIMEOUT"
+	envFormatJobLeaseDuration = "FORMAT_JOB_LEASE_DURATION"
+)
+
+// 整形ワーカープールの並列数と時間制限
+type WorkerPoolConfig struct {
+	Concurrency   int
+	JobTimeout    time.Duration
+	DrainTimeout  time.Duration
+	LeaseDuration time.Duration
+}
+
+/**
+ * 環境変数から整形ワーカーの並列数、1 件あたりの制限時間、停止時の待ち時間、ジョブのリース期間を読み込む。
+ * 制限時間がリース期間以上だと処理中のジョブを他ワーカーが取り直してしまうため、起動時にエラーとする。
+ */
+func LoadWorkerPoolConfigFromEnv() (*WorkerPoolConfig, error) {
+	cfg := &WorkerPoolConfig{
+		Concurrency:   DefaultWorkerConcurrency,
+		JobTimeout:    DefaultFormatJobTimeout,
+		DrainTimeout:  DefaultWorkerDrainTimeout,
+		LeaseDuration: DefaultFormatJobLeaseDuration,
+	}
+
+	if raw := strings.TrimSpace(os.Getenv(envWorkerConcurrency)); raw != "" {
+		concurrency, err := strconv.Atoi(raw)
+		if err != nil || concurrency < 1 {
+			return nil, fmt.Errorf("config: %s must be a positive integer: %q", envWorkerConcurrency, raw)
+		}
+		cfg.Concurrency = concurrency
+	}
+
+	jobTimeout, err := loadPositiveDuration(envFormatJobTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if jobTimeout > 0 {
+		cfg.JobTimeout = jobTimeout
+	}
+
+	drainTimeout, err := loadPositiveDuration(envWorkerDrainTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if drainTimeout > 0 {
+		cfg.DrainTimeout = drainTimeout
+	}
+
+	if cfg.LeaseDuration, err = LoadFormatJobLeaseDuration(); err != nil {
+		return nil, err
+	}
+	if cfg.JobTimeout >= cfg.LeaseDuration {
+		return nil, fmt.Errorf("config: %s (%s) must be shorter than %s (%s)",
+			envFormatJobTimeout, cfg.JobTimeout, envFormatJobLeaseDuration, cfg.LeaseDuration)
+	}
+	return cfg, nil
+}
+
+/**
+ * 環境変数から整形ジョブのリース期間を読み込む。未設定なら既定値を返す。
+ */
+func LoadFormatJobLeaseDuration() (time.Duration, error) {
+	lease, err := loadPositiveDuration(envFormatJobLeaseDuration)
+	if err != nil {
+		return 0, err
+	}
+	if lease == 0 {
+		return DefaultFormatJobLeaseDuration, nil
+	}
+	return lease, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadWorkerPoolConfigFromEnv(t *testing.T) {
+	t.Setenv(envWorkerConcurrency, "")
+	t.Setenv(envFormatJobTimeout, "")
+	t.Setenv(envWorkerDrainTimeout, "")
+	t.Setenv(envFormatJobLeaseDuration, "")
+	cfg, err := LoadWorkerPoolConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error when unset: %v", err)
+	}
+	if cfg.Concurrency != DefaultWorkerConcurrency || cfg.JobTimeout != DefaultFormatJobTimeout ||
+		cfg.DrainTimeout != DefaultWorkerDrainTimeout || cfg.LeaseDuration != DefaultFormatJobLeaseDuration {
+		t.Fatalf("expected defaults, got %+v", cfg)
+	}
+
+	t.Setenv(envWorkerConcurrency, "8")
+	t.Setenv(envFormatJobTimeout, "45s")
+	t.Setenv(envWorkerDrainTimeout, "1m")
+	t.Setenv(envFormatJobLeaseDuration, "90s")
+	cfg, err = LoadWorkerPoolConfigFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Concurrency != 8 || cfg.JobTimeout != 45*time.Second || cfg.DrainTimeout != time.Minute || cfg.LeaseDuration != 90*time.Second {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadWorkerPoolConfigFromEnv_Invalid(t *testing.T) {
+	t.Setenv(envWorkerConcurrency, "-1")
+	if _, err := LoadWorkerPoolConfigFromEnv(); err == nil {
+		t.Fatalf("expected error for negative concurrency")
+	}
+
+	t.Setenv(envWorkerConcurrency, "")
+	t.Setenv(envFormatJobTimeout, "0s")
+	if _, err := LoadWorkerPoolConfigFromEnv(); err == nil {
+		t.Fatalf("expected error for zero timeout")
+	}
+}
+
+func TestLoadWorkerPoolConfigFromEnv_RejectsTimeoutLongerThanLease(t *testing.T) {
+	t.Setenv(envWorkerConcurrency, "")
+	t.Setenv(envWorkerDrainTimeout, "")
+	t.Setenv(envFormatJobLeaseDuration, "")
+
+	// 既定のリース期間 5 分以上の制限時間は処理中に他ワーカーへ渡ってしまう
+	t.Setenv(envFormatJobTimeout, "5m")
+	if _, err := LoadWorkerPoolConfigFromEnv(); err == nil {
+		t.Fatalf("expected error when timeout equals lease duration")
+	}
+
+	t.Setenv(envFormatJobTimeout, "10m")
+	t.Setenv(envFormatJobLeaseDuration, "15m")
+	if _, err := LoadWorkerPoolConfigFromEnv(); err != nil {
+		t.Fatalf("expected longer lease to accept timeout, got %v", err)
+	}
+
+	t.Setenv(envFormatJobLeaseDuration, "-1s")
+	if _, err := LoadFormatJobLeaseDuration(); err == nil {
+		t.Fatalf("expected error for negative lease duration")
+	}
+}
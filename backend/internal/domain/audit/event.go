@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"errors"
+	"maps"
+	"time"
+
+	"backend/internal/domain/post"
+)
+
+var (
+	// ErrEmptyPostID は対象の Post ID が空の場合に返される。
+	ErrEmptyPostID = errors.New("audit: post id is empty")
+	// ErrEmptyAction は出来事の種類が空の場合に返される。
+	ErrEmptyAction = errors.New("audit: action is empty")
+)
+
+// Action は投稿・おみくじ結果・整形ジョブに起きた出来事の種類。
+type Action string
+
+// Action の種類
+const (
+	// ActionPostCreated は投稿が受け付けられ整形ジョブが積まれたこと。
+	ActionPostCreated Action = "post.created"
+	// ActionFormatStarted はワーカーが整形を始めたこと。
+	ActionFormatStarted Action = "post.formatting"
+	// ActionDrawVerified は検証を通過して公開されたこと。
+	ActionDrawVerified Action = "draw.verified"
+	// ActionDrawRejected は検証や管理者の判断で公開不可になったこと。
+	ActionDrawRejected Action = "draw.rejected"
+	// ActionDrawApproved は管理者が判定を覆して公開したこと。
+	ActionDrawApproved Action = "draw.approved"
+	// ActionFormatFailed は再試行上限に達して整形を諦めたこと。
+	ActionFormatFailed Action = "post.failed"
+	// ActionPostRequeued は管理者が整形ジョブを積み直したこと。
+	ActionPostRequeued Action = "post.requeued"
+	// ActionPostDeleted は管理者が投稿を削除したこと。
+	ActionPostDeleted Action = "post.deleted"
+	// ActionReportsResolved は管理者が未確認の通報を確認して締めたこと。
+	ActionReportsResolved Action = "reports.resolved"
+	// ActionJobLeased はワーカーが整形ジョブのリースを取ったこと。
+	ActionJobLeased Action = "job.leased"
+	// ActionJobReleased は停止指示で試行回数を増やさずにリースを手放したこと。
+	ActionJobReleased Action = "job.released"
+	// ActionJobRetried は一時的な失敗で整形ジョブを待機後の再試行へ回したこと。
+	ActionJobRetried Action = "job.retried"
+	// ActionJobDeadLettered は再試行の上限に達して整形ジョブを隔離したこと。
+	ActionJobDeadLettered Action = "job.dead_lettered"
+)
+
+// ActorKind は出来事を起こした主体の種類。
+type ActorKind string
+
+// ActorKind の種類
+const (
+	ActorClient ActorKind = "client"
+	ActorWorker ActorKind = "worker"
+	ActorAdmin  ActorKind = "admin"
+	// ActorReports は通報がしきい値に達したことによる自動の判断。
+	ActorReports ActorKind = "reports"
+)
+
+// Actor は出来事を起こした主体。ID は匿名クライアント ID など、分かる場合だけ入る。
+type Actor struct {
+	Kind ActorKind
+	ID   string
+}
+
+// Client は投稿した匿名クライアントを主体として返す。ID が空なら投稿者不明。
+func Client(id post.ClientID) Actor {
+	return Actor{Kind: ActorClient, ID: string(id)}
+}
+
+// Worker は整形ワーカーを主体として返す。
+func Worker() Actor {
+	return Actor{Kind: ActorWorker}
+}
+
+// Admin は管理 API の利用者を主体として返す。
+func Admin() Actor {
+	return Actor{Kind: ActorAdmin}
+}
+
+// Reports は通報のしきい値による自動の取り下げを主体として返す。
+func Reports() Actor {
+	return Actor{Kind: ActorReports}
+}
+
+// Event は投稿 1 件に起きた出来事 1 つを表す。記録後は変更しない。
+type Event struct {
+	postID  post.DarkPostID
+	action  Action
+	actor   Actor
+	details map[string]string
+	// 記録された日時。記録前はゼロ値
+	occurredAt time.Time
+}
+
+// New は Post ID と出来事の種類、主体、補足から Event を生成する。
+// details には状態の移り先や理由など、後から経緯を追うための値を入れる。
+func New(postID post.DarkPostID, action Action, actor Actor, details map[string]string) (*Event, error) {
+	if postID == "" {
+		return nil, ErrEmptyPostID
+	}
+	if action == "" {
+		return nil, ErrEmptyAction
+	}
+	return &Event{
+		postID:  postID,
+		action:  action,
+		actor:   actor,
+		details: maps.Clone(details),
+	}, nil
+}
+
+// PostID は出来事が起きた投稿の ID を返す。
+func (e *Event) PostID() post.DarkPostID {
+	return e.postID
+}
+
+// Action は出来事の種類を返す。
+func (e *Event) Action() Action {
+	return e.action
+}
+
+// Actor は出来事を起こした主体を返す。
+func (e *Event) Actor() Actor {
+	return e.actor
+}
+
+// Details は補足の複製を返す。補足が無ければ nil。
+func (e *Event) Details() map[string]string {
+	return maps.Clone(e.details)
+}
+
+// OccurredAt は記録された日時を返す。記録前はゼロ値。
+func (e *Event) OccurredAt() time.Time {
+	return e.occurredAt
+}
+
+// RestoreOccurredAt はリポジトリが記録した日時を復元する。
+func (e *Event) RestoreOccurredAt(t time.Time) {
+	e.occurredAt = t
+}
@@ -0,0 +1,31 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	details := map[string]string{"reason": "攻撃的"}
+	e, err := New("post-1", ActionDrawRejected, Worker(), details)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 渡した補足を後から書き換えても記録は変わらない
+	details["reason"] = "changed"
+	if got := e.Details()["reason"]; got != "攻撃的" {
+		t.Fatalf("details should be copied, got %q", got)
+	}
+	if e.Actor().Kind != ActorWorker || e.Action() != ActionDrawRejected || !e.OccurredAt().IsZero() {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if _, err := New("", ActionPostCreated, Admin(), nil); !errors.Is(err, ErrEmptyPostID) {
+		t.Fatalf("expected ErrEmptyPostID, got %v", err)
+	}
+	if _, err := New("post-1", "", Admin(), nil); !errors.Is(err, ErrEmptyAction) {
+		t.Fatalf("expected ErrEmptyAction, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package draw
 
 import (
 	"errors"
+	"time"
 
 	"backend/internal/domain/post"
 )
@@ -17,6 +18,8 @@ var (
 	ErrNilPost = errors.New("draw: nil post supplied")
 	// ErrPostNotReady は ready でない Post から Draw を生成しようとした際に返される。
 	ErrPostNotReady = errors.New("draw: post is not ready")
+	// ErrInvalidTransition は今の状態から許されない遷移をしようとした際に返される。
+	ErrInvalidTransition = errors.New("draw: invalid status transition")
 )
 
 type (
@@ -24,6 +27,10 @@ type (
 	Status           string
 )
 
+// NoFormattedResult は整形結果が得られないまま公開不可になったおみくじ結果に入れる目印。
+// 管理者の承認で公開され得るため、整形前の本文を代わりに入れてはいけない。
+const NoFormattedResult FormattedContent = "（整形結果なし）"
+
 // Status の種類
 const (
 	StatusPending  Status = "pending"
@@ -31,11 +38,36 @@ const (
 	StatusRejected Status = "rejected"
 )
 
+// Provenance はおみくじ結果を生成した LLM の記録。モデルやプロンプトごとの品質を比べるために残す。
+type Provenance struct {
+	// Provider は gemini / openai などの LLM の提供元
+	Provider string
+	// Model は実際に呼び出したモデル名
+	Model string
+	// PromptVersion は整形に使ったプロンプトの版
+	PromptVersion string
+	// PromptTokens と CompletionTokens は提供元が返した消費トークン数。返らなければ 0
+	PromptTokens     int
+	CompletionTokens int
+	// Latency は整形のリクエストにかかった時間
+	Latency time.Duration
+}
+
+// IsZero は何も記録されていないかを返す。LLM を通さずに作られた結果や、記録を始める前の結果が該当する。
+func (p Provenance) IsZero() bool {
+	return p == Provenance{}
+}
+
 // Draw はおみくじ結果を表す。
 type Draw struct {
-	postID post.DarkPostID
-	result FormattedContent
-	status Status
+	postID     post.DarkPostID
+	result     FormattedContent
+	status     Status
+	reason     string
+	author     post.ClientID
+	provenance Provenance
+	// 保存された日時。保存前はゼロ値
+	createdAt time.Time
 }
 
 // New は Post ID と結果から Draw を生成する。
@@ -82,7 +114,12 @@ func FromPost(p *post.Post, result FormattedContent) (*Draw, error) {
 		return nil, ErrPostNotReady
 	}
 
-	return New(p.ID(), result)
+	d, err := New(p.ID(), result)
+	if err != nil {
+		return nil, err
+	}
+	d.AssignAuthor(p.Author())
+	return d, nil
 }
 
 // PostID は元となった Post の ID を返す。
@@ -95,14 +132,93 @@ func (d *Draw) Result() FormattedContent {
 	return d.result
 }
 
+// HasFormattedResult は公開できる整形結果を持つかを返す。NoFormattedResult の結果は公開しない。
+func (d *Draw) HasFormattedResult() bool {
+	return d.result != "" && d.result != NoFormattedResult
+}
+
+// Author は元になった投稿の投稿者を返す。
+func (d *Draw) Author() post.ClientID {
+	return d.author
+}
+
+// AssignAuthor は元になった投稿の投稿者を記録する。本人への出題を避けるために使う。
+func (d *Draw) AssignAuthor(author post.ClientID) {
+	d.author = author
+}
+
+// Provenance は結果を生成した LLM の記録を返す。不明ならゼロ値。
+func (d *Draw) Provenance() Provenance {
+	return d.provenance
+}
+
+// AttachProvenance は結果を生成した LLM の記録を残す。リポジトリからの復元にも使う。
+func (d *Draw) AttachProvenance(p Provenance) {
+	d.provenance = p
+}
+
+// CreatedAt は保存された日時を返す。保存前や不明な場合はゼロ値。
+func (d *Draw) CreatedAt() time.Time {
+	return d.createdAt
+}
+
+// RestoreCreatedAt はリポジトリが保存した日時を復元する。
+func (d *Draw) RestoreCreatedAt(t time.Time) {
+	d.createdAt = t
+}
+
 // Status はおみくじ結果の状態を返す。
 func (d *Draw) Status() Status {
 	return d.status
 }
 
+// Reason は公開不可と判定された理由を返す。rejected 以外では空文字。
+func (d *Draw) Reason() string {
+	return d.reason
+}
+
 // MarkVerified は結果を検証済み状態へ遷移させる。
 func (d *Draw) MarkVerified() {
 	d.status = StatusVerified
+	d.reason = ""
+}
+
+// MarkRejected は結果を公開不可状態へ遷移させ、判定理由を記録する。
+func (d *Draw) MarkRejected(reason string) {
+	d.status = StatusRejected
+	d.reason = reason
+}
+
+// TakeDown は公開中（verified）の結果を取り下げて公開不可状態へ遷移させ、理由を記録する。
+// 通報や管理者の判断で公開後に止める場合に使い、verified 以外からは ErrInvalidTransition を返す。
+func (d *Draw) TakeDown(reason string) error {
+	if d.status != StatusVerified {
+		return ErrInvalidTransition
+	}
+	d.MarkRejected(reason)
+	return nil
+}
+
+// Reinstate は公開不可の結果を公開中へ戻す。rejected 以外からは ErrInvalidTransition を返す。
+func (d *Draw) Reinstate() error {
+	if d.status != StatusRejected {
+		return ErrInvalidTransition
+	}
+	d.MarkVerified()
+	return nil
+}
+
+// Supports は保存済みの結果が投稿をその状態にする根拠になるかを返す。
+// ready は verified、rejected は rejected の結果だけが裏付けになり、それ以外の組み合わせは false。
+func (d *Draw) Supports(status post.Status) bool {
+	switch status {
+	case post.StatusReady:
+		return d.status == StatusVerified
+	case post.StatusRejected:
+		return d.status == StatusRejected
+	default:
+		return false
+	}
 }
 
 func (s Status) isValid() bool {
@@ -2,6 +2,7 @@ package draw
 
 import (
 	"testing"
+	"time"
 
 	"backend/internal/domain/post"
 )
@@ -40,6 +41,7 @@ func TestFromPost(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	p.AssignAuthor(post.ClientID("client-a"))
 	if err := p.MarkReady(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -52,6 +54,9 @@ func TestFromPost(t *testing.T) {
 	if draw.PostID() != p.ID() {
 		t.Fatalf("expected post id %s but got %s", p.ID(), draw.PostID())
 	}
+	if draw.Author() != p.Author() {
+		t.Fatalf("expected author %s but got %s", p.Author(), draw.Author())
+	}
 }
 
 func TestFromPost_NotReady(t *testing.T) {
@@ -79,3 +84,109 @@ func TestMarkVerified(t *testing.T) {
 		t.Fatalf("expected status verified but got %s", draw.Status())
 	}
 }
+
+func TestMarkRejected(t *testing.T) {
+	t.Parallel()
+
+	draw, err := New(post.DarkPostID("post-id"), FormattedContent("result"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	draw.MarkRejected("個人情報を含む")
+	if draw.Status() != StatusRejected {
+		t.Fatalf("expected status rejected but got %s", draw.Status())
+	}
+	if draw.Reason() != "個人情報を含む" {
+		t.Fatalf("unexpected reason: %s", draw.Reason())
+	}
+}
+
+func TestTakeDownAndReinstate(t *testing.T) {
+	t.Parallel()
+
+	draw, err := New(post.DarkPostID("post-id"), FormattedContent("result"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := draw.TakeDown("通報"); err != ErrInvalidTransition {
+		t.Fatalf("pending draw should not be taken down, got %v", err)
+	}
+	if err := draw.Reinstate(); err != ErrInvalidTransition {
+		t.Fatalf("pending draw should not be reinstated, got %v", err)
+	}
+
+	draw.MarkVerified()
+	if err := draw.TakeDown("通報が 3 件に達した"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draw.Status() != StatusRejected || draw.Reason() != "通報が 3 件に達した" {
+		t.Fatalf("unexpected state: %s %q", draw.Status(), draw.Reason())
+	}
+	if err := draw.TakeDown("二重"); err != ErrInvalidTransition {
+		t.Fatalf("rejected draw should not be taken down again, got %v", err)
+	}
+
+	if err := draw.Reinstate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draw.Status() != StatusVerified || draw.Reason() != "" {
+		t.Fatalf("unexpected state: %s %q", draw.Status(), draw.Reason())
+	}
+}
+
+func TestSupports(t *testing.T) {
+	t.Parallel()
+
+	draw, err := New(post.DarkPostID("post-id"), FormattedContent("result"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draw.Supports(post.StatusReady) || draw.Supports(post.StatusRejected) {
+		t.Fatalf("pending draw should support neither ready nor rejected")
+	}
+	draw.MarkVerified()
+	if !draw.Supports(post.StatusReady) || draw.Supports(post.StatusRejected) {
+		t.Fatalf("verified draw should support ready only")
+	}
+	draw.MarkRejected("NG")
+	if draw.Supports(post.StatusReady) || !draw.Supports(post.StatusRejected) {
+		t.Fatalf("rejected draw should support rejected only")
+	}
+	if draw.Supports(post.StatusFormatting) {
+		t.Fatalf("non-terminal post status should not be supported")
+	}
+}
+
+func TestAttachProvenance(t *testing.T) {
+	t.Parallel()
+
+	draw, err := New(post.DarkPostID("post-id"), FormattedContent("result"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !draw.Provenance().IsZero() {
+		t.Fatalf("new draw should have no provenance, got %+v", draw.Provenance())
+	}
+
+	want := Provenance{Provider: "gemini", Model: "gemini-2.5-flash", PromptVersion: "v1", PromptTokens: 120, CompletionTokens: 40, Latency: 800 * time.Millisecond}
+	draw.AttachProvenance(want)
+	// 状態を変えても生成元の記録は残る
+	draw.MarkVerified()
+	if err := draw.TakeDown("通報"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := draw.Provenance(); got != want || got.IsZero() {
+		t.Fatalf("unexpected provenance: %+v", got)
+	}
+}
+
+func TestHasFormattedResult(t *testing.T) {
+	formatted, _ := New("post-1", "大吉")
+	if !formatted.HasFormattedResult() {
+		t.Fatal("formatted result should be publishable")
+	}
+	placeholder, _ := New("post-1", NoFormattedResult)
+	if placeholder.HasFormattedResult() {
+		t.Fatal("placeholder should not count as a formatted result")
+	}
+}
@@ -0,0 +1,236 @@
+package fortune
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// sentenceTerminator はお告げの文を区切る句点。
+const sentenceTerminator = "。"
+
+// ErrInvalidRules は下限が上限を超えるなど、満たしようのない規則を受け取った際に返される。
+var ErrInvalidRules = errors.New("fortune: invalid rules")
+
+// ViolationCode は違反の種類。ログや監査ログで集計できるよう固定の文字列にする。
+type ViolationCode string
+
+// ViolationCode の種類
+const (
+	ViolationEmpty          ViolationCode = "empty"
+	ViolationTooShort       ViolationCode = "too_short"
+	ViolationTooLong        ViolationCode = "too_long"
+	ViolationForbiddenWord  ViolationCode = "forbidden_word"
+	ViolationURL            ViolationCode = "url"
+	ViolationMissingPrefix  ViolationCode = "missing_prefix"
+	ViolationSentenceCount  ViolationCode = "sentence_count"
+	ViolationSentenceEnding ViolationCode = "sentence_ending"
+)
+
+// Violation は規則に反した箇所 1 件。
+type Violation struct {
+	Code ViolationCode
+	// Sentence は違反した文の番号（1 始まり）。文単位でない違反では 0
+	Sentence int
+	// Detail は見つかった禁止語や期待した値など、理由を組み立てるための補足
+	Detail string
+}
+
+// Message は運用者向けの理由を返す。draw の公開不可の理由として保存する。
+func (v Violation) Message() string {
+	switch v.Code {
+	case ViolationEmpty:
+		return "整形結果が空です"
+	case ViolationTooShort:
+		return fmt.Sprintf("整形結果が短すぎます（%s 文字以上）", v.Detail)
+	case ViolationTooLong:
+		return fmt.Sprintf("整形結果が長すぎます（%s 文字以下）", v.Detail)
+	case ViolationForbiddenWord:
+		return fmt.Sprintf("不適切な語句(%s)が含まれています", v.Detail)
+	case ViolationURL:
+		return "URL は含めないでください"
+	case ViolationMissingPrefix:
+		return fmt.Sprintf("冒頭は「%s」で始めてください", v.Detail)
+	case ViolationSentenceCount:
+		return fmt.Sprintf("お告げは%s文構成で書いてください", v.Detail)
+	case ViolationSentenceEnding:
+		return fmt.Sprintf("%d文目は「〜%s」で終えてください", v.Sentence, v.Detail)
+	default:
+		return string(v.Code)
+	}
+}
+
+// Violations は 1 つのお告げに見つかった違反の一覧。
+type Violations []Violation
+
+// Reason は違反の理由を見つかった順に連ねて返す。違反が無ければ空文字。
+func (vs Violations) Reason() string {
+	messages := make([]string, 0, len(vs))
+	for _, v := range vs {
+		messages = append(messages, v.Message())
+	}
+	return strings.Join(messages, " / ")
+}
+
+// Codes は違反の種類を見つかった順に返す。同じ種類は 1 度だけ含める。
+func (vs Violations) Codes() []ViolationCode {
+	codes := make([]ViolationCode, 0, len(vs))
+	for _, v := range vs {
+		if !slices.Contains(codes, v.Code) {
+			codes = append(codes, v.Code)
+		}
+	}
+	return codes
+}
+
+// Rules はお告げが満たすべき規則。数値の 0 と空の値はその検査をしないことを表す。
+type Rules struct {
+	// MinLength と MaxLength は冒頭の決まり文句を含めた文字数（rune 数）の範囲
+	MinLength int
+	MaxLength int
+	// Prefix はお告げの冒頭に置く決まり文句
+	Prefix string
+	// SentenceCount は決まり文句を除いた本文の文の数
+	SentenceCount int
+	// SentenceEndings は各文の末尾（句点の直前）として認める語。どれか 1 つで終わればよい
+	SentenceEndings []string
+	// ForbiddenWords は含めてはいけない語。大文字小文字は区別しない
+	ForbiddenWords []string
+	// AllowURLs が false なら http:// と https:// を含むお告げを拒否する
+	AllowURLs bool
+}
+
+// DefaultRules はきらくじのプロンプトに合わせた既定の規則を返す。
+func DefaultRules() Rules {
+	return Rules{
+		MinLength:       30,
+		MaxLength:       150,
+		Prefix:          "今日のきらくじ:",
+		SentenceCount:   3,
+		SentenceEndings: []string{"ます"},
+		ForbiddenWords:  []string{"kill", "suicide", "die"},
+	}
+}
+
+// Validator は LLM の提供元によらず、整形されたお告げを同じ規則で検査する。
+type Validator struct {
+	rules Rules
+}
+
+// NewValidator は規則を点検して Validator を生成する。負の値や下限が上限を超える規則は ErrInvalidRules。
+func NewValidator(rules Rules) (*Validator, error) {
+	if rules.MinLength < 0 || rules.MaxLength < 0 || rules.SentenceCount < 0 {
+		return nil, fmt.Errorf("%w: negative bound", ErrInvalidRules)
+	}
+	if rules.MaxLength > 0 && rules.MinLength > rules.MaxLength {
+		return nil, fmt.Errorf("%w: min length %d exceeds max length %d", ErrInvalidRules, rules.MinLength, rules.MaxLength)
+	}
+	rules.SentenceEndings = nonEmpty(rules.SentenceEndings)
+	rules.ForbiddenWords = nonEmpty(rules.ForbiddenWords)
+	return &Validator{rules: rules}, nil
+}
+
+// Rules は検査に使う規則の写しを返す。
+func (v *Validator) Rules() Rules {
+	rules := v.rules
+	rules.SentenceEndings = slices.Clone(rules.SentenceEndings)
+	rules.ForbiddenWords = slices.Clone(rules.ForbiddenWords)
+	return rules
+}
+
+// Validate は Normalize 済みのお告げを検査し、見つかった違反をすべて返す。違反が無ければ空。
+func (v *Validator) Validate(text string) Violations {
+	if text == "" {
+		return Violations{{Code: ViolationEmpty}}
+	}
+
+	var violations Violations
+	length := utf8.RuneCountInString(text)
+	if v.rules.MinLength > 0 && length < v.rules.MinLength {
+		violations = append(violations, Violation{Code: ViolationTooShort, Detail: fmt.Sprint(v.rules.MinLength)})
+	}
+	if v.rules.MaxLength > 0 && length > v.rules.MaxLength {
+		violations = append(violations, Violation{Code: ViolationTooLong, Detail: fmt.Sprint(v.rules.MaxLength)})
+	}
+
+	lower := strings.ToLower(text)
+	for _, word := range v.rules.ForbiddenWords {
+		if strings.Contains(lower, strings.ToLower(word)) {
+			violations = append(violations, Violation{Code: ViolationForbiddenWord, Detail: word})
+		}
+	}
+	if !v.rules.AllowURLs && (strings.Contains(lower, "http://") || strings.Contains(lower, "https://")) {
+		violations = append(violations, Violation{Code: ViolationURL})
+	}
+
+	body := text
+	if v.rules.Prefix != "" {
+		trimmed, ok := strings.CutPrefix(text, v.rules.Prefix)
+		if !ok {
+			violations = append(violations, Violation{Code: ViolationMissingPrefix, Detail: v.rules.Prefix})
+		}
+		body = trimmed
+	}
+	return append(violations, v.validateSentences(body)...)
+}
+
+// validateSentences は本文の文の数と各文の末尾を検査する。
+func (v *Validator) validateSentences(body string) Violations {
+	sentences := SplitSentences(body)
+	if v.rules.SentenceCount > 0 && len(sentences) != v.rules.SentenceCount {
+		// 文の数が違えば末尾の番号もずれるため、語尾までは見ない
+		return Violations{{Code: ViolationSentenceCount, Detail: fmt.Sprint(v.rules.SentenceCount)}}
+	}
+	if len(v.rules.SentenceEndings) == 0 {
+		return nil
+	}
+	var violations Violations
+	for idx, sentence := range sentences {
+		if !hasAnySuffix(sentence, v.rules.SentenceEndings) {
+			violations = append(violations, Violation{
+				Code:     ViolationSentenceEnding,
+				Sentence: idx + 1,
+				Detail:   strings.Join(v.rules.SentenceEndings, "」「〜"),
+			})
+		}
+	}
+	return violations
+}
+
+// Normalize は改行を取り除き前後の空白を詰めて、検査と保存に使う 1 行のお告げにする。
+func Normalize(text string) string {
+	noCR := strings.ReplaceAll(text, "\r", "")
+	noLF := strings.ReplaceAll(noCR, "\n", "")
+	return strings.TrimSpace(noLF)
+}
+
+// SplitSentences は句点で区切った文を、空の要素を除いて返す。
+func SplitSentences(body string) []string {
+	raw := strings.Split(body, sentenceTerminator)
+	sentences := make([]string, 0, len(raw))
+	for _, part := range raw {
+		trimmed := strings.TrimSpace(part)
+		if trimmed == "" {
+			continue
+		}
+		sentences = append(sentences, trimmed)
+	}
+	return sentences
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	return slices.ContainsFunc(suffixes, func(suffix string) bool { return strings.HasSuffix(s, suffix) })
+}
+
+// nonEmpty は前後の空白を除いた空でない要素だけを新しいスライスで返す。
+func nonEmpty(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
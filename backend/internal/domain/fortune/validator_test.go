@@ -0,0 +1,129 @@
+package fortune
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+const (
+	fortuneValid         = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+	fortuneShort         = "今日のきらくじ: つらいです。待ちます。笑えます。"
+	fortuneLong          = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続き、ため息が増えています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進め、証拠の順序も丁寧に整えます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒される余韻がしばらく長く残ります。"
+	fortuneKeyword       = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、KILLという語がちらついています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付けます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+	fortuneURL           = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、https://example.comの通知が気になります。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付けます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+	fortuneMissingPrefix = "心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も少し続いて眠りも浅くなっています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めます。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+	fortuneTwoSentences  = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず黙々と進め、返信の時間も決め、痕跡を整え、最後は少し笑えて癒されます。"
+	fortuneBadEnding     = "今日のきらくじ: 心の奥がじっと湿って、気になる言葉が何度も頭に残り、寝不足も続いています。ひとつずつ事実を確認し、記録を残して、反応を待ちながら淡々と片付け、手順を崩さず進めよう。最後には執念が効いて小さな勝ちを拾えたと笑え、ふっと癒されます。"
+)
+
+func TestValidator_DefaultRules(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewValidator(DefaultRules())
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+
+	cases := []struct {
+		name string
+		text string
+		want []ViolationCode
+	}{
+		{name: "規則を満たす", text: fortuneValid},
+		{name: "空", text: "", want: []ViolationCode{ViolationEmpty}},
+		{name: "短すぎる（語尾の違反もあわせて返す）", text: fortuneShort, want: []ViolationCode{ViolationTooShort, ViolationSentenceEnding}},
+		{name: "長すぎる", text: fortuneLong, want: []ViolationCode{ViolationTooLong}},
+		{name: "禁止語は大文字でも拒否する", text: fortuneKeyword, want: []ViolationCode{ViolationForbiddenWord}},
+		{name: "URL", text: fortuneURL, want: []ViolationCode{ViolationURL}},
+		{name: "冒頭の決まり文句が無い", text: fortuneMissingPrefix, want: []ViolationCode{ViolationMissingPrefix}},
+		{name: "文の数が違う", text: fortuneTwoSentences, want: []ViolationCode{ViolationSentenceCount}},
+		{name: "語尾が違う", text: fortuneBadEnding, want: []ViolationCode{ViolationSentenceEnding}},
+	}
+	for _, tc := range cases {
+		got := v.Validate(tc.text)
+		if !slices.Equal(got.Codes(), tc.want) {
+			t.Fatalf("%s: unexpected violations: %+v", tc.name, got)
+		}
+	}
+
+	ending := v.Validate(fortuneBadEnding)[0]
+	if ending.Sentence != 2 || ending.Message() != "2文目は「〜ます」で終えてください" {
+		t.Fatalf("unexpected ending violation: %+v %q", ending, ending.Message())
+	}
+	if got := v.Validate(fortuneKeyword).Reason(); got != "不適切な語句(kill)が含まれています" {
+		t.Fatalf("unexpected reason: %q", got)
+	}
+}
+
+func TestValidator_ReportsEveryViolation(t *testing.T) {
+	t.Parallel()
+
+	v, _ := NewValidator(DefaultRules())
+	got := v.Validate("die。https://example.com")
+	want := []ViolationCode{ViolationTooShort, ViolationForbiddenWord, ViolationURL, ViolationMissingPrefix, ViolationSentenceCount}
+	if !slices.Equal(got.Codes(), want) {
+		t.Fatalf("unexpected violations: %+v", got)
+	}
+	if got.Reason() == "" {
+		t.Fatal("expected a combined reason")
+	}
+}
+
+func TestValidator_CustomRules(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewValidator(Rules{
+		MaxLength:       40,
+		Prefix:          "お告げ:",
+		SentenceCount:   2,
+		SentenceEndings: []string{"です", " ます ", ""},
+		AllowURLs:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewValidator() error = %v", err)
+	}
+	if got := v.Validate("お告げ: 明日は晴れです。https://example.com を見ます。"); len(got) != 0 {
+		t.Fatalf("expected no violations, got %+v", got)
+	}
+	got := v.Validate("お告げ: 明日は晴れだ。傘を持ちます。")
+	if !slices.Equal(got.Codes(), []ViolationCode{ViolationSentenceEnding}) || got[0].Message() != "1文目は「〜です」「〜ます」で終えてください" {
+		t.Fatalf("unexpected violations: %+v %q", got, got.Reason())
+	}
+	// 0 と空の規則は検査しない
+	unlimited, _ := NewValidator(Rules{})
+	if got := unlimited.Validate("短い"); len(got) != 0 {
+		t.Fatalf("empty rules should accept any text except URLs, got %+v", got)
+	}
+
+	// 返した規則を書き換えても検査には影響しない
+	rules := v.Rules()
+	rules.SentenceEndings[0] = "だ"
+	if got := v.Validate("お告げ: 明日は晴れだ。傘を持ちます。"); len(got) == 0 {
+		t.Fatal("rules should not be shared with the caller")
+	}
+}
+
+func TestNewValidator_InvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for _, rules := range []Rules{
+		{MinLength: 100, MaxLength: 50},
+		{MinLength: -1},
+		{SentenceCount: -3},
+	} {
+		if _, err := NewValidator(rules); !errors.Is(err, ErrInvalidRules) {
+			t.Fatalf("expected ErrInvalidRules for %+v, got %v", rules, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	raw := "今日の闇みくじ:\r\n 一文目です。\n 二文目です。\n 三文目です。 "
+	want := "今日の闇みくじ: 一文目です。 二文目です。 三文目です。"
+	if got := Normalize(raw); got != want {
+		t.Fatalf("Normalize mismatch\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -1,10 +1,15 @@
 package post
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 type (
 	// 闇投稿を一意に識別する ID。
 	DarkPostID string
+	// 投稿した匿名クライアントを識別する ID。空なら投稿者不明として扱う。
+	ClientID string
 	// 整形前本文
 	DarkContent string
 	// 闇投稿の状態
@@ -13,9 +18,31 @@ type (
 
 const (
 	StatusPending Status = "pending"
-	StatusReady   Status = "ready"
+	// StatusFormatting はワーカーが整形・検証している途中の状態。
+	StatusFormatting Status = "formatting"
+	StatusReady      Status = "ready"
+	// StatusRejected は検証で公開不可と判定された状態。管理者の承認でのみ ready へ移る。
+	StatusRejected Status = "rejected"
+	// StatusFailed は整形の再試行上限に達した状態。隔離ジョブの再投入でのみ pending へ戻る。
+	StatusFailed Status = "failed"
+	// StatusArchived は公開を終えて一覧から外した状態。
+	StatusArchived Status = "archived"
+	// StatusDeleted は論理削除された終端状態。
+	StatusDeleted Status = "deleted"
 )
 
+// transitions は状態ごとに遷移を許可する行き先の一覧。ここに無い遷移はすべて拒否する。
+var transitions = map[Status][]Status{
+	StatusPending:    {StatusFormatting, StatusReady, StatusRejected, StatusFailed, StatusDeleted},
+	StatusFormatting: {StatusPending, StatusReady, StatusRejected, StatusFailed, StatusDeleted},
+	// ready <-> rejected は管理者が判定を覆す場合に使う
+	StatusReady:    {StatusRejected, StatusArchived, StatusDeleted},
+	StatusRejected: {StatusReady, StatusDeleted},
+	StatusFailed:   {StatusPending, StatusDeleted},
+	StatusArchived: {StatusDeleted},
+	StatusDeleted:  {},
+}
+
 var (
 	// ErrEmptyContent は投稿内容が空の場合に返される。
 	ErrEmptyContent = errors.New("post: content is empty")
@@ -30,6 +57,9 @@ type Post struct {
 	id      DarkPostID
 	content DarkContent
 	status  Status
+	author  ClientID
+	// 保存された日時。保存前はゼロ値
+	createdAt time.Time
 }
 
 // New は新しい闇投稿を pending 状態で作成する。
@@ -76,21 +106,102 @@ func (p *Post) Status() Status {
 	return p.status
 }
 
+// Author は投稿した匿名クライアントの ID を返す。
+func (p *Post) Author() ClientID {
+	return p.author
+}
+
+// AssignAuthor は作成時・復元時に投稿者を記録する。
+func (p *Post) AssignAuthor(author ClientID) {
+	p.author = author
+}
+
+// CreatedAt は保存された日時を返す。保存前や不明な場合はゼロ値。
+func (p *Post) CreatedAt() time.Time {
+	return p.createdAt
+}
+
+// RestoreCreatedAt はリポジトリが保存した日時を復元する。
+func (p *Post) RestoreCreatedAt(t time.Time) {
+	p.createdAt = t
+}
+
 // IsReady は ready 状態かどうかを返す。
 func (p *Post) IsReady() bool {
 	return p.status == StatusReady
 }
 
-// MarkReady は pending -> ready の状態遷移のみを許可する。
+// MarkFormatting は pending -> formatting の状態遷移を行う。
+func (p *Post) MarkFormatting() error {
+	return p.transition(StatusFormatting)
+}
+
+// MarkReady は pending / formatting -> ready の状態遷移を行う。管理者の承認では rejected からも移れる。
 func (p *Post) MarkReady() error {
-	if p.status != StatusPending {
+	return p.transition(StatusReady)
+}
+
+// MarkRejected は pending / formatting -> rejected の状態遷移を行う。管理者の却下では ready からも移れる。
+func (p *Post) MarkRejected() error {
+	return p.transition(StatusRejected)
+}
+
+// MarkFailed は pending / formatting -> failed の状態遷移を行う。
+func (p *Post) MarkFailed() error {
+	return p.transition(StatusFailed)
+}
+
+// Reopen は整形をやり直すため failed / formatting -> pending の状態遷移を行う。
+func (p *Post) Reopen() error {
+	return p.transition(StatusPending)
+}
+
+// MarkArchived は ready -> archived の状態遷移を行う。
+func (p *Post) MarkArchived() error {
+	return p.transition(StatusArchived)
+}
+
+// MarkDeleted は deleted 以外 -> deleted の状態遷移を行う。
+func (p *Post) MarkDeleted() error {
+	return p.transition(StatusDeleted)
+}
+
+// CanTransitionTo は遷移表に従って next へ移れるかを返す。
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AwaitsFormat は整形の結果を書き込める状態（pending / formatting）かを返す。
+// 整形中に削除や管理者の判断で状態が変わった投稿へ、ワーカーの結果を上書きしないために使う。
+func (s Status) AwaitsFormat() bool {
+	return s == StatusPending || s == StatusFormatting
+}
+
+// transition は遷移表で許可されている場合のみ状態を変える。
+func (p *Post) transition(next Status) error {
+	if !p.status.CanTransitionTo(next) {
 		return ErrInvalidStatusTransition
 	}
 
-	p.status = StatusReady
+	p.status = next
 	return nil
 }
 
+// ParseStatus は文字列を投稿の状態として解釈する。遷移表に無い値は ErrInvalidStatus。
+func ParseStatus(raw string) (Status, error) {
+	s := Status(raw)
+	if !s.isValid() {
+		return "", ErrInvalidStatus
+	}
+	return s, nil
+}
+
 func (s Status) isValid() bool {
-	return s == StatusPending || s == StatusReady
+	_, ok := transitions[s]
+	return ok
 }
@@ -59,6 +59,65 @@ func TestMarkReady_InvalidTransition(t *testing.T) {
 	}
 }
 
+func TestMarkRejected(t *testing.T) {
+	t.Parallel()
+
+	post, err := New(DarkPostID("id"), DarkContent("闇"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := post.MarkRejected(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if post.Status() != StatusRejected {
+		t.Fatalf("expected rejected but got %s", post.Status())
+	}
+	// 整形のやり直しには戻せない
+	if err := post.Reopen(); err != ErrInvalidStatusTransition {
+		t.Fatalf("expected ErrInvalidStatusTransition but got %v", err)
+	}
+}
+
+func TestMarkFailedAndReopen(t *testing.T) {
+	t.Parallel()
+
+	post, err := New(DarkPostID("id"), DarkContent("闇"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := post.MarkFailed(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if post.Status() != StatusFailed {
+		t.Fatalf("expected failed but got %s", post.Status())
+	}
+	if err := post.MarkFailed(); err != ErrInvalidStatusTransition {
+		t.Fatalf("expected ErrInvalidStatusTransition but got %v", err)
+	}
+
+	if err := post.Reopen(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if post.Status() != StatusPending {
+		t.Fatalf("expected pending but got %s", post.Status())
+	}
+}
+
+func TestReopen_InvalidTransition(t *testing.T) {
+	t.Parallel()
+
+	post, err := Restore(DarkPostID("id"), DarkContent("闇"), StatusRejected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := post.Reopen(); err != ErrInvalidStatusTransition {
+		t.Fatalf("expected ErrInvalidStatusTransition but got %v", err)
+	}
+}
+
 func TestRestore_InvalidStatus(t *testing.T) {
 	t.Parallel()
 
@@ -66,3 +125,109 @@ func TestRestore_InvalidStatus(t *testing.T) {
 		t.Fatalf("expected ErrInvalidStatus but got %v", err)
 	}
 }
+
+func TestTransitionTable(t *testing.T) {
+	t.Parallel()
+
+	all := []Status{StatusPending, StatusFormatting, StatusReady, StatusRejected, StatusFailed, StatusArchived, StatusDeleted}
+	allowed := map[Status]map[Status]bool{
+		StatusPending:    {StatusFormatting: true, StatusReady: true, StatusRejected: true, StatusFailed: true, StatusDeleted: true},
+		StatusFormatting: {StatusPending: true, StatusReady: true, StatusRejected: true, StatusFailed: true, StatusDeleted: true},
+		StatusReady:      {StatusRejected: true, StatusArchived: true, StatusDeleted: true},
+		StatusRejected:   {StatusReady: true, StatusDeleted: true},
+		StatusFailed:     {StatusPending: true, StatusDeleted: true},
+		StatusArchived:   {StatusDeleted: true},
+		StatusDeleted:    {},
+	}
+
+	for _, from := range all {
+		for _, to := range all {
+			if got := from.CanTransitionTo(to); got != allowed[from][to] {
+				t.Fatalf("CanTransitionTo(%s -> %s) = %v, want %v", from, to, got, allowed[from][to])
+			}
+		}
+	}
+}
+
+func TestMarkMethods(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		from Status
+		mark func(*Post) error
+		want Status
+	}{
+		{name: "formatting", from: StatusPending, mark: (*Post).MarkFormatting, want: StatusFormatting},
+		{name: "ready from formatting", from: StatusFormatting, mark: (*Post).MarkReady, want: StatusReady},
+		{name: "rejected from formatting", from: StatusFormatting, mark: (*Post).MarkRejected, want: StatusRejected},
+		{name: "failed from formatting", from: StatusFormatting, mark: (*Post).MarkFailed, want: StatusFailed},
+		{name: "reopen formatting", from: StatusFormatting, mark: (*Post).Reopen, want: StatusPending},
+		{name: "archived", from: StatusReady, mark: (*Post).MarkArchived, want: StatusArchived},
+		{name: "approve rejected", from: StatusRejected, mark: (*Post).MarkReady, want: StatusReady},
+		{name: "reject ready", from: StatusReady, mark: (*Post).MarkRejected, want: StatusRejected},
+		{name: "deleted from archived", from: StatusArchived, mark: (*Post).MarkDeleted, want: StatusDeleted},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			post, err := Restore(DarkPostID("id"), DarkContent("闇"), tc.from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tc.mark(post); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if post.Status() != tc.want {
+				t.Fatalf("expected %s but got %s", tc.want, post.Status())
+			}
+		})
+	}
+}
+
+func TestMarkMethods_InvalidTransition(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		from Status
+		mark func(*Post) error
+	}{
+		{name: "formatting twice", from: StatusFormatting, mark: (*Post).MarkFormatting},
+		{name: "archive pending", from: StatusPending, mark: (*Post).MarkArchived},
+		{name: "delete twice", from: StatusDeleted, mark: (*Post).MarkDeleted},
+		{name: "reopen ready", from: StatusReady, mark: (*Post).Reopen},
+		{name: "approve archived", from: StatusArchived, mark: (*Post).MarkReady},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			post, err := Restore(DarkPostID("id"), DarkContent("闇"), tc.from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tc.mark(post); err != ErrInvalidStatusTransition {
+				t.Fatalf("expected ErrInvalidStatusTransition but got %v", err)
+			}
+			if post.Status() != tc.from {
+				t.Fatalf("status should not change on invalid transition, got %s", post.Status())
+			}
+		})
+	}
+}
+
+func TestStatusAwaitsFormat(t *testing.T) {
+	for status, want := range map[Status]bool{
+		StatusPending:    true,
+		StatusFormatting: true,
+		StatusReady:      false,
+		StatusRejected:   false,
+		StatusFailed:     false,
+		StatusArchived:   false,
+		StatusDeleted:    false,
+	} {
+		if got := status.AwaitsFormat(); got != want {
+			t.Fatalf("%s.AwaitsFormat() = %v, want %v", status, got, want)
+		}
+	}
+}
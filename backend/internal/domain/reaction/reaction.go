@@ -0,0 +1,102 @@
+package reaction
+
+import (
+	"errors"
+	"strings"
+
+	"backend/internal/domain/post"
+)
+
+var (
+	// ErrInvalidKind は定義されていないリアクションを受け取った際に返される。
+	ErrInvalidKind = errors.New("reaction: invalid kind")
+	// ErrEmptyPostID は対象の Post ID が空の場合に返される。
+	ErrEmptyPostID = errors.New("reaction: post id is empty")
+)
+
+// Kind はおみくじへのリアクションの種類。
+type Kind string
+
+// Kind の種類
+const (
+	// KindSaved は「救われた」。
+	KindSaved Kind = "saved"
+	// KindLaughed は「笑った」。
+	KindLaughed Kind = "laughed"
+	// KindPierced は「刺さった」。
+	KindPierced Kind = "pierced"
+)
+
+// Kinds は定義済みのリアクションを表示順に返す。
+func Kinds() []Kind {
+	return []Kind{KindSaved, KindLaughed, KindPierced}
+}
+
+// ParseKind は文字列をリアクションの種類へ変換する。大文字小文字と前後の空白は無視する。
+func ParseKind(s string) (Kind, error) {
+	k := Kind(strings.ToLower(strings.TrimSpace(s)))
+	if !k.IsValid() {
+		return "", ErrInvalidKind
+	}
+	return k, nil
+}
+
+// IsValid は定義済みのリアクションかを返す。
+func (k Kind) IsValid() bool {
+	return k == KindSaved || k == KindLaughed || k == KindPierced
+}
+
+// Reaction はおみくじ 1 件へのリアクション 1 回を表す。
+type Reaction struct {
+	postID post.DarkPostID
+	kind   Kind
+	client post.ClientID
+}
+
+// New はおみくじの Post ID と種類、リアクションした匿名クライアントから Reaction を生成する。
+// client が空の場合は同じ人の重複を見分けられない。
+func New(postID post.DarkPostID, kind Kind, client post.ClientID) (*Reaction, error) {
+	if postID == "" {
+		return nil, ErrEmptyPostID
+	}
+	if !kind.IsValid() {
+		return nil, ErrInvalidKind
+	}
+	return &Reaction{postID: postID, kind: kind, client: client}, nil
+}
+
+// PostID はリアクション先のおみくじの Post ID を返す。
+func (r *Reaction) PostID() post.DarkPostID {
+	return r.postID
+}
+
+// Kind はリアクションの種類を返す。
+func (r *Reaction) Kind() Kind {
+	return r.kind
+}
+
+// Client はリアクションした匿名クライアントを返す。不明なら空文字。
+func (r *Reaction) Client() post.ClientID {
+	return r.client
+}
+
+// Counts はおみくじ 1 件のリアクションを種類ごとに集計したもの。
+type Counts map[Kind]int
+
+// NewCounts はすべての種類を 0 で埋めた Counts を返す。
+func NewCounts() Counts {
+	c := make(Counts, len(Kinds()))
+	for _, k := range Kinds() {
+		c[k] = 0
+	}
+	return c
+}
+
+// Total はすべての種類の合計を返す。
+func (c Counts) Total() int {
+	total := 0
+	for _, n := range c {
+		total += n
+	}
+	return total
+}
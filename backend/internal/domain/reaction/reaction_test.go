@@ -0,0 +1,49 @@
+package reaction
+
+import "testing"
+
+func TestParseKind(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"saved", " Laughed ", "PIERCED"} {
+		if _, err := ParseKind(raw); err != nil {
+			t.Fatalf("expected %q to be valid: %v", raw, err)
+		}
+	}
+	if _, err := ParseKind("angry"); err != ErrInvalidKind {
+		t.Fatalf("expected ErrInvalidKind but got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	r, err := New("post-id", KindSaved, "client-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.PostID() != "post-id" || r.Kind() != KindSaved || r.Client() != "client-1" {
+		t.Fatalf("unexpected reaction: %+v", r)
+	}
+
+	if _, err := New("", KindSaved, ""); err != ErrEmptyPostID {
+		t.Fatalf("expected ErrEmptyPostID but got %v", err)
+	}
+	if _, err := New("post-id", Kind("angry"), ""); err != ErrInvalidKind {
+		t.Fatalf("expected ErrInvalidKind but got %v", err)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	t.Parallel()
+
+	c := NewCounts()
+	if len(c) != len(Kinds()) || c.Total() != 0 {
+		t.Fatalf("expected zero counts for every kind, got %v", c)
+	}
+	c[KindSaved] += 2
+	c[KindPierced]++
+	if c.Total() != 3 {
+		t.Fatalf("expected total 3 but got %d", c.Total())
+	}
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"unicode/utf8"
+
+	"backend/internal/domain/post"
+)
+
+// MaxNoteLength は通報に添えられる補足の最大文字数。
+const MaxNoteLength = 200
+
+var (
+	// ErrInvalidReason は定義されていない通報理由を受け取った際に返される。
+	ErrInvalidReason = errors.New("report: invalid reason")
+	// ErrEmptyPostID は対象の Post ID が空の場合に返される。
+	ErrEmptyPostID = errors.New("report: post id is empty")
+	// ErrNoteTooLong は補足が MaxNoteLength 文字を超えた場合に返される。
+	ErrNoteTooLong = errors.New("report: note is too long")
+)
+
+// Reason は公開中のおみくじを通報する理由。
+type Reason string
+
+// Reason の種類
+const (
+	// ReasonAbusive は誹謗中傷や差別的な表現。
+	ReasonAbusive Reason = "abusive"
+	// ReasonPersonalInfo は個人を特定できる情報。
+	ReasonPersonalInfo Reason = "personal_info"
+	// ReasonSelfHarm は自傷や危険な行為を促す表現。
+	ReasonSelfHarm Reason = "self_harm"
+	// ReasonSpam は宣伝や無意味な内容。
+	ReasonSpam Reason = "spam"
+	// ReasonOther はその他。
+	ReasonOther Reason = "other"
+)
+
+// Reasons は定義済みの通報理由を表示順に返す。
+func Reasons() []Reason {
+	return []Reason{ReasonAbusive, ReasonPersonalInfo, ReasonSelfHarm, ReasonSpam, ReasonOther}
+}
+
+// ParseReason は文字列を通報理由へ変換する。大文字小文字と前後の空白は無視する。
+func ParseReason(s string) (Reason, error) {
+	r := Reason(strings.ToLower(strings.TrimSpace(s)))
+	if !r.IsValid() {
+		return "", ErrInvalidReason
+	}
+	return r, nil
+}
+
+// IsValid は定義済みの通報理由かを返す。
+func (r Reason) IsValid() bool {
+	switch r {
+	case ReasonAbusive, ReasonPersonalInfo, ReasonSelfHarm, ReasonSpam, ReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// Report は公開中のおみくじ 1 件への通報 1 回を表す。
+type Report struct {
+	postID post.DarkPostID
+	reason Reason
+	note   string
+	client post.ClientID
+	origin Origin
+}
+
+// Origin は通報が届いたネットワーク。クライアント ID は作り直せるため、自動の取り下げはこちらの数で判断する。
+type Origin string
+
+// OriginFromIP は接続元の IP アドレスから Origin を返す。IPv6 は同じ回線から容易に増やせるため /64 へまとめる。
+// 解釈できなければ空文字を返す。
+func OriginFromIP(ip string) Origin {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		return Origin(addr.String())
+	}
+	prefix, err := addr.Prefix(64)
+	if err != nil {
+		return ""
+	}
+	return Origin(prefix.String())
+}
+
+// New はおみくじの Post ID と理由、補足、通報した匿名クライアントと通報元のネットワークから Report を生成する。
+// client が空の場合は同じ人の重複を見分けられず、origin が空の場合は自動の取り下げの件数に数えない。
+func New(postID post.DarkPostID, reason Reason, note string, client post.ClientID, origin Origin) (*Report, error) {
+	if postID == "" {
+		return nil, ErrEmptyPostID
+	}
+	if !reason.IsValid() {
+		return nil, ErrInvalidReason
+	}
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return nil, ErrNoteTooLong
+	}
+	return &Report{postID: postID, reason: reason, note: note, client: client, origin: origin}, nil
+}
+
+// PostID は通報先のおみくじの Post ID を返す。
+func (r *Report) PostID() post.DarkPostID {
+	return r.postID
+}
+
+// Reason は通報理由を返す。
+func (r *Report) Reason() Reason {
+	return r.reason
+}
+
+// Note は通報に添えられた補足を返す。無ければ空文字。
+func (r *Report) Note() string {
+	return r.note
+}
+
+// Client は通報した匿名クライアントを返す。不明なら空文字。
+func (r *Report) Client() post.ClientID {
+	return r.client
+}
+
+// Origin は通報が届いたネットワークを返す。不明なら空文字。
+func (r *Report) Origin() Origin {
+	return r.origin
+}
+
+// Resolution は管理者が通報を確認した結果。
+type Resolution string
+
+// Resolution の種類
+const (
+	// ResolutionConfirmed は通報を認めて公開を止めたままにした。
+	ResolutionConfirmed Resolution = "confirmed"
+	// ResolutionRestored は通報を退けて公開へ戻した。
+	ResolutionRestored Resolution = "restored"
+)
+
+// IsValid は定義済みの確認結果かを返す。
+func (r Resolution) IsValid() bool {
+	return r == ResolutionConfirmed || r == ResolutionRestored
+}
@@ -0,0 +1,64 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReason(t *testing.T) {
+	t.Parallel()
+
+	for _, raw := range []string{"abusive", " Personal_Info ", "SPAM"} {
+		if _, err := ParseReason(raw); err != nil {
+			t.Fatalf("expected %q to be valid: %v", raw, err)
+		}
+	}
+	if _, err := ParseReason("boring"); err != ErrInvalidReason {
+		t.Fatalf("expected ErrInvalidReason but got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	r, err := New("post-id", ReasonSelfHarm, "  危ない  ", "client-1", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.PostID() != "post-id" || r.Reason() != ReasonSelfHarm || r.Note() != "危ない" || r.Client() != "client-1" || r.Origin() != "192.0.2.1" {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+
+	if _, err := New("", ReasonSpam, "", "", ""); err != ErrEmptyPostID {
+		t.Fatalf("expected ErrEmptyPostID but got %v", err)
+	}
+	if _, err := New("post-id", Reason("boring"), "", "", ""); err != ErrInvalidReason {
+		t.Fatalf("expected ErrInvalidReason but got %v", err)
+	}
+	// 文字数は byte ではなく rune で数える
+	if _, err := New("post-id", ReasonOther, strings.Repeat("闇", MaxNoteLength), "", ""); err != nil {
+		t.Fatalf("note of %d runes should be accepted: %v", MaxNoteLength, err)
+	}
+	if _, err := New("post-id", ReasonOther, strings.Repeat("闇", MaxNoteLength+1), "", ""); err != ErrNoteTooLong {
+		t.Fatalf("expected ErrNoteTooLong but got %v", err)
+	}
+}
+
+func TestOriginFromIP(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]Origin{
+		"192.0.2.10":           "192.0.2.10",
+		" 192.0.2.10 ":         "192.0.2.10",
+		"::ffff:192.0.2.10":    "192.0.2.10",
+		"2001:db8:1:2:3:4:5:6": "2001:db8:1:2::/64",
+		"2001:db8:1:2:ffff::1": "2001:db8:1:2::/64",
+		"not-an-ip":            "",
+		"":                     "",
+	}
+	for ip, want := range cases {
+		if got := OriginFromIP(ip); got != want {
+			t.Fatalf("OriginFromIP(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package idgen
+
+import "backend/internal/domain/post"
+
+/**
+ * 闇投稿 ID を払い出す契約。
+ * NewPostID: 推測や衝突のしにくい新しい ID を返す
+ */
+type PostIDGenerator interface {
+	NewPostID() (post.DarkPostID, error)
+}
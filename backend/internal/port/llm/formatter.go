@@ -30,12 +30,14 @@ type FormatRequest struct {
  * @param FormattedContent 整形後の本文
  * @param Status 整形結果の状態
  * @param ValidationReason 検証理由（Status が Rejected の場合にセットされる）
+ * @param Provenance 整形した LLM の提供元・モデル・プロンプトの版・消費トークン数・所要時間（Format がセットし、Validate は引き継ぐ）
  */
 type FormatResult struct {
 	DarkPostID       post.DarkPostID
 	FormattedContent draw.FormattedContent
 	Status           draw.Status
 	ValidationReason string
+	Provenance       draw.Provenance
 }
 
 /**
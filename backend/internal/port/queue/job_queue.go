@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"errors"
+	"time"
 
 	"backend/internal/domain/post"
 )
@@ -11,13 +12,153 @@ var (
 	ErrJobAlreadyScheduled = errors.New("queue: 同一 ID のジョブがすでに存在します")
 	ErrQueueClosed         = errors.New("queue: ジョブキューが停止しました")
 	ErrContextClosed       = errors.New("queue: コンテキストが終了しました")
+	ErrJobNotLeased        = errors.New("queue: 自身が取得中のジョブではありません")
+	ErrJobDeadLettered     = errors.New("queue: 再試行上限に達したためジョブを隔離しました")
+	ErrDeadJobNotFound     = errors.New("queue: 隔離済みのジョブが見つかりません")
+	ErrJobNotFound         = errors.New("queue: ジョブが見つかりません")
+	ErrLeaseExpired        = errors.New("queue: リース期限内に処理が確定しませんでした")
 )
 
+/**
+ * 取り出した整形ジョブ 1 件のリース
+ * @param PostID 闇投稿 ID
+ * @param Token 取り出しのたびに払い出す識別子。期限切れ後に他の取り出しへ渡ったリースを確定させないために使う
+ */
+type Lease struct {
+	PostID post.DarkPostID
+	Token  string
+}
+
 /**
  * 闇投稿の整形ジョブを溜めたり取り出したりする契約。
+ * DequeueFormat: ジョブを削除せずリース付きで取得する。期限切れのリースは試行 1 回として数えて再取得可能にし、上限到達時は ErrLeaseExpired を理由に隔離する
+ * AckFormat: 処理完了したジョブを削除する（リースを失っていれば ErrJobNotLeased）
+ * NackFormat: 試行回数を増やさずにリースを手放し、すぐ再取得可能に戻す（リースを失っていれば ErrJobNotLeased）
+ * RetryFormat: 試行回数を 1 増やして待機時間後に再取得可能にする。上限到達時は隔離して ErrJobDeadLettered を返す
+ * Ack / Nack / Retry には DequeueFormat が返したリースを渡し、期限切れで取り直されたリースは ErrJobNotLeased になる
  */
 type JobQueue interface {
 	EnqueueFormat(ctx context.Context, postID post.DarkPostID) error
-	DequeueFormat(ctx context.Context) (post.DarkPostID, error)
+	DequeueFormat(ctx context.Context) (Lease, error)
+	AckFormat(ctx context.Context, lease Lease) error
+	NackFormat(ctx context.Context, lease Lease) error
+	RetryFormat(ctx context.Context, lease Lease, cause error) error
 	Close() error
 }
+
+/**
+ * 再試行上限に達して隔離された整形ジョブ
+ * @param PostID 闇投稿 ID
+ * @param Attempts 失敗した試行回数
+ * @param LastError 最後に失敗した理由
+ * @param FailedAt 隔離された時刻
+ */
+type DeadFormatJob struct {
+	PostID    post.DarkPostID
+	Attempts  int
+	LastError string
+	FailedAt  time.Time
+}
+
+/**
+ * 隔離された整形ジョブを運用者が確認・再投入するための契約。
+ * ListDeadFormat: 新しく隔離された順に最大 limit 件返す
+ * ReplayDeadFormat: 試行回数を戻して整形待ちへ再投入する（未存在時は ErrDeadJobNotFound）
+ */
+type DeadLetterQueue interface {
+	ListDeadFormat(ctx context.Context, limit int) ([]*DeadFormatJob, error)
+	ReplayDeadFormat(ctx context.Context, postID post.DarkPostID) error
+}
+
+/**
+ * リースの期限切れで隔離したジョブを知らせる契約。Retry を経ない隔離はワーカーから見えないため、この口で受け取る。
+ * NotifyExpiredDeadLetters: 期限切れで隔離するたびに呼ぶ関数を登録する（最後に登録した関数だけを呼ぶ）
+ */
+type ExpiredLeaseNotifier interface {
+	NotifyExpiredDeadLetters(fn func(ctx context.Context, job DeadFormatJob))
+}
+
+// 整形ジョブの現在の扱い
+type FormatJobState string
+
+const (
+	FormatJobPending  FormatJobState = "pending"
+	FormatJobLeased   FormatJobState = "leased"
+	FormatJobRetrying FormatJobState = "retrying"
+	FormatJobDead     FormatJobState = "dead"
+)
+
+/**
+ * 整形ジョブ 1 件の進み具合
+ * @param PostID 闇投稿 ID
+ * @param State 現在の扱い
+ * @param Attempts 失敗した試行回数
+ * @param NextAttemptAt 再試行待ちのときに再取得可能になる時刻
+ * @param LastError 最後に失敗した理由
+ */
+type FormatJobStatus struct {
+	PostID        post.DarkPostID
+	State         FormatJobState
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+}
+
+/**
+ * 投稿者へ進み具合を返すため、整形ジョブ 1 件の状態を参照する契約。
+ * InspectFormat: 処理待ち・処理中・再試行待ち・隔離済みのジョブを返す（完了済みや未登録は ErrJobNotFound）
+ */
+type JobInspector interface {
+	InspectFormat(ctx context.Context, postID post.DarkPostID) (*FormatJobStatus, error)
+}
+
+/**
+ * 整形ジョブの再試行方針
+ * @param MaxAttempts 隔離するまでに許す試行回数
+ * @param BaseDelay 1 回目の失敗後に待つ時間
+ * @param MaxDelay 待機時間の上限
+ */
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+/**
+ * 設定が無い場合に使う再試行方針を返す。
+ */
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   30 * time.Second,
+		MaxDelay:    30 * time.Minute,
+	}
+}
+
+/**
+ * attempts 回失敗した時点で隔離すべきかを返す。
+ */
+func (p RetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+/**
+ * attempts 回目の失敗後に待つ時間を指数的に伸ばして返す。
+ */
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts <= 0 || p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		// 上限を超えたらそれ以上は倍にしない（オーバーフロー防止も兼ねる）
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 0},
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 4, want: 5 * time.Second},
+		{attempts: 100, want: 5 * time.Second},
+	}
+	for _, tc := range cases {
+		if got := policy.Backoff(tc.attempts); got != tc.want {
+			t.Fatalf("Backoff(%d) = %v, want %v", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{MaxAttempts: 3}
+	if policy.Exhausted(2) {
+		t.Fatalf("2 attempts should not be exhausted")
+	}
+	if !policy.Exhausted(3) {
+		t.Fatalf("3 attempts should be exhausted")
+	}
+}
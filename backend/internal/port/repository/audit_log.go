@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+)
+
+/**
+ * 投稿ごとの出来事を追記だけで残す監査ログの契約
+ * Append: 出来事を 1 件追記し、記録した日時を e に反映する。記録済みの出来事は書き換えも削除もしない
+ * ListByPost: postID の出来事を記録された順に返す（無ければ空）
+ */
+type AuditLog interface {
+	Append(ctx context.Context, e *audit.Event) error
+	ListByPost(ctx context.Context, postID post.DarkPostID) ([]*audit.Event, error)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/domain/post"
+)
+
+var ErrEmptyClientID = errors.New("repository: クライアント ID が指定されていません")
+
+/**
+ * 匿名クライアントごとに最近引いたおみくじの履歴を保持するリポジトリの契約
+ * Recent: client が最近引いた闇投稿 ID を新しい順に最大 limit 件返す（履歴が無ければ空、client が空の場合は ErrEmptyClientID）
+ * Record: client の履歴の先頭に postID を加え、新しい順に keep 件だけ残す（すでに含まれていれば先頭へ移す）
+ */
+type DrawHistoryRepository interface {
+	Recent(ctx context.Context, client post.ClientID, limit int) ([]post.DarkPostID, error)
+	Record(ctx context.Context, client post.ClientID, postID post.DarkPostID, keep int) error
+}
+
+/**
+ * 履歴の先頭に postID を置き、重複を除いて keep 件までに切り詰めた新しいスライスを返す。
+ */
+func PushDrawHistory(history []post.DarkPostID, postID post.DarkPostID, keep int) []post.DarkPostID {
+	next := make([]post.DarkPostID, 0, len(history)+1)
+	next = append(next, postID)
+	for _, id := range history {
+		if len(next) >= keep {
+			break
+		}
+		if id != postID {
+			next = append(next, id)
+		}
+	}
+	return next
+}
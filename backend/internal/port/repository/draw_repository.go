@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
+	"slices"
 
 	"backend/internal/domain/draw"
 	"backend/internal/domain/post"
@@ -17,10 +19,69 @@ var (
  * おみくじ結果を扱うリポジトリの契約
  * Create: 新規保存（重複時は ErrDrawAlreadyExists）
  * GetByPostID: 闇投稿 ID から結果を取得（postID が空の場合、未存在時は ErrDrawNotFound）
+ * Update: 既存の結果の状態と理由を更新（未存在時は ErrDrawNotFound）。verified でなくなれば ListReady・PickRandom から外れる
  * ListReady: 公開可能（verified）なおみくじ結果一覧を返す。
+ * PickRandom: 公開可能（verified）なおみくじ結果のうち filter で除かれないものから 1 件を件数によらずほぼ一定の手間で無作為に返す（1 件も無い場合は ErrDrawNotFound）
  */
 type DrawRepository interface {
 	Create(ctx context.Context, d *draw.Draw) error
 	GetByPostID(ctx context.Context, postID post.DarkPostID) (*draw.Draw, error)
+	Update(ctx context.Context, d *draw.Draw) error
 	ListReady(ctx context.Context) ([]*draw.Draw, error)
+	PickRandom(ctx context.Context, filter DrawFilter) (*draw.Draw, error)
+}
+
+/**
+ * 抽選から外すおみくじ結果の条件
+ * ExcludeAuthor: この投稿者の投稿から作られたおみくじ結果を除く（空なら除かない）
+ * ExcludePostIDs: これらの闇投稿から作られたおみくじ結果を除く（最近引いたものを続けて出さないため）
+ */
+type DrawFilter struct {
+	ExcludeAuthor  post.ClientID
+	ExcludePostIDs []post.DarkPostID
+}
+
+/**
+ * 除外条件が 1 つも無いかを返す。
+ */
+func (f DrawFilter) IsZero() bool {
+	return f.ExcludeAuthor == "" && len(f.ExcludePostIDs) == 0
+}
+
+/**
+ * d が抽選の対象に残るかを返す。
+ */
+func (f DrawFilter) Allows(d *draw.Draw) bool {
+	if d == nil {
+		return false
+	}
+	if f.ExcludeAuthor != "" && d.Author() == f.ExcludeAuthor {
+		return false
+	}
+	return !slices.Contains(f.ExcludePostIDs, d.PostID())
+}
+
+/**
+ * 重み付きの抽選のために、候補を無作為にまとめて取り出す契約。
+ * SampleVerified: 公開可能（verified）なおみくじ結果のうち filter で除かれないものから最大 n 件を無作為に返す（1 件も無ければ空）
+ *   - 乱数はすべて rng から引く。rng が nil ならリポジトリ自身の乱数を使う（DRAW_SELECTION_SEED で標本を再現するときに渡す）
+ */
+type DrawSampler interface {
+	SampleVerified(ctx context.Context, filter DrawFilter, n int, rng *rand.Rand) ([]*draw.Draw, error)
+}
+
+/**
+ * 一様な抽選の乱数を呼び出し側から渡すための契約。DRAW_SELECTION_SEED で抽選を再現するときに使う。
+ * PickRandomWith: PickRandom と同じ条件で、乱数をすべて rng から引いて 1 件を返す（1 件も無い場合は ErrDrawNotFound）
+ */
+type SeededDrawPicker interface {
+	PickRandomWith(ctx context.Context, filter DrawFilter, rng *rand.Rand) (*draw.Draw, error)
+}
+
+/**
+ * 抽選用の乱数キーを持たない既存のおみくじ結果へキーを振る契約。
+ * BackfillRandomKeys: キーを振った件数を返す（キーを保存しない実装は持たなくてよい）
+ */
+type DrawRandomKeyBackfiller interface {
+	BackfillRandomKeys(ctx context.Context) (int, error)
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/domain/draw"
+	"backend/internal/domain/post"
+)
+
+var (
+	// 保存済みのおみくじ結果が投稿の更新先の状態を裏付けない場合に返す
+	ErrDrawStatusConflict = errors.New("repository: 保存済みのおみくじ結果の状態が投稿の更新先と一致しません")
+	// 保存済みの投稿が整形待ち・整形中ではなくなっていた場合に返す（整形中の削除や管理者の判断など）
+	ErrPostStatusConflict = errors.New("repository: 保存済みの投稿が整形の結果を書き込める状態ではありません")
+)
+
+/**
+ * 整形の結果として draws と posts を 1 つの操作で書き込む契約。
+ * Complete: おみくじ結果 d を保存し、投稿 p の状態を更新する。どちらかが失敗すれば両方とも書き込まない
+ *   - 同じ投稿のおみくじ結果がすでにある場合は保存済みの内容を残し、投稿の更新だけを行う（再試行を成功扱いにする）
+ *   - ただし保存済みの結果の状態が投稿の更新先を裏付けない場合（verified 以外で ready にする等）は何も書き込まず ErrDrawStatusConflict
+ *   - 保存済みの投稿が pending / formatting 以外に変わっていれば何も書き込まず ErrPostStatusConflict（同じ状態への再書き込みは受け付ける）
+ *   - 投稿が無ければ ErrPostNotFound
+ */
+type FormatCompleter interface {
+	Complete(ctx context.Context, p *post.Post, d *draw.Draw) error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/domain/post"
+)
+
+var ErrEmptyIdempotencyKey = errors.New("repository: 冪等キーが指定されていません")
+
+/**
+ * 冪等キーに紐づけた内容
+ * @param PostID 最初のリクエストで払い出した闇投稿 ID（レスポンスはこの ID から再現する）
+ * @param Fingerprint 最初のリクエスト内容の指紋
+ * @param ExpiresAt 紐づけの有効期限。ゼロ値なら期限なし
+ */
+type IdempotencyRecord struct {
+	PostID      post.DarkPostID
+	Fingerprint string
+	ExpiresAt   time.Time
+}
+
+/**
+ * now の時点で紐づけの有効期限を過ぎているかを返す。
+ */
+func (r IdempotencyRecord) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+/**
+ * クライアントが送る冪等キーと最初のリクエストの対応を保持するリポジトリの契約
+ * Reserve: clientID ごとに分けた key に record を紐づけてそのまま返す。
+ *   有効期限内の紐づけがあれば保存済みの内容を返し、期限切れなら record で上書きする（key が空の場合は ErrEmptyIdempotencyKey）
+ *   別のクライアントが同じ key を使っても互いの紐づけは見えない。clientID が空のリクエスト同士は同じ範囲を共有する
+ */
+type IdempotencyKeyRepository interface {
+	Reserve(ctx context.Context, clientID post.ClientID, key string, record *IdempotencyRecord) (*IdempotencyRecord, error)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/domain/draw"
+	"backend/internal/domain/post"
+)
+
+// 保存済みの投稿やおみくじ結果の状態が、読み取った時点から変わっていた場合に返す
+var ErrModerationConflict = errors.New("repository: 読み取った後に投稿かおみくじ結果の状態が変わっています")
+
+/**
+ * 書き換える直前に保存されているはずの状態
+ * Post: 投稿の状態
+ * Draw: おみくじ結果の状態。おみくじ結果を書き換えない（d が nil の）場合は見ない
+ */
+type ModerationState struct {
+	Post post.Status
+	Draw draw.Status
+}
+
+/**
+ * 管理者の判断や通報で公開の可否を変えるとき、draws と posts を 1 つの操作で書き換える契約。
+ * Apply: 保存済みのおみくじ結果 d と投稿 p の状態をまとめて更新する。どちらかが失敗すれば両方とも書き込まない
+ *   - d が nil なら投稿だけを更新する（おみくじ結果がまだ無い投稿の削除など）
+ *   - 保存済みの状態が from と食い違えば何も書き込まず ErrModerationConflict（並行する整形や他の操作を上書きしない）
+ *   - 投稿が無ければ ErrPostNotFound、おみくじ結果が無ければ ErrDrawNotFound
+ */
+type ModerationWriter interface {
+	Apply(ctx context.Context, p *post.Post, d *draw.Draw, from ModerationState) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/domain/post"
+)
+
+/**
+ * 闇投稿と整形ジョブを 1 つの書き込み単位として保存するアウトボックスの契約
+ * CreateWithFormatJob: 投稿と整形待ちジョブをまとめて保存し、どちらかが失敗すれば両方とも残さない
+ * （投稿の重複は ErrPostAlreadyExists、ジョブの重複は queue.ErrJobAlreadyScheduled）
+ */
+type PostOutbox interface {
+	CreateWithFormatJob(ctx context.Context, p *post.Post) error
+}
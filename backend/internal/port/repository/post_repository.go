@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"backend/internal/domain/post"
 )
@@ -10,6 +11,7 @@ import (
 var (
 	ErrPostNotFound      = errors.New("repository: 投稿が見つかりません")
 	ErrPostAlreadyExists = errors.New("repository: 投稿がすでに存在します")
+	ErrInvalidPostCursor = errors.New("repository: 投稿一覧のカーソルが不正です")
 )
 
 /**
@@ -25,3 +27,30 @@ type PostRepository interface {
 	ListReady(ctx context.Context, limit int) ([]*post.Post, error)
 	Update(ctx context.Context, p *post.Post) error
 }
+
+/**
+ * 取り残された整形待ち投稿を探すための契約。
+ * ListPendingBefore: before より前に作成された pending / formatting 投稿を古い順に最大 limit 件返す。
+ *   formatting を含めるのは、整形を始めたワーカーが落ちたりジョブを失ったりした投稿も取り残しとして拾うため
+ */
+type PendingPostScanner interface {
+	ListPendingBefore(ctx context.Context, before time.Time, limit int) ([]*post.Post, error)
+}
+
+/**
+ * 状態ごとの投稿一覧の 1 ページ
+ * @param Posts 新しい順に並んだ投稿
+ * @param NextCursor 続きを取得するためのカーソル（続きが無ければ空）
+ */
+type PostPage struct {
+	Posts      []*post.Post
+	NextCursor string
+}
+
+/**
+ * 運用者が投稿を状態ごとにたどるための契約。
+ * ListByStatus: status の投稿を作成日時の新しい順に最大 limit 件返す。cursor には前のページの NextCursor を渡す（空なら先頭から、不正なら ErrInvalidPostCursor）
+ */
+type PostBrowser interface {
+	ListByStatus(ctx context.Context, status post.Status, cursor string, limit int) (*PostPage, error)
+}
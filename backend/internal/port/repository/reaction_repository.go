@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+)
+
+var ErrReactionAlreadyExists = errors.New("repository: 同じクライアントから同じリアクションがすでに届いています")
+
+/**
+ * おみくじへのリアクションを集計するリポジトリの契約
+ * Add: リアクションを 1 件数える。同じクライアントの同じ種類は 1 回だけ数える（重複時は ErrReactionAlreadyExists、クライアントが空なら重複を確かめない）
+ * Counts: postIDs ごとに種類別の件数を返す（リアクションの無いものも全種類 0 で返す）
+ */
+type ReactionRepository interface {
+	Add(ctx context.Context, r *reaction.Reaction) error
+	Counts(ctx context.Context, postIDs []post.DarkPostID) (map[post.DarkPostID]reaction.Counts, error)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+)
+
+var (
+	ErrReportAlreadyExists = errors.New("repository: 同じクライアントからの通報がすでに届いています")
+	ErrReportNotFound      = errors.New("repository: 未確認の通報が見つかりません")
+)
+
+/**
+ * おみくじ 1 件に届いている未確認の通報のまとめ
+ * OpenCount: 未確認の通報の件数
+ * OpenOrigins: 未確認の通報が届いたネットワークの数（通報元が不明な通報は数えない）
+ * Reasons: 未確認の通報の理由ごとの件数
+ * LastReportedAt: 最後に通報された日時
+ */
+type ReportSummary struct {
+	PostID         post.DarkPostID
+	OpenCount      int
+	OpenOrigins    int
+	Reasons        map[report.Reason]int
+	LastReportedAt time.Time
+}
+
+/**
+ * 公開中のおみくじへの通報を扱うリポジトリの契約
+ * Add: 通報を 1 件保存し、加えた後の未確認の通報のまとめを返す。同じクライアントからは 1 回だけ受け付ける（重複時は ErrReportAlreadyExists、クライアントが空なら重複を確かめない）
+ * GetOpen: おみくじ 1 件の未確認の通報のまとめを返す（未確認の通報が無い場合は ErrReportNotFound）
+ * ListOpen: 未確認の通報があるおみくじを最後に通報された順に最大 limit 件返す
+ * Resolve: 未確認の通報をまとめて確認済みにし、件数を 0 へ戻す（未確認の通報が無い場合は ErrReportNotFound）
+ */
+type ReportRepository interface {
+	Add(ctx context.Context, r *report.Report) (*ReportSummary, error)
+	GetOpen(ctx context.Context, postID post.DarkPostID) (*ReportSummary, error)
+	ListOpen(ctx context.Context, limit int) ([]ReportSummary, error)
+	Resolve(ctx context.Context, postID post.DarkPostID, resolution report.Resolution) error
+}
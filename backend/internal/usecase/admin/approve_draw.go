@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// 検証や通報の判定を覆しておみくじ結果を公開するユースケース。
+type ApproveDrawUsecase struct {
+	postRepo repository.PostRepository
+	drawRepo repository.DrawRepository
+	writer   repository.ModerationWriter
+	auditLog repository.AuditLog
+}
+
+// 依存をまとめて強制承認用ユースケースを組み立てる。auditLog が nil なら操作を記録しない。
+func NewApproveDrawUsecase(postRepo repository.PostRepository, drawRepo repository.DrawRepository, writer repository.ModerationWriter, auditLog repository.AuditLog) *ApproveDrawUsecase {
+	return &ApproveDrawUsecase{postRepo: postRepo, drawRepo: drawRepo, writer: writer, auditLog: auditLog}
+}
+
+/**
+ * おみくじ結果を verified、投稿を ready にそろえる。すでに公開中なら何も書き込まない。
+ * 削除済み・公開終了の投稿は ErrInvalidTransition。
+ */
+func (u *ApproveDrawUsecase) Execute(ctx context.Context, postID string) (*PostDetail, error) {
+	p, d, err := findPostWithDraw(ctx, u.postRepo, u.drawRepo, postID)
+	if err != nil {
+		return nil, err
+	}
+	return u.apply(ctx, p, d)
+}
+
+/**
+ * 取得済みの投稿とおみくじ結果を公開へそろえ、draw と投稿を 1 つの操作で書き込む。
+ * 整形結果の無いおみくじ結果は公開できないため ErrNoFormattedResult を返す。
+ */
+func (u *ApproveDrawUsecase) apply(ctx context.Context, p *post.Post, d *drawdomain.Draw) (*PostDetail, error) {
+	if !d.HasFormattedResult() {
+		return nil, ErrNoFormattedResult
+	}
+	details := map[string]string{"post_from": string(p.Status()), "draw_from": string(d.Status())}
+	from := repository.ModerationState{Post: p.Status(), Draw: d.Status()}
+	// 書き込む前に投稿が公開へ移れることを確かめる
+	postChanged := p.Status() != post.StatusReady
+	if postChanged {
+		if err := p.MarkReady(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+		}
+	}
+	drawChanged := d.Status() != drawdomain.StatusVerified
+	if drawChanged {
+		d.MarkVerified()
+	}
+	if postChanged || drawChanged {
+		if err := applyModeration(ctx, u.writer, p, d, from); err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, u.auditLog, p.ID(), audit.ActionDrawApproved, details)
+	}
+	return &PostDetail{Post: p, Draw: d}, nil
+}
@@ -0,0 +1,172 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+func TestApproveDraw(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusRejected, drawdomain.StatusRejected)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewApproveDrawUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), auditLog)
+
+	detail, err := usecase.Execute(ctx, "post-a")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if detail.Post.Status() != post.StatusReady || detail.Draw.Status() != drawdomain.StatusVerified || detail.Draw.Reason() != "" {
+		t.Fatalf("unexpected result: %s %s %q", detail.Post.Status(), detail.Draw.Status(), detail.Draw.Reason())
+	}
+	if ready, _ := drawRepo.ListReady(ctx); len(ready) != 1 {
+		t.Fatalf("approved draw should join the pool, got %d", len(ready))
+	}
+	if stored, _ := postRepo.Get(ctx, "post-a"); stored.Status() != post.StatusReady {
+		t.Fatalf("post should be stored as ready, got %s", stored.Status())
+	}
+	// 公開中なら何もしない
+	if _, err := usecase.Execute(ctx, "post-a"); err != nil {
+		t.Fatalf("approving twice should succeed, got %v", err)
+	}
+	// 何も変えなかった 2 回目は監査ログに残さない
+	events, _ := auditLog.ListByPost(ctx, "post-a")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Action() != audit.ActionDrawApproved || e.Actor().Kind != audit.ActorAdmin || e.Details()["post_from"] != string(post.StatusRejected) {
+		t.Fatalf("unexpected audit event: %s %+v %v", e.Action(), e.Actor(), e.Details())
+	}
+}
+
+func TestApproveDraw_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "deleted", post.StatusDeleted, drawdomain.StatusRejected)
+	pending, _ := post.New("no-draw", "闇")
+	if err := postRepo.Create(ctx, pending); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	usecase := NewApproveDrawUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), nil)
+
+	if _, err := usecase.Execute(ctx, "deleted"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+	// 削除済みの投稿のおみくじ結果は公開しない
+	if d, _ := drawRepo.GetByPostID(ctx, "deleted"); d.Status() != drawdomain.StatusRejected {
+		t.Fatalf("draw should stay rejected, got %s", d.Status())
+	}
+	if _, err := usecase.Execute(ctx, "no-draw"); !errors.Is(err, ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+	if _, err := usecase.Execute(ctx, "missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestRejectDraw(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusReady, drawdomain.StatusVerified)
+	usecase := NewRejectDrawUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), nil)
+
+	detail, err := usecase.Execute(ctx, &RejectDrawInput{PostID: "post-a", Reason: "  個人名が含まれる "})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if detail.Post.Status() != post.StatusRejected || detail.Draw.Status() != drawdomain.StatusRejected || detail.Draw.Reason() != "個人名が含まれる" {
+		t.Fatalf("unexpected result: %s %s %q", detail.Post.Status(), detail.Draw.Status(), detail.Draw.Reason())
+	}
+	if ready, _ := drawRepo.ListReady(ctx); len(ready) != 0 {
+		t.Fatalf("rejected draw should leave the pool, got %d", len(ready))
+	}
+
+	// 理由を省けば既定の理由で上書きする
+	if _, err := usecase.Execute(ctx, &RejectDrawInput{PostID: "post-a"}); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if stored, _ := drawRepo.GetByPostID(ctx, "post-a"); stored.Reason() != defaultRejectReason {
+		t.Fatalf("expected default reason, got %q", stored.Reason())
+	}
+
+	if _, err := usecase.Execute(ctx, nil); !errors.Is(err, ErrNilInput) {
+		t.Fatalf("expected ErrNilInput, got %v", err)
+	}
+}
+
+func TestApproveDraw_ConcurrentDeleteIsNotOverwritten(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusRejected, drawdomain.StatusRejected)
+	writer := repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo)
+	deleteUsecase := NewDeletePostUsecase(postRepo, drawRepo, writer, nil)
+	// 承認の読み取りと書き込みの間に削除が入る
+	racing := racingModerationWriter{ModerationWriter: writer, before: func() {
+		if err := deleteUsecase.Execute(ctx, "post-a"); err != nil {
+			t.Errorf("delete: %v", err)
+		}
+	}}
+	usecase := NewApproveDrawUsecase(postRepo, drawRepo, racing, nil)
+
+	if _, err := usecase.Execute(ctx, "post-a"); !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	if p, _ := postRepo.Get(ctx, "post-a"); p.Status() != post.StatusDeleted {
+		t.Fatalf("post should stay deleted, got %s", p.Status())
+	}
+	if d, _ := drawRepo.GetByPostID(ctx, "post-a"); d.Status() != drawdomain.StatusRejected || d.Reason() != deletedDrawReason {
+		t.Fatalf("draw should stay withdrawn, got %s %q", d.Status(), d.Reason())
+	}
+}
+
+// racingModerationWriter は書き込みの直前に別の操作を割り込ませる ModerationWriter。
+type racingModerationWriter struct {
+	repository.ModerationWriter
+	before func()
+}
+
+func (w racingModerationWriter) Apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	w.before()
+	return w.ModerationWriter.Apply(ctx, p, d, from)
+}
+
+func TestApproveDraw_RefusesDrawWithoutFormattedResult(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	p, _ := post.Restore("post-a", "整形前の闇", post.StatusRejected)
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// 整形結果が得られないまま LLM に拒否された
+	d, _ := drawdomain.New(p.ID(), drawdomain.NoFormattedResult)
+	d.MarkRejected("NG")
+	if err := drawRepo.Create(ctx, d); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	usecase := NewApproveDrawUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), nil)
+
+	if _, err := usecase.Execute(ctx, "post-a"); !errors.Is(err, ErrNoFormattedResult) {
+		t.Fatalf("expected ErrNoFormattedResult, got %v", err)
+	}
+	if stored, _ := drawRepo.GetByPostID(ctx, p.ID()); stored.Status() != drawdomain.StatusRejected {
+		t.Fatalf("draw should stay rejected, got %s", stored.Status())
+	}
+	if stored, _ := postRepo.Get(ctx, p.ID()); stored.Status() != post.StatusRejected {
+		t.Fatalf("post should stay rejected, got %s", stored.Status())
+	}
+}
@@ -0,0 +1,70 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// deletedDrawReason は投稿の削除に合わせて公開を止めたおみくじ結果に残す理由。
+const deletedDrawReason = "管理者が投稿を削除しました"
+
+// 投稿を論理削除し、おみくじ結果を抽選から外すユースケース。
+type DeletePostUsecase struct {
+	postRepo repository.PostRepository
+	drawRepo repository.DrawRepository
+	writer   repository.ModerationWriter
+	auditLog repository.AuditLog
+}
+
+// 依存をまとめて削除用ユースケースを組み立てる。auditLog が nil なら操作を記録しない。
+func NewDeletePostUsecase(postRepo repository.PostRepository, drawRepo repository.DrawRepository, writer repository.ModerationWriter, auditLog repository.AuditLog) *DeletePostUsecase {
+	return &DeletePostUsecase{postRepo: postRepo, drawRepo: drawRepo, writer: writer, auditLog: auditLog}
+}
+
+/**
+ * 投稿を deleted にし、おみくじ結果があれば削除の理由付きで rejected にする。
+ * 強制承認・強制却下と同じく draw と投稿を 1 つの操作で書き換える。削除済みの投稿を再度削除しても成功として扱う。
+ */
+func (u *DeletePostUsecase) Execute(ctx context.Context, postID string) error {
+	p, err := findPost(ctx, u.postRepo, postID)
+	if err != nil {
+		return err
+	}
+	d, err := findDraw(ctx, u.drawRepo, p.ID())
+	if err != nil {
+		return err
+	}
+
+	withdrawDraw := d != nil && (d.Status() != drawdomain.StatusRejected || d.Reason() != deletedDrawReason)
+	from := repository.ModerationState{Post: p.Status()}
+	if d != nil {
+		from.Draw = d.Status()
+	}
+	if from.Post == post.StatusDeleted && !withdrawDraw {
+		return nil
+	}
+	if from.Post != post.StatusDeleted {
+		if err := p.MarkDeleted(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+		}
+	}
+	if withdrawDraw {
+		d.MarkRejected(deletedDrawReason)
+	} else {
+		// 書き換えの要らないおみくじ結果は渡さず、投稿だけを更新する
+		d = nil
+	}
+	if err := applyModeration(ctx, u.writer, p, d, from); err != nil {
+		return err
+	}
+	if from.Post == post.StatusDeleted {
+		return nil
+	}
+	recordAudit(ctx, u.auditLog, p.ID(), audit.ActionPostDeleted, map[string]string{"from": string(from.Post)})
+	return nil
+}
@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+)
+
+func TestDeletePost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusReady, drawdomain.StatusVerified)
+	usecase := NewDeletePostUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), nil)
+
+	if err := usecase.Execute(ctx, "post-a"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, "post-a"); stored.Status() != post.StatusDeleted {
+		t.Fatalf("post should be deleted, got %s", stored.Status())
+	}
+	d, _ := drawRepo.GetByPostID(ctx, "post-a")
+	if d.Status() != drawdomain.StatusRejected || d.Reason() != deletedDrawReason {
+		t.Fatalf("draw should be withdrawn, got %s %q", d.Status(), d.Reason())
+	}
+	if ready, _ := drawRepo.ListReady(ctx); len(ready) != 0 {
+		t.Fatalf("deleted draw should leave the pool, got %d", len(ready))
+	}
+	// 再度の削除も成功扱い
+	if err := usecase.Execute(ctx, "post-a"); err != nil {
+		t.Fatalf("deleting twice should succeed, got %v", err)
+	}
+
+	// おみくじ結果が無い投稿も削除できる
+	pending, _ := post.New("no-draw", "闇")
+	if err := postRepo.Create(ctx, pending); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	if err := usecase.Execute(ctx, "no-draw"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, "no-draw"); stored.Status() != post.StatusDeleted {
+		t.Fatalf("post without draw should be deleted, got %s", stored.Status())
+	}
+	if err := usecase.Execute(ctx, "missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+// 運用者向けに投稿とおみくじ結果、整形ジョブの状況をまとめて返すユースケース。
+type GetPostUsecase struct {
+	postRepo     repository.PostRepository
+	drawRepo     repository.DrawRepository
+	jobInspector queue.JobInspector
+}
+
+// 依存をまとめて投稿詳細用ユースケースを組み立てる。jobInspector が nil ならジョブ状況を返さない。
+func NewGetPostUsecase(postRepo repository.PostRepository, drawRepo repository.DrawRepository, jobInspector queue.JobInspector) *GetPostUsecase {
+	return &GetPostUsecase{postRepo: postRepo, drawRepo: drawRepo, jobInspector: jobInspector}
+}
+
+/**
+ * 削除済みを含めて投稿 1 件の状況を返す。公開不可の判定理由はおみくじ結果に残っている。
+ */
+func (u *GetPostUsecase) Execute(ctx context.Context, postID string) (*PostDetail, error) {
+	p, err := findPost(ctx, u.postRepo, postID)
+	if err != nil {
+		return nil, err
+	}
+	d, err := findDraw(ctx, u.drawRepo, p.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &PostDetail{Post: p, Draw: d}
+	if u.jobInspector != nil {
+		job, err := u.jobInspector.InspectFormat(ctx, p.ID())
+		if err != nil && !errors.Is(err, queue.ErrJobNotFound) {
+			return nil, fmt.Errorf("inspect format job: %w", err)
+		}
+		detail.Job = job
+	}
+	return detail, nil
+}
@@ -0,0 +1,42 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/domain/audit"
+	"backend/internal/port/repository"
+)
+
+var ErrAuditLogUnavailable = errors.New("admin: 監査ログが設定されていません")
+
+// 投稿 1 件に起きた出来事を古い順に返すユースケース。
+type GetPostTimelineUsecase struct {
+	postRepo repository.PostRepository
+	auditLog repository.AuditLog
+}
+
+// 依存をまとめて経緯参照用ユースケースを組み立てる。
+func NewGetPostTimelineUsecase(postRepo repository.PostRepository, auditLog repository.AuditLog) *GetPostTimelineUsecase {
+	return &GetPostTimelineUsecase{postRepo: postRepo, auditLog: auditLog}
+}
+
+/**
+ * 投稿が存在することを確かめてから、監査ログの出来事を記録された順に返す。
+ * 監査ログの導入前に作られた投稿は途中からの記録になる。
+ */
+func (u *GetPostTimelineUsecase) Execute(ctx context.Context, postID string) ([]*audit.Event, error) {
+	if u.auditLog == nil {
+		return nil, ErrAuditLogUnavailable
+	}
+	p, err := findPost(ctx, u.postRepo, postID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := u.auditLog.ListByPost(ctx, p.ID())
+	if err != nil {
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+	return events, nil
+}
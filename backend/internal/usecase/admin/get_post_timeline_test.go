@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+)
+
+func TestGetPostTimeline(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusReady, drawdomain.StatusVerified)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	if _, err := NewRejectDrawUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), auditLog).Execute(ctx, &RejectDrawInput{PostID: "post-a"}); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	if err := NewDeletePostUsecase(postRepo, drawRepo, repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo), auditLog).Execute(ctx, "post-a"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	usecase := NewGetPostTimelineUsecase(postRepo, auditLog)
+
+	events, err := usecase.Execute(ctx, "post-a")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Action() != audit.ActionDrawRejected || events[1].Action() != audit.ActionPostDeleted {
+		t.Fatalf("unexpected timeline: %v", events)
+	}
+	if events[0].Details()["reason"] != defaultRejectReason || events[1].Details()["from"] != string(post.StatusRejected) {
+		t.Fatalf("unexpected details: %v %v", events[0].Details(), events[1].Details())
+	}
+
+	if _, err := usecase.Execute(ctx, "missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := NewGetPostTimelineUsecase(postRepo, nil).Execute(ctx, "post-a"); !errors.Is(err, ErrAuditLogUnavailable) {
+		t.Fatalf("expected ErrAuditLogUnavailable, got %v", err)
+	}
+}
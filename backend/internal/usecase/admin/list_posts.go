@@ -0,0 +1,75 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+const (
+	// DefaultPostListLimit は投稿一覧で件数の指定が無い場合に返す件数。
+	DefaultPostListLimit = 50
+	// MaxPostListLimit は投稿一覧で 1 回に返す件数の上限。
+	MaxPostListLimit = 200
+)
+
+var (
+	ErrInvalidStatus       = errors.New("admin: 投稿の状態の指定が不正です")
+	ErrInvalidCursor       = errors.New("admin: 投稿一覧のカーソルが不正です")
+	ErrPostListUnavailable = errors.New("admin: 投稿リポジトリが状態ごとの一覧に対応していません")
+)
+
+/**
+ * 投稿一覧の条件
+ * Status: 一覧する投稿の状態
+ * Cursor: 前のページの NextCursor（空なら先頭から）
+ * Limit: 1 ページの件数（0 以下なら DefaultPostListLimit）
+ */
+type ListPostsInput struct {
+	Status string
+	Cursor string
+	Limit  int
+}
+
+// 運用者向けに投稿を状態ごとに新しい順で一覧するユースケース。
+type ListPostsUsecase struct {
+	browser repository.PostBrowser
+}
+
+// 状態ごとの一覧に対応した投稿リポジトリから投稿一覧用ユースケースを組み立てる。browser が nil なら一覧できない。
+func NewListPostsUsecase(browser repository.PostBrowser) *ListPostsUsecase {
+	return &ListPostsUsecase{browser: browser}
+}
+
+/**
+ * 指定の状態の投稿を 1 ページ分返す。Limit は MaxPostListLimit に丸める。
+ */
+func (u *ListPostsUsecase) Execute(ctx context.Context, in *ListPostsInput) (*repository.PostPage, error) {
+	if in == nil {
+		return nil, ErrNilInput
+	}
+	if u.browser == nil {
+		return nil, ErrPostListUnavailable
+	}
+	status, err := post.ParseStatus(in.Status)
+	if err != nil {
+		return nil, ErrInvalidStatus
+	}
+	limit := in.Limit
+	if limit <= 0 {
+		limit = DefaultPostListLimit
+	}
+	limit = min(limit, MaxPostListLimit)
+
+	page, err := u.browser.ListByStatus(ctx, status, in.Cursor, limit)
+	if errors.Is(err, repository.ErrInvalidPostCursor) {
+		return nil, ErrInvalidCursor
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list posts by status: %w", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+)
+
+func TestListPosts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	for _, id := range []post.DarkPostID{"a", "b", "c"} {
+		p, _ := post.New(id, "闇")
+		if err := postRepo.Create(ctx, p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	usecase := NewListPostsUsecase(postRepo)
+
+	first, err := usecase.Execute(ctx, &ListPostsInput{Status: "pending", Limit: 2})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(first.Posts) != 2 || first.NextCursor == "" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	second, err := usecase.Execute(ctx, &ListPostsInput{Status: "pending", Cursor: first.NextCursor, Limit: 2})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if len(second.Posts) != 1 || second.NextCursor != "" {
+		t.Fatalf("unexpected second page: %+v", second)
+	}
+
+	cases := []struct {
+		name string
+		in   *ListPostsInput
+		want error
+	}{
+		{name: "nil input", in: nil, want: ErrNilInput},
+		{name: "unknown status", in: &ListPostsInput{Status: "hidden"}, want: ErrInvalidStatus},
+		{name: "unknown cursor", in: &ListPostsInput{Status: "pending", Cursor: "zzz"}, want: ErrInvalidCursor},
+	}
+	for _, tc := range cases {
+		if _, err := usecase.Execute(ctx, tc.in); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+	if _, err := NewListPostsUsecase(nil).Execute(ctx, &ListPostsInput{Status: "pending"}); !errors.Is(err, ErrPostListUnavailable) {
+		t.Fatalf("expected ErrPostListUnavailable, got %v", err)
+	}
+}
+
+func TestGetPost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "rejected", post.StatusRejected, drawdomain.StatusRejected)
+	pending, _ := post.New("pending", "闇")
+	if err := postRepo.Create(ctx, pending); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	jobQueue := queueMemory.NewInMemoryJobQueue(0)
+	t.Cleanup(func() { _ = jobQueue.Close() })
+	if err := jobQueue.EnqueueFormat(ctx, "pending"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	usecase := NewGetPostUsecase(postRepo, drawRepo, jobQueue)
+
+	detail, err := usecase.Execute(ctx, "rejected")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if detail.Draw == nil || detail.Draw.Reason() != "LLM が不適切と判定" || detail.Job != nil {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+
+	detail, err = usecase.Execute(ctx, "pending")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if detail.Draw != nil || detail.Job == nil || detail.Job.State != "pending" {
+		t.Fatalf("unexpected detail: %+v", detail)
+	}
+
+	if _, err := usecase.Execute(ctx, "missing"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := usecase.Execute(ctx, ""); !errors.Is(err, ErrEmptyPostID) {
+		t.Fatalf("expected ErrEmptyPostID, got %v", err)
+	}
+}
+
+// newModerationFixture は指定の状態の投稿とおみくじ結果を 1 組だけ保存したリポジトリを返す。
+func newModerationFixture(t *testing.T, id post.DarkPostID, postStatus post.Status, drawStatus drawdomain.Status) (*repoMemory.InMemoryPostRepository, *repoMemory.InMemoryDrawRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	p, err := post.Restore(id, "闇", postStatus)
+	if err != nil {
+		t.Fatalf("restore post: %v", err)
+	}
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	d, err := drawdomain.New(id, "おみくじ")
+	if err != nil {
+		t.Fatalf("new draw: %v", err)
+	}
+	switch drawStatus {
+	case drawdomain.StatusVerified:
+		d.MarkVerified()
+	case drawdomain.StatusRejected:
+		d.MarkRejected("LLM が不適切と判定")
+	}
+	if err := drawRepo.Create(ctx, d); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	return postRepo, drawRepo
+}
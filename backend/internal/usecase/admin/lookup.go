@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var (
+	ErrNilInput          = errors.New("admin: 入力が指定されていません")
+	ErrEmptyPostID       = errors.New("admin: 投稿 ID が指定されていません")
+	ErrPostNotFound      = errors.New("admin: 投稿が見つかりません")
+	ErrDrawNotFound      = errors.New("admin: 投稿のおみくじ結果がまだありません")
+	ErrInvalidTransition = errors.New("admin: 今の投稿の状態ではその操作を行えません")
+	ErrConflict          = errors.New("admin: 操作の間に投稿かおみくじ結果の状態が変わりました")
+	ErrNoFormattedResult = errors.New("admin: おみくじ結果に公開できる整形結果がありません")
+)
+
+/**
+ * 運用者が確認する投稿 1 件の状況
+ * Post: 投稿本体（削除済みも含む）
+ * Draw: 整形後のおみくじ結果。整形前なら nil。公開不可の理由は Draw.Reason() で参照する
+ * Job: 整形ジョブの状況。ジョブが無いか参照できなければ nil
+ */
+type PostDetail struct {
+	Post *post.Post
+	Draw *drawdomain.Draw
+	Job  *queue.FormatJobStatus
+}
+
+/**
+ * 管理者を主体として監査ログへ出来事を追記する。記録に失敗しても反映済みの操作は取り消せないため、操作の失敗にはしない。
+ */
+func recordAudit(ctx context.Context, auditLog repository.AuditLog, id post.DarkPostID, action audit.Action, details map[string]string) {
+	if auditLog == nil {
+		return
+	}
+	e, err := audit.New(id, action, audit.Admin(), details)
+	if err != nil {
+		return
+	}
+	_ = auditLog.Append(ctx, e)
+}
+
+/**
+ * 投稿 ID の投稿を状態によらず取得する。
+ */
+func findPost(ctx context.Context, postRepo repository.PostRepository, rawID string) (*post.Post, error) {
+	if rawID == "" {
+		return nil, ErrEmptyPostID
+	}
+	p, err := postRepo.Get(ctx, post.DarkPostID(rawID))
+	if errors.Is(err, repository.ErrPostNotFound) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get post: %w", err)
+	}
+	return p, nil
+}
+
+/**
+ * 投稿のおみくじ結果を取得する。まだ無ければ nil を返す。
+ */
+func findDraw(ctx context.Context, drawRepo repository.DrawRepository, id post.DarkPostID) (*drawdomain.Draw, error) {
+	d, err := drawRepo.GetByPostID(ctx, id)
+	if errors.Is(err, repository.ErrDrawNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get draw: %w", err)
+	}
+	return d, nil
+}
+
+/**
+ * 投稿とおみくじ結果をそろえて取得する。おみくじ結果が無ければ ErrDrawNotFound。
+ */
+func findPostWithDraw(ctx context.Context, postRepo repository.PostRepository, drawRepo repository.DrawRepository, rawID string) (*post.Post, *drawdomain.Draw, error) {
+	p, err := findPost(ctx, postRepo, rawID)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := findDraw(ctx, drawRepo, p.ID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if d == nil {
+		return nil, nil, ErrDrawNotFound
+	}
+	return p, d, nil
+}
+
+/**
+ * おみくじ結果と投稿の状態を 1 つの操作で書き込む。途中で消えていた場合は取得時と同じエラーに揃え、
+ * 読み取った時点の状態 from から変わっていた場合は ErrConflict を返す。
+ */
+func applyModeration(ctx context.Context, writer repository.ModerationWriter, p *post.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	err := writer.Apply(ctx, p, d, from)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrPostNotFound):
+		return ErrPostNotFound
+	case errors.Is(err, repository.ErrDrawNotFound):
+		return ErrDrawNotFound
+	case errors.Is(err, repository.ErrModerationConflict):
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	default:
+		return fmt.Errorf("apply moderation: %w", err)
+	}
+}
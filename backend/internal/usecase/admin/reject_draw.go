@@ -0,0 +1,81 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+// defaultRejectReason は却下の理由が指定されなかった場合に残す理由。
+const defaultRejectReason = "管理者が公開不可と判断しました"
+
+/**
+ * 強制却下の入力値
+ * PostID: 対象の投稿 ID
+ * Reason: おみくじ結果に残す公開不可の理由（空なら defaultRejectReason）
+ */
+type RejectDrawInput struct {
+	PostID string
+	Reason string
+}
+
+// 検証の判定を覆しておみくじ結果を公開不可にするユースケース。
+type RejectDrawUsecase struct {
+	postRepo repository.PostRepository
+	drawRepo repository.DrawRepository
+	writer   repository.ModerationWriter
+	auditLog repository.AuditLog
+}
+
+// 依存をまとめて強制却下用ユースケースを組み立てる。auditLog が nil なら操作を記録しない。
+func NewRejectDrawUsecase(postRepo repository.PostRepository, drawRepo repository.DrawRepository, writer repository.ModerationWriter, auditLog repository.AuditLog) *RejectDrawUsecase {
+	return &RejectDrawUsecase{postRepo: postRepo, drawRepo: drawRepo, writer: writer, auditLog: auditLog}
+}
+
+/**
+ * おみくじ結果を理由付きで rejected、投稿を rejected にそろえる。削除済み・公開終了の投稿は ErrInvalidTransition。
+ */
+func (u *RejectDrawUsecase) Execute(ctx context.Context, in *RejectDrawInput) (*PostDetail, error) {
+	if in == nil {
+		return nil, ErrNilInput
+	}
+	p, d, err := findPostWithDraw(ctx, u.postRepo, u.drawRepo, in.PostID)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if reason == "" {
+		reason = defaultRejectReason
+	}
+	return u.apply(ctx, p, d, reason)
+}
+
+/**
+ * 取得済みの投稿とおみくじ結果を公開不可へそろえ、draw と投稿を 1 つの操作で書き込む。
+ */
+func (u *RejectDrawUsecase) apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, reason string) (*PostDetail, error) {
+	details := map[string]string{"post_from": string(p.Status()), "draw_from": string(d.Status()), "reason": reason}
+	from := repository.ModerationState{Post: p.Status(), Draw: d.Status()}
+	postChanged := p.Status() != post.StatusRejected
+	if postChanged {
+		if err := p.MarkRejected(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTransition, err)
+		}
+	}
+	drawChanged := d.Status() != drawdomain.StatusRejected || d.Reason() != reason
+	if drawChanged {
+		d.MarkRejected(reason)
+	}
+	if postChanged || drawChanged {
+		if err := applyModeration(ctx, u.writer, p, d, from); err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, u.auditLog, p.ID(), audit.ActionDrawRejected, details)
+	}
+	return &PostDetail{Post: p, Draw: d}, nil
+}
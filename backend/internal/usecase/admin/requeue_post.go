@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var ErrJobAlreadyQueued = errors.New("admin: 投稿の整形ジョブはすでに積まれています")
+
+// 整形待ち・失敗の投稿の整形ジョブを積み直すユースケース。
+type RequeuePostUsecase struct {
+	postRepo    repository.PostRepository
+	jobQueue    queue.JobQueue
+	deadLetters queue.DeadLetterQueue
+	auditLog    repository.AuditLog
+}
+
+// 依存をまとめて再投入用ユースケースを組み立てる。deadLetters が nil なら隔離済みのジョブを戻さず新しく積む。
+// auditLog が nil なら操作を記録しない。
+func NewRequeuePostUsecase(postRepo repository.PostRepository, jobQueue queue.JobQueue, deadLetters queue.DeadLetterQueue, auditLog repository.AuditLog) *RequeuePostUsecase {
+	return &RequeuePostUsecase{postRepo: postRepo, jobQueue: jobQueue, deadLetters: deadLetters, auditLog: auditLog}
+}
+
+/**
+ * pending / formatting / failed の投稿の整形ジョブを積む。failed の投稿は隔離済みのジョブがあればそれを戻す。
+ * failed から pending へ戻すのはジョブを受け取ったワーカーが行う。
+ * ジョブがすでにあれば ErrJobAlreadyQueued、それ以外の状態は ErrInvalidTransition。
+ */
+func (u *RequeuePostUsecase) Execute(ctx context.Context, postID string) error {
+	p, err := findPost(ctx, u.postRepo, postID)
+	if err != nil {
+		return err
+	}
+
+	switch p.Status() {
+	case post.StatusPending, post.StatusFormatting:
+	case post.StatusFailed:
+		if u.deadLetters != nil {
+			err := u.deadLetters.ReplayDeadFormat(ctx, p.ID())
+			if err == nil {
+				recordAudit(ctx, u.auditLog, p.ID(), audit.ActionPostRequeued, map[string]string{"from": string(p.Status()), "dead_letter": "replayed"})
+				return nil
+			}
+			if !errors.Is(err, queue.ErrDeadJobNotFound) {
+				return translateEnqueueError(err)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s の投稿は整形し直せません", ErrInvalidTransition, p.Status())
+	}
+
+	if err := translateEnqueueError(u.jobQueue.EnqueueFormat(ctx, p.ID())); err != nil {
+		return err
+	}
+	recordAudit(ctx, u.auditLog, p.ID(), audit.ActionPostRequeued, map[string]string{"from": string(p.Status())})
+	return nil
+}
+
+/**
+ * ジョブの重複を ErrJobAlreadyQueued に読み替える。
+ */
+func translateEnqueueError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, queue.ErrJobAlreadyScheduled) {
+		return ErrJobAlreadyQueued
+	}
+	return fmt.Errorf("enqueue format job: %w", err)
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+)
+
+func TestRequeuePost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	for id, status := range map[post.DarkPostID]post.Status{
+		"pending":  post.StatusPending,
+		"failed":   post.StatusFailed,
+		"dead":     post.StatusFailed,
+		"rejected": post.StatusRejected,
+	} {
+		p, _ := post.Restore(id, "闇", status)
+		if err := postRepo.Create(ctx, p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	jobQueue := queueMemory.NewInMemoryJobQueue(0, queueMemory.WithRetryPolicy(queue.RetryPolicy{MaxAttempts: 1}))
+	t.Cleanup(func() { _ = jobQueue.Close() })
+	// dead のジョブを 1 回失敗させて隔離しておく
+	if err := jobQueue.EnqueueFormat(ctx, "dead"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	lease, err := jobQueue.DequeueFormat(ctx)
+	if err != nil {
+		t.Fatalf("dequeue: %v", err)
+	}
+	if err := jobQueue.RetryFormat(ctx, lease, errors.New("llm down")); !errors.Is(err, queue.ErrJobDeadLettered) {
+		t.Fatalf("expected dead letter, got %v", err)
+	}
+	usecase := NewRequeuePostUsecase(postRepo, jobQueue, jobQueue, nil)
+
+	for _, id := range []string{"pending", "failed", "dead"} {
+		if err := usecase.Execute(ctx, id); err != nil {
+			t.Fatalf("%s: Execute() error = %v", id, err)
+		}
+		if job, err := jobQueue.InspectFormat(ctx, post.DarkPostID(id)); err != nil || job.State != queue.FormatJobPending {
+			t.Fatalf("%s: job should be pending, got %+v %v", id, job, err)
+		}
+	}
+	if dead, _ := jobQueue.ListDeadFormat(ctx, 0); len(dead) != 0 {
+		t.Fatalf("dead job should be replayed, got %d", len(dead))
+	}
+
+	if err := usecase.Execute(ctx, "pending"); !errors.Is(err, ErrJobAlreadyQueued) {
+		t.Fatalf("expected ErrJobAlreadyQueued, got %v", err)
+	}
+	if err := usecase.Execute(ctx, "rejected"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("expected ErrInvalidTransition, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+// reportConfirmedReason は公開中のおみくじを通報の確認で止めた場合に残す理由。
+const reportConfirmedReason = "管理者が通報を確認し公開を停止しました"
+
+var (
+	ErrInvalidResolution = errors.New("admin: 確認結果は confirmed か restored を指定してください")
+	ErrNoOpenReports     = errors.New("admin: 未確認の通報がありません")
+)
+
+/**
+ * 通報の確認結果の入力値
+ * PostID: 通報先のおみくじの投稿 ID
+ * Resolution: confirmed なら公開を止めたままにし、restored なら公開へ戻す
+ */
+type ResolveReportsInput struct {
+	PostID     string
+	Resolution string
+}
+
+// 管理者が通報を確認し、強制却下・強制承認と同じ手順でおみくじと投稿の公開を止めるか戻すユースケース。
+type ResolveReportsUsecase struct {
+	reports  repository.ReportRepository
+	approve  *ApproveDrawUsecase
+	reject   *RejectDrawUsecase
+	auditLog repository.AuditLog
+}
+
+// 通報の保存先と、公開を戻す・止めるユースケースをまとめて通報確認用ユースケースを組み立てる。auditLog が nil なら確認を記録しない。
+func NewResolveReportsUsecase(reports repository.ReportRepository, approve *ApproveDrawUsecase, reject *RejectDrawUsecase, auditLog repository.AuditLog) *ResolveReportsUsecase {
+	return &ResolveReportsUsecase{reports: reports, approve: approve, reject: reject, auditLog: auditLog}
+}
+
+/**
+ * 未確認の通報があるおみくじと投稿の状態を確認結果に合わせ、通報を確認済みにして遷移後の状態を返す。
+ * 状態を先に書き換えるため、通報を閉じる前に失敗しても同じ入力でやり直せる。
+ * 状態が変わらなかった場合も、通報を締めたことは確認結果と件数を添えて記録する。
+ */
+func (u *ResolveReportsUsecase) Execute(ctx context.Context, in *ResolveReportsInput) (*PostDetail, error) {
+	if in == nil {
+		return nil, ErrNilInput
+	}
+	resolution := report.Resolution(in.Resolution)
+	if !resolution.IsValid() {
+		return nil, ErrInvalidResolution
+	}
+	p, d, err := findPostWithDraw(ctx, u.approve.postRepo, u.approve.drawRepo, in.PostID)
+	if err != nil {
+		return nil, err
+	}
+	// 通報の無いおみくじ（LLM の検証で公開不可になったものなど）は戻さない
+	summary, err := u.reports.GetOpen(ctx, p.ID())
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return nil, ErrNoOpenReports
+		}
+		return nil, fmt.Errorf("get open reports: %w", err)
+	}
+
+	details := map[string]string{
+		"resolution":   string(resolution),
+		"draw_from":    string(d.Status()),
+		"open_reports": strconv.Itoa(summary.OpenCount),
+		"open_origins": strconv.Itoa(summary.OpenOrigins),
+	}
+	detail, err := u.apply(ctx, p, d, resolution)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.reports.Resolve(ctx, post.DarkPostID(in.PostID), resolution); err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return nil, ErrNoOpenReports
+		}
+		return nil, fmt.Errorf("resolve reports: %w", err)
+	}
+	recordAudit(ctx, u.auditLog, p.ID(), audit.ActionReportsResolved, details)
+	return detail, nil
+}
+
+/**
+ * 確認結果に合わせて強制却下か強制承認を行う。すでに取り下げ済みなら取り下げたときの理由を残す。
+ */
+func (u *ResolveReportsUsecase) apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, resolution report.Resolution) (*PostDetail, error) {
+	if resolution == report.ResolutionRestored {
+		return u.approve.apply(ctx, p, d)
+	}
+	reason := reportConfirmedReason
+	if d.Status() == drawdomain.StatusRejected && d.Reason() != "" {
+		reason = d.Reason()
+	}
+	return u.reject.apply(ctx, p, d, reason)
+}
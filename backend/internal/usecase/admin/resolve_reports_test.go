@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+func TestResolveReports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// a は通報で取り下げられた状態、b は公開中のまま通報が届いた状態
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusReady, drawdomain.StatusRejected)
+	b, _ := post.Restore("post-b", "闇", post.StatusReady)
+	if err := postRepo.Create(ctx, b); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	published, _ := drawdomain.New("post-b", "おみくじ")
+	published.MarkVerified()
+	if err := drawRepo.Create(ctx, published); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	reports := repoMemory.NewInMemoryReportRepository()
+	for _, id := range []post.DarkPostID{"post-a", "post-b"} {
+		re, _ := report.New(id, report.ReasonSpam, "", "c1", "")
+		if _, err := reports.Add(ctx, re); err != nil {
+			t.Fatalf("add report: %v", err)
+		}
+	}
+	writer := repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewResolveReportsUsecase(reports,
+		NewApproveDrawUsecase(postRepo, drawRepo, writer, auditLog),
+		NewRejectDrawUsecase(postRepo, drawRepo, writer, auditLog),
+		auditLog)
+
+	// 取り下げられていた a を戻すと投稿も公開へそろう
+	restored, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "post-a", Resolution: "restored"})
+	if err != nil || restored.Draw.Status() != drawdomain.StatusVerified || restored.Post.Status() != post.StatusReady {
+		t.Fatalf("restore: %+v, %v", restored, err)
+	}
+	// 公開中の b を止めると投稿も rejected になる
+	confirmed, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "post-b", Resolution: "confirmed"})
+	if err != nil || confirmed.Draw.Status() != drawdomain.StatusRejected || confirmed.Draw.Reason() != reportConfirmedReason {
+		t.Fatalf("confirm: %+v, %v", confirmed, err)
+	}
+	if stored, _ := postRepo.Get(ctx, "post-b"); stored.Status() != post.StatusRejected {
+		t.Fatalf("confirmed post should be stored as rejected, got %s", stored.Status())
+	}
+	if picked, err := drawRepo.PickRandom(ctx, repository.DrawFilter{}); err != nil || picked.PostID() != "post-a" {
+		t.Fatalf("only the restored draw should be published, got %v, %v", picked, err)
+	}
+	if open, _ := reports.ListOpen(ctx, 10); len(open) != 0 {
+		t.Fatalf("resolved reports should be closed, got %d", len(open))
+	}
+	// 判定の変更に続けて、通報を締めたことを確認結果と件数付きで残す
+	for id, want := range map[post.DarkPostID]audit.Action{"post-a": audit.ActionDrawApproved, "post-b": audit.ActionDrawRejected} {
+		events, _ := auditLog.ListByPost(ctx, id)
+		if len(events) != 2 || events[0].Action() != want || events[1].Action() != audit.ActionReportsResolved || events[1].Actor() != audit.Admin() {
+			t.Fatalf("unexpected audit events for %s: %+v", id, events)
+		}
+		if events[1].Details()["open_reports"] != "1" {
+			t.Fatalf("resolved event should carry the report count, got %v", events[1].Details())
+		}
+	}
+	if events, _ := auditLog.ListByPost(ctx, "post-b"); events[1].Details()["resolution"] != string(report.ResolutionConfirmed) {
+		t.Fatalf("unexpected resolution: %v", events[1].Details())
+	}
+
+	if _, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "post-a", Resolution: "restored"}); !errors.Is(err, ErrNoOpenReports) {
+		t.Fatalf("expected ErrNoOpenReports, got %v", err)
+	}
+	if _, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "post-a", Resolution: "ignored"}); !errors.Is(err, ErrInvalidResolution) {
+		t.Fatalf("expected ErrInvalidResolution, got %v", err)
+	}
+	if _, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "missing", Resolution: "confirmed"}); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
+	if _, err := usecase.Execute(ctx, nil); !errors.Is(err, ErrNilInput) {
+		t.Fatalf("expected ErrNilInput, got %v", err)
+	}
+}
+
+func TestResolveReports_ConfirmKeepsTakedownReason(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusRejected, drawdomain.StatusRejected)
+	reports := repoMemory.NewInMemoryReportRepository()
+	re, _ := report.New("post-a", report.ReasonAbusive, "", "c1", "")
+	if _, err := reports.Add(ctx, re); err != nil {
+		t.Fatalf("add report: %v", err)
+	}
+	writer := repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewResolveReportsUsecase(reports,
+		NewApproveDrawUsecase(postRepo, drawRepo, writer, auditLog),
+		NewRejectDrawUsecase(postRepo, drawRepo, writer, auditLog),
+		auditLog)
+
+	detail, err := usecase.Execute(ctx, &ResolveReportsInput{PostID: "post-a", Resolution: "confirmed"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if detail.Draw.Reason() != "LLM が不適切と判定" {
+		t.Fatalf("takedown reason should be kept, got %q", detail.Draw.Reason())
+	}
+	// 状態が変わらなくても、通報を締めたことは残す
+	events, _ := auditLog.ListByPost(ctx, "post-a")
+	if len(events) != 1 || events[0].Action() != audit.ActionReportsResolved || events[0].Details()["draw_from"] != string(drawdomain.StatusRejected) {
+		t.Fatalf("unexpected audit events: %+v", events)
+	}
+}
+
+func TestApproveDraw_WriterFailureLeavesBothUnchanged(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	postRepo, drawRepo := newModerationFixture(t, "post-a", post.StatusRejected, drawdomain.StatusRejected)
+	expected := errors.New("transaction aborted")
+	usecase := NewApproveDrawUsecase(postRepo, drawRepo, failingModerationWriter{err: expected}, nil)
+
+	if _, err := usecase.Execute(ctx, "post-a"); !errors.Is(err, expected) {
+		t.Fatalf("expected writer error, got %v", err)
+	}
+	if d, _ := drawRepo.GetByPostID(ctx, "post-a"); d.Status() != drawdomain.StatusRejected {
+		t.Fatalf("draw should stay rejected, got %s", d.Status())
+	}
+	if p, _ := postRepo.Get(ctx, "post-a"); p.Status() != post.StatusRejected {
+		t.Fatalf("post should stay rejected, got %s", p.Status())
+	}
+}
+
+// failingModerationWriter は何も書き込まずに常に同じエラーを返す ModerationWriter。
+type failingModerationWriter struct {
+	err error
+}
+
+func (w failingModerationWriter) Apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	return w.err
+}
@@ -2,52 +2,126 @@ package draw
 
 import (
 	"context"
-	"math/rand"
-	"time"
+	"errors"
+	"fmt"
 
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
 	"backend/internal/port/repository"
 )
 
+// おみくじを引く際の入力値
+// ClientID: 任意。引いた匿名クライアントの ID。本人の投稿から作られたおみくじと、直近に引いたおみくじは出さない
+type DrawFortuneInput struct {
+	ClientID string
+}
+
+// 引いたおみくじと、そのおみくじへのリアクションの件数
+type DrawFortuneOutput struct {
+	Draw      *drawdomain.Draw
+	Reactions reaction.Counts
+}
+
 // FortuneUsecase は検証済みのおみくじを 1 件返すユースケース。
 type FortuneUsecase struct {
-	repo repository.DrawRepository
-	rand *rand.Rand
+	strategy SelectionStrategy
+	history  repository.DrawHistoryRepository
+	// 同じクライアントに続けて出さない直近の件数（0 以下なら履歴を使わない）
+	window    int
+	reactions repository.ReactionRepository
 }
 
-// NewFortuneUsecase は FortuneUsecase を生成する。
-func NewFortuneUsecase(repo repository.DrawRepository) *FortuneUsecase {
+// NewFortuneUsecase は FortuneUsecase を生成する。strategy が 1 件の選び方を決め、history が nil なら直近の結果を避けずに抽選する。
+// reactions が nil ならリアクションの件数はすべて 0 で返す。
+func NewFortuneUsecase(strategy SelectionStrategy, history repository.DrawHistoryRepository, window int, reactions repository.ReactionRepository) *FortuneUsecase {
 	return &FortuneUsecase{
-		repo: repo,
-		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		strategy:  strategy,
+		history:   history,
+		window:    window,
+		reactions: reactions,
 	}
 }
 
-// DrawFortune は Verified 状態のおみくじから 1 件をランダムに返す。
-func (u *FortuneUsecase) DrawFortune(ctx context.Context) (*drawdomain.Draw, error) {
-	draws, err := u.repo.ListReady(ctx)
+// DrawFortune は Verified 状態のおみくじから、引いた本人の投稿と直近に引いたもの以外の 1 件をランダムに返す。
+// 選び方は SelectionStrategy に任せ、件数が増えても全件を読み込まない。
+// 直近の結果を除くと候補が尽きる場合は、直前の 1 件だけを除いて、それでも尽きれば除かずに引き直す。
+func (u *FortuneUsecase) DrawFortune(ctx context.Context, in *DrawFortuneInput) (*DrawFortuneOutput, error) {
+	var client post.ClientID
+	if in != nil {
+		client = post.ClientID(in.ClientID)
+	}
+
+	recent, err := u.recent(ctx, client)
 	if err != nil {
 		return nil, err
 	}
 
-	verified := make([]*drawdomain.Draw, 0, len(draws))
-	for _, d := range draws {
-		if d == nil {
-			continue
+	d, err := u.pick(ctx, client, recent)
+	if err != nil {
+		return nil, err
+	}
+	// 履歴の記録に失敗しても引けた結果は返す。次回に同じものが出うるだけで済む
+	if u.remembers(client) {
+		_ = u.history.Record(ctx, client, d.PostID(), u.window)
+	}
+
+	counts := reaction.NewCounts()
+	if u.reactions != nil {
+		all, err := u.reactions.Counts(ctx, []post.DarkPostID{d.PostID()})
+		if err != nil {
+			return nil, fmt.Errorf("load reaction counts: %w", err)
 		}
-		if d.Status() != drawdomain.StatusVerified {
+		counts = all[d.PostID()]
+	}
+	return &DrawFortuneOutput{Draw: d, Reactions: counts}, nil
+}
+
+/**
+ * 除外する直近の結果を広い順に試し、最初に見つかった 1 件を返す。
+ */
+func (u *FortuneUsecase) pick(ctx context.Context, client post.ClientID, recent []post.DarkPostID) (*drawdomain.Draw, error) {
+	attempts := [][]post.DarkPostID{recent}
+	if len(recent) > 1 {
+		attempts = append(attempts, recent[:1])
+	}
+	if len(recent) > 0 {
+		attempts = append(attempts, nil)
+	}
+
+	for _, exclude := range attempts {
+		d, err := u.strategy.Select(ctx, repository.DrawFilter{
+			ExcludeAuthor:  client,
+			ExcludePostIDs: exclude,
+		})
+		if errors.Is(err, repository.ErrDrawNotFound) {
 			continue
 		}
-		verified = append(verified, d)
+		if err != nil {
+			return nil, err
+		}
+		if d == nil || d.Status() != drawdomain.StatusVerified {
+			return nil, drawdomain.ErrEmptyResult
+		}
+		return d, nil
 	}
+	return nil, drawdomain.ErrEmptyResult
+}
 
-	if len(verified) == 0 {
-		return nil, drawdomain.ErrEmptyResult
+/**
+ * client が直近に引いた結果を新しい順に返す。履歴を使わない場合は空。
+ */
+func (u *FortuneUsecase) recent(ctx context.Context, client post.ClientID) ([]post.DarkPostID, error) {
+	if !u.remembers(client) {
+		return nil, nil
 	}
-	if len(verified) == 1 {
-		return verified[0], nil
+	recent, err := u.history.Recent(ctx, client, u.window)
+	if err != nil {
+		return nil, fmt.Errorf("load draw history: %w", err)
 	}
+	return recent, nil
+}
 
-	index := u.rand.Intn(len(verified))
-	return verified[index], nil
+func (u *FortuneUsecase) remembers(client post.ClientID) bool {
+	return client != "" && u.history != nil && u.window > 0
 }
@@ -3,83 +3,224 @@ package draw
 import (
 	"context"
 	"errors"
-	"math/rand"
 	"testing"
 
+	repoMemory "backend/internal/adapter/repository/memory"
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
 	"backend/internal/port/repository"
 )
 
 func TestDrawFortune_Success(t *testing.T) {
 	t.Parallel()
 
-	repo := &fakeDrawRepository{
-		draws: []*drawdomain.Draw{
-			newVerifiedDraw(t, "post-1", "fortune-1"),
-			newVerifiedDraw(t, "post-2", "fortune-2"),
-		},
-	}
-	usecase := NewFortuneUsecase(repo)
-	usecase.rand = rand.New(rand.NewSource(1)) // テストの乱択結果を固定
+	repo := &fakeDrawRepository{picked: newVerifiedDraw(t, "post-2", "fortune-2")}
+	usecase := NewFortuneUsecase(NewUniformStrategy(repo), nil, 0, nil)
 
-	got, err := usecase.DrawFortune(context.Background())
+	got, err := usecase.DrawFortune(context.Background(), nil)
 	if err != nil {
 		t.Fatalf("DrawFortune() error = %v", err)
 	}
 	if got == nil {
-		t.Fatal("DrawFortune() returned nil draw")
+		t.Fatal("DrawFortune() returned nil output")
+	}
+	if got.Draw.Status() != drawdomain.StatusVerified {
+		t.Fatalf("expected verified status, got %s", got.Draw.Status())
+	}
+	if got.Draw.PostID() != post.DarkPostID("post-2") {
+		t.Fatalf("unexpected draw selected, got %s", got.Draw.PostID())
+	}
+	if repo.pickCalls != 1 {
+		t.Fatalf("expected PickRandom to be called once, got %d", repo.pickCalls)
+	}
+}
+
+func TestDrawFortune_IncludesReactionCounts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	reactions := repoMemory.NewInMemoryReactionRepository()
+	saved, _ := reaction.New("post-a", reaction.KindSaved, "client-2")
+	if err := reactions.Add(ctx, saved); err != nil {
+		t.Fatalf("add reaction: %v", err)
 	}
-	if got.Status() != drawdomain.StatusVerified {
-		t.Fatalf("expected verified status, got %s", got.Status())
+	usecase := NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a")), nil, 0, reactions)
+	got, err := usecase.DrawFortune(ctx, nil)
+	if err != nil {
+		t.Fatalf("DrawFortune() error = %v", err)
 	}
-	if got.PostID() != post.DarkPostID("post-2") {
-		t.Fatalf("unexpected draw selected, got %s", got.PostID())
+	if got.Reactions[reaction.KindSaved] != 1 || got.Reactions[reaction.KindLaughed] != 0 {
+		t.Fatalf("unexpected counts: %v", got.Reactions)
+	}
+
+	// 件数の保存先が無ければすべて 0 で返す
+	got, err = NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a")), nil, 0, nil).DrawFortune(ctx, nil)
+	if err != nil || got.Reactions.Total() != 0 || len(got.Reactions) != len(reaction.Kinds()) {
+		t.Fatalf("unexpected counts without repository: %v, %v", got, err)
+	}
+}
+
+func TestDrawFortune_ExcludesRequesterPosts(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakeDrawRepository{picked: newVerifiedDraw(t, "post-2", "fortune-2")}
+	usecase := NewFortuneUsecase(NewUniformStrategy(repo), nil, 0, nil)
+
+	if _, err := usecase.DrawFortune(context.Background(), &DrawFortuneInput{ClientID: "client-1"}); err != nil {
+		t.Fatalf("DrawFortune() error = %v", err)
+	}
+	if repo.lastFilter.ExcludeAuthor != post.ClientID("client-1") {
+		t.Fatalf("expected requester to be excluded, got %+v", repo.lastFilter)
 	}
 }
 
 func TestDrawFortune_EmptyResults(t *testing.T) {
 	t.Parallel()
 
-	usecase := NewFortuneUsecase(&fakeDrawRepository{})
-	_, err := usecase.DrawFortune(context.Background())
+	usecase := NewFortuneUsecase(NewUniformStrategy(&fakeDrawRepository{pickErr: repository.ErrDrawNotFound}), nil, 0, nil)
+	_, err := usecase.DrawFortune(context.Background(), nil)
 	if !errors.Is(err, drawdomain.ErrEmptyResult) {
 		t.Fatalf("expected ErrEmptyResult, got %v", err)
 	}
 }
 
+func TestDrawFortune_UnverifiedPickIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	pending, err := drawdomain.New(post.DarkPostID("post-1"), "fortune")
+	if err != nil {
+		t.Fatalf("drawdomain.New() error = %v", err)
+	}
+	usecase := NewFortuneUsecase(NewUniformStrategy(&fakeDrawRepository{picked: pending}), nil, 0, nil)
+	if _, err := usecase.DrawFortune(context.Background(), nil); !errors.Is(err, drawdomain.ErrEmptyResult) {
+		t.Fatalf("expected ErrEmptyResult, got %v", err)
+	}
+}
+
 func TestDrawFortune_RepositoryError(t *testing.T) {
 	t.Parallel()
 
 	expectedErr := errors.New("repository failure")
-	usecase := NewFortuneUsecase(&fakeDrawRepository{listErr: expectedErr})
+	usecase := NewFortuneUsecase(NewUniformStrategy(&fakeDrawRepository{pickErr: expectedErr}), nil, 0, nil)
 
-	_, err := usecase.DrawFortune(context.Background())
+	_, err := usecase.DrawFortune(context.Background(), nil)
 	if !errors.Is(err, expectedErr) {
 		t.Fatalf("expected %v, got %v", expectedErr, err)
 	}
 }
 
-type fakeDrawRepository struct {
-	repository.DrawRepository
+func TestDrawFortune_AvoidsRecentDraws(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	history := repoMemory.NewInMemoryDrawHistoryRepository()
+	usecase := NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a", "post-b")), history, 5, nil)
+	if err := history.Record(ctx, "client-1", "post-a", 5); err != nil {
+		t.Fatalf("record: %v", err)
+	}
 
-	draws   []*drawdomain.Draw
-	listErr error
+	for i := 0; i < 5; i++ {
+		got, err := usecase.DrawFortune(ctx, &DrawFortuneInput{ClientID: "client-1"})
+		if err != nil {
+			t.Fatalf("DrawFortune() error = %v", err)
+		}
+		// a と b を交互に避けるため、続けて同じ結果は出ない
+		recent, _ := history.Recent(ctx, "client-1", 2)
+		if len(recent) != 2 || recent[0] != got.Draw.PostID() || recent[1] == got.Draw.PostID() {
+			t.Fatalf("draw %d repeated the previous result: got %s, history %v", i, got.Draw.PostID(), recent)
+		}
+	}
+}
+
+func TestDrawFortune_FallsBackWhenRecentDrawsExhaustPool(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	history := repoMemory.NewInMemoryDrawHistoryRepository()
+	for _, id := range []post.DarkPostID{"post-a", "post-b"} {
+		if err := history.Record(ctx, "client-1", id, 5); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	// 2 件とも直近に引いているので、直前の b だけを避けて a を返す
+	usecase := NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a", "post-b")), history, 5, nil)
+	got, err := usecase.DrawFortune(ctx, &DrawFortuneInput{ClientID: "client-1"})
+	if err != nil {
+		t.Fatalf("DrawFortune() error = %v", err)
+	}
+	if got.Draw.PostID() != "post-a" {
+		t.Fatalf("expected post-a, got %s", got.Draw.PostID())
+	}
+
+	// 1 件しか無ければ直前と同じでも返す
+	usecase = NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a")), history, 5, nil)
+	got, err = usecase.DrawFortune(ctx, &DrawFortuneInput{ClientID: "client-1"})
+	if err != nil {
+		t.Fatalf("DrawFortune() error = %v", err)
+	}
+	if got.Draw.PostID() != "post-a" {
+		t.Fatalf("expected post-a, got %s", got.Draw.PostID())
+	}
+}
+
+func TestDrawFortune_HistoryError(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("history failure")
+	usecase := NewFortuneUsecase(NewUniformStrategy(newDrawPool(t, "post-a")), &failingDrawHistory{err: expectedErr}, 5, nil)
+	if _, err := usecase.DrawFortune(context.Background(), &DrawFortuneInput{ClientID: "client-1"}); !errors.Is(err, expectedErr) {
+		t.Fatalf("expected %v, got %v", expectedErr, err)
+	}
+	// クライアント ID が無ければ履歴を読まない
+	if _, err := usecase.DrawFortune(context.Background(), nil); err != nil {
+		t.Fatalf("DrawFortune() without client error = %v", err)
+	}
 }
 
-func (f *fakeDrawRepository) Create(ctx context.Context, d *drawdomain.Draw) error {
-	return nil
+// newDrawPool は指定した ID の Verified な結果を持つメモリリポジトリを返す。
+func newDrawPool(t *testing.T, ids ...string) *repoMemory.InMemoryDrawRepository {
+	t.Helper()
+	repo := repoMemory.NewInMemoryDrawRepository()
+	for _, id := range ids {
+		if err := repo.Create(context.Background(), newVerifiedDraw(t, id, "fortune")); err != nil {
+			t.Fatalf("create draw: %v", err)
+		}
+	}
+	return repo
 }
 
-func (f *fakeDrawRepository) GetByPostID(ctx context.Context, postID post.DarkPostID) (*drawdomain.Draw, error) {
-	return nil, repository.ErrDrawNotFound
+// failingDrawHistory は常に同じエラーを返す DrawHistoryRepository。
+type failingDrawHistory struct {
+	err error
+}
+
+func (f *failingDrawHistory) Recent(ctx context.Context, client post.ClientID, limit int) ([]post.DarkPostID, error) {
+	return nil, f.err
+}
+
+func (f *failingDrawHistory) Record(ctx context.Context, client post.ClientID, postID post.DarkPostID, keep int) error {
+	return f.err
+}
+
+// fakeDrawRepository は PickRandom だけを持つ。ListReady を呼ぶと埋め込んだ nil インターフェースで panic する。
+type fakeDrawRepository struct {
+	repository.DrawRepository
+
+	picked     *drawdomain.Draw
+	pickErr    error
+	pickCalls  int
+	lastFilter repository.DrawFilter
 }
 
-func (f *fakeDrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, error) {
-	if f.listErr != nil {
-		return nil, f.listErr
+func (f *fakeDrawRepository) PickRandom(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	f.pickCalls++
+	f.lastFilter = filter
+	if f.pickErr != nil {
+		return nil, f.pickErr
 	}
-	return f.draws, nil
+	return f.picked, nil
 }
 
 func newVerifiedDraw(t *testing.T, postID, result string) *drawdomain.Draw {
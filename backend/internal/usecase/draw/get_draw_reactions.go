@@ -0,0 +1,30 @@
+package draw
+
+import (
+	"context"
+
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+)
+
+// 公開中のおみくじへのリアクションの件数を返すユースケース。
+type GetDrawReactionsUsecase struct {
+	drawRepo  repository.DrawRepository
+	reactions repository.ReactionRepository
+}
+
+// 依存をまとめてリアクション件数の参照用ユースケースを組み立てる。
+func NewGetDrawReactionsUsecase(drawRepo repository.DrawRepository, reactions repository.ReactionRepository) *GetDrawReactionsUsecase {
+	return &GetDrawReactionsUsecase{drawRepo: drawRepo, reactions: reactions}
+}
+
+/**
+ * 公開中のおみくじであることを確かめ、種類別の件数を返す。
+ */
+func (u *GetDrawReactionsUsecase) Execute(ctx context.Context, postID string) (reaction.Counts, error) {
+	d, err := publishedDraw(ctx, u.drawRepo, postID)
+	if err != nil {
+		return nil, err
+	}
+	return countsOf(ctx, u.reactions, d.PostID())
+}
@@ -0,0 +1,48 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+var (
+	ErrNilInput     = errors.New("draw: 入力が指定されていません")
+	ErrEmptyPostID  = errors.New("draw: おみくじの投稿 ID が指定されていません")
+	ErrDrawNotFound = errors.New("draw: 対象のおみくじが見つかりません")
+)
+
+/**
+ * 投稿 ID のおみくじを取得し、公開中（verified）であることを確かめる。
+ */
+func publishedDraw(ctx context.Context, drawRepo repository.DrawRepository, rawID string) (*drawdomain.Draw, error) {
+	d, err := findDraw(ctx, drawRepo, rawID)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status() != drawdomain.StatusVerified {
+		return nil, ErrDrawNotFound
+	}
+	return d, nil
+}
+
+/**
+ * 投稿 ID のおみくじを状態によらず取得する。
+ */
+func findDraw(ctx context.Context, drawRepo repository.DrawRepository, rawID string) (*drawdomain.Draw, error) {
+	if rawID == "" {
+		return nil, ErrEmptyPostID
+	}
+	d, err := drawRepo.GetByPostID(ctx, post.DarkPostID(rawID))
+	if errors.Is(err, repository.ErrDrawNotFound) {
+		return nil, ErrDrawNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get draw: %w", err)
+	}
+	return d, nil
+}
@@ -0,0 +1,72 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+)
+
+/**
+ * おみくじへのリアクションの入力値
+ * PostID: リアクション先のおみくじの投稿 ID
+ * Kind: リアクションの種類（saved / laughed / pierced）
+ * ClientID: 任意。リアクションした匿名クライアントの ID。同じ種類を何度送っても 1 回だけ数える
+ */
+type ReactDrawInput struct {
+	PostID   string
+	Kind     string
+	ClientID string
+}
+
+// 公開中のおみくじへリアクションを 1 件加えるユースケース。
+type ReactDrawUsecase struct {
+	drawRepo  repository.DrawRepository
+	reactions repository.ReactionRepository
+}
+
+// 依存をまとめてリアクション用ユースケースを組み立てる。
+func NewReactDrawUsecase(drawRepo repository.DrawRepository, reactions repository.ReactionRepository) *ReactDrawUsecase {
+	return &ReactDrawUsecase{drawRepo: drawRepo, reactions: reactions}
+}
+
+/**
+ * リアクションを数え、数えた後の種類別の件数を返す。
+ * 同じクライアントの同じ種類の再送はエラーにせず、数え直さずに件数を返す。
+ */
+func (u *ReactDrawUsecase) Execute(ctx context.Context, in *ReactDrawInput) (reaction.Counts, error) {
+	if in == nil {
+		return nil, ErrNilInput
+	}
+	kind, err := reaction.ParseKind(in.Kind)
+	if err != nil {
+		return nil, err
+	}
+	d, err := publishedDraw(ctx, u.drawRepo, in.PostID)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := reaction.New(d.PostID(), kind, post.ClientID(in.ClientID))
+	if err != nil {
+		return nil, err
+	}
+	if err := u.reactions.Add(ctx, re); err != nil && !errors.Is(err, repository.ErrReactionAlreadyExists) {
+		return nil, fmt.Errorf("add reaction: %w", err)
+	}
+	return countsOf(ctx, u.reactions, d.PostID())
+}
+
+/**
+ * おみくじ 1 件のリアクションの件数を返す。
+ */
+func countsOf(ctx context.Context, reactions repository.ReactionRepository, postID post.DarkPostID) (reaction.Counts, error) {
+	all, err := reactions.Counts(ctx, []post.DarkPostID{postID})
+	if err != nil {
+		return nil, fmt.Errorf("load reaction counts: %w", err)
+	}
+	return all[postID], nil
+}
@@ -0,0 +1,70 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+)
+
+func TestReactDraw_CountsOncePerClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	reactions := repoMemory.NewInMemoryReactionRepository()
+	usecase := NewReactDrawUsecase(newDrawPool(t, "post-a"), reactions)
+
+	for _, client := range []string{"client-1", "client-1", "client-2"} {
+		if _, err := usecase.Execute(ctx, &ReactDrawInput{PostID: "post-a", Kind: "saved", ClientID: client}); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	got, err := usecase.Execute(ctx, &ReactDrawInput{PostID: "post-a", Kind: "pierced", ClientID: "client-1"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got[reaction.KindSaved] != 2 || got[reaction.KindPierced] != 1 || got[reaction.KindLaughed] != 0 {
+		t.Fatalf("unexpected counts: %v", got)
+	}
+
+	counts, err := NewGetDrawReactionsUsecase(newDrawPool(t, "post-a"), reactions).Execute(ctx, "post-a")
+	if err != nil || counts.Total() != 3 {
+		t.Fatalf("unexpected counts: %v, %v", counts, err)
+	}
+}
+
+func TestReactDraw_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	drawRepo := newDrawPool(t, "post-a")
+	pending, err := drawdomain.New(post.DarkPostID("post-pending"), "fortune")
+	if err != nil {
+		t.Fatalf("drawdomain.New() error = %v", err)
+	}
+	if err := drawRepo.Create(ctx, pending); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	usecase := NewReactDrawUsecase(drawRepo, repoMemory.NewInMemoryReactionRepository())
+
+	cases := []struct {
+		name string
+		in   *ReactDrawInput
+		want error
+	}{
+		{name: "nil input", in: nil, want: ErrNilInput},
+		{name: "invalid kind", in: &ReactDrawInput{PostID: "post-a", Kind: "angry"}, want: reaction.ErrInvalidKind},
+		{name: "empty post id", in: &ReactDrawInput{Kind: "saved"}, want: ErrEmptyPostID},
+		{name: "unknown draw", in: &ReactDrawInput{PostID: "missing", Kind: "saved"}, want: ErrDrawNotFound},
+		{name: "unverified draw", in: &ReactDrawInput{PostID: "post-pending", Kind: "saved"}, want: ErrDrawNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := usecase.Execute(ctx, tc.in); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+/**
+ * おみくじへの通報の入力値
+ * PostID: 通報先のおみくじの投稿 ID
+ * Reason: 通報理由（abusive / personal_info / self_harm / spam / other）
+ * Note: 任意。補足（report.MaxNoteLength 文字まで）
+ * ClientID: 任意。通報した匿名クライアントの ID。同じおみくじへは 1 回だけ数える
+ * RemoteIP: 任意。信頼できるプロキシを通して得た接続元の IP アドレス。自動の取り下げはこのネットワークの数で判断する
+ */
+type ReportDrawInput struct {
+	PostID   string
+	Reason   string
+	Note     string
+	ClientID string
+	RemoteIP string
+}
+
+// 公開中のおみくじへの通報を受け付け、通報元のネットワークの数がしきい値に達したら取り下げるユースケース。
+type ReportDrawUsecase struct {
+	postRepo repository.PostRepository
+	drawRepo repository.DrawRepository
+	reports  repository.ReportRepository
+	writer   repository.ModerationWriter
+	auditLog repository.AuditLog
+	// 自動で取り下げる未確認の通報元のネットワークの数（0 以下なら自動では取り下げない）
+	threshold int
+}
+
+// 依存と自動で取り下げる数をまとめて通報用ユースケースを組み立てる。auditLog が nil なら取り下げを記録しない。
+func NewReportDrawUsecase(postRepo repository.PostRepository, drawRepo repository.DrawRepository, reports repository.ReportRepository, writer repository.ModerationWriter, auditLog repository.AuditLog, threshold int) *ReportDrawUsecase {
+	return &ReportDrawUsecase{postRepo: postRepo, drawRepo: drawRepo, reports: reports, writer: writer, auditLog: auditLog, threshold: threshold}
+}
+
+/**
+ * 通報を保存し、未確認の通報が届いたネットワークの数がしきい値に達したおみくじを取り下げる。
+ * クライアント ID は作り直せるため件数には使わず、通報元が分からない通報は取り下げの判断に数えない。
+ * 同じクライアントからの再送はエラーにせず受け付けたものとして扱う。
+ */
+func (u *ReportDrawUsecase) Execute(ctx context.Context, in *ReportDrawInput) error {
+	if in == nil {
+		return ErrNilInput
+	}
+	reason, err := report.ParseReason(in.Reason)
+	if err != nil {
+		return err
+	}
+	d, err := publishedDraw(ctx, u.drawRepo, in.PostID)
+	if err != nil {
+		return err
+	}
+
+	re, err := report.New(d.PostID(), reason, in.Note, post.ClientID(in.ClientID), report.OriginFromIP(in.RemoteIP))
+	if err != nil {
+		return err
+	}
+	summary, err := u.reports.Add(ctx, re)
+	if errors.Is(err, repository.ErrReportAlreadyExists) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("add report: %w", err)
+	}
+
+	if u.threshold <= 0 || summary.OpenOrigins < u.threshold {
+		return nil
+	}
+	return u.takeDown(ctx, d, summary)
+}
+
+/**
+ * おみくじ結果を公開不可に、公開中の投稿を rejected にそろえ、1 つの操作で書き込んで監査ログへ残す。
+ * 同時に届いた通報で先に取り下げられていれば、そのままにする。
+ */
+func (u *ReportDrawUsecase) takeDown(ctx context.Context, d *drawdomain.Draw, summary *repository.ReportSummary) error {
+	p, err := u.postRepo.Get(ctx, d.PostID())
+	if err != nil {
+		return fmt.Errorf("get post: %w", err)
+	}
+	details := map[string]string{
+		"post_from":    string(p.Status()),
+		"draw_from":    string(d.Status()),
+		"open_reports": strconv.Itoa(summary.OpenCount),
+		"open_origins": strconv.Itoa(summary.OpenOrigins),
+	}
+	from := repository.ModerationState{Post: p.Status(), Draw: d.Status()}
+	reason := fmt.Sprintf("%d か所からの通報が届いたため公開を停止しました", summary.OpenOrigins)
+	if err := d.TakeDown(reason); err != nil {
+		if errors.Is(err, drawdomain.ErrInvalidTransition) {
+			return nil
+		}
+		return err
+	}
+	details["reason"] = reason
+	// 公開終了などで投稿を rejected にできなければ、おみくじ結果だけを止める
+	if p.Status().CanTransitionTo(post.StatusRejected) {
+		if err := p.MarkRejected(); err != nil {
+			return err
+		}
+	}
+	if err := u.writer.Apply(ctx, p, d, from); err != nil {
+		// 同時に管理者の判断などが書き込まれていれば、そちらを優先して公開停止を見送る
+		if errors.Is(err, repository.ErrModerationConflict) {
+			return nil
+		}
+		return fmt.Errorf("take down draw: %w", err)
+	}
+	u.recordAudit(ctx, p.ID(), details)
+	return nil
+}
+
+/**
+ * 通報のしきい値を主体として取り下げを監査ログへ追記する。記録に失敗しても取り下げ済みの結果は戻せないため、通報の失敗にはしない。
+ */
+func (u *ReportDrawUsecase) recordAudit(ctx context.Context, id post.DarkPostID, details map[string]string) {
+	if u.auditLog == nil {
+		return
+	}
+	e, err := audit.New(id, audit.ActionDrawRejected, audit.Reports(), details)
+	if err != nil {
+		return
+	}
+	_ = u.auditLog.Append(ctx, e)
+}
@@ -0,0 +1,201 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/report"
+	"backend/internal/port/repository"
+)
+
+func TestReportDraw_TakesDownAtThreshold(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	f := newReportFixture(t, 2, "post-a")
+	report := func(client, ip string) error {
+		return f.usecase.Execute(ctx, &ReportDrawInput{PostID: "post-a", Reason: "abusive", ClientID: client, RemoteIP: ip})
+	}
+	if err := report("client-1", "192.0.2.1"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	// 同じクライアントの再送も、クライアント ID を作り直した同じネットワークからの通報も数えない
+	if err := report("client-1", "198.51.100.7"); err != nil {
+		t.Fatalf("duplicate report should be accepted, got %v", err)
+	}
+	for _, client := range []string{"client-2", "client-3", "client-4"} {
+		if err := report(client, "192.0.2.1"); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	// 通報元が分からない通報も数えない
+	if err := report("client-5", ""); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if d, _ := f.drawRepo.GetByPostID(ctx, "post-a"); d.Status() != drawdomain.StatusVerified {
+		t.Fatalf("draw should stay published below threshold, got %s", d.Status())
+	}
+	if events, _ := f.auditLog.ListByPost(ctx, "post-a"); len(events) != 0 {
+		t.Fatalf("no audit event expected below threshold, got %d", len(events))
+	}
+
+	if err := report("client-6", "2001:db8::1"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	d, _ := f.drawRepo.GetByPostID(ctx, "post-a")
+	if d.Status() != drawdomain.StatusRejected || d.Reason() == "" {
+		t.Fatalf("draw should be taken down, got %s %q", d.Status(), d.Reason())
+	}
+	// 投稿も同じ書き込みで rejected にそろう
+	if p, _ := f.postRepo.Get(ctx, "post-a"); p.Status() != post.StatusRejected {
+		t.Fatalf("post should be rejected together, got %s", p.Status())
+	}
+	if ready, _ := f.drawRepo.ListReady(ctx); len(ready) != 0 {
+		t.Fatalf("taken down draw should leave ListReady, got %d", len(ready))
+	}
+	events, _ := f.auditLog.ListByPost(ctx, "post-a")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Action() != audit.ActionDrawRejected || e.Actor() != audit.Reports() ||
+		e.Details()["open_origins"] != "2" || e.Details()["open_reports"] != "6" || e.Details()["post_from"] != string(post.StatusReady) {
+		t.Fatalf("unexpected audit event: %s %+v %v", e.Action(), e.Actor(), e.Details())
+	}
+	// 取り下げ後は公開中ではないため通報を受け付けない
+	if err := report("client-7", "203.0.113.9"); !errors.Is(err, ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+}
+
+func TestReportDraw_WriterFailureLeavesBothPublished(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	f := newReportFixture(t, 1, "post-a")
+	f.usecase.writer = failingModerationWriter{err: errors.New("boom")}
+	if err := f.usecase.Execute(ctx, &ReportDrawInput{PostID: "post-a", Reason: "spam", ClientID: "a", RemoteIP: "192.0.2.1"}); err == nil {
+		t.Fatal("expected error from writer")
+	}
+	if d, _ := f.drawRepo.GetByPostID(ctx, "post-a"); d.Status() != drawdomain.StatusVerified {
+		t.Fatalf("draw should stay published, got %s", d.Status())
+	}
+	if p, _ := f.postRepo.Get(ctx, "post-a"); p.Status() != post.StatusReady {
+		t.Fatalf("post should stay ready, got %s", p.Status())
+	}
+	if events, _ := f.auditLog.ListByPost(ctx, "post-a"); len(events) != 0 {
+		t.Fatalf("failed takedown should not be audited, got %d", len(events))
+	}
+}
+
+func TestReportDraw_ZeroThresholdNeverTakesDown(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	f := newReportFixture(t, 0, "post-a")
+	for i, client := range []string{"a", "b", "c", "d"} {
+		in := &ReportDrawInput{PostID: "post-a", Reason: "spam", ClientID: client, RemoteIP: fmt.Sprintf("192.0.2.%d", i+1)}
+		if err := f.usecase.Execute(ctx, in); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+	}
+	if d, _ := f.drawRepo.GetByPostID(ctx, "post-a"); d.Status() != drawdomain.StatusVerified {
+		t.Fatalf("draw should stay published, got %s", d.Status())
+	}
+}
+
+func TestReportDraw_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	usecase := newReportFixture(t, 3, "post-a").usecase
+	cases := []struct {
+		name string
+		in   *ReportDrawInput
+		want error
+	}{
+		{name: "nil input", in: nil, want: ErrNilInput},
+		{name: "invalid reason", in: &ReportDrawInput{PostID: "post-a", Reason: "boring"}, want: report.ErrInvalidReason},
+		{name: "note too long", in: &ReportDrawInput{PostID: "post-a", Reason: "other", Note: strings.Repeat("闇", report.MaxNoteLength+1)}, want: report.ErrNoteTooLong},
+		{name: "unknown draw", in: &ReportDrawInput{PostID: "missing", Reason: "spam"}, want: ErrDrawNotFound},
+	}
+	for _, tc := range cases {
+		if err := usecase.Execute(ctx, tc.in); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestListReportedDraws(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	f := newReportFixture(t, 2, "post-a", "post-b")
+	drawRepo, reports, reportDraw := f.drawRepo, f.reports, f.usecase
+	for _, in := range []*ReportDrawInput{
+		{PostID: "post-a", Reason: "abusive", ClientID: "c1"},
+		{PostID: "post-a", Reason: "spam", ClientID: "c2"},
+		{PostID: "post-b", Reason: "other", ClientID: "c1"},
+	} {
+		if err := reportDraw.Execute(ctx, in); err != nil {
+			t.Fatalf("report: %v", err)
+		}
+	}
+
+	listed, err := NewListReportedDrawsUsecase(drawRepo, reports).Execute(ctx, 0)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("list: %+v, %v", listed, err)
+	}
+	for _, item := range listed {
+		if item.Draw == nil || item.Draw.PostID() != item.Summary.PostID {
+			t.Fatalf("listed item should carry its draw: %+v", item)
+		}
+	}
+}
+
+// reportFixture は公開中の投稿とおみくじ結果をそろえた通報用ユースケースの依存。
+type reportFixture struct {
+	postRepo *repoMemory.InMemoryPostRepository
+	drawRepo *repoMemory.InMemoryDrawRepository
+	reports  *repoMemory.InMemoryReportRepository
+	auditLog *repoMemory.InMemoryAuditLog
+	usecase  *ReportDrawUsecase
+}
+
+func newReportFixture(t *testing.T, threshold int, ids ...string) *reportFixture {
+	t.Helper()
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	for _, id := range ids {
+		p, _ := post.Restore(post.DarkPostID(id), "闇", post.StatusReady)
+		if err := postRepo.Create(context.Background(), p); err != nil {
+			t.Fatalf("create post: %v", err)
+		}
+	}
+	drawRepo := newDrawPool(t, ids...)
+	reports := repoMemory.NewInMemoryReportRepository()
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	writer := repoMemory.NewInMemoryModerationWriter(postRepo, drawRepo)
+	return &reportFixture{
+		postRepo: postRepo,
+		drawRepo: drawRepo,
+		reports:  reports,
+		auditLog: auditLog,
+		usecase:  NewReportDrawUsecase(postRepo, drawRepo, reports, writer, auditLog, threshold),
+	}
+}
+
+// failingModerationWriter は何も書き込まずに同じエラーを返す ModerationWriter。
+type failingModerationWriter struct {
+	err error
+}
+
+func (w failingModerationWriter) Apply(ctx context.Context, p *post.Post, d *drawdomain.Draw, from repository.ModerationState) error {
+	return w.err
+}
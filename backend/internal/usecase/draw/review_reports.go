@@ -0,0 +1,63 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/port/repository"
+)
+
+const (
+	// DefaultReportListLimit は通報一覧で件数の指定が無い場合に返す件数。
+	DefaultReportListLimit = 50
+	// MaxReportListLimit は通報一覧で 1 回に返す件数の上限。
+	MaxReportListLimit = 200
+)
+
+/**
+ * 未確認の通報が届いているおみくじ
+ * Summary: 未確認の通報の件数と理由ごとの件数
+ * Draw: 通報先のおみくじ。すでに削除されていれば nil
+ */
+type ReportedDraw struct {
+	Summary repository.ReportSummary
+	Draw    *drawdomain.Draw
+}
+
+// 未確認の通報が届いているおみくじを一覧するユースケース。
+type ListReportedDrawsUsecase struct {
+	drawRepo repository.DrawRepository
+	reports  repository.ReportRepository
+}
+
+// 依存をまとめて通報一覧用ユースケースを組み立てる。
+func NewListReportedDrawsUsecase(drawRepo repository.DrawRepository, reports repository.ReportRepository) *ListReportedDrawsUsecase {
+	return &ListReportedDrawsUsecase{drawRepo: drawRepo, reports: reports}
+}
+
+/**
+ * 最後に通報された順に最大 limit 件を、おみくじの本文と状態を添えて返す。
+ * limit が 0 以下なら DefaultReportListLimit、MaxReportListLimit を超えれば上限に丸める。
+ */
+func (u *ListReportedDrawsUsecase) Execute(ctx context.Context, limit int) ([]ReportedDraw, error) {
+	if limit <= 0 {
+		limit = DefaultReportListLimit
+	}
+	limit = min(limit, MaxReportListLimit)
+
+	summaries, err := u.reports.ListOpen(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list open reports: %w", err)
+	}
+	result := make([]ReportedDraw, 0, len(summaries))
+	for _, summary := range summaries {
+		d, err := u.drawRepo.GetByPostID(ctx, summary.PostID)
+		if err != nil && !errors.Is(err, repository.ErrDrawNotFound) {
+			return nil, fmt.Errorf("get draw: %w", err)
+		}
+		result = append(result, ReportedDraw{Summary: summary, Draw: d})
+	}
+	return result, nil
+}
@@ -0,0 +1,242 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/repository"
+)
+
+var (
+	ErrInvalidSampleSize = errors.New("draw_selection: 候補の件数は 1 以上を指定してください")
+	ErrInvalidHalfLife   = errors.New("draw_selection: 半減期は正の値を指定してください")
+	ErrNilSampler        = errors.New("draw_selection: 候補を取り出すリポジトリが設定されていません")
+	// ErrSeededPickerUnsupported は種を指定した一様な抽選に、乱数を受け取れないリポジトリを渡した場合に返す
+	ErrSeededPickerUnsupported = errors.New("draw_selection: おみくじのリポジトリが乱数を渡した抽選に対応していません")
+)
+
+/**
+ * Verified なおみくじから 1 件を選ぶ方針。
+ * Select: filter で除かれないものから 1 件を返す（候補が無い場合は repository.ErrDrawNotFound）
+ */
+type SelectionStrategy interface {
+	Select(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error)
+}
+
+/**
+ * おみくじごとの評価を返す契約。評価の高いものほど rating 重み付けで選ばれやすくなる。
+ * Ratings: ids の評価を返す（評価の無いものは含めなくてよい）
+ */
+type RatingSource interface {
+	Ratings(ctx context.Context, ids []post.DarkPostID) (map[post.DarkPostID]int, error)
+}
+
+// UniformStrategy は全件から一様に 1 件を選ぶ。抽選はリポジトリに任せ、件数によらず読み取りは一定。
+type UniformStrategy struct {
+	repo   repository.DrawRepository
+	picker repository.SeededDrawPicker
+	rng    *rand.Rand
+}
+
+// NewUniformStrategy は UniformStrategy を生成する。抽選にはリポジトリ自身の乱数を使う。
+func NewUniformStrategy(repo repository.DrawRepository) *UniformStrategy {
+	return &UniformStrategy{repo: repo}
+}
+
+/**
+ * src から引いた乱数で抽選する UniformStrategy を生成する。種を固定すれば同じおみくじの集合に対して同じ順で選ぶ。
+ * リポジトリは repository.SeededDrawPicker に対応している必要がある（未対応なら ErrSeededPickerUnsupported）。
+ */
+func NewSeededUniformStrategy(repo repository.DrawRepository, src rand.Source) (*UniformStrategy, error) {
+	picker, ok := repo.(repository.SeededDrawPicker)
+	if !ok {
+		return nil, ErrSeededPickerUnsupported
+	}
+	// 同時に呼ばれても乱数の列が壊れないよう、種から引く部分だけを直列にする
+	return &UniformStrategy{repo: repo, picker: picker, rng: rand.New(&lockedSource{src: src})}, nil
+}
+
+// Select はリポジトリの PickRandom で 1 件を選ぶ。種が指定されていれば乱数を渡して選ばせる。
+func (s *UniformStrategy) Select(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	if s.picker != nil {
+		return s.picker.PickRandomWith(ctx, filter, s.rng)
+	}
+	return s.repo.PickRandom(ctx, filter)
+}
+
+// lockedSource は複数の抽選から同時に引かれる乱数源を排他する。
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// weightedStrategy は無作為に取り出した候補へ重みを付け、重みに比例した確率で 1 件を選ぶ。
+// 全件を読まずに済むよう、重みは取り出した候補の中だけで比べる。候補の取り出しにも同じ乱数を渡す。
+type weightedStrategy struct {
+	sampler    repository.DrawSampler
+	sampleSize int
+	weigh      func(ctx context.Context, candidates []*drawdomain.Draw) ([]float64, error)
+	rng        *rand.Rand
+}
+
+func newWeightedStrategy(
+	sampler repository.DrawSampler,
+	sampleSize int,
+	src rand.Source,
+	weigh func(ctx context.Context, candidates []*drawdomain.Draw) ([]float64, error),
+) (*weightedStrategy, error) {
+	if sampler == nil {
+		return nil, ErrNilSampler
+	}
+	if sampleSize < 1 {
+		return nil, ErrInvalidSampleSize
+	}
+	return &weightedStrategy{
+		sampler:    sampler,
+		sampleSize: sampleSize,
+		weigh:      weigh,
+		rng:        rand.New(&lockedSource{src: src}),
+	}, nil
+}
+
+// Select は候補を取り出して重みを付け、1 件を選ぶ。
+func (s *weightedStrategy) Select(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	candidates, err := s.sampler.SampleVerified(ctx, filter, s.sampleSize, s.rng)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, repository.ErrDrawNotFound
+	}
+	weights, err := s.weigh(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[s.choose(weights)], nil
+}
+
+/**
+ * 重みに比例した確率で添字を選ぶ。重みの合計が 0 以下なら一様に選ぶ。
+ */
+func (s *weightedStrategy) choose(weights []float64) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	if total <= 0 {
+		return s.rng.IntN(len(weights))
+	}
+	r := s.rng.Float64() * total
+	for i, w := range weights {
+		r -= w
+		if r < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+/**
+ * 作成からの経過時間に応じて重みを半減させる方針を生成する。
+ * halfLife 経過するごとに選ばれやすさが半分になり、新しいおみくじほど出やすい。
+ */
+func NewRecencyWeightedStrategy(sampler repository.DrawSampler, sampleSize int, halfLife time.Duration, src rand.Source, now func() time.Time) (SelectionStrategy, error) {
+	if halfLife <= 0 {
+		return nil, ErrInvalidHalfLife
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return newWeightedStrategy(sampler, sampleSize, src, func(ctx context.Context, candidates []*drawdomain.Draw) ([]float64, error) {
+		return recencyWeights(candidates, halfLife, now()), nil
+	})
+}
+
+/**
+ * 経過時間から重みを求める。作成日時が分からないものは、候補の中で最も古いものと同じ重みにする。
+ */
+func recencyWeights(candidates []*drawdomain.Draw, halfLife time.Duration, now time.Time) []float64 {
+	weights := make([]float64, len(candidates))
+	oldest := 1.0
+	for i, d := range candidates {
+		if d.CreatedAt().IsZero() {
+			weights[i] = -1
+			continue
+		}
+		age := max(now.Sub(d.CreatedAt()), 0)
+		weights[i] = math.Exp2(-float64(age) / float64(halfLife))
+		oldest = min(oldest, weights[i])
+	}
+	for i, w := range weights {
+		if w < 0 {
+			weights[i] = oldest
+		}
+	}
+	return weights
+}
+
+/**
+ * 評価に応じて重みを増やす方針を生成する。重みは 1 + 評価で、評価の無いものも選ばれうる。
+ * ratings が nil ならすべて同じ重みになり、候補の中から一様に選ぶ。
+ */
+func NewRatingWeightedStrategy(sampler repository.DrawSampler, sampleSize int, ratings RatingSource, src rand.Source) (SelectionStrategy, error) {
+	return newWeightedStrategy(sampler, sampleSize, src, func(ctx context.Context, candidates []*drawdomain.Draw) ([]float64, error) {
+		weights := make([]float64, len(candidates))
+		for i := range weights {
+			weights[i] = 1
+		}
+		if ratings == nil {
+			return weights, nil
+		}
+
+		ids := make([]post.DarkPostID, len(candidates))
+		for i, d := range candidates {
+			ids[i] = d.PostID()
+		}
+		scores, err := ratings.Ratings(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("load draw ratings: %w", err)
+		}
+		for i, id := range ids {
+			weights[i] += float64(max(scores[id], 0))
+		}
+		return weights, nil
+	})
+}
+
+// ReactionRatings はリアクションの合計件数をおみくじの評価として返す RatingSource。
+type ReactionRatings struct {
+	reactions repository.ReactionRepository
+}
+
+// NewReactionRatings は ReactionRatings を生成する。
+func NewReactionRatings(reactions repository.ReactionRepository) *ReactionRatings {
+	return &ReactionRatings{reactions: reactions}
+}
+
+// Ratings は ids ごとのリアクションの合計件数を返す。
+func (r *ReactionRatings) Ratings(ctx context.Context, ids []post.DarkPostID) (map[post.DarkPostID]int, error) {
+	counts, err := r.reactions.Counts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	ratings := make(map[post.DarkPostID]int, len(counts))
+	for id, c := range counts {
+		ratings[id] = c.Total()
+	}
+	return ratings, nil
+}
@@ -0,0 +1,216 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/domain/reaction"
+	"backend/internal/port/repository"
+)
+
+func TestRecencyWeightedStrategy_FavoursFreshDraws(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fresh := newVerifiedDrawAt(t, "fresh", now.Add(-time.Hour))
+	old := newVerifiedDrawAt(t, "old", now.Add(-30*24*time.Hour))
+	strategy, err := NewRecencyWeightedStrategy(&fakeSampler{draws: []*drawdomain.Draw{old, fresh}}, 10, 7*24*time.Hour, rand.NewPCG(1, 2), func() time.Time { return now })
+	if err != nil {
+		t.Fatalf("NewRecencyWeightedStrategy() error = %v", err)
+	}
+
+	counts := countSelections(t, strategy, 1000)
+	// 30 日前のものは半減期 7 日で重みが 1/16 程度になる
+	if counts["fresh"] < 900 {
+		t.Fatalf("fresh draw should dominate, got %v", counts)
+	}
+	if counts["old"] == 0 {
+		t.Fatalf("old draw should still be selectable, got %v", counts)
+	}
+}
+
+func TestRecencyWeights_UnknownCreatedAtIsOldest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	weights := recencyWeights([]*drawdomain.Draw{
+		newVerifiedDrawAt(t, "new", now),
+		newVerifiedDrawAt(t, "day", now.Add(-24*time.Hour)),
+		newVerifiedDrawAt(t, "unknown", time.Time{}),
+	}, 24*time.Hour, now)
+
+	if weights[0] != 1 || weights[1] != 0.5 || weights[2] != 0.5 {
+		t.Fatalf("unexpected weights: %v", weights)
+	}
+}
+
+func TestRatingWeightedStrategy_FavoursWellRatedDraws(t *testing.T) {
+	t.Parallel()
+
+	sampler := &fakeSampler{draws: []*drawdomain.Draw{
+		newVerifiedDraw(t, "plain", "fortune"),
+		newVerifiedDraw(t, "loved", "fortune"),
+	}}
+	ratings := fakeRatings{"loved": 9, "plain": -3}
+	strategy, err := NewRatingWeightedStrategy(sampler, 10, ratings, rand.NewPCG(3, 4))
+	if err != nil {
+		t.Fatalf("NewRatingWeightedStrategy() error = %v", err)
+	}
+
+	counts := countSelections(t, strategy, 1000)
+	// 重みは 10 : 1 になる（負の評価は 0 として扱う）
+	if counts["loved"] < 850 || counts["plain"] == 0 {
+		t.Fatalf("unexpected distribution: %v", counts)
+	}
+}
+
+func TestWeightedStrategy_SameSeedSameSequence(t *testing.T) {
+	t.Parallel()
+
+	sampler := &fakeSampler{draws: []*drawdomain.Draw{
+		newVerifiedDraw(t, "a", "fortune"),
+		newVerifiedDraw(t, "b", "fortune"),
+		newVerifiedDraw(t, "c", "fortune"),
+	}}
+	first, _ := NewRatingWeightedStrategy(sampler, 10, nil, rand.NewPCG(7, 7))
+	second, _ := NewRatingWeightedStrategy(sampler, 10, nil, rand.NewPCG(7, 7))
+
+	for i := 0; i < 50; i++ {
+		a, err := first.Select(context.Background(), repository.DrawFilter{})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		b, _ := second.Select(context.Background(), repository.DrawFilter{})
+		if a.PostID() != b.PostID() {
+			t.Fatalf("selection %d diverged: %s vs %s", i, a.PostID(), b.PostID())
+		}
+	}
+}
+
+func TestSeededUniformStrategy_SameSeedSameSequence(t *testing.T) {
+	t.Parallel()
+
+	first, err := NewSeededUniformStrategy(newDrawPool(t, "a", "b", "c", "d"), rand.NewPCG(7, 7))
+	if err != nil {
+		t.Fatalf("NewSeededUniformStrategy() error = %v", err)
+	}
+	second, _ := NewSeededUniformStrategy(newDrawPool(t, "a", "b", "c", "d"), rand.NewPCG(7, 7))
+
+	seen := map[post.DarkPostID]bool{}
+	for i := 0; i < 50; i++ {
+		a, err := first.Select(context.Background(), repository.DrawFilter{})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		b, _ := second.Select(context.Background(), repository.DrawFilter{})
+		if a.PostID() != b.PostID() {
+			t.Fatalf("selection %d diverged: %s vs %s", i, a.PostID(), b.PostID())
+		}
+		seen[a.PostID()] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("seeded selection should still vary between draws, got %v", seen)
+	}
+
+	// 乱数を受け取れないリポジトリでは種を反映できない
+	if _, err := NewSeededUniformStrategy(&fakeDrawRepository{}, rand.NewPCG(7, 7)); !errors.Is(err, ErrSeededPickerUnsupported) {
+		t.Fatalf("expected ErrSeededPickerUnsupported, got %v", err)
+	}
+}
+
+func TestWeightedStrategy_Errors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	src := rand.NewPCG(1, 1)
+
+	if _, err := NewRatingWeightedStrategy(nil, 10, nil, src); !errors.Is(err, ErrNilSampler) {
+		t.Fatalf("expected ErrNilSampler, got %v", err)
+	}
+	if _, err := NewRatingWeightedStrategy(&fakeSampler{}, 0, nil, src); !errors.Is(err, ErrInvalidSampleSize) {
+		t.Fatalf("expected ErrInvalidSampleSize, got %v", err)
+	}
+	if _, err := NewRecencyWeightedStrategy(&fakeSampler{}, 10, 0, src, nil); !errors.Is(err, ErrInvalidHalfLife) {
+		t.Fatalf("expected ErrInvalidHalfLife, got %v", err)
+	}
+
+	empty, _ := NewRatingWeightedStrategy(&fakeSampler{}, 10, nil, src)
+	if _, err := empty.Select(ctx, repository.DrawFilter{}); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("expected ErrDrawNotFound, got %v", err)
+	}
+
+	ratingErr := errors.New("ratings down")
+	failing, _ := NewRatingWeightedStrategy(&fakeSampler{draws: []*drawdomain.Draw{newVerifiedDraw(t, "a", "fortune")}}, 10, failingRatings{err: ratingErr}, src)
+	if _, err := failing.Select(ctx, repository.DrawFilter{}); !errors.Is(err, ratingErr) {
+		t.Fatalf("expected %v, got %v", ratingErr, err)
+	}
+}
+
+func countSelections(t *testing.T, strategy SelectionStrategy, n int) map[post.DarkPostID]int {
+	t.Helper()
+	counts := make(map[post.DarkPostID]int)
+	for i := 0; i < n; i++ {
+		d, err := strategy.Select(context.Background(), repository.DrawFilter{})
+		if err != nil {
+			t.Fatalf("Select() error = %v", err)
+		}
+		counts[d.PostID()]++
+	}
+	return counts
+}
+
+func newVerifiedDrawAt(t *testing.T, postID string, createdAt time.Time) *drawdomain.Draw {
+	t.Helper()
+	d := newVerifiedDraw(t, postID, "fortune")
+	d.RestoreCreatedAt(createdAt)
+	return d
+}
+
+// fakeSampler は filter を見ずに同じ候補を返す DrawSampler。
+type fakeSampler struct {
+	draws []*drawdomain.Draw
+}
+
+func (f *fakeSampler) SampleVerified(ctx context.Context, filter repository.DrawFilter, n int, rng *rand.Rand) ([]*drawdomain.Draw, error) {
+	return f.draws, nil
+}
+
+type fakeRatings map[post.DarkPostID]int
+
+func (f fakeRatings) Ratings(ctx context.Context, ids []post.DarkPostID) (map[post.DarkPostID]int, error) {
+	return f, nil
+}
+
+type failingRatings struct {
+	err error
+}
+
+func (f failingRatings) Ratings(ctx context.Context, ids []post.DarkPostID) (map[post.DarkPostID]int, error) {
+	return nil, f.err
+}
+
+func TestReactionRatings_UsesTotalCounts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	reactions := repoMemory.NewInMemoryReactionRepository()
+	for _, kind := range []reaction.Kind{reaction.KindSaved, reaction.KindPierced} {
+		re, _ := reaction.New("post-a", kind, "client-1")
+		if err := reactions.Add(ctx, re); err != nil {
+			t.Fatalf("add reaction: %v", err)
+		}
+	}
+
+	got, err := NewReactionRatings(reactions).Ratings(ctx, []post.DarkPostID{"post-a", "post-b"})
+	if err != nil {
+		t.Fatalf("Ratings() error = %v", err)
+	}
+	if got["post-a"] != 2 || got["post-b"] != 0 {
+		t.Fatalf("unexpected ratings: %v", got)
+	}
+}
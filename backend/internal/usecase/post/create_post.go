@@ -2,47 +2,79 @@ package post
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"time"
 
+	"backend/internal/domain/audit"
 	"backend/internal/domain/post"
+	"backend/internal/port/idgen"
 	"backend/internal/port/queue"
 	"backend/internal/port/repository"
 )
 
 var (
-	ErrNilInput            = errors.New("create_post: 入力が指定されていません")
-	ErrPostAlreadyExists   = errors.New("create_post: 投稿がすでに存在します")
-	ErrJobAlreadyScheduled = errors.New("create_post: 整形ジョブがすでに登録済みです")
+	ErrNilInput             = errors.New("create_post: 入力が指定されていません")
+	ErrPostAlreadyExists    = errors.New("create_post: 投稿がすでに存在します")
+	ErrJobAlreadyScheduled  = errors.New("create_post: 整形ジョブがすでに登録済みです")
+	ErrIdempotencyKeyReused = errors.New("create_post: 冪等キーが別の内容のリクエストで再利用されました")
 )
 
 // 闇投稿作成の入力値
+// IdempotencyKey: 任意。同じクライアントから同じ内容での再送には最初に払い出した投稿 ID を返し、別の内容なら ErrIdempotencyKeyReused
+// ClientID: 任意。投稿した匿名クライアントの ID。本人に自分の投稿のおみくじを出さないために記録する
 type CreatePostInput struct {
-	DarkPostID string
-	Content    string
+	IdempotencyKey string
+	Content        string
+	ClientID       string
 }
 
 // 闇投稿作成後に呼び出し側へ返す値
+// Replayed: 冪等キーの再送で既存の投稿を返した場合 true
 type CreatePostOutput struct {
 	DarkPostID string
+	Replayed   bool
 }
 
 /**
  * 闇投稿作成のユースケース
- * postRepo: 投稿リポジトリ
- * jobQueue: 整形ジョブキュー
+ * postRepo: 投稿リポジトリ（再送時の参照用）
+ * outbox: 投稿と整形ジョブをまとめて書き込むアウトボックス
+ * idGen: 投稿 ID の払い出し
+ * keyRepo: 冪等キーと投稿 ID の対応
+ * keyTTL: 冪等キーの紐づけを保持する期間（0 以下なら期限なし）
+ * auditLog: 投稿の受け付けを残す監査ログ（nil の場合は残さない）
  */
 type CreatePostUsecase struct {
 	postRepo repository.PostRepository
-	jobQueue queue.JobQueue
+	outbox   repository.PostOutbox
+	idGen    idgen.PostIDGenerator
+	keyRepo  repository.IdempotencyKeyRepository
+	keyTTL   time.Duration
+	auditLog repository.AuditLog
+	now      func() time.Time
 }
 
 /**
  * ユースケース毎に初期化
  */
-func NewCreatePostUsecase(postRepo repository.PostRepository, jobQueue queue.JobQueue) *CreatePostUsecase {
+func NewCreatePostUsecase(
+	postRepo repository.PostRepository,
+	outbox repository.PostOutbox,
+	idGen idgen.PostIDGenerator,
+	keyRepo repository.IdempotencyKeyRepository,
+	keyTTL time.Duration,
+	auditLog repository.AuditLog,
+) *CreatePostUsecase {
 	return &CreatePostUsecase{
 		postRepo: postRepo,
-		jobQueue: jobQueue,
+		outbox:   outbox,
+		idGen:    idGen,
+		keyRepo:  keyRepo,
+		keyTTL:   keyTTL,
+		auditLog: auditLog,
+		now:      time.Now,
 	}
 }
 
@@ -55,31 +87,112 @@ func (u *CreatePostUsecase) Execute(ctx context.Context, in *CreatePostInput) (*
 		return nil, ErrNilInput
 	}
 
-	// 投稿オブジェクトの生成
-	p, err := post.New(post.DarkPostID(in.DarkPostID), post.DarkContent(in.Content))
+	// 投稿 ID はクライアントに選ばせず毎回払い出す
+	id, err := u.idGen.NewPostID()
 	if err != nil {
 		return nil, err
 	}
 
-	// 投稿の保存
-	if err := u.postRepo.Create(ctx, p); err != nil {
-		// 重複時はエラー
-		if errors.Is(err, repository.ErrPostAlreadyExists) {
-			return nil, ErrPostAlreadyExists
-		}
-
+	// 投稿オブジェクトの生成（本文が不正ならキーを消費しない）
+	p, err := post.New(id, post.DarkContent(in.Content))
+	if err != nil {
 		return nil, err
 	}
+	p.AssignAuthor(post.ClientID(in.ClientID))
 
-	// 整形ジョブの登録
-	if err := u.jobQueue.EnqueueFormat(ctx, p.ID()); err != nil {
-		// 重複時はエラー
-		if errors.Is(err, queue.ErrJobAlreadyScheduled) {
-			return nil, ErrJobAlreadyScheduled
+	// 冪等キーがあればクライアントごとに ID とリクエストの指紋を紐づけ、再送なら既存の投稿を返す
+	if in.IdempotencyKey != "" {
+		fp := fingerprint(p.Content())
+		record := &repository.IdempotencyRecord{PostID: id, Fingerprint: fp}
+		if u.keyTTL > 0 {
+			record.ExpiresAt = u.now().Add(u.keyTTL)
+		}
+		reserved, err := u.keyRepo.Reserve(ctx, p.Author(), in.IdempotencyKey, record)
+		if err != nil {
+			return nil, err
 		}
+		if reserved.PostID != id {
+			// 指紋の無い記録は指紋導入前に作られたものなので内容を問わず再送とみなす
+			if reserved.Fingerprint != "" && reserved.Fingerprint != fp {
+				return nil, ErrIdempotencyKeyReused
+			}
+			return u.replay(ctx, reserved.PostID, p)
+		}
+	}
 
+	// 投稿と整形ジョブをまとめて保存し、ジョブの無い整形待ちを残さない
+	if err := u.create(ctx, p); err != nil {
+		// キーを紐づけた直後に並行する再送が同じ ID で保存し終えていれば、このリクエストも成功として扱う
+		if in.IdempotencyKey != "" && errors.Is(err, ErrPostAlreadyExists) {
+			return &CreatePostOutput{DarkPostID: string(p.ID())}, nil
+		}
 		return nil, err
 	}
 
 	return &CreatePostOutput{DarkPostID: string(p.ID())}, nil
 }
+
+/**
+ * 冪等キーの再送に対し、前回の途中で失敗した保存をやり直して既存の投稿 ID を返す。
+ */
+func (u *CreatePostUsecase) replay(ctx context.Context, id post.DarkPostID, requested *post.Post) (*CreatePostOutput, error) {
+	_, err := u.postRepo.Get(ctx, id)
+	if errors.Is(err, repository.ErrPostNotFound) {
+		// キーの紐づけ後に保存で失敗していたので、紐づけた ID で保存し直す
+		p, err := post.New(id, requested.Content())
+		if err != nil {
+			return nil, err
+		}
+		p.AssignAuthor(requested.Author())
+		// 並行する再送が先に保存していれば、それをそのまま返す
+		if err := u.create(ctx, p); err != nil && !errors.Is(err, ErrPostAlreadyExists) {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// 投稿が見つかった場合はジョブも同じ書き込みで保存済み
+	return &CreatePostOutput{DarkPostID: string(id), Replayed: true}, nil
+}
+
+/**
+ * 投稿と整形ジョブをアウトボックスで保存し、重複エラーをユースケースのエラーへ写し替える。
+ */
+func (u *CreatePostUsecase) create(ctx context.Context, p *post.Post) error {
+	err := u.outbox.CreateWithFormatJob(ctx, p)
+	switch {
+	case err == nil:
+		recordAudit(ctx, u.auditLog, p.ID(), audit.ActionPostCreated, audit.Client(p.Author()), nil)
+		return nil
+	// 重複時はエラー
+	case errors.Is(err, repository.ErrPostAlreadyExists):
+		return ErrPostAlreadyExists
+	case errors.Is(err, queue.ErrJobAlreadyScheduled):
+		return ErrJobAlreadyScheduled
+	default:
+		return err
+	}
+}
+
+/**
+ * 監査ログへ出来事を追記する。記録に失敗しても保存済みの投稿は取り消せないため、呼び出し元の失敗にはしない。
+ */
+func recordAudit(ctx context.Context, auditLog repository.AuditLog, id post.DarkPostID, action audit.Action, actor audit.Actor, details map[string]string) {
+	if auditLog == nil {
+		return
+	}
+	e, err := audit.New(id, action, actor, details)
+	if err != nil {
+		return
+	}
+	_ = auditLog.Append(ctx, e)
+}
+
+/**
+ * 冪等キーの再利用を見分けるため、リクエスト内容の指紋を返す。
+ */
+func fingerprint(content post.DarkContent) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
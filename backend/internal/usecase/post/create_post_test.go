@@ -3,8 +3,12 @@ package post
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
 	"backend/internal/domain/post"
 	"backend/internal/port/queue"
 	"backend/internal/port/repository"
@@ -23,13 +27,13 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 	}
 
 	newUsecase := func(repo repository.PostRepository, q queue.JobQueue) *CreatePostUsecase {
-		return NewCreatePostUsecase(repo, q)
+		return NewCreatePostUsecase(repo, &stubPostOutbox{repo: repo, queue: q}, stubPostIDGenerator{id: "abc123"}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, nil)
 	}
 
 	cases := []testCase{
 		{
 			name:  "投稿保存とジョブ投入が成功する",
-			input: &CreatePostInput{DarkPostID: "abc123", Content: "闇"},
+			input: &CreatePostInput{Content: "闇"},
 			setupRepo: func() *stubPostRepository {
 				return &stubPostRepository{
 					createFunc: func(ctx context.Context, p *post.Post) error {
@@ -55,6 +59,24 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 			},
 			wantID: "abc123",
 		},
+		{
+			name:  "投稿者のクライアント ID を投稿に記録する",
+			input: &CreatePostInput{Content: "闇", ClientID: "client-1"},
+			setupRepo: func() *stubPostRepository {
+				return &stubPostRepository{
+					createFunc: func(ctx context.Context, p *post.Post) error {
+						if p.Author() != post.ClientID("client-1") {
+							t.Fatalf("想定外の投稿者: %s", p.Author())
+						}
+						return nil
+					},
+				}
+			},
+			setupQueue: func() *stubJobQueue {
+				return &stubJobQueue{}
+			},
+			wantID: "abc123",
+		},
 		{
 			name:    "入力がnilなら ErrNilInput",
 			input:   nil,
@@ -69,8 +91,7 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 		{
 			name: "post.New のバリデーションエラーを返す",
 			input: &CreatePostInput{
-				DarkPostID: "abc123",
-				Content:    "",
+				Content: "",
 			},
 			wantErr: post.ErrEmptyContent,
 			setupRepo: func() *stubPostRepository {
@@ -83,8 +104,7 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 		{
 			name: "リポジトリの重複エラーを変換する",
 			input: &CreatePostInput{
-				DarkPostID: "abc123",
-				Content:    "闇",
+				Content: "闇",
 			},
 			wantErr: ErrPostAlreadyExists,
 			setupRepo: func() *stubPostRepository {
@@ -101,8 +121,7 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 		{
 			name: "リポジトリでの一般的なエラーはそのまま返す",
 			input: &CreatePostInput{
-				DarkPostID: "abc123",
-				Content:    "闇",
+				Content: "闇",
 			},
 			wantErr: errors.New("リポジトリで異常が発生"),
 			setupRepo: func() *stubPostRepository {
@@ -119,8 +138,7 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 		{
 			name: "ジョブキューの重複エラーを変換する",
 			input: &CreatePostInput{
-				DarkPostID: "abc123",
-				Content:    "闇",
+				Content: "闇",
 			},
 			wantErr: ErrJobAlreadyScheduled,
 			setupRepo: func() *stubPostRepository {
@@ -137,8 +155,7 @@ func TestCreatePostUsecase_Execute(t *testing.T) {
 		{
 			name: "ジョブキューの一般的なエラーはそのまま返す",
 			input: &CreatePostInput{
-				DarkPostID: "abc123",
-				Content:    "闇",
+				Content: "闇",
 			},
 			wantErr: errors.New("ジョブキューで異常が発生"),
 			setupRepo: func() *stubPostRepository {
@@ -226,10 +243,267 @@ func (s *stubJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) er
 	return nil
 }
 
-func (s *stubJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", queue.ErrQueueClosed
+func (s *stubJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	return queue.Lease{}, queue.ErrQueueClosed
+}
+
+func (s *stubJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (s *stubJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+func (s *stubJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	return nil
 }
 
 func (s *stubJobQueue) Close() error {
 	return nil
 }
+
+func TestCreatePostUsecase_ExecuteIdempotent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("同じキーの再送は最初の投稿 ID を返し二重登録しない", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		q := &recordingJobQueue{}
+		auditLog := repoMemory.NewInMemoryAuditLog()
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, q), &sequencePostIDGenerator{}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, auditLog)
+
+		first, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇", ClientID: "client-1"})
+		if err != nil {
+			t.Fatalf("初回の作成に失敗: %v", err)
+		}
+		second, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇", ClientID: "client-1"})
+		if err != nil {
+			t.Fatalf("再送に失敗: %v", err)
+		}
+		if first.Replayed || !second.Replayed || first.DarkPostID != second.DarkPostID {
+			t.Fatalf("再送では同じ ID を返すべき: first=%+v second=%+v", first, second)
+		}
+
+		other, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-2", Content: "闇"})
+		if err != nil {
+			t.Fatalf("別キーの作成に失敗: %v", err)
+		}
+		if other.DarkPostID == first.DarkPostID {
+			t.Fatalf("別キーでは新しい ID を払い出すべき: %s", other.DarkPostID)
+		}
+		// 再送時は登録済みのジョブを重複扱いで読み飛ばす
+		if len(q.scheduled) != 2 {
+			t.Fatalf("ジョブは 2 件のはず: %v", q.scheduled)
+		}
+		// 作成の記録は再送で増やさない
+		events, _ := auditLog.ListByPost(ctx, post.DarkPostID(first.DarkPostID))
+		if len(events) != 1 || events[0].Action() != audit.ActionPostCreated || events[0].Actor() != audit.Client("client-1") {
+			t.Fatalf("作成の記録は 1 件のはず: %v", events)
+		}
+	})
+
+	t.Run("同じキーを別の本文で使うと ErrIdempotencyKeyReused", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		q := &recordingJobQueue{}
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, q), &sequencePostIDGenerator{}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, nil)
+
+		if _, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"}); err != nil {
+			t.Fatalf("初回の作成に失敗: %v", err)
+		}
+		out, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "別の闇"})
+		if !errors.Is(err, ErrIdempotencyKeyReused) || out != nil {
+			t.Fatalf("ErrIdempotencyKeyReused を期待: out=%+v err=%v", out, err)
+		}
+		if _, err := postRepo.Get(ctx, post.DarkPostID("post-2")); err == nil {
+			t.Fatalf("別の本文の投稿を保存すべきでない")
+		}
+	})
+
+	t.Run("前回の保存に失敗していれば紐づけた ID で保存し直す", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		failing := &stubPostRepository{
+			createFunc: func(context.Context, *post.Post) error { return errors.New("firestore down") },
+		}
+		keyRepo := repoMemory.NewInMemoryIdempotencyKeyRepository()
+		gen := &sequencePostIDGenerator{}
+
+		if _, err := NewCreatePostUsecase(failing, &stubPostOutbox{repo: failing, queue: &recordingJobQueue{}}, gen, keyRepo, 0, nil).Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"}); err == nil {
+			t.Fatalf("初回はエラーを期待")
+		}
+
+		q := &recordingJobQueue{}
+		out, err := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, q), gen, keyRepo, 0, nil).Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"})
+		if err != nil {
+			t.Fatalf("再送に失敗: %v", err)
+		}
+		if out.DarkPostID != "post-1" || !out.Replayed {
+			t.Fatalf("最初に払い出した ID を返すべき: %+v", out)
+		}
+		if _, err := postRepo.Get(ctx, post.DarkPostID("post-1")); err != nil {
+			t.Fatalf("投稿が保存されていない: %v", err)
+		}
+		if len(q.scheduled) != 1 {
+			t.Fatalf("ジョブを登録し直すべき: %v", q.scheduled)
+		}
+	})
+
+	t.Run("整形が始まった投稿の再送ではジョブを登録しない", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		q := &recordingJobQueue{}
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, q), &sequencePostIDGenerator{}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, nil)
+
+		first, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"})
+		if err != nil {
+			t.Fatalf("初回の作成に失敗: %v", err)
+		}
+		p, _ := postRepo.Get(ctx, post.DarkPostID(first.DarkPostID))
+		if err := p.MarkReady(); err != nil {
+			t.Fatalf("状態遷移に失敗: %v", err)
+		}
+		if err := postRepo.Update(ctx, p); err != nil {
+			t.Fatalf("更新に失敗: %v", err)
+		}
+		// 完了済みのジョブは消えているので、登録し直すと再整形が走ってしまう
+		q.scheduled = nil
+
+		if _, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"}); err != nil {
+			t.Fatalf("再送に失敗: %v", err)
+		}
+		if len(q.scheduled) != 0 {
+			t.Fatalf("ジョブを登録すべきでない: %v", q.scheduled)
+		}
+	})
+
+	t.Run("キーを紐づけた直後に並行する再送が保存していても成功を返す", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		// 並行する再送が、紐づけた ID の投稿を先に保存し終えた状態
+		p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("闇"))
+		if err := postRepo.Create(ctx, p); err != nil {
+			t.Fatalf("事前の保存に失敗: %v", err)
+		}
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, &recordingJobQueue{}), &sequencePostIDGenerator{}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, nil)
+
+		out, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇"})
+		if err != nil {
+			t.Fatalf("ErrPostAlreadyExists を返すべきでない: %v", err)
+		}
+		if out.DarkPostID != "post-1" {
+			t.Fatalf("紐づけた ID を返すべき: %+v", out)
+		}
+	})
+
+	t.Run("冪等キーはクライアントごとに分かれる", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, &recordingJobQueue{}), &sequencePostIDGenerator{}, repoMemory.NewInMemoryIdempotencyKeyRepository(), 0, nil)
+
+		mine, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇", ClientID: "client-1"})
+		if err != nil {
+			t.Fatalf("初回の作成に失敗: %v", err)
+		}
+		// 他人のキーを推測して送っても、その投稿 ID は得られず別の本文扱いにもならない
+		theirs, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "別の闇", ClientID: "client-2"})
+		if err != nil {
+			t.Fatalf("別クライアントの作成に失敗: %v", err)
+		}
+		if theirs.Replayed || theirs.DarkPostID == mine.DarkPostID {
+			t.Fatalf("別クライアントには新しい投稿を作るべき: mine=%+v theirs=%+v", mine, theirs)
+		}
+	})
+
+	t.Run("紐づけに有効期限を付ける", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		postRepo := repoMemory.NewInMemoryPostRepository()
+		keyRepo := &recordingIdempotencyKeyRepository{}
+		uc := NewCreatePostUsecase(postRepo, repoMemory.NewInMemoryPostOutbox(postRepo, &recordingJobQueue{}), &sequencePostIDGenerator{}, keyRepo, time.Hour, nil)
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		uc.now = func() time.Time { return now }
+
+		if _, err := uc.Execute(ctx, &CreatePostInput{IdempotencyKey: "key-1", Content: "闇", ClientID: "client-1"}); err != nil {
+			t.Fatalf("作成に失敗: %v", err)
+		}
+		if keyRepo.clientID != "client-1" || !keyRepo.record.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Fatalf("クライアント ID と有効期限を渡すべき: client=%q record=%+v", keyRepo.clientID, keyRepo.record)
+		}
+	})
+}
+
+// recordingIdempotencyKeyRepository は最後に紐づけようとした内容を覚え、常に新規として扱う。
+type recordingIdempotencyKeyRepository struct {
+	clientID post.ClientID
+	record   repository.IdempotencyRecord
+}
+
+func (r *recordingIdempotencyKeyRepository) Reserve(_ context.Context, clientID post.ClientID, _ string, record *repository.IdempotencyRecord) (*repository.IdempotencyRecord, error) {
+	r.clientID = clientID
+	r.record = *record
+	return record, nil
+}
+
+// stubPostOutbox は投稿保存とジョブ投入を順に呼ぶだけのアウトボックス。
+type stubPostOutbox struct {
+	repo  repository.PostRepository
+	queue queue.JobQueue
+}
+
+func (s *stubPostOutbox) CreateWithFormatJob(ctx context.Context, p *post.Post) error {
+	if err := s.repo.Create(ctx, p); err != nil {
+		return err
+	}
+	return s.queue.EnqueueFormat(ctx, p.ID())
+}
+
+// stubPostIDGenerator は固定 ID を返す。
+type stubPostIDGenerator struct {
+	id  post.DarkPostID
+	err error
+}
+
+func (s stubPostIDGenerator) NewPostID() (post.DarkPostID, error) {
+	return s.id, s.err
+}
+
+// sequencePostIDGenerator は post-1, post-2, ... を順に返す。
+type sequencePostIDGenerator struct {
+	n int
+}
+
+func (s *sequencePostIDGenerator) NewPostID() (post.DarkPostID, error) {
+	s.n++
+	return post.DarkPostID(fmt.Sprintf("post-%d", s.n)), nil
+}
+
+// recordingJobQueue は登録済みの ID を覚え、重複を ErrJobAlreadyScheduled にする。
+type recordingJobQueue struct {
+	stubJobQueue
+	scheduled []post.DarkPostID
+}
+
+func (q *recordingJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error {
+	for _, scheduled := range q.scheduled {
+		if scheduled == id {
+			return queue.ErrJobAlreadyScheduled
+		}
+	}
+	q.scheduled = append(q.scheduled, id)
+	return nil
+}
@@ -0,0 +1,132 @@
+package post
+
+import (
+	"context"
+	"errors"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var (
+	ErrEmptyPostID  = errors.New("get_post_status: 投稿 ID が指定されていません")
+	ErrPostNotFound = errors.New("get_post_status: 投稿が存在しません")
+)
+
+/**
+ * 投稿者へ返す闇投稿の進み具合
+ * Job: 整形待ち・整形中・失敗のときに参照できた整形ジョブの状況
+ * Fortune: 公開済みのときに生成された検証済みのおみくじ
+ */
+type GetPostStatusOutput struct {
+	DarkPostID string
+	Status     post.Status
+	Job        *queue.FormatJobStatus
+	Fortune    *drawdomain.Draw
+}
+
+/**
+ * 闇投稿の進み具合を返すユースケース
+ * postRepo: 投稿リポジトリ
+ * drawRepo: おみくじリポジトリ
+ * jobInspector: 整形ジョブの参照口（nil の場合はジョブ状況を返さない）
+ */
+type GetPostStatusUsecase struct {
+	postRepo     repository.PostRepository
+	drawRepo     repository.DrawRepository
+	jobInspector queue.JobInspector
+}
+
+/**
+ * ユースケース毎に初期化
+ */
+func NewGetPostStatusUsecase(
+	postRepo repository.PostRepository,
+	drawRepo repository.DrawRepository,
+	jobInspector queue.JobInspector,
+) *GetPostStatusUsecase {
+	return &GetPostStatusUsecase{
+		postRepo:     postRepo,
+		drawRepo:     drawRepo,
+		jobInspector: jobInspector,
+	}
+}
+
+/**
+ * 闇投稿の状態と、整形ジョブの状況もしくは生成されたおみくじを返す
+ */
+func (u *GetPostStatusUsecase) Execute(ctx context.Context, postID string) (*GetPostStatusOutput, error) {
+	if postID == "" {
+		return nil, ErrEmptyPostID
+	}
+
+	p, err := u.postRepo.Get(ctx, post.DarkPostID(postID))
+	if err != nil {
+		if errors.Is(err, repository.ErrPostNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	// 削除済みの投稿は存在しないものとして扱う
+	if p.Status() == post.StatusDeleted {
+		return nil, ErrPostNotFound
+	}
+
+	out := &GetPostStatusOutput{
+		DarkPostID: string(p.ID()),
+		Status:     p.Status(),
+	}
+
+	switch p.Status() {
+	case post.StatusPending, post.StatusFormatting, post.StatusFailed:
+		job, err := u.inspectJob(ctx, p.ID())
+		if err != nil {
+			return nil, err
+		}
+		out.Job = job
+	case post.StatusReady, post.StatusArchived:
+		fortune, err := u.findFortune(ctx, p.ID())
+		if err != nil {
+			return nil, err
+		}
+		out.Fortune = fortune
+	}
+
+	return out, nil
+}
+
+/**
+ * 整形ジョブの状況を返す。完了直後などでジョブが見つからない場合は nil とする
+ */
+func (u *GetPostStatusUsecase) inspectJob(ctx context.Context, id post.DarkPostID) (*queue.FormatJobStatus, error) {
+	if u.jobInspector == nil {
+		return nil, nil
+	}
+	job, err := u.jobInspector.InspectFormat(ctx, id)
+	if err != nil {
+		if errors.Is(err, queue.ErrJobNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+/**
+ * 検証済みのおみくじを返す。保存前や拒否済みの場合は nil とする
+ */
+func (u *GetPostStatusUsecase) findFortune(ctx context.Context, id post.DarkPostID) (*drawdomain.Draw, error) {
+	d, err := u.drawRepo.GetByPostID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrDrawNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if d.Status() != drawdomain.StatusVerified {
+		return nil, nil
+	}
+	return d, nil
+}
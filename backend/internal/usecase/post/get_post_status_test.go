@@ -0,0 +1,151 @@
+package post
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+)
+
+func TestGetPostStatusUsecase_Execute(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		postID      string
+		status      post.Status
+		draw        func(post.DarkPostID) *drawdomain.Draw
+		inspector   *stubJobInspector
+		wantErr     error
+		wantJob     bool
+		wantFortune string
+	}
+
+	cases := []testCase{
+		{
+			name:    "ID が空なら ErrEmptyPostID",
+			postID:  "",
+			wantErr: ErrEmptyPostID,
+		},
+		{
+			name:    "投稿が無ければ ErrPostNotFound",
+			postID:  "missing",
+			wantErr: ErrPostNotFound,
+		},
+		{
+			name:    "削除済みの投稿は ErrPostNotFound",
+			postID:  "post-1",
+			status:  post.StatusDeleted,
+			wantErr: ErrPostNotFound,
+		},
+		{
+			name:   "整形待ちならジョブ状況を返す",
+			postID: "post-1",
+			status: post.StatusPending,
+			inspector: &stubJobInspector{
+				status: &queue.FormatJobStatus{State: queue.FormatJobRetrying, Attempts: 2},
+			},
+			wantJob: true,
+		},
+		{
+			name:      "ジョブが見つからなくても状態は返す",
+			postID:    "post-1",
+			status:    post.StatusFormatting,
+			inspector: &stubJobInspector{err: queue.ErrJobNotFound},
+		},
+		{
+			name:      "ジョブ参照の失敗は伝える",
+			postID:    "post-1",
+			status:    post.StatusPending,
+			inspector: &stubJobInspector{err: errors.New("firestore down")},
+			wantErr:   errors.New("firestore down"),
+		},
+		{
+			name:   "公開済みなら検証済みのおみくじを返す",
+			postID: "post-1",
+			status: post.StatusReady,
+			draw: func(id post.DarkPostID) *drawdomain.Draw {
+				d, _ := drawdomain.New(id, "大吉")
+				d.MarkVerified()
+				return d
+			},
+			wantFortune: "大吉",
+		},
+		{
+			name:   "拒否済みのおみくじは返さない",
+			postID: "post-1",
+			status: post.StatusRejected,
+			draw: func(id post.DarkPostID) *drawdomain.Draw {
+				d, _ := drawdomain.New(id, "凶")
+				d.MarkRejected("不適切")
+				return d
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			postRepo := repoMemory.NewInMemoryPostRepository()
+			drawRepo := repoMemory.NewInMemoryDrawRepository()
+			if tc.status != "" {
+				p, err := post.Restore(post.DarkPostID(tc.postID), "闇", tc.status)
+				if err != nil {
+					t.Fatalf("restore post: %v", err)
+				}
+				if err := postRepo.Create(ctx, p); err != nil {
+					t.Fatalf("create post: %v", err)
+				}
+			}
+			if tc.draw != nil {
+				if err := drawRepo.Create(ctx, tc.draw(post.DarkPostID(tc.postID))); err != nil {
+					t.Fatalf("create draw: %v", err)
+				}
+			}
+
+			var inspector queue.JobInspector
+			if tc.inspector != nil {
+				inspector = tc.inspector
+			}
+			out, err := NewGetPostStatusUsecase(postRepo, drawRepo, inspector).Execute(ctx, tc.postID)
+
+			if tc.wantErr != nil {
+				if err == nil || (!errors.Is(err, tc.wantErr) && err.Error() != tc.wantErr.Error()) {
+					t.Fatalf("want err %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.DarkPostID != tc.postID || out.Status != tc.status {
+				t.Fatalf("unexpected output: %+v", out)
+			}
+			if (out.Job != nil) != tc.wantJob {
+				t.Fatalf("want job %v, got %+v", tc.wantJob, out.Job)
+			}
+			if tc.wantFortune == "" && out.Fortune != nil {
+				t.Fatalf("fortune should be empty, got %+v", out.Fortune)
+			}
+			if tc.wantFortune != "" && (out.Fortune == nil || string(out.Fortune.Result()) != tc.wantFortune) {
+				t.Fatalf("want fortune %q, got %+v", tc.wantFortune, out.Fortune)
+			}
+		})
+	}
+}
+
+// stubJobInspector は JobInspector の簡易モック。
+type stubJobInspector struct {
+	status *queue.FormatJobStatus
+	err    error
+}
+
+func (s *stubJobInspector) InspectFormat(ctx context.Context, id post.DarkPostID) (*queue.FormatJobStatus, error) {
+	return s.status, s.err
+}
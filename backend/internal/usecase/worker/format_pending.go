@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"strings"
 
+	"backend/internal/domain/audit"
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/fortune"
 	"backend/internal/domain/post"
 	"backend/internal/port/llm"
-	"backend/internal/port/queue"
 	"backend/internal/port/repository"
 )
 
@@ -20,7 +21,6 @@ var (
 	ErrFormatterUnavailable = errors.New("format_pending: 整形サービスに接続できません")
 	ErrContentRejected      = errors.New("format_pending: 投稿内容が拒否されました")
 	ErrDrawCreationFailed   = errors.New("format_pending: おみくじ結果を保存できませんでした")
-	ErrRequeueFailed        = errors.New("format_pending: おみくじ結果保存失敗後の再キューに失敗しました")
 	ErrNilUsecase           = errors.New("format_pending: ユースケースが初期化されていません")
 	ErrNilContext           = errors.New("format_pending: コンテキストが指定されていません")
 )
@@ -29,24 +29,28 @@ const maxDrawResultLength = 400
 
 // 整形待ち投稿の整形から公開準備までを担う。
 type FormatPendingUsecase struct {
-	postRepo repository.PostRepository
-	drawRepo repository.DrawRepository
-	llm      llm.Formatter
-	jobQueue queue.JobQueue
+	postRepo  repository.PostRepository
+	completer repository.FormatCompleter
+	llm       llm.Formatter
+	auditLog  repository.AuditLog
+	validator *fortune.Validator
 }
 
-// 依存をまとめて整形用ユースケースを組み立てる。
+// 依存をまとめて整形用ユースケースを組み立てる。auditLog が nil なら状態の移り変わりを残さない。
+// validator が nil なら文字数や文構成の検査を省き、LLM アダプタの検証結果だけで判断する。
 func NewFormatPendingUsecase(
 	postRepo repository.PostRepository,
-	drawRepo repository.DrawRepository,
+	completer repository.FormatCompleter,
 	llmFormatter llm.Formatter,
-	jobQueue queue.JobQueue,
+	auditLog repository.AuditLog,
+	validator *fortune.Validator,
 ) *FormatPendingUsecase {
 	return &FormatPendingUsecase{
-		postRepo: postRepo,
-		drawRepo: drawRepo,
-		llm:      llmFormatter,
-		jobQueue: jobQueue,
+		postRepo:  postRepo,
+		completer: completer,
+		llm:       llmFormatter,
+		auditLog:  auditLog,
+		validator: validator,
 	}
 }
 
@@ -71,8 +75,26 @@ func (u *FormatPendingUsecase) Execute(ctx context.Context, postID string) error
 		return err
 	}
 
-	// ステータスが pending 以外の場合は即終了
-	if p.Status() != post.StatusPending {
+	from := p.Status()
+	// 隔離ジョブの再投入で届いた failed の投稿は整形待ちへ戻してからやり直す
+	if p.Status() == post.StatusFailed {
+		if err := p.Reopen(); err != nil {
+			return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+		}
+	}
+
+	// 整形中のまま残った投稿はリース切れによる再取得なのでそのまま続行する
+	switch p.Status() {
+	case post.StatusPending:
+		if err := p.MarkFormatting(); err != nil {
+			return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+		}
+		if err := u.postRepo.Update(ctx, p); err != nil {
+			return err
+		}
+		u.recordAudit(ctx, p.ID(), audit.ActionFormatStarted, map[string]string{"from": string(from)})
+	case post.StatusFormatting:
+	default:
 		return ErrPostNotPending
 	}
 
@@ -81,8 +103,9 @@ func (u *FormatPendingUsecase) Execute(ctx context.Context, postID string) error
 		DarkContent: p.Content(),
 	})
 	if err != nil {
+		// 再試行の記録に原因を残すため元エラーも保持する
 		if errors.Is(err, llm.ErrFormatterUnavailable) {
-			return ErrFormatterUnavailable
+			return fmt.Errorf("%w: %v", ErrFormatterUnavailable, err)
 		}
 		return err
 	}
@@ -90,14 +113,21 @@ func (u *FormatPendingUsecase) Execute(ctx context.Context, postID string) error
 	validated, err := u.llm.Validate(ctx, formatResult)
 	if err != nil {
 		if errors.Is(err, llm.ErrContentRejected) {
-			return ErrContentRejected
+			return u.reject(ctx, p, formatResult, validated)
 		}
 		return err
 	}
 
-	// 検証で公開不可となった場合はここで終了
+	// 検証で公開不可となった場合は理由を残して終端状態にする
 	if validated.Status != drawdomain.StatusVerified {
-		return nil
+		return u.reject(ctx, p, formatResult, validated)
+	}
+
+	// 提供元ごとに基準がぶれないよう、お告げの規則はここで一律に検査する
+	if violations := u.validateFortune(validated.FormattedContent); len(violations) > 0 {
+		validated.Status = drawdomain.StatusRejected
+		validated.ValidationReason = violations.Reason()
+		return u.reject(ctx, p, formatResult, validated)
 	}
 
 	drawContent := normalizeDrawContent(validated.FormattedContent)
@@ -106,25 +136,152 @@ func (u *FormatPendingUsecase) Execute(ctx context.Context, postID string) error
 		return err
 	}
 	drawEntity.MarkVerified()
-	if err := u.drawRepo.Create(ctx, drawEntity); err != nil {
-		if err := u.requeueFormatJob(ctx, p.ID()); err != nil {
-			return fmt.Errorf("%w: %v", ErrRequeueFailed, err)
-		}
-		return fmt.Errorf("%w: %v", ErrDrawCreationFailed, err)
-	}
+	drawEntity.AssignAuthor(p.Author())
+	drawEntity.AttachProvenance(formatResult.Provenance)
 
 	// 公開待ちへの状態遷移に失敗した場合は元エラーも保持しつつ整形待ちではないとみなす
 	if err := p.MarkReady(); err != nil {
 		return fmt.Errorf("%w: %v", ErrPostNotPending, err)
 	}
 
-	if err := u.postRepo.Update(ctx, p); err != nil {
+	if err := u.complete(ctx, p, drawEntity); err != nil {
+		return err
+	}
+	u.recordAudit(ctx, p.ID(), audit.ActionDrawVerified, provenanceDetails(drawEntity.Provenance(), nil))
+	return nil
+}
+
+// 公開不可と判定された結果を理由付きの rejected な draw として残し、投稿も rejected で確定させる。
+// 保存できた場合も ErrContentRejected を返し、呼び出し側にはジョブを完了扱いにさせる。
+func (u *FormatPendingUsecase) reject(ctx context.Context, p *post.Post, formatted, validated *llm.FormatResult) error {
+	// 拒否時に検証結果が返らない実装もあるため、整形結果で補う
+	result := validated
+	if result == nil {
+		result = formatted
+	}
+	reason := ""
+	content := drawdomain.FormattedContent("")
+	if result != nil {
+		reason = result.ValidationReason
+		content = normalizeDrawContent(result.FormattedContent)
+	}
+	// 整形結果が無ければ目印だけを入れる。元の本文は posts に残っており、承認で公開されないよう draws へは写さない
+	if content == "" {
+		content = drawdomain.NoFormattedResult
+	}
+
+	drawEntity, err := drawdomain.New(p.ID(), content)
+	if err != nil {
+		return err
+	}
+	drawEntity.MarkRejected(reason)
+	drawEntity.AssignAuthor(p.Author())
+	// 公開不可になりやすいモデルやプロンプトを比べられるよう、拒否した結果にも生成元を残す
+	if formatted != nil {
+		drawEntity.AttachProvenance(formatted.Provenance)
+	}
+
+	if err := p.MarkRejected(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+	}
+	if err := u.complete(ctx, p, drawEntity); err != nil {
 		return err
 	}
+	u.recordAudit(ctx, p.ID(), audit.ActionDrawRejected, provenanceDetails(drawEntity.Provenance(), map[string]string{"reason": reason}))
+
+	if reason == "" {
+		return ErrContentRejected
+	}
+	return fmt.Errorf("%w: %s", ErrContentRejected, reason)
+}
+
+// おみくじ結果の保存と投稿の状態更新を 1 つの操作で書き込む。
+// 再試行で同じ投稿を処理し直した場合は保存済みのおみくじ結果をそのまま使うため、書き込み済みでも失敗にはならない。
+// 書き込めなかった場合や保存済みの結果が今回の判定を裏付けない場合は ErrDrawCreationFailed を返し、プールにジョブを再試行へ回させる。
+// 整形中に投稿が削除されるなどして整形待ちではなくなっていた場合は ErrPostNotPending を返し、ジョブを完了扱いにさせる。
+func (u *FormatPendingUsecase) complete(ctx context.Context, p *post.Post, d *drawdomain.Draw) error {
+	err := u.completer.Complete(ctx, p, d)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, repository.ErrPostNotFound) {
+		return ErrPostNotFound
+	}
+	// 整形中に削除などで状態が変わった投稿はやり直しても書き込めないため、整形待ちではないとみなす
+	if errors.Is(err, repository.ErrPostStatusConflict) {
+		return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+	}
+	return fmt.Errorf("%w: %v", ErrDrawCreationFailed, err)
+}
+
+// 再試行上限に達した整形待ち・整形中の投稿を failed で確定させる。すでに終端状態なら何もしない。
+func (u *FormatPendingUsecase) MarkFailed(ctx context.Context, postID string) error {
+	if u == nil {
+		return ErrNilUsecase
+	}
+	if ctx == nil {
+		return ErrNilContext
+	}
+	if postID == "" {
+		return ErrEmptyPostID
+	}
 
+	p, err := u.postRepo.Get(ctx, post.DarkPostID(postID))
+	if err != nil {
+		if errors.Is(err, repository.ErrPostNotFound) {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if !p.Status().CanTransitionTo(post.StatusFailed) {
+		return nil
+	}
+	if err := p.MarkFailed(); err != nil {
+		return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+	}
+	if err := u.postRepo.Update(ctx, p); err != nil {
+		return err
+	}
+	u.recordAudit(ctx, p.ID(), audit.ActionFormatFailed, nil)
 	return nil
 }
 
+// ワーカーを主体として監査ログへ出来事を追記する。記録に失敗しても確定済みの整形結果は取り消せないため、ジョブの失敗にはしない。
+func (u *FormatPendingUsecase) recordAudit(ctx context.Context, id post.DarkPostID, action audit.Action, details map[string]string) {
+	if u.auditLog == nil {
+		return
+	}
+	e, err := audit.New(id, action, audit.Worker(), details)
+	if err != nil {
+		return
+	}
+	_ = u.auditLog.Append(ctx, e)
+}
+
+// 監査ログの補足へ生成元の提供元・モデル・プロンプトの版を加える。分からない項目は入れない。
+func provenanceDetails(p drawdomain.Provenance, details map[string]string) map[string]string {
+	if details == nil {
+		details = make(map[string]string, 3)
+	}
+	for key, value := range map[string]string{"provider": p.Provider, "model": p.Model, "prompt_version": p.PromptVersion} {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
+}
+
+// 整形結果を共通のお告げ規則で検査し、違反をすべて返す。validator が未設定なら常に違反なしとする。
+func (u *FormatPendingUsecase) validateFortune(content drawdomain.FormattedContent) fortune.Violations {
+	if u.validator == nil {
+		return nil
+	}
+	return u.validator.Validate(fortune.Normalize(string(content)))
+}
+
 func normalizeDrawContent(content drawdomain.FormattedContent) drawdomain.FormattedContent {
 	trimmed := strings.TrimSpace(string(content))
 	runes := []rune(trimmed)
@@ -134,10 +291,3 @@ func normalizeDrawContent(content drawdomain.FormattedContent) drawdomain.Format
 	}
 	return drawdomain.FormattedContent(trimmed)
 }
-
-func (u *FormatPendingUsecase) requeueFormatJob(ctx context.Context, postID post.DarkPostID) error {
-	if u.jobQueue == nil {
-		return errors.New("format_pending: 再整形ジョブキューが未設定です")
-	}
-	return u.jobQueue.EnqueueFormat(ctx, postID)
-}
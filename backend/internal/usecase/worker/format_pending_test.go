@@ -3,13 +3,18 @@ package worker
 import (
 	"context"
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
 	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/fortune"
 	"backend/internal/domain/post"
 	"backend/internal/port/llm"
-	"backend/internal/port/queue"
+	"backend/internal/port/repository"
 	"backend/internal/usecase/worker/testutil"
 )
 
@@ -18,13 +23,16 @@ func TestFormatPendingUsecase_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to create post: %v", err)
 	}
+	p.AssignAuthor("client-1")
 	repo := testutil.NewStubPostRepository(p)
 	drawRepo := &testutil.StubDrawRepository{}
+	provenance := drawdomain.Provenance{Provider: "gemini", Model: "gemini-2.5-flash", PromptVersion: "v1", PromptTokens: 100, CompletionTokens: 30, Latency: time.Second}
 	formatter := &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusPending,
 			FormattedContent: "formatted",
+			Provenance:       provenance,
 		},
 		ValidateResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
@@ -32,7 +40,8 @@ func TestFormatPendingUsecase_Success(t *testing.T) {
 			FormattedContent: "formatted",
 		},
 	}
-	usecase := NewFormatPendingUsecase(repo, drawRepo, formatter, testutil.StubJobQueue{})
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), formatter, auditLog, nil)
 
 	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
 		t.Fatalf("execute returned error: %v", err)
@@ -56,11 +65,23 @@ func TestFormatPendingUsecase_Success(t *testing.T) {
 	if created.Status() != drawdomain.StatusVerified {
 		t.Fatalf("expected verified draw, got %s", created.Status())
 	}
+	if created.Author() != p.Author() {
+		t.Fatalf("draw should inherit post author, got %q", created.Author())
+	}
+	if created.Provenance() != provenance {
+		t.Fatalf("draw should keep the formatter provenance, got %+v", created.Provenance())
+	}
+	events := assertAuditActions(t, auditLog, p.ID(), audit.ActionFormatStarted, audit.ActionDrawVerified)
+	// 公開した結果がどのモデルとプロンプトから生まれたかを経緯から追える
+	want := map[string]string{"provider": "gemini", "model": "gemini-2.5-flash", "prompt_version": "v1"}
+	if got := events[1].Details(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("verified event should carry provenance, got %v", got)
+	}
 }
 
 func TestFormatPendingUsecase_PostNotFound(t *testing.T) {
 	repo := testutil.NewStubPostRepository(nil)
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{}, testutil.StubJobQueue{})
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "unknown")
 	if !errors.Is(err, ErrPostNotFound) {
@@ -71,7 +92,7 @@ func TestFormatPendingUsecase_PostNotFound(t *testing.T) {
 func TestFormatPendingUsecase_GetGenericError(t *testing.T) {
 	repo := testutil.NewStubPostRepository(nil)
 	repo.GetErr = errors.New("get failed")
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{}, testutil.StubJobQueue{})
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, repo.GetErr) {
@@ -82,9 +103,9 @@ func TestFormatPendingUsecase_GetGenericError(t *testing.T) {
 func TestFormatPendingUsecase_FormatterUnavailable(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{
 		FormatErr: llm.ErrFormatterUnavailable,
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, ErrFormatterUnavailable) {
@@ -95,17 +116,148 @@ func TestFormatPendingUsecase_FormatterUnavailable(t *testing.T) {
 func TestFormatPendingUsecase_ContentRejected(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{
-		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
-		ValidateErr:  llm.ErrContentRejected,
-	}, testutil.StubJobQueue{})
+	drawRepo := &testutil.StubDrawRepository{}
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	provenance := drawdomain.Provenance{Provider: "openai", Model: "gpt-4o-mini", PromptVersion: "v1"}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID(), FormattedContent: "formatted", Provenance: provenance},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusRejected,
+			FormattedContent: "formatted",
+			ValidationReason: "個人情報を含む",
+		},
+		ValidateErr: llm.ErrContentRejected,
+	}, auditLog, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, ErrContentRejected) {
 		t.Fatalf("expected ErrContentRejected, got %v", err)
 	}
-	if repo.Updated != nil {
-		t.Fatalf("post should not be updated on rejection")
+	// 拒否理由付きの draw が残り、投稿は rejected で確定する
+	if len(drawRepo.Created) != 1 {
+		t.Fatalf("expected rejected draw to be stored, got %d", len(drawRepo.Created))
+	}
+	created := drawRepo.Created[0]
+	if created.Status() != drawdomain.StatusRejected || created.Reason() != "個人情報を含む" {
+		t.Fatalf("unexpected rejected draw: status=%s reason=%s", created.Status(), created.Reason())
+	}
+	if created.Provenance() != provenance {
+		t.Fatalf("rejected draw should keep the formatter provenance, got %+v", created.Provenance())
+	}
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusRejected {
+		t.Fatalf("expected post to be marked rejected")
+	}
+	events := assertAuditActions(t, auditLog, p.ID(), audit.ActionFormatStarted, audit.ActionDrawRejected)
+	if events[1].Details()["reason"] != "個人情報を含む" || events[1].Details()["model"] != "gpt-4o-mini" || events[1].Actor() != audit.Worker() {
+		t.Fatalf("unexpected rejected event: %+v %v", events[1].Actor(), events[1].Details())
+	}
+}
+
+func TestFormatPendingUsecase_FortuneRulesRejectVerifiedResult(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	repo := testutil.NewStubPostRepository(p)
+	drawRepo := &testutil.StubDrawRepository{}
+	provenance := drawdomain.Provenance{Provider: "openai", Model: "gpt-4o-mini"}
+	validator, err := fortune.NewValidator(fortune.Rules{Prefix: "今日のきらくじ:", SentenceCount: 2})
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	// アダプタが通した結果でも、共通の規則に反すれば拒否する
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID(), Provenance: provenance},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "ひとつめです。",
+		},
+	}, auditLog, validator)
+
+	err = usecase.Execute(context.Background(), "post-1")
+	if !errors.Is(err, ErrContentRejected) {
+		t.Fatalf("expected ErrContentRejected, got %v", err)
+	}
+	if len(drawRepo.Created) != 1 {
+		t.Fatalf("expected rejected draw to be stored, got %d", len(drawRepo.Created))
+	}
+	created := drawRepo.Created[0]
+	want := "冒頭は「今日のきらくじ:」で始めてください / お告げは2文構成で書いてください"
+	if created.Status() != drawdomain.StatusRejected || created.Reason() != want {
+		t.Fatalf("unexpected rejected draw: status=%s reason=%s", created.Status(), created.Reason())
+	}
+	if created.Provenance() != provenance {
+		t.Fatalf("expected provenance on rejected draw, got %+v", created.Provenance())
+	}
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusRejected {
+		t.Fatalf("expected post to be rejected")
+	}
+	events := assertAuditActions(t, auditLog, p.ID(), audit.ActionFormatStarted, audit.ActionDrawRejected)
+	if got := events[1].Details()["reason"]; got != want {
+		t.Fatalf("unexpected audit reason: %q", got)
+	}
+}
+
+func TestFormatPendingUsecase_FortuneRulesPassNormalizedResult(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	repo := testutil.NewStubPostRepository(p)
+	drawRepo := &testutil.StubDrawRepository{}
+	validator, err := fortune.NewValidator(fortune.Rules{Prefix: "今日のきらくじ:", SentenceCount: 2, SentenceEndings: []string{"ます"}})
+	if err != nil {
+		t.Fatalf("new validator: %v", err)
+	}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "今日のきらくじ: 待ちます。\r\n笑えます。",
+		},
+	}, nil, validator)
+
+	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
+		t.Fatalf("execute returned error: %v", err)
+	}
+	if len(drawRepo.Created) != 1 || drawRepo.Created[0].Status() != drawdomain.StatusVerified {
+		t.Fatalf("expected verified draw, got %+v", drawRepo.Created)
+	}
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusReady {
+		t.Fatalf("expected post to be ready")
+	}
+}
+
+func TestFormatPendingUsecase_ContentRejectedWithoutResult(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("元の闇"))
+	repo := testutil.NewStubPostRepository(p)
+	drawRepo := &testutil.StubDrawRepository{}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
+		ValidateErr:  llm.ErrContentRejected,
+	}, nil, nil)
+
+	if err := usecase.Execute(context.Background(), "post-1"); !errors.Is(err, ErrContentRejected) {
+		t.Fatalf("expected ErrContentRejected, got %v", err)
+	}
+	// 整形結果が空なら目印だけを残し、承認で公開されないよう元の本文は写さない
+	if len(drawRepo.Created) != 1 || drawRepo.Created[0].Result() != drawdomain.NoFormattedResult || drawRepo.Created[0].HasFormattedResult() {
+		t.Fatalf("expected rejected draw without formatted result, got %+v", drawRepo.Created)
+	}
+}
+
+func TestFormatPendingUsecase_RejectedDrawCreateFailed(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	repo := testutil.NewStubPostRepository(p)
+	drawRepo := &testutil.StubDrawRepository{CreateErr: errors.New("save failed")}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID(), FormattedContent: "formatted"},
+		ValidateErr:  llm.ErrContentRejected,
+	}, nil, nil)
+
+	if err := usecase.Execute(context.Background(), "post-1"); !errors.Is(err, ErrDrawCreationFailed) {
+		t.Fatalf("expected ErrDrawCreationFailed, got %v", err)
+	}
+	if repo.UpdateCalls != 1 {
+		t.Fatalf("post should stay formatting when rejected draw was not stored, got %d updates", repo.UpdateCalls)
 	}
 }
 
@@ -113,27 +265,141 @@ func TestFormatPendingUsecase_UpdateFailed(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
 	repo.UpdateErr = errors.New("update failed")
+	// 整形中への更新は通し、公開待ちへの更新だけ失敗させる
+	repo.UpdateErrAfter = 1
 	drawRepo := &testutil.StubDrawRepository{}
-	usecase := NewFormatPendingUsecase(repo, drawRepo, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
 		ValidateResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusVerified,
 			FormattedContent: "formatted",
 		},
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
+	// 公開待ちへの更新はおみくじ結果の保存とまとめて失敗扱いにし、整形からやり直させる
 	err := usecase.Execute(context.Background(), "post-1")
-	if err == nil || !errors.Is(err, repo.UpdateErr) {
-		t.Fatalf("expected update error, got %v", err)
+	if !errors.Is(err, ErrDrawCreationFailed) || !strings.Contains(err.Error(), "update failed") {
+		t.Fatalf("expected ErrDrawCreationFailed with update error, got %v", err)
 	}
-	if len(drawRepo.Created) != 1 {
-		t.Fatalf("expected draw to be created before update")
+}
+
+func TestFormatPendingUsecase_ExistingDrawIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// 前回の試行でおみくじ結果だけが保存済みの状態
+	saved, _ := drawdomain.New(p.ID(), "formatted")
+	saved.MarkVerified()
+	if err := drawRepo.Create(ctx, saved); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	usecase := NewFormatPendingUsecase(postRepo, repoMemory.NewInMemoryFormatCompleter(postRepo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "formatted",
+		},
+	}, nil, nil)
+
+	if err := usecase.Execute(ctx, "post-1"); err != nil {
+		t.Fatalf("retry should succeed, got %v", err)
+	}
+	stored, _ := postRepo.Get(ctx, p.ID())
+	if stored.Status() != post.StatusReady {
+		t.Fatalf("post should be ready, got %s", stored.Status())
+	}
+}
+
+func TestFormatPendingUsecase_StoredPendingDrawIsRetried(t *testing.T) {
+	ctx := context.Background()
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// 検証前のおみくじ結果が残っていても公開待ちにはしない
+	saved, _ := drawdomain.New(p.ID(), "formatted")
+	if err := drawRepo.Create(ctx, saved); err != nil {
+		t.Fatalf("create draw: %v", err)
+	}
+	usecase := NewFormatPendingUsecase(postRepo, repoMemory.NewInMemoryFormatCompleter(postRepo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "formatted",
+		},
+	}, nil, nil)
+
+	err := usecase.Execute(ctx, "post-1")
+	if !errors.Is(err, ErrDrawCreationFailed) || !strings.Contains(err.Error(), "draw=pending") {
+		t.Fatalf("expected retryable ErrDrawCreationFailed, got %v", err)
+	}
+	stored, _ := postRepo.Get(ctx, p.ID())
+	if stored.Status() == post.StatusReady {
+		t.Fatalf("post must not be promoted with a pending draw")
 	}
 }
 
+func TestFormatPendingUsecase_DeletedDuringFormatIsNotPublished(t *testing.T) {
+	ctx := context.Background()
+	postRepo := repoMemory.NewInMemoryPostRepository()
+	drawRepo := repoMemory.NewInMemoryDrawRepository()
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	if err := postRepo.Create(ctx, p); err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	// LLM の応答を待つ間に管理者が投稿を削除する
+	formatter := &deletingFormatter{
+		StubFormatter: testutil.StubFormatter{
+			FormatResult:   &llm.FormatResult{DarkPostID: p.ID()},
+			ValidateResult: &llm.FormatResult{DarkPostID: p.ID(), Status: drawdomain.StatusVerified, FormattedContent: "formatted"},
+		},
+		postRepo: postRepo,
+	}
+	usecase := NewFormatPendingUsecase(postRepo, repoMemory.NewInMemoryFormatCompleter(postRepo, drawRepo), formatter, nil, nil)
+
+	err := usecase.Execute(ctx, "post-1")
+	if !errors.Is(err, ErrPostNotPending) {
+		t.Fatalf("expected ErrPostNotPending so the job is acked, got %v", err)
+	}
+	if stored, _ := postRepo.Get(ctx, p.ID()); stored.Status() != post.StatusDeleted {
+		t.Fatalf("deleted post must stay deleted, got %s", stored.Status())
+	}
+	if _, err := drawRepo.GetByPostID(ctx, p.ID()); !errors.Is(err, repository.ErrDrawNotFound) {
+		t.Fatalf("no draw should be published for a deleted post, got %v", err)
+	}
+}
+
+// deletingFormatter は整形の途中で投稿を削除済みにする整形器。
+type deletingFormatter struct {
+	testutil.StubFormatter
+	postRepo repository.PostRepository
+}
+
+func (f *deletingFormatter) Format(ctx context.Context, req *llm.FormatRequest) (*llm.FormatResult, error) {
+	stored, err := f.postRepo.Get(ctx, req.DarkPostID)
+	if err != nil {
+		return nil, err
+	}
+	if err := stored.MarkDeleted(); err != nil {
+		return nil, err
+	}
+	if err := f.postRepo.Update(ctx, stored); err != nil {
+		return nil, err
+	}
+	return f.StubFormatter.Format(ctx, req)
+}
+
 /**
- * 非対象ステータスなら LLM検証 Draw作成 / 再キュー / 投稿更新が一切走らない
+ * 非対象ステータスなら LLM検証 Draw作成 / 投稿更新が一切走らない
  */
 func TestFormatPendingUsecase_PostNotPending(t *testing.T) {
 	p, err := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
@@ -153,8 +419,7 @@ func TestFormatPendingUsecase_PostNotPending(t *testing.T) {
 			FormattedContent: "formatted",
 		},
 	}
-	jobQueue := &recordingJobQueue{}
-	usecase := NewFormatPendingUsecase(repo, drawRepo, formatter, jobQueue)
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), formatter, nil, nil)
 
 	err = usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, ErrPostNotPending) {
@@ -169,16 +434,13 @@ func TestFormatPendingUsecase_PostNotPending(t *testing.T) {
 	if len(drawRepo.Created) != 0 {
 		t.Fatalf("draw の作成が行われてはいけません")
 	}
-	if len(jobQueue.enqueued) != 0 {
-		t.Fatalf("再キューが行われてはいけません")
-	}
 	if repo.Updated != nil {
 		t.Fatalf("投稿更新が行われてはいけません")
 	}
 }
 
 func TestFormatPendingUsecase_EmptyPostID(t *testing.T) {
-	usecase := NewFormatPendingUsecase(testutil.NewStubPostRepository(nil), &testutil.StubDrawRepository{}, &testutil.StubFormatter{}, testutil.StubJobQueue{})
+	usecase := NewFormatPendingUsecase(testutil.NewStubPostRepository(nil), testutil.NewStubFormatCompleter(testutil.NewStubPostRepository(nil), &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, nil, nil)
 	if err := usecase.Execute(context.Background(), ""); !errors.Is(err, ErrEmptyPostID) {
 		t.Fatalf("expected ErrEmptyPostID, got %v", err)
 	}
@@ -194,25 +456,25 @@ func TestFormatPendingUsecase_NilUsecase(t *testing.T) {
 func TestFormatPendingUsecase_ValidationNotVerified(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
 		ValidateResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusRejected,
 			FormattedContent: "formatted",
 		},
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
-	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	if err := usecase.Execute(context.Background(), "post-1"); !errors.Is(err, ErrContentRejected) {
+		t.Fatalf("expected ErrContentRejected, got %v", err)
 	}
-	if repo.Updated != nil {
-		t.Fatalf("post should not be updated when not verified")
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusRejected {
+		t.Fatalf("post should be marked rejected when not verified")
 	}
 }
 
 func TestFormatPendingUsecase_NilContext(t *testing.T) {
-	usecase := NewFormatPendingUsecase(testutil.NewStubPostRepository(nil), &testutil.StubDrawRepository{}, &testutil.StubFormatter{}, testutil.StubJobQueue{})
+	usecase := NewFormatPendingUsecase(testutil.NewStubPostRepository(nil), testutil.NewStubFormatCompleter(testutil.NewStubPostRepository(nil), &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, nil, nil)
 
 	var nilCtx context.Context
 	if err := usecase.Execute(nilCtx, "post-1"); !errors.Is(err, ErrNilContext) {
@@ -224,9 +486,9 @@ func TestFormatPendingUsecase_FormatGenericError(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
 	expectedErr := errors.New("format failed")
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{
 		FormatErr: expectedErr,
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, expectedErr) {
@@ -238,10 +500,10 @@ func TestFormatPendingUsecase_ValidateGenericError(t *testing.T) {
 	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
 	repo := testutil.NewStubPostRepository(p)
 	expectedErr := errors.New("validate failed")
-	usecase := NewFormatPendingUsecase(repo, &testutil.StubDrawRepository{}, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
 		ValidateErr:  expectedErr,
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, expectedErr) {
@@ -255,29 +517,25 @@ func TestFormatPendingUsecase_DrawCreateFailed(t *testing.T) {
 	drawRepo := &testutil.StubDrawRepository{
 		CreateErr: errors.New("draw create failed"),
 	}
-	jobQueue := &recordingJobQueue{}
-	usecase := NewFormatPendingUsecase(repo, drawRepo, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
 		ValidateResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusVerified,
 			FormattedContent: "formatted",
 		},
-	}, jobQueue)
+	}, nil, nil)
 
 	err := usecase.Execute(context.Background(), "post-1")
 	if !errors.Is(err, ErrDrawCreationFailed) {
 		t.Fatalf("expected ErrDrawCreationFailed, got %v", err)
 	}
-	if repo.Updated != nil {
-		t.Fatalf("post should not be updated when draw creation fails")
+	if repo.UpdateCalls != 1 {
+		t.Fatalf("post should stay formatting when draw creation fails, got %d updates", repo.UpdateCalls)
 	}
 	if len(drawRepo.Created) != 0 {
 		t.Fatalf("draw should not be recorded when create fails")
 	}
-	if len(jobQueue.enqueued) != 1 || jobQueue.enqueued[0] != p.ID() {
-		t.Fatalf("expected post to be requeued once")
-	}
 }
 
 func TestFormatPendingUsecase_DrawContentTrimmedAndLimited(t *testing.T) {
@@ -286,7 +544,7 @@ func TestFormatPendingUsecase_DrawContentTrimmedAndLimited(t *testing.T) {
 	drawRepo := &testutil.StubDrawRepository{}
 	raw := drawdomain.FormattedContent("  \n" + strings.Repeat("運", maxDrawResultLength+5) + "  ")
 
-	usecase := NewFormatPendingUsecase(repo, drawRepo, &testutil.StubFormatter{
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusPending,
@@ -297,7 +555,7 @@ func TestFormatPendingUsecase_DrawContentTrimmedAndLimited(t *testing.T) {
 			Status:           drawdomain.StatusVerified,
 			FormattedContent: raw,
 		},
-	}, testutil.StubJobQueue{})
+	}, nil, nil)
 
 	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
 		t.Fatalf("execute returned error: %v", err)
@@ -314,53 +572,99 @@ func TestFormatPendingUsecase_DrawContentTrimmedAndLimited(t *testing.T) {
 	}
 }
 
-type recordingJobQueue struct {
-	enqueued   []post.DarkPostID
-	enqueueErr error
-}
+func TestFormatPendingUsecase_MarkFailed(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	repo := testutil.NewStubPostRepository(p)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, auditLog, nil)
 
-func (q *recordingJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error {
-	if q.enqueueErr != nil {
-		return q.enqueueErr
+	if err := usecase.MarkFailed(context.Background(), "post-1"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusFailed {
+		t.Fatalf("expected post to be marked failed")
 	}
-	q.enqueued = append(q.enqueued, id)
-	return nil
-}
-
-func (*recordingJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", queue.ErrQueueClosed
-}
 
-func (*recordingJobQueue) Close() error {
-	return nil
+	// 終端状態の投稿はそのまま
+	repo.Updated = nil
+	if err := usecase.MarkFailed(context.Background(), "post-1"); err != nil {
+		t.Fatalf("mark failed twice: %v", err)
+	}
+	if repo.Updated != nil {
+		t.Fatalf("terminal post should not be updated again")
+	}
+	assertAuditActions(t, auditLog, p.ID(), audit.ActionFormatFailed)
+	if err := usecase.MarkFailed(context.Background(), "unknown"); !errors.Is(err, ErrPostNotFound) {
+		t.Fatalf("expected ErrPostNotFound, got %v", err)
+	}
 }
 
-func TestFormatPendingUsecase_DrawCreateFailed_RequeueError(t *testing.T) {
-	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+func TestFormatPendingUsecase_ReplayedFailedPostIsReopened(t *testing.T) {
+	p, _ := post.Restore(post.DarkPostID("post-1"), post.DarkContent("test"), post.StatusFailed)
 	repo := testutil.NewStubPostRepository(p)
-	drawRepo := &testutil.StubDrawRepository{
-		CreateErr: errors.New("draw create failed"),
+	drawRepo := &testutil.StubDrawRepository{}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
+		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
+		ValidateResult: &llm.FormatResult{
+			DarkPostID:       p.ID(),
+			Status:           drawdomain.StatusVerified,
+			FormattedContent: "formatted",
+		},
+	}, nil, nil)
+
+	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
+		t.Fatalf("execute returned error: %v", err)
 	}
-	jobQueue := &recordingJobQueue{
-		enqueueErr: errors.New("queue down"),
+	if repo.Updated == nil || repo.Updated.Status() != post.StatusReady {
+		t.Fatalf("expected replayed post to become ready")
 	}
-	usecase := NewFormatPendingUsecase(repo, drawRepo, &testutil.StubFormatter{
+	if len(drawRepo.Created) != 1 {
+		t.Fatalf("expected draw to be created, got %d", len(drawRepo.Created))
+	}
+}
+
+/**
+ * 整形中のまま残った投稿はリース切れの再取得として処理を続行する
+ */
+func TestFormatPendingUsecase_ResumesFormattingPost(t *testing.T) {
+	p, _ := post.Restore(post.DarkPostID("post-1"), post.DarkContent("test"), post.StatusFormatting)
+	repo := testutil.NewStubPostRepository(p)
+	drawRepo := &testutil.StubDrawRepository{}
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, drawRepo), &testutil.StubFormatter{
 		FormatResult: &llm.FormatResult{DarkPostID: p.ID()},
 		ValidateResult: &llm.FormatResult{
 			DarkPostID:       p.ID(),
 			Status:           drawdomain.StatusVerified,
 			FormattedContent: "formatted",
 		},
-	}, jobQueue)
+	}, nil, nil)
 
-	err := usecase.Execute(context.Background(), "post-1")
-	if !errors.Is(err, ErrRequeueFailed) {
-		t.Fatalf("expected ErrRequeueFailed, got %v", err)
+	if err := usecase.Execute(context.Background(), "post-1"); err != nil {
+		t.Fatalf("execute returned error: %v", err)
 	}
-	if repo.Updated != nil {
-		t.Fatalf("post should not be updated when requeue fails")
+	if p.Status() != post.StatusReady {
+		t.Fatalf("expected resumed post to become ready, got %s", p.Status())
+	}
+	// 整形中への更新は不要なので最後の 1 回だけ
+	if repo.UpdateCalls != 1 {
+		t.Fatalf("expected single update, got %d", repo.UpdateCalls)
+	}
+}
+
+// assertAuditActions は監査ログに want の出来事がこの順で記録されていることを確かめる。
+func assertAuditActions(t *testing.T, auditLog *repoMemory.InMemoryAuditLog, id post.DarkPostID, want ...audit.Action) []*audit.Event {
+	t.Helper()
+	events, err := auditLog.ListByPost(context.Background(), id)
+	if err != nil {
+		t.Fatalf("list audit events: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d audit events, got %d", len(want), len(events))
 	}
-	if len(jobQueue.enqueued) != 0 {
-		t.Fatalf("requeue should not record success when enqueue fails")
+	for i, e := range events {
+		if e.Action() != want[i] {
+			t.Fatalf("audit event %d: expected %s, got %s", i, want[i], e.Action())
+		}
 	}
+	return events
 }
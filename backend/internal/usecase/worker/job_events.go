@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+)
+
+/**
+ * ワーカーが整形ジョブのリースを取ったことを監査ログへ残す。
+ */
+func (u *FormatPendingUsecase) RecordJobLeased(ctx context.Context, postID string) {
+	u.recordJobEvent(ctx, postID, audit.ActionJobLeased, nil)
+}
+
+/**
+ * 停止指示で試行回数を増やさずにリースを手放したことを監査ログへ残す。
+ */
+func (u *FormatPendingUsecase) RecordJobReleased(ctx context.Context, postID string) {
+	u.recordJobEvent(ctx, postID, audit.ActionJobReleased, nil)
+}
+
+/**
+ * 一時的な失敗で待機後の再試行へ回したことを、原因とあわせて監査ログへ残す。
+ */
+func (u *FormatPendingUsecase) RecordJobRetried(ctx context.Context, postID string, cause error) {
+	u.recordJobEvent(ctx, postID, audit.ActionJobRetried, causeDetails(cause))
+}
+
+/**
+ * 再試行の上限に達してジョブを隔離したことを、最後の原因とあわせて監査ログへ残す。
+ */
+func (u *FormatPendingUsecase) RecordJobDeadLettered(ctx context.Context, postID string, cause error) {
+	u.recordJobEvent(ctx, postID, audit.ActionJobDeadLettered, causeDetails(cause))
+}
+
+/**
+ * ジョブの移り変わりを投稿の出来事として残す。整形と同じく記録の失敗はジョブの失敗にしない。
+ */
+func (u *FormatPendingUsecase) recordJobEvent(ctx context.Context, postID string, action audit.Action, details map[string]string) {
+	if u == nil || ctx == nil || postID == "" {
+		return
+	}
+	u.recordAudit(ctx, post.DarkPostID(postID), action, details)
+}
+
+func causeDetails(cause error) map[string]string {
+	if cause == nil {
+		return nil
+	}
+	return map[string]string{"error": cause.Error()}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoMemory "backend/internal/adapter/repository/memory"
+	"backend/internal/domain/audit"
+	"backend/internal/domain/post"
+	"backend/internal/usecase/worker/testutil"
+)
+
+func TestFormatPendingUsecase_RecordsJobEvents(t *testing.T) {
+	p, _ := post.New(post.DarkPostID("post-1"), post.DarkContent("test"))
+	repo := testutil.NewStubPostRepository(p)
+	auditLog := repoMemory.NewInMemoryAuditLog()
+	usecase := NewFormatPendingUsecase(repo, testutil.NewStubFormatCompleter(repo, &testutil.StubDrawRepository{}), &testutil.StubFormatter{}, auditLog, nil)
+	ctx := context.Background()
+
+	cause := errors.New("llm unavailable")
+	usecase.RecordJobLeased(ctx, "post-1")
+	usecase.RecordJobReleased(ctx, "post-1")
+	usecase.RecordJobRetried(ctx, "post-1", cause)
+	usecase.RecordJobDeadLettered(ctx, "post-1", cause)
+	// 投稿 ID が無ければ記録しない
+	usecase.RecordJobLeased(ctx, "")
+
+	events := assertAuditActions(t, auditLog, p.ID(), audit.ActionJobLeased, audit.ActionJobReleased, audit.ActionJobRetried, audit.ActionJobDeadLettered)
+	for _, e := range events {
+		if e.Actor() != audit.Worker() {
+			t.Fatalf("job events should be recorded by the worker, got %+v", e.Actor())
+		}
+	}
+	if events[0].Details() != nil || events[2].Details()["error"] != cause.Error() || events[3].Details()["error"] != cause.Error() {
+		t.Fatalf("unexpected details: %v %v %v", events[0].Details(), events[2].Details(), events[3].Details())
+	}
+
+	// 監査ログが無くても記録を求めて失敗しない
+	var nilUsecase *FormatPendingUsecase
+	nilUsecase.RecordJobLeased(ctx, "post-1")
+	NewFormatPendingUsecase(repo, nil, nil, nil, nil).RecordJobRetried(ctx, "post-1", cause)
+}
@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+	"backend/internal/port/repository"
+)
+
+var (
+	ErrInvalidReconcileThreshold = errors.New("reconcile_pending: 経過時間のしきい値は 0 以上を指定してください")
+	ErrReconcileUnavailable      = errors.New("reconcile_pending: 整合性確認に必要な依存が設定されていません")
+)
+
+/**
+ * 整合性確認の条件
+ * OlderThan: 作成からこの時間を過ぎた pending / formatting 投稿だけを対象にする
+ * Limit: 1 回で確認する投稿の上限（0 以下なら無制限）
+ * DryRun: 修復や再投入を行わず、行う予定の内容だけを報告する
+ */
+type ReconcileInput struct {
+	OlderThan time.Duration
+	Limit     int
+	DryRun    bool
+}
+
+// 状態を直した投稿と、直した後の状態
+type RepairedPost struct {
+	PostID post.DarkPostID
+	Status post.Status
+}
+
+// 確認中に失敗した投稿と、その原因
+type ReconcileFailure struct {
+	PostID post.DarkPostID
+	Err    error
+}
+
+/**
+ * 整合性確認の結果
+ * Scanned: 確認した pending / formatting 投稿の件数
+ * Queued: 整形ジョブが残っていたため手を付けなかった件数
+ * Requeued: 整形ジョブが無かったため再投入した投稿
+ * Repaired: おみくじ結果があるのに pending / formatting のままだったため状態を直した投稿
+ * Failures: 確認や修復に失敗した投稿
+ */
+type ReconcileReport struct {
+	Scanned  int
+	Queued   int
+	Requeued []post.DarkPostID
+	Repaired []RepairedPost
+	Failures []ReconcileFailure
+}
+
+// クラッシュや書き込み途中の失敗で取り残された整形待ち・整形中の投稿を洗い出して直す。
+type ReconcilePendingUsecase struct {
+	scanner      repository.PendingPostScanner
+	postRepo     repository.PostRepository
+	drawRepo     repository.DrawRepository
+	jobQueue     queue.JobQueue
+	jobInspector queue.JobInspector
+	now          func() time.Time
+}
+
+// 依存をまとめて整合性確認用ユースケースを組み立てる。
+func NewReconcilePendingUsecase(
+	scanner repository.PendingPostScanner,
+	postRepo repository.PostRepository,
+	drawRepo repository.DrawRepository,
+	jobQueue queue.JobQueue,
+	jobInspector queue.JobInspector,
+) *ReconcilePendingUsecase {
+	return &ReconcilePendingUsecase{
+		scanner:      scanner,
+		postRepo:     postRepo,
+		drawRepo:     drawRepo,
+		jobQueue:     jobQueue,
+		jobInspector: jobInspector,
+		now:          time.Now,
+	}
+}
+
+// しきい値より古い pending / formatting 投稿ごとに posts / draws / format_jobs を突き合わせ、結果を報告する。
+// 個々の投稿での失敗は報告に積んで残りの確認を続ける。
+func (u *ReconcilePendingUsecase) Execute(ctx context.Context, in ReconcileInput) (*ReconcileReport, error) {
+	if u == nil {
+		return nil, ErrNilUsecase
+	}
+	if ctx == nil {
+		return nil, ErrNilContext
+	}
+	if u.scanner == nil || u.postRepo == nil || u.drawRepo == nil || u.jobQueue == nil || u.jobInspector == nil {
+		return nil, ErrReconcileUnavailable
+	}
+	if in.OlderThan < 0 {
+		return nil, ErrInvalidReconcileThreshold
+	}
+
+	posts, err := u.scanner.ListPendingBefore(ctx, u.now().Add(-in.OlderThan), in.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list pending posts: %w", err)
+	}
+
+	report := &ReconcileReport{Scanned: len(posts)}
+	for _, p := range posts {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := u.reconcile(ctx, p, in.DryRun, report); err != nil {
+			report.Failures = append(report.Failures, ReconcileFailure{PostID: p.ID(), Err: err})
+		}
+	}
+	return report, nil
+}
+
+/**
+ * 投稿 1 件を確認する。おみくじ結果があれば状態を直し、無ければ整形ジョブの有無を確かめて再投入する。
+ */
+func (u *ReconcilePendingUsecase) reconcile(ctx context.Context, p *post.Post, dryRun bool, report *ReconcileReport) error {
+	d, err := u.drawRepo.GetByPostID(ctx, p.ID())
+	if err != nil && !errors.Is(err, repository.ErrDrawNotFound) {
+		return fmt.Errorf("get draw: %w", err)
+	}
+	if d != nil {
+		if next, ok := repairedStatus(d); ok {
+			return u.repair(ctx, p, next, dryRun, report)
+		}
+	}
+
+	_, err = u.jobInspector.InspectFormat(ctx, p.ID())
+	if err == nil {
+		// 処理待ち・再試行待ち・隔離済みのいずれでもジョブがあればワーカーと運用者に任せる
+		report.Queued++
+		return nil
+	}
+	if !errors.Is(err, queue.ErrJobNotFound) {
+		return fmt.Errorf("inspect format job: %w", err)
+	}
+
+	if !dryRun {
+		err := u.jobQueue.EnqueueFormat(ctx, p.ID())
+		// 確認の直後にジョブが作られた場合は取り残されていなかったとみなす
+		if errors.Is(err, queue.ErrJobAlreadyScheduled) {
+			report.Queued++
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("enqueue format job: %w", err)
+		}
+	}
+	report.Requeued = append(report.Requeued, p.ID())
+	return nil
+}
+
+/**
+ * 保存済みのおみくじ結果に合わせて投稿の状態を進める。
+ */
+func (u *ReconcilePendingUsecase) repair(ctx context.Context, p *post.Post, next post.Status, dryRun bool, report *ReconcileReport) error {
+	var err error
+	switch next {
+	case post.StatusReady:
+		err = p.MarkReady()
+	case post.StatusRejected:
+		err = p.MarkRejected()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPostNotPending, err)
+	}
+	if !dryRun {
+		if err := u.postRepo.Update(ctx, p); err != nil {
+			return fmt.Errorf("update post: %w", err)
+		}
+	}
+	report.Repaired = append(report.Repaired, RepairedPost{PostID: p.ID(), Status: next})
+	return nil
+}
+
+/**
+ * おみくじ結果の状態から投稿が本来あるべき状態を返す。検証前の結果なら直さない。
+ */
+func repairedStatus(d *drawdomain.Draw) (post.Status, bool) {
+	switch d.Status() {
+	case drawdomain.StatusVerified:
+		return post.StatusReady, true
+	case drawdomain.StatusRejected:
+		return post.StatusRejected, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,169 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	queueMemory "backend/internal/adapter/queue/memory"
+	repoMemory "backend/internal/adapter/repository/memory"
+	drawdomain "backend/internal/domain/draw"
+	"backend/internal/domain/post"
+	"backend/internal/port/queue"
+)
+
+// reconcileFixture は取り残し方の異なる pending / formatting 投稿を並べた状態を作る。
+type reconcileFixture struct {
+	postRepo *repoMemory.InMemoryPostRepository
+	drawRepo *repoMemory.InMemoryDrawRepository
+	jobQueue *queueMemory.InMemoryJobQueue
+}
+
+func newReconcileFixture(t *testing.T) *reconcileFixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &reconcileFixture{
+		postRepo: repoMemory.NewInMemoryPostRepository(),
+		drawRepo: repoMemory.NewInMemoryDrawRepository(),
+		jobQueue: queueMemory.NewInMemoryJobQueue(0),
+	}
+	t.Cleanup(func() { _ = f.jobQueue.Close() })
+
+	for _, id := range []post.DarkPostID{"orphan", "queued", "verified", "rejected", "stuck"} {
+		p, _ := post.New(id, "闇")
+		// 整形を始めたワーカーが落ちてジョブも失われた投稿
+		if id == "stuck" {
+			_ = p.MarkFormatting()
+		}
+		if err := f.postRepo.Create(ctx, p); err != nil {
+			t.Fatalf("create post %s: %v", id, err)
+		}
+	}
+	if err := f.jobQueue.EnqueueFormat(ctx, "queued"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	verified, _ := drawdomain.New("verified", "大吉")
+	verified.MarkVerified()
+	rejected, _ := drawdomain.New("rejected", "凶")
+	rejected.MarkRejected("不適切")
+	for _, d := range []*drawdomain.Draw{verified, rejected} {
+		if err := f.drawRepo.Create(ctx, d); err != nil {
+			t.Fatalf("create draw: %v", err)
+		}
+	}
+	return f
+}
+
+func (f *reconcileFixture) usecase(inspector queue.JobInspector) *ReconcilePendingUsecase {
+	u := NewReconcilePendingUsecase(f.postRepo, f.postRepo, f.drawRepo, f.jobQueue, inspector)
+	// 作成直後の投稿もしきい値を過ぎたものとして扱う
+	u.now = func() time.Time { return time.Now().Add(time.Hour) }
+	return u
+}
+
+func TestReconcilePendingUsecase_RequeuesOrphansAndRepairsPosts(t *testing.T) {
+	f := newReconcileFixture(t)
+	ctx := context.Background()
+
+	report, err := f.usecase(f.jobQueue).Execute(ctx, ReconcileInput{OlderThan: 30 * time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Scanned != 5 || report.Queued != 1 || len(report.Failures) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	requeued := map[post.DarkPostID]bool{}
+	for _, id := range report.Requeued {
+		requeued[id] = true
+	}
+	if len(report.Requeued) != 2 || !requeued["orphan"] || !requeued["stuck"] {
+		t.Fatalf("orphan and stuck formatting posts should be requeued: %+v", report.Requeued)
+	}
+	for _, id := range []post.DarkPostID{"orphan", "stuck"} {
+		if _, err := f.jobQueue.InspectFormat(ctx, id); err != nil {
+			t.Fatalf("%s job should exist: %v", id, err)
+		}
+	}
+
+	want := map[post.DarkPostID]post.Status{"verified": post.StatusReady, "rejected": post.StatusRejected}
+	if len(report.Repaired) != len(want) {
+		t.Fatalf("unexpected repaired: %+v", report.Repaired)
+	}
+	for _, r := range report.Repaired {
+		if want[r.PostID] != r.Status {
+			t.Fatalf("unexpected repaired entry: %+v", r)
+		}
+		stored, _ := f.postRepo.Get(ctx, r.PostID)
+		if stored.Status() != r.Status {
+			t.Fatalf("post %s should be %s, got %s", r.PostID, r.Status, stored.Status())
+		}
+	}
+}
+
+func TestReconcilePendingUsecase_DryRunLeavesStateUntouched(t *testing.T) {
+	f := newReconcileFixture(t)
+	ctx := context.Background()
+
+	report, err := f.usecase(f.jobQueue).Execute(ctx, ReconcileInput{OlderThan: 30 * time.Minute, DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Requeued) != 2 || len(report.Repaired) != 2 {
+		t.Fatalf("dry run should still report planned work: %+v", report)
+	}
+	if _, err := f.jobQueue.InspectFormat(ctx, "orphan"); !errors.Is(err, queue.ErrJobNotFound) {
+		t.Fatalf("dry run should not enqueue, got %v", err)
+	}
+	stored, _ := f.postRepo.Get(ctx, "verified")
+	if stored.Status() != post.StatusPending {
+		t.Fatalf("dry run should not update posts, got %s", stored.Status())
+	}
+}
+
+func TestReconcilePendingUsecase_SkipsRecentPosts(t *testing.T) {
+	f := newReconcileFixture(t)
+
+	report, err := f.usecase(f.jobQueue).Execute(context.Background(), ReconcileInput{OlderThan: 2 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Scanned != 0 {
+		t.Fatalf("recent posts should be skipped: %+v", report)
+	}
+}
+
+func TestReconcilePendingUsecase_CollectsFailuresAndContinues(t *testing.T) {
+	f := newReconcileFixture(t)
+	inspector := &failingJobInspector{err: errors.New("firestore down")}
+
+	report, err := f.usecase(inspector).Execute(context.Background(), ReconcileInput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// ジョブ参照が必要な 3 件だけ失敗し、おみくじ結果のある 2 件は直せる
+	if len(report.Failures) != 3 || len(report.Repaired) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestReconcilePendingUsecase_InvalidInput(t *testing.T) {
+	f := newReconcileFixture(t)
+
+	if _, err := f.usecase(f.jobQueue).Execute(context.Background(), ReconcileInput{OlderThan: -time.Second}); !errors.Is(err, ErrInvalidReconcileThreshold) {
+		t.Fatalf("expected ErrInvalidReconcileThreshold, got %v", err)
+	}
+	u := NewReconcilePendingUsecase(nil, f.postRepo, f.drawRepo, f.jobQueue, f.jobQueue)
+	if _, err := u.Execute(context.Background(), ReconcileInput{}); !errors.Is(err, ErrReconcileUnavailable) {
+		t.Fatalf("expected ErrReconcileUnavailable, got %v", err)
+	}
+}
+
+// failingJobInspector は常に同じエラーを返す JobInspector。
+type failingJobInspector struct {
+	err error
+}
+
+func (f *failingJobInspector) InspectFormat(ctx context.Context, id post.DarkPostID) (*queue.FormatJobStatus, error) {
+	return nil, f.err
+}
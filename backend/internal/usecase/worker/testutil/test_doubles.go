@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"context"
+	"errors"
 
 	drawdomain "backend/internal/domain/draw"
 	"backend/internal/domain/post"
@@ -11,11 +12,14 @@ import (
 )
 
 // テストで投稿取得を差し替えるための簡易リポジトリ。
+// UpdateErrAfter を指定すると、その回数だけ更新に成功した後から UpdateErr を返す。
 type StubPostRepository struct {
-	Store     map[post.DarkPostID]*post.Post
-	GetErr    error
-	UpdateErr error
-	Updated   *post.Post
+	Store          map[post.DarkPostID]*post.Post
+	GetErr         error
+	UpdateErr      error
+	UpdateErrAfter int
+	UpdateCalls    int
+	Updated        *post.Post
 }
 
 /**
@@ -61,7 +65,8 @@ func (r *StubPostRepository) ListReady(ctx context.Context, limit int) ([]*post.
  * 更新内容を覚えて、必要ならエラーを返す。
  */
 func (r *StubPostRepository) Update(ctx context.Context, p *post.Post) error {
-	if r.UpdateErr != nil {
+	r.UpdateCalls++
+	if r.UpdateErr != nil && r.UpdateCalls > r.UpdateErrAfter {
 		return r.UpdateErr
 	}
 	r.Updated = p
@@ -94,6 +99,13 @@ func (StubDrawRepository) GetByPostID(ctx context.Context, postID post.DarkPostI
 	return nil, repository.ErrDrawNotFound
 }
 
+/**
+ * Update は既定で見つからない扱いにする。
+ */
+func (StubDrawRepository) Update(ctx context.Context, d *drawdomain.Draw) error {
+	return repository.ErrDrawNotFound
+}
+
 /**
  * ListReady は空を返す。
  */
@@ -101,8 +113,41 @@ func (StubDrawRepository) ListReady(ctx context.Context) ([]*drawdomain.Draw, er
 	return nil, nil
 }
 
+/**
+ * PickRandom は既定で見つからない扱いにする。
+ */
+func (StubDrawRepository) PickRandom(ctx context.Context, filter repository.DrawFilter) (*drawdomain.Draw, error) {
+	return nil, repository.ErrDrawNotFound
+}
+
 var _ repository.DrawRepository = (*StubDrawRepository)(nil)
 
+// おみくじ結果の保存と投稿の更新を順に委譲する簡易な FormatCompleter。
+// 保存に失敗した場合は投稿を更新しないため、テストでは書き込みがまとめて失敗したものとして扱える。
+type StubFormatCompleter struct {
+	PostRepo repository.PostRepository
+	DrawRepo repository.DrawRepository
+}
+
+/**
+ * 投稿とおみくじ結果の保存先を受け取ってスタブを返す。
+ */
+func NewStubFormatCompleter(postRepo repository.PostRepository, drawRepo repository.DrawRepository) *StubFormatCompleter {
+	return &StubFormatCompleter{PostRepo: postRepo, DrawRepo: drawRepo}
+}
+
+/**
+ * 保存済みのおみくじ結果は成功扱いにして、投稿の状態を更新する。
+ */
+func (c *StubFormatCompleter) Complete(ctx context.Context, p *post.Post, d *drawdomain.Draw) error {
+	if err := c.DrawRepo.Create(ctx, d); err != nil && !errors.Is(err, repository.ErrDrawAlreadyExists) {
+		return err
+	}
+	return c.PostRepo.Update(ctx, p)
+}
+
+var _ repository.FormatCompleter = (*StubFormatCompleter)(nil)
+
 // 整形と検証の結果を切り替えられるテスト用スタブ。
 type StubFormatter struct {
 	FormatResult   *llm.FormatResult
@@ -125,12 +170,13 @@ func (f *StubFormatter) Format(ctx context.Context, req *llm.FormatRequest) (*ll
 }
 
 /**
- * 設定された結果かエラーを返す。
+ * 設定された結果とエラーを返す。
  */
 func (f *StubFormatter) Validate(ctx context.Context, result *llm.FormatResult) (*llm.FormatResult, error) {
 	f.ValidateCalls++
+	// 実装と同じく、拒否時は判定結果とエラーを両方返す
 	if f.ValidateErr != nil {
-		return nil, f.ValidateErr
+		return f.ValidateResult, f.ValidateErr
 	}
 	return f.ValidateResult, nil
 }
@@ -150,8 +196,29 @@ func (StubJobQueue) EnqueueFormat(ctx context.Context, id post.DarkPostID) error
 /**
  * 閉鎖エラーを返す。
  */
-func (StubJobQueue) DequeueFormat(ctx context.Context) (post.DarkPostID, error) {
-	return "", queue.ErrQueueClosed
+func (StubJobQueue) DequeueFormat(ctx context.Context) (queue.Lease, error) {
+	return queue.Lease{}, queue.ErrQueueClosed
+}
+
+/**
+ * 常に nil を返す。
+ */
+func (StubJobQueue) AckFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+/**
+ * 常に nil を返す。
+ */
+func (StubJobQueue) NackFormat(ctx context.Context, lease queue.Lease) error {
+	return nil
+}
+
+/**
+ * 常に nil を返す。
+ */
+func (StubJobQueue) RetryFormat(ctx context.Context, lease queue.Lease, cause error) error {
+	return nil
 }
 
 /**
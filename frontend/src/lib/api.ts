@@ -15,3 +15,49 @@ export const getApiBaseUrl = () => {
  * API ベースURL末尾のスラッシュを除去して返す。
  */
 export const normalizeApiBaseUrl = () => getApiBaseUrl().replace(/\/+$/, "");
+
+const clientTokenHeader = "X-Client-Token";
+const clientTokenStorageKey = "dark-client-token";
+
+/**
+ * サーバーが発行した匿名クライアント ID のトークンを付けた fetch。
+ * Cookie を送れない環境でも本人の投稿を見分けられるよう、トークンはヘッダーでも受け渡す。
+ */
+export const fetchWithClientToken = async (
+  input: string,
+  init: RequestInit = {},
+) => {
+  const headers = new Headers(init.headers);
+  const stored = readClientToken();
+  if (stored) {
+    headers.set(clientTokenHeader, stored);
+  }
+
+  const response = await fetch(input, {
+    ...init,
+    headers,
+    credentials: "include",
+  });
+
+  const issued = response.headers.get(clientTokenHeader);
+  if (issued) {
+    writeClientToken(issued);
+  }
+  return response;
+};
+
+const readClientToken = () => {
+  try {
+    return window.localStorage.getItem(clientTokenStorageKey);
+  } catch {
+    return null;
+  }
+};
+
+const writeClientToken = (token: string) => {
+  try {
+    window.localStorage.setItem(clientTokenStorageKey, token);
+  } catch {
+    // 保存できなくても Cookie で識別できるため無視する
+  }
+};
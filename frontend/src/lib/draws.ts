@@ -1,12 +1,18 @@
-import type { DrawResponse } from "@/types/api";
+import type {
+  DrawResponse,
+  ReactionKind,
+  ReactionsResponse,
+  ReportReason,
+  ReportResponse,
+} from "@/types/api";
 import { getApiErrorMessageFromResponse } from "@/utils/api";
-import { normalizeApiBaseUrl } from "./api";
+import { fetchWithClientToken, normalizeApiBaseUrl } from "./api";
 
 /**
- * 検証済みのおみくじをランダムに取得する。
+ * 検証済みのおみくじをランダムに取得する。自分の投稿から作られたおみくじは出ない。
  */
 export const fetchRandomDraw = async (): Promise<DrawResponse> => {
-  const response = await fetch(`${normalizeApiBaseUrl()}/draws/random`);
+  const response = await fetchWithClientToken(`${normalizeApiBaseUrl()}/draws/random`);
 
   if (!response.ok) {
     const errorMessage = await getApiErrorMessageFromResponse(
@@ -18,3 +24,58 @@ export const fetchRandomDraw = async (): Promise<DrawResponse> => {
 
   return (await response.json()) as DrawResponse;
 };
+
+/**
+ * おみくじへリアクションを送り、送った後の件数を返す。同じ種類を何度送っても 1 回だけ数えられる。
+ */
+export const postDrawReaction = async (
+  postId: string,
+  kind: ReactionKind,
+): Promise<ReactionsResponse> => {
+  const response = await fetchWithClientToken(
+    `${normalizeApiBaseUrl()}/draws/${encodeURIComponent(postId)}/reactions`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ kind }),
+    },
+  );
+
+  if (!response.ok) {
+    const errorMessage = await getApiErrorMessageFromResponse(
+      response,
+      "リアクションの送信に失敗しました",
+    );
+    throw new Error(errorMessage);
+  }
+
+  return (await response.json()) as ReactionsResponse;
+};
+
+/**
+ * おみくじを通報する。通報が一定数に達したおみくじは公開が止まる。
+ */
+export const postDrawReport = async (
+  postId: string,
+  reason: ReportReason,
+  note?: string,
+): Promise<ReportResponse> => {
+  const response = await fetchWithClientToken(
+    `${normalizeApiBaseUrl()}/draws/${encodeURIComponent(postId)}/reports`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ reason, note }),
+    },
+  );
+
+  if (!response.ok) {
+    const errorMessage = await getApiErrorMessageFromResponse(
+      response,
+      "通報の送信に失敗しました",
+    );
+    throw new Error(errorMessage);
+  }
+
+  return (await response.json()) as ReportResponse;
+};
@@ -1,24 +1,27 @@
 import type { CreatePostRequest, CreatePostResponse } from "@/types/api";
 import { getApiErrorMessage } from "@/utils/api";
-import { normalizeApiBaseUrl } from "./api";
+import { fetchWithClientToken, normalizeApiBaseUrl } from "./api";
 
 /**
- * 闇投稿を登録する。
+ * 闇投稿を登録する。投稿 ID はサーバーが払い出し、レスポンスで返る。
+ * 同じ Idempotency-Key で同じ内容を再送すると、二重投稿にならず同じ投稿 ID が返る。
  */
-export const createPost = async (content: string) => {
-  const postId = crypto.randomUUID();
+export const createPost = async (
+  content: string,
+  idempotencyKey: string = crypto.randomUUID(),
+) => {
   const payload: CreatePostRequest = {
-    post_id: postId,
     content,
   };
 
   const controller = new AbortController();
   const timeoutId = window.setTimeout(() => controller.abort(), 10_000);
 
-  const response = await fetch(`${normalizeApiBaseUrl()}/posts`, {
+  const response = await fetchWithClientToken(`${normalizeApiBaseUrl()}/posts`, {
     method: "POST",
     headers: {
       "Content-Type": "application/json",
+      "Idempotency-Key": idempotencyKey,
     },
     body: JSON.stringify(payload),
     signal: controller.signal,
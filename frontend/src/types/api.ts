@@ -3,7 +3,6 @@ export type ApiErrorResponse = {
 };
 
 export type CreatePostRequest = {
-  post_id: string;
   content: string;
 };
 
@@ -11,8 +10,24 @@ export type CreatePostResponse = {
   post_id: string;
 };
 
+export type ReactionKind = "saved" | "laughed" | "pierced";
+
+export type ReactionCounts = Record<ReactionKind, number>;
+
 export type DrawResponse = {
   post_id: string;
   result: string;
   status: string;
+  reactions: ReactionCounts;
+};
+
+export type ReactionsResponse = {
+  post_id: string;
+  reactions: ReactionCounts;
+};
+
+export type ReportReason = "abusive" | "personal_info" | "self_harm" | "spam" | "other";
+
+export type ReportResponse = {
+  post_id: string;
 };